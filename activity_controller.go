@@ -36,10 +36,27 @@ type activityModel struct {
 	Start       string         `json:"start" validate:"required"`
 	End         string         `json:"end" validate:"required"`
 	Description string         `json:"description" validate:"max=500"`
+	Tags        []string       `json:"tags" validate:"max=10,dive,min=1,max=50"`
+	Billable    *bool          `json:"billable"`
 	Duration    *durationModel `json:"duration"`
+	Overlaps    []string       `json:"overlaps,omitempty"`
 	Links       *hal.Links     `json:"_links"`
 }
 
+type activityImportModel struct {
+	Imported    bool                      `json:"imported"`
+	Rows        []*activityImportRowModel `json:"rows"`
+	NewProjects []string                  `json:"newProjects"`
+	Links       *hal.Links                `json:"_links"`
+}
+
+type activityImportRowModel struct {
+	Line     int            `json:"line"`
+	Project  string         `json:"project"`
+	Errors   []string       `json:"errors"`
+	Activity *activityModel `json:"activity,omitempty"`
+}
+
 type durationModel struct {
 	Hours     int     `json:"hours"`
 	Minutes   int     `json:"minutes"`
@@ -56,7 +73,7 @@ func (a *app) HandleGetActivities() http.HandlerFunc {
 		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 		pageParams := paged.PageParamsOf(r)
 
-		filter, err := filterFromQueryParams(r.URL.Query())
+		filter, err := filterFromQueryParams(r.URL.Query(), principal.Location())
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, errors.New("invalid query params"))
 			return
@@ -89,7 +106,13 @@ func (a *app) HandleGetActivities() http.HandlerFunc {
 		}
 
 		activityModels := mapToActivityModels(activitiesPage.Activities)
-		projectModels := mapToProjectModels(principal, projects)
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		projectModels := mapToProjectModels(permissions, projects)
 
 		activitiesModel := &activitiesModel{
 			EmbeddedActivities: &EmbeddedActivities{
@@ -124,21 +147,49 @@ func (a *app) HandleCreateActivity() http.HandlerFunc {
 			return
 		}
 
-		activityToCreate, err := mapToActivity(&activityModel)
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		activityToCreate, err := mapToActivity(&activityModel, principal.Location())
+		if errors.Is(err, ErrActivityEndNotAfterStart) {
+			http.Error(w, problem.New(problem.Title("activity end must be after start")).JSONString(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
 			return
 		}
 
-		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+		// overlaps are only reported, the service rejects them if configured
+		overlaps, err := a.ReadOverlappingActivities(r.Context(), principal, activityToCreate)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
 
 		activity, err := a.CreateActivity(r.Context(), principal, activityToCreate)
+		if errors.Is(err, ErrActivityOverlaps) {
+			http.Error(w, problem.New(problem.Title("activity overlaps with other activities")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrActivityLocked) {
+			http.Error(w, problem.New(problem.Title("activity is locked by an approved timesheet")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrPeriodLocked) {
+			http.Error(w, problem.New(problem.Title("activity is in a closed period")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrProjectNotAssigned) {
+			http.Error(w, problem.New(problem.Title("not assigned to project")).JSONString(), http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
 		activityModelCreated := mapToActivityModel(activity)
+		activityModelCreated.Overlaps = mapToActivityIDs(overlaps)
 
 		w.WriteHeader(http.StatusCreated)
 		util.RenderJSON(w, activityModelCreated)
@@ -158,7 +209,7 @@ func (a *app) HandleGetActivity() http.HandlerFunc {
 			return
 		}
 
-		activity, err := a.ActivityRepository.FindActivityByID(r.Context(), activityID, principal.OrganizationID)
+		activity, err := a.ReadActivity(r.Context(), principal, activityID)
 		if errors.Is(err, ErrActivityNotFound) {
 			http.Error(w, problem.New(problem.Title("activity not found")).JSONString(), http.StatusNotFound)
 			return
@@ -191,6 +242,14 @@ func (a *app) HandleDeleteActivity() http.HandlerFunc {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, ErrActivityLocked) {
+			http.Error(w, problem.New(problem.Title("activity is locked by an approved timesheet")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrPeriodLocked) {
+			http.Error(w, problem.New(problem.Title("activity is in a closed period")).JSONString(), http.StatusConflict)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
@@ -221,7 +280,11 @@ func (a *app) HandleUpdateActivity() http.HandlerFunc {
 			return
 		}
 
-		activity, err := mapToActivity(&activityModel)
+		activity, err := mapToActivity(&activityModel, principal.Location())
+		if errors.Is(err, ErrActivityEndNotAfterStart) {
+			http.Error(w, problem.New(problem.Title("activity end must be after start")).JSONString(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
 			return
@@ -234,22 +297,173 @@ func (a *app) HandleUpdateActivity() http.HandlerFunc {
 		}
 		activity.ID = activityID
 
+		// overlaps are only reported, the service rejects them if configured
+		overlaps, err := a.ReadOverlappingActivities(r.Context(), principal, activity)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
 		activityUpdate, err := a.UpdateActivity(r.Context(), principal, activity)
 		if errors.Is(err, ErrActivityNotFound) {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, ErrActivityOverlaps) {
+			http.Error(w, problem.New(problem.Title("activity overlaps with other activities")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrProjectNotAssigned) {
+			http.Error(w, problem.New(problem.Title("not assigned to project")).JSONString(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, ErrActivityLocked) {
+			http.Error(w, problem.New(problem.Title("activity is locked by an approved timesheet")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrPeriodLocked) {
+			http.Error(w, problem.New(problem.Title("activity is in a closed period")).JSONString(), http.StatusConflict)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
 		activityModelUpdate := mapToActivityModel(activityUpdate)
+		activityModelUpdate.Overlaps = mapToActivityIDs(overlaps)
 		util.RenderJSON(w, activityModelUpdate)
 	}
 }
 
-func mapToActivity(activityModel *activityModel) (*Activity, error) {
+// HandleImportActivities imports activities from an uploaded CSV or Excel file
+func (a *app) HandleImportActivities() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		records, err := readActivityImportUpload(w, r)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("activity import not valid"), problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		createProjects := r.URL.Query().Get("createProjects") == "true"
+
+		var activityImport *ActivityImport
+		if r.URL.Query().Get("dryRun") == "true" {
+			activityImport, err = a.PreviewActivityImport(r.Context(), principal, records, createProjects)
+		} else {
+			activityImport, err = a.ImportActivities(r.Context(), principal, records, createProjects)
+		}
+		if errors.Is(err, ErrActivityImportFormatInvalid) || errors.Is(err, ErrActivityImportTooLarge) {
+			http.Error(w, problem.New(problem.Title("activity import not valid"), problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		activityImportModel := mapToActivityImportModel(activityImport)
+		activityImportModel.Links = hal.NewLinks(
+			hal.NewSelfLink(r.RequestURI),
+		)
+
+		switch {
+		case activityImport.Imported:
+			w.Header().Set("HX-Trigger", "baralga__activities-changed")
+			w.WriteHeader(http.StatusCreated)
+		case !activityImport.Valid():
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		util.RenderJSON(w, activityImportModel)
+	}
+}
+
+// readActivityImportUpload reads the records of the CSV or Excel file uploaded as multipart form field file
+func readActivityImportUpload(w http.ResponseWriter, r *http.Request) ([][]string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxActivityImportSize)
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	format := ActivityImportFormatCSV
+	if strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".xlsx") {
+		format = ActivityImportFormatExcel
+	}
+
+	return ReadActivityImportRecords(file, format)
+}
+
+func mapToActivityImportModel(activityImport *ActivityImport) *activityImportModel {
+	activityImportModel := &activityImportModel{
+		Imported:    activityImport.Imported,
+		Rows:        make([]*activityImportRowModel, len(activityImport.Rows)),
+		NewProjects: make([]string, len(activityImport.NewProjects)),
+	}
+
+	for i, row := range activityImport.Rows {
+		rowModel := &activityImportRowModel{
+			Line:    row.Line,
+			Project: row.ProjectTitle,
+			Errors:  row.Errors,
+		}
+		if row.Valid() {
+			rowModel.Activity = mapToActivityModel(row.Activity)
+		}
+		activityImportModel.Rows[i] = rowModel
+	}
+
+	for i, project := range activityImport.NewProjects {
+		activityImportModel.NewProjects[i] = project.Title
+	}
+
+	return activityImportModel
+}
+
+// HandleGetCalendarFeed renders the activities of the user of the secret calendar feed url as iCalendar
+func (a *app) HandleGetCalendarFeed() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret := chi.URLParam(r, "calendar-token")
+
+		principal, err := a.AuthenticateCalendarFeed(r.Context(), secret)
+		if errors.Is(err, ErrCalendarFeedNotFound) {
+			http.Error(w, "Calendar feed not found.", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		filter, err := filterFromQueryParams(r.URL.Query(), principal.Location())
+		if err != nil {
+			http.Error(w, "Invalid filter.", http.StatusBadRequest)
+			return
+		}
+
+		activities, projects, err := a.ReadCalendarFeed(r.Context(), principal, filter)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", "inline; filename=\"baralga.ics\"")
+		err = a.WriteAsICS(activities, projects, w)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+	}
+}
+
+func mapToActivity(activityModel *activityModel, loc *time.Location) (*Activity, error) {
 	var activityID uuid.UUID
 
 	if activityModel.ID != "" {
@@ -260,12 +474,12 @@ func mapToActivity(activityModel *activityModel) (*Activity, error) {
 		activityID = aID
 	}
 
-	start, err := util.ParseDateTime(activityModel.Start)
+	start, err := util.ParseDateTimeIn(activityModel.Start, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	end, err := util.ParseDateTime(activityModel.End)
+	end, err := util.ParseDateTimeIn(activityModel.End, loc)
 	if err != nil {
 		return nil, err
 	}
@@ -276,21 +490,37 @@ func mapToActivity(activityModel *activityModel) (*Activity, error) {
 		return nil, err
 	}
 
+	// activities are billable unless stated otherwise
+	billable := true
+	if activityModel.Billable != nil {
+		billable = *activityModel.Billable
+	}
+
 	activity := &Activity{
 		ID:          activityID,
 		Start:       *start,
 		End:         *end,
 		ProjectID:   projectID,
 		Description: activityModel.Description,
+		Tags:        NormalizeTags(activityModel.Tags),
+		Billable:    billable,
+	}
+
+	err = activity.Validate()
+	if err != nil {
+		return nil, err
 	}
 
 	return activity, nil
 }
 
 func mapToActivityModel(activity *Activity) *activityModel {
+	billable := activity.Billable
 	return &activityModel{
 		ID:          activity.ID.String(),
 		Description: activity.Description,
+		Tags:        activity.Tags,
+		Billable:    &billable,
 		Start:       util.FormatDateTime(activity.Start),
 		End:         util.FormatDateTime(activity.End),
 		Links: hal.NewLinks(
@@ -319,18 +549,33 @@ func mapToActivityModels(activities []*Activity) []*activityModel {
 	return activityModels
 }
 
-func mapToProjectModels(principal *Principal, projects []*Project) []*projectModel {
+func mapToActivityIDs(activities []*Activity) []string {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	activityIDs := make([]string, len(activities))
+	for i, activity := range activities {
+		activityIDs[i] = activity.ID.String()
+	}
+
+	return activityIDs
+}
+
+func mapToProjectModels(permissions *Permissions, projects []*Project) []*projectModel {
 	activityModels := make([]*projectModel, len(projects))
 
 	for i, project := range projects {
-		projectModel := mapToProjectModel(principal, project)
+		projectModel := mapToProjectModel(permissions, project)
 		activityModels[i] = projectModel
 	}
 
 	return activityModels
 }
 
-func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
+// filterFromQueryParams reads the activity filter from the query params, the timespan
+// defaults to the current week in the location
+func filterFromQueryParams(params url.Values, loc *time.Location) (*ActivityFilter, error) {
 	if len(params["t"]) == 0 {
 		params["t"] = []string{"week"}
 	}
@@ -359,6 +604,34 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 		sortOrder: sortOrder,
 	}
 
+	if len(params["tag"]) != 0 {
+		filter.tag = NormalizeTag(params["tag"][0])
+	}
+
+	filter.splitAtMidnight = params.Get("split") == "midnight"
+
+	for _, projectParam := range params["project"] {
+		if projectParam == "" {
+			continue
+		}
+		projectID, err := uuid.Parse(projectParam)
+		if err != nil {
+			return nil, err
+		}
+		filter.projectIDs = append(filter.projectIDs, projectID)
+	}
+
+	for _, userParam := range params["user"] {
+		if userParam == "" {
+			continue
+		}
+		filter.usernames = append(filter.usernames, userParam)
+	}
+
+	if len(params["description"]) != 0 {
+		filter.description = strings.TrimSpace(params["description"][0])
+	}
+
 	if timespan == TimespanCustom && len(params["start"]) == 0 && len(params["end"]) == 0 {
 		return nil, errors.New("missing timespan value")
 	}
@@ -366,12 +639,12 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 	if len(params["v"]) != 0 {
 		value = params["v"][0]
 	} else {
-		value = filter.NewValue()
+		value = filter.NewValue(loc)
 	}
 
 	switch timespan {
 	case TimespanYear:
-		start, err := time.Parse("2006", value)
+		start, err := time.ParseInLocation("2006", value, loc)
 		if err != nil {
 			return nil, err
 		}
@@ -381,7 +654,7 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 			return nil, errors.New("invalid quarter")
 		}
 		valueParts := strings.Split(value, "-")
-		start, err := time.Parse("2006", valueParts[0])
+		start, err := time.ParseInLocation("2006", valueParts[0], loc)
 		if err != nil {
 			return nil, err
 		}
@@ -395,7 +668,7 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 		}
 		filter.start = start.AddDate(0, 3*(startQuarterOfYear-1), 0)
 	case TimespanMonth:
-		start, err := time.Parse("2006-01", value)
+		start, err := time.ParseInLocation("2006-01", value, loc)
 		if err != nil {
 			return nil, err
 		}
@@ -415,9 +688,9 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 			return nil, err
 		}
 
-		filter.start = isoweek.StartTime(startYear, startWeekOfYear, time.UTC)
+		filter.start = isoweek.StartTime(startYear, startWeekOfYear, loc)
 	case TimespanDay:
-		start, err := time.Parse("2006-01-02", value)
+		start, err := time.ParseInLocation("2006-01-02", value, loc)
 		if err != nil {
 			return nil, err
 		}
@@ -429,7 +702,7 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 			if err != nil {
 				return nil, err
 			}
-			filter.start = *startParam
+			filter.start = util.WallClockIn(*startParam, loc)
 		}
 
 		endParamValue := params.Get("end")
@@ -438,7 +711,7 @@ func filterFromQueryParams(params url.Values) (*ActivityFilter, error) {
 			if err != nil {
 				return nil, err
 			}
-			filter.end = *endParam
+			filter.end = util.WallClockIn(*endParam, loc)
 		}
 	default:
 		return nil, errors.New("invalid activity filter")
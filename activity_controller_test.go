@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +15,7 @@ import (
 	"time"
 
 	"github.com/baralga/hal"
+	"github.com/baralga/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/matryer/is"
@@ -22,19 +27,73 @@ func TestMapToActivity(t *testing.T) {
 	activityModel := &activityModel{
 		ID:    "d9fbfab6-2750-4703-8a7b-77498756d64a",
 		Start: "2021-11-06T21:37:00",
-		End:   "2021-11-06T21:37:00",
+		End:   "2021-11-06T22:37:00",
 		Links: hal.NewLinks(
 			hal.NewLink("project", "/api/projects/efa45cae-5dc7-412a-887f-945ddbb0a23f"),
 		),
 	}
 
-	activity, err := mapToActivity(activityModel)
+	activity, err := mapToActivity(activityModel, time.UTC)
 
 	is.NoErr(err)
 	is.Equal(activityModel.ID, activity.ID.String())
 	is.Equal(uuid.MustParse("efa45cae-5dc7-412a-887f-945ddbb0a23f"), activity.ProjectID)
 	is.Equal(2021, activity.Start.Year())
 	is.Equal(2021, activity.End.Year())
+	is.True(activity.Billable)
+}
+
+func TestMapToActivityWithTags(t *testing.T) {
+	is := is.New(t)
+
+	activityModel := &activityModel{
+		Start: "2021-11-06T21:37:00",
+		End:   "2021-11-06T22:37:00",
+		Tags:  []string{"Meeting", " support", "meeting"},
+		Links: hal.NewLinks(
+			hal.NewLink("project", "/api/projects/efa45cae-5dc7-412a-887f-945ddbb0a23f"),
+		),
+	}
+
+	activity, err := mapToActivity(activityModel, time.UTC)
+
+	is.NoErr(err)
+	is.Equal([]string{"meeting", "support"}, activity.Tags)
+}
+
+func TestMapToActivitySpanningSeveralDays(t *testing.T) {
+	is := is.New(t)
+
+	activityModel := &activityModel{
+		Start: "2021-11-06T21:37:00",
+		End:   "2021-11-09T09:37:00",
+		Links: hal.NewLinks(
+			hal.NewLink("project", "/api/projects/efa45cae-5dc7-412a-887f-945ddbb0a23f"),
+		),
+	}
+
+	activity, err := mapToActivity(activityModel, time.UTC)
+
+	is.NoErr(err)
+	is.Equal(activity.DurationMinutesTotal(), 60*60)
+}
+
+func TestMapToActivityEndNotAfterStart(t *testing.T) {
+	is := is.New(t)
+
+	for _, end := range []string{"2021-11-06T21:37:00", "2021-11-06T20:37:00"} {
+		activityModel := &activityModel{
+			Start: "2021-11-06T21:37:00",
+			End:   end,
+			Links: hal.NewLinks(
+				hal.NewLink("project", "/api/projects/efa45cae-5dc7-412a-887f-945ddbb0a23f"),
+			),
+		}
+
+		_, err := mapToActivity(activityModel, time.UTC)
+
+		is.True(errors.Is(err, ErrActivityEndNotAfterStart))
+	}
 }
 
 func TestMapToActivityIdNotValid(t *testing.T) {
@@ -43,13 +102,13 @@ func TestMapToActivityIdNotValid(t *testing.T) {
 	activityModel := &activityModel{
 		ID:    "no-uuid",
 		Start: "2021-11-06T21:37:00",
-		End:   "2021-11-06T21:37:00",
+		End:   "2021-11-06T22:37:00",
 		Links: hal.NewLinks(
 			hal.NewLink("project", "/api/projects/efa45cae-5dc7-412a-887f-945ddbb0a23f"),
 		),
 	}
 
-	_, err := mapToActivity(activityModel)
+	_, err := mapToActivity(activityModel, time.UTC)
 
 	is.True(err != nil)
 }
@@ -224,9 +283,14 @@ func TestHandleCreateActivity(t *testing.T) {
 
 	repo := NewInMemActivityRepository()
 	a := &app{
-		Config:             &config{},
-		RepositoryTxer:     NewInMemRepositoryTxer(),
-		ActivityRepository: repo,
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
 	}
 
 	countBefore := len(repo.activities)
@@ -234,7 +298,7 @@ func TestHandleCreateActivity(t *testing.T) {
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description":"",
 		"_links":{
 		   "project":{
@@ -245,13 +309,132 @@ func TestHandleCreateActivity(t *testing.T) {
 	`
 
 	r, _ := http.NewRequest("POST", "/api/activities", strings.NewReader(body))
-	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+	}))
 
 	a.HandleCreateActivity()(httpRec, r)
 	is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
 	is.Equal(countBefore+1, len(repo.activities))
 }
 
+func TestHandleCreateActivityInClosedPeriod(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	organizationRepository := NewInMemOrganizationRepository()
+	lockedUntil := time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC)
+	organizationRepository.organizations[0].LockedUntil = &lockedUntil
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: organizationRepository,
+	}
+
+	countBefore := len(repo.activities)
+	body := `
+	{
+		"id":null,
+		"start":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
+		"description":"",
+		"_links":{
+		   "project":{
+			  "href":"http://localhost:8080/api/projects/f4b1087c-8fbb-4c8d-bbb7-ab4d46da16ea"
+		   }
+		}
+	 }
+	`
+
+	r, _ := http.NewRequest("POST", "/api/activities", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleCreateActivity()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+	is.Equal(countBefore, len(repo.activities))
+}
+
+func TestHandleCreateActivityWithOverlap(t *testing.T) {
+	is := is.New(t)
+
+	body := `
+	{
+		"start":"2021-11-06T09:30:00",
+		"end":"2021-11-06T10:30:00",
+		"description":"",
+		"_links":{
+		   "project":{
+			  "href":"http://localhost:8080/api/projects/f4b1087c-8fbb-4c8d-bbb7-ab4d46da16ea"
+		   }
+		}
+	 }
+	`
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	t.Run("Warning", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+
+		repo := NewInMemActivityRepository()
+		repo.activities[0].Start = time.Date(2021, 11, 6, 9, 0, 0, 0, time.UTC)
+		repo.activities[0].End = time.Date(2021, 11, 6, 10, 0, 0, 0, time.UTC)
+		a := &app{
+			Config:                 &config{},
+			RepositoryTxer:         NewInMemRepositoryTxer(),
+			ActivityRepository:     repo,
+			ProjectRepository:      NewInMemProjectRepository(),
+			OrganizationRepository: NewInMemOrganizationRepository(),
+			AuditLogRepository:     NewInMemAuditLogRepository(),
+			WebhookRepository:      NewInMemWebhookRepository(),
+			TimesheetRepository:    NewInMemTimesheetRepository(),
+		}
+
+		r, _ := http.NewRequest("POST", "/api/activities", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleCreateActivity()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+		is.Equal(len(repo.activities), 2)
+
+		var activityModel activityModel
+		err := json.NewDecoder(httpRec.Body).Decode(&activityModel)
+		is.NoErr(err)
+		is.Equal(activityModel.Overlaps, []string{repo.activities[0].ID.String()})
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+
+		repo := NewInMemActivityRepository()
+		repo.activities[0].Start = time.Date(2021, 11, 6, 9, 0, 0, 0, time.UTC)
+		repo.activities[0].End = time.Date(2021, 11, 6, 10, 0, 0, 0, time.UTC)
+		a := &app{
+			Config:                 &config{RejectOverlaps: true},
+			RepositoryTxer:         NewInMemRepositoryTxer(),
+			ActivityRepository:     repo,
+			ProjectRepository:      NewInMemProjectRepository(),
+			OrganizationRepository: NewInMemOrganizationRepository(),
+			TimesheetRepository:    NewInMemTimesheetRepository(),
+		}
+
+		r, _ := http.NewRequest("POST", "/api/activities", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleCreateActivity()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+		is.Equal(len(repo.activities), 1)
+	})
+}
+
 func TestHandleCreateInvalidActivity(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -266,7 +449,7 @@ func TestHandleCreateInvalidActivity(t *testing.T) {
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description":"Lorem ipsum dolor sit amet, consectetuer adipiscing elit. Aenean commodo ligula eget dolor. Aenean massa. Cum sociis natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Donec quam felis, ultricies nec, pellentesque eu, pretium quis, sem. Nulla consequat massa quis enim. Donec pede justo, fringilla vel, aliquet nec, vulputate eget, arcu. In enim justo, rhoncus ut, imperdiet a, venenatis vitae, justo. Nullam dictum felis eu pede mollis pretium. Integer tincidunt. Cras dapibus. Vivamus elementum semper nisi. Aenean vulputate eleifend tellus. Aenean leo ligula, porttitor eu, consequat vitae, eleifend ac, enim. Aliquam lorem ante, dapibus in, viverra quis, feugiat a, tellus. Phasellus viverra nulla ut metus varius laoreet. Quisque rutrum. Aenean imperdiet. Etiam ultricies nisi vel augue. Curabitur ullamcorper ultricies nisi. Nam eget dui. Etiam rhoncus. Maecenas tempus, tellus eget condimentum rhoncus, sem quam semper libero, sit amet adipiscing sem neque sed ipsum. Nam quam nunc, blandit vel, luctus pulvinar, hendrerit id, lorem. Maecenas nec odio et ante tincidunt tempus. Donec vitae sapien ut libero venenatis faucibus. Nullam quis ante. Etiam sit amet orci eget eros faucibus tincidunt. Duis leo. Sed fringilla mauris sit amet nibh. Donec sodales sagittis magna. Sed consequat, leo eget bibendum sodales, augue velit cursus nunc, quis gravida magna mi a libero. Fusce vulputate eleifend sapien. Vestibulum purus quam, scelerisque ut, mollis sed, nonummy id, metus. Nullam accumsan lorem in dui. Cras ultricies mi eu turpis hendrerit fringilla. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; In ac dui quis mi consectetuer lacinia. Nam pretium turpis et arcu. Duis arcu tortor, suscipit eget, imperdiet nec, imperdiet iaculis, ipsum. Sed aliquam ultrices mauris. Integer ante arcu, accumsan a, consectetuer eget, posuere ut, mauris. Praesent adipiscing. Phasellus ullamcorper ipsum rutrum nunc. Nunc nonummy metus. Vestibulum volutpat pretium libero. Cras id dui. Aenean ut eros et nisl sagittis vestibulum. Nullam nulla eros, ultricies sit amet, nonummy id, imperdiet feugiat, pede. Sed lectus. Donec mollis hendrerit risus. Phasellus nec sem in justo pellentesque facilisis. Etiam imperdiet imperdiet orci. Nunc nec neque. Phasellus leo dolor, tempus non, auctor et, hendrerit quis, nisi. Curabitur ligula sapien, tincidunt non, euismod vitae, posuere imperdiet, leo. Maecenas malesuada. Praesent congue erat at massa. Sed cursus turpis vitae tortor. Donec posuere vulputate arcu. Phasellus accumsan cursus velit. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Sed aliquam, nisi quis porttitor congue, elit erat euismod orci, ac placerat dolor lectus quis orci. Phasellus consectetuer vestibulum elit. Aenean tellus metus, bibendum sed, posuere ac, mattis non, nunc. Vestibulum fringilla pede sit amet augue. In turpis. Pellentesque posuere. Praesent turpis. Aenean posuere, tortor sed cursus feugiat, nunc augue blandit nunc, eu sollicitudin urna dolor sagittis lacus. Donec elit libero, sodales nec, volutpat a, suscipit non, turpis. Nullam sagittis. Suspendisse pulvinar, augue ac venenatis condimentum, sem libero volutpat nibh, nec pellentesque velit pede quis nunc. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Fusce id purus. Ut varius tincidunt libero. Phasellus dolor. Maecenas vestibulum mollis diam. ",
 		"_links":{
 		   "project":{
@@ -289,9 +472,12 @@ func TestHandleDeleteActivityAsAdmin(t *testing.T) {
 
 	repo := NewInMemActivityRepository()
 	a := &app{
-		Config:             &config{},
-		RepositoryTxer:     NewInMemRepositoryTxer(),
-		ActivityRepository: repo,
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ActivityRepository:  repo,
+		TimesheetRepository: NewInMemTimesheetRepository(),
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
 	}
 
 	r, _ := http.NewRequest("DELETE", "/api/activities/00000000-0000-0000-2222-000000000001", nil)
@@ -315,14 +501,20 @@ func TestHandleDeleteActivityAsMatchingUser(t *testing.T) {
 
 	repo := NewInMemActivityRepository()
 	a := &app{
-		Config:             &config{},
-		RepositoryTxer:     NewInMemRepositoryTxer(),
-		ActivityRepository: repo,
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		ProjectRepository:      NewInMemProjectRepository(),
 	}
 
 	r, _ := http.NewRequest("DELETE", "/api/activities/00000000-0000-0000-2222-000000000001", nil)
 	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
-		Username: "user1",
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
 	}))
 
 	rctx := chi.NewRouteContext()
@@ -376,6 +568,42 @@ func TestHandleCreateActivityWithInvalidBody(t *testing.T) {
 	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
 }
 
+func TestHandleDeleteActivityAsProjectManager(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "manager", Role: ProjectRoleManager, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	r, _ := http.NewRequest("DELETE", "/api/activities/00000000-0000-0000-2222-000000000001", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "manager",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("activity-id", "00000000-0000-0000-2222-000000000001")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteActivity()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(repo.activities))
+}
+
 func TestHandleDeleteActivityAsNonMatchingUser(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -385,6 +613,7 @@ func TestHandleDeleteActivityAsNonMatchingUser(t *testing.T) {
 		Config:             &config{},
 		RepositoryTxer:     NewInMemRepositoryTxer(),
 		ActivityRepository: repo,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	r, _ := http.NewRequest("DELETE", "/api/activities/00000000-0000-0000-2222-000000000001", nil)
@@ -407,16 +636,20 @@ func TestHandleUpdateActivity(t *testing.T) {
 
 	repo := NewInMemActivityRepository()
 	a := &app{
-		Config:             &config{},
-		RepositoryTxer:     NewInMemRepositoryTxer(),
-		ActivityRepository: repo,
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ActivityRepository:  repo,
+		ProjectRepository:   NewInMemProjectRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
 	}
 
 	body := `
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description": "My updated Description",
 		"_links":{
 		   "project":{
@@ -443,6 +676,57 @@ func TestHandleUpdateActivity(t *testing.T) {
 	is.Equal("My updated Description", activityUpdate.Description)
 }
 
+func TestHandleUpdateActivityLockedByTimesheet(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	repo.activities[0].Start = time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	repo.activities[0].End = time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusApproved
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		TimesheetRepository:    timesheetRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+	}
+
+	body := `
+	{
+		"id":null,
+		"start":"2021-11-10T09:00:00",
+		"end":"2021-11-10T11:00:00",
+		"description": "My updated Description",
+		"_links":{
+		   "project":{
+			  "href":"http://localhost:8080/api/projects/f4b1087c-8fbb-4c8d-bbb7-ab4d46da16ea"
+		   }
+		}
+	 }
+	`
+
+	r, _ := http.NewRequest("PATCH", "/api/activities/00000000-0000-0000-2222-000000000001", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("activity-id", "00000000-0000-0000-2222-000000000001")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateActivity()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+	is.Equal("", repo.activities[0].Description)
+}
+
 func TestHandleUpdateInvalidActivity(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -457,7 +741,7 @@ func TestHandleUpdateInvalidActivity(t *testing.T) {
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description": "Lorem ipsum dolor sit amet, consectetuer adipiscing elit. Aenean commodo ligula eget dolor. Aenean massa. Cum sociis natoque penatibus et magnis dis parturient montes, nascetur ridiculus mus. Donec quam felis, ultricies nec, pellentesque eu, pretium quis, sem. Nulla consequat massa quis enim. Donec pede justo, fringilla vel, aliquet nec, vulputate eget, arcu. In enim justo, rhoncus ut, imperdiet a, venenatis vitae, justo. Nullam dictum felis eu pede mollis pretium. Integer tincidunt. Cras dapibus. Vivamus elementum semper nisi. Aenean vulputate eleifend tellus. Aenean leo ligula, porttitor eu, consequat vitae, eleifend ac, enim. Aliquam lorem ante, dapibus in, viverra quis, feugiat a, tellus. Phasellus viverra nulla ut metus varius laoreet. Quisque rutrum. Aenean imperdiet. Etiam ultricies nisi vel augue. Curabitur ullamcorper ultricies nisi. Nam eget dui. Etiam rhoncus. Maecenas tempus, tellus eget condimentum rhoncus, sem quam semper libero, sit amet adipiscing sem neque sed ipsum. Nam quam nunc, blandit vel, luctus pulvinar, hendrerit id, lorem. Maecenas nec odio et ante tincidunt tempus. Donec vitae sapien ut libero venenatis faucibus. Nullam quis ante. Etiam sit amet orci eget eros faucibus tincidunt. Duis leo. Sed fringilla mauris sit amet nibh. Donec sodales sagittis magna. Sed consequat, leo eget bibendum sodales, augue velit cursus nunc, quis gravida magna mi a libero. Fusce vulputate eleifend sapien. Vestibulum purus quam, scelerisque ut, mollis sed, nonummy id, metus. Nullam accumsan lorem in dui. Cras ultricies mi eu turpis hendrerit fringilla. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; In ac dui quis mi consectetuer lacinia. Nam pretium turpis et arcu. Duis arcu tortor, suscipit eget, imperdiet nec, imperdiet iaculis, ipsum. Sed aliquam ultrices mauris. Integer ante arcu, accumsan a, consectetuer eget, posuere ut, mauris. Praesent adipiscing. Phasellus ullamcorper ipsum rutrum nunc. Nunc nonummy metus. Vestibulum volutpat pretium libero. Cras id dui. Aenean ut eros et nisl sagittis vestibulum. Nullam nulla eros, ultricies sit amet, nonummy id, imperdiet feugiat, pede. Sed lectus. Donec mollis hendrerit risus. Phasellus nec sem in justo pellentesque facilisis. Etiam imperdiet imperdiet orci. Nunc nec neque. Phasellus leo dolor, tempus non, auctor et, hendrerit quis, nisi. Curabitur ligula sapien, tincidunt non, euismod vitae, posuere imperdiet, leo. Maecenas malesuada. Praesent congue erat at massa. Sed cursus turpis vitae tortor. Donec posuere vulputate arcu. Phasellus accumsan cursus velit. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Sed aliquam, nisi quis porttitor congue, elit erat euismod orci, ac placerat dolor lectus quis orci. Phasellus consectetuer vestibulum elit. Aenean tellus metus, bibendum sed, posuere ac, mattis non, nunc. Vestibulum fringilla pede sit amet augue. In turpis. Pellentesque posuere. Praesent turpis. Aenean posuere, tortor sed cursus feugiat, nunc augue blandit nunc, eu sollicitudin urna dolor sagittis lacus. Donec elit libero, sodales nec, volutpat a, suscipit non, turpis. Nullam sagittis. Suspendisse pulvinar, augue ac venenatis condimentum, sem libero volutpat nibh, nec pellentesque velit pede quis nunc. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia Curae; Fusce id purus. Ut varius tincidunt libero. Phasellus dolor. Maecenas vestibulum mollis diam. ",
 		"_links":{
 		   "project":{
@@ -486,16 +770,21 @@ func TestHandleUpdateActivityAsUser(t *testing.T) {
 
 	repo := NewInMemActivityRepository()
 	a := &app{
-		Config:             &config{},
-		RepositoryTxer:     NewInMemRepositoryTxer(),
-		ActivityRepository: repo,
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
 	}
 
 	body := `
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description": "My updated Description",
 		"_links":{
 		   "project":{
@@ -507,7 +796,8 @@ func TestHandleUpdateActivityAsUser(t *testing.T) {
 
 	r, _ := http.NewRequest("POST", "/api/activities", strings.NewReader(body))
 	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
-		Username: "user1",
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
 	}))
 
 	rctx := chi.NewRouteContext()
@@ -531,13 +821,14 @@ func TestHandleUpdateActivityWithNonMatchingUser(t *testing.T) {
 		Config:             &config{},
 		RepositoryTxer:     NewInMemRepositoryTxer(),
 		ActivityRepository: repo,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	body := `
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description": "My updated Description",
 		"_links":{
 		   "project":{
@@ -601,7 +892,7 @@ func TestHandleUpdateActivityIdNotValid(t *testing.T) {
 	{
 		"id":null,
 		"start":"2021-11-06T21:37:00",
-		"end":"2021-11-06T21:37:00",
+		"end":"2021-11-06T22:37:00",
 		"description": "My updated Description",
 		"_links":{
 		   "project":{
@@ -629,7 +920,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params := make(url.Values)
 		params.Add("t", "year")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(time.Now().Year(), filter.Start().Year())
@@ -640,7 +931,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "year")
 		params.Add("sort", "project:asc")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(time.Now().Year(), filter.Start().Year())
@@ -653,7 +944,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "year")
 		params.Add("v", "2021")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
@@ -667,7 +958,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "year")
 		params.Add("v", "XXXX")
 
-		_, err := filterFromQueryParams(params)
+		_, err := filterFromQueryParams(params, time.UTC)
 
 		is.True(err != nil)
 	})
@@ -677,7 +968,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "quarter")
 		params.Add("v", "2021-2")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
@@ -685,12 +976,51 @@ func TestFilterFromQueryParams(t *testing.T) {
 		is.Equal(0, filter.Start().Hour())
 	})
 
+	t.Run("filter with tag from query params", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "year")
+		params.Add("tag", "Meeting")
+
+		filter, err := filterFromQueryParams(params, time.UTC)
+
+		is.NoErr(err)
+		is.Equal("meeting", filter.Tag())
+	})
+
+	t.Run("filter with criteria from query params", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "year")
+		params.Add("project", projectIDSample.String())
+		params.Add("user", "user1")
+		params.Add("user", "user2")
+		params.Add("description", " invoice ")
+
+		filter, err := filterFromQueryParams(params, time.UTC)
+
+		is.NoErr(err)
+		is.Equal([]uuid.UUID{projectIDSample}, filter.ProjectIDs())
+		is.Equal([]string{"user1", "user2"}, filter.Usernames())
+		is.Equal("invoice", filter.Description())
+		is.True(filter.HasCriteria())
+		is.True(!filter.WithoutCriteria().HasCriteria())
+	})
+
+	t.Run("filter with invalid project from query params", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "year")
+		params.Add("project", "XXXX")
+
+		_, err := filterFromQueryParams(params, time.UTC)
+
+		is.True(err != nil)
+	})
+
 	t.Run("quarter filter from invalid query params", func(t *testing.T) {
 		params := make(url.Values)
 		params.Add("t", "quarter")
 		params.Add("v", "XXXX-9")
 
-		_, err := filterFromQueryParams(params)
+		_, err := filterFromQueryParams(params, time.UTC)
 
 		is.True(err != nil)
 	})
@@ -700,7 +1030,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "month")
 		params.Add("v", "2021-11")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
@@ -714,7 +1044,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "month")
 		params.Add("v", "2020-99")
 
-		_, err := filterFromQueryParams(params)
+		_, err := filterFromQueryParams(params, time.UTC)
 
 		is.True(err != nil)
 	})
@@ -724,7 +1054,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "week")
 		params.Add("v", "2021-1")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
@@ -738,7 +1068,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "week")
 		params.Add("v", "2023-10")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2023, filter.Start().Year())
@@ -752,7 +1082,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "week")
 		params.Add("v", "2020-ccc")
 
-		_, err := filterFromQueryParams(params)
+		_, err := filterFromQueryParams(params, time.UTC)
 
 		is.True(err != nil)
 	})
@@ -762,7 +1092,7 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "month")
 		params.Add("v", "2021-03")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
@@ -774,11 +1104,207 @@ func TestFilterFromQueryParams(t *testing.T) {
 		params.Add("t", "day")
 		params.Add("v", "2021-11-10")
 
-		filter, err := filterFromQueryParams(params)
+		filter, err := filterFromQueryParams(params, time.UTC)
 
 		is.NoErr(err)
 		is.Equal(2021, filter.Start().Year())
 		is.Equal(time.November, filter.Start().Month())
 	})
 
+	t.Run("filter split at midnight from query params", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "week")
+		params.Add("split", "midnight")
+
+		filter, err := filterFromQueryParams(params, time.UTC)
+
+		is.NoErr(err)
+		is.True(filter.SplitAtMidnight())
+	})
+
+	t.Run("week filter without value in location", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "week")
+		loc, _ := time.LoadLocation("Pacific/Kiritimati")
+
+		filter, err := filterFromQueryParams(params, loc)
+
+		is.NoErr(err)
+		year, week := time.Now().In(loc).ISOWeek()
+		is.Equal(fmt.Sprintf("%v-%v", year, week), filter.String())
+		is.Equal(loc, filter.Start().Location())
+		is.Equal(0, filter.Start().Hour())
+	})
+
+	t.Run("quarter filter without value", func(t *testing.T) {
+		params := make(url.Values)
+		params.Add("t", "quarter")
+
+		filter, err := filterFromQueryParams(params, time.UTC)
+
+		is.NoErr(err)
+		is.Equal(util.Quarter(time.Now().UTC()), util.Quarter(filter.Start()))
+	})
+}
+
+func TestHandleImportActivities(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		countBefore := len(repo.activities)
+
+		r := newActivityImportRequest(t, "/api/activities/import?dryRun=true", "activities.csv", "Date;Start;End;Project\n2021-11-12;09:00;10:00;My Project\n", nil)
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleImportActivities()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+		is.Equal(countBefore, len(repo.activities))
+
+		var model activityImportModel
+		err := json.NewDecoder(httpRec.Body).Decode(&model)
+		is.NoErr(err)
+		is.True(!model.Imported)
+		is.Equal(len(model.Rows), 1)
+		is.True(model.Rows[0].Activity != nil)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		countBefore := len(repo.activities)
+
+		r := newActivityImportRequest(t, "/api/activities/import", "activities.csv", "Date;Start;End;Project\n2021-11-12;09:00;10:00;My Project\n", nil)
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleImportActivities()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+		is.Equal(countBefore+1, len(repo.activities))
+	})
+
+	t.Run("ImportWithInvalidRow", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		countBefore := len(repo.activities)
+
+		r := newActivityImportRequest(t, "/api/activities/import", "activities.csv", "Date;Start;End;Project\n2021-11-12;09:00;10:00;Unknown\n", nil)
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleImportActivities()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusUnprocessableEntity)
+		is.Equal(countBefore, len(repo.activities))
+
+		var model activityImportModel
+		err := json.NewDecoder(httpRec.Body).Decode(&model)
+		is.NoErr(err)
+		is.Equal(model.Rows[0].Errors, []string{"Project 'Unknown' not found."})
+	})
+
+	t.Run("MissingColumn", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+
+		r := newActivityImportRequest(t, "/api/activities/import", "activities.csv", "Date;Start\n", nil)
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleImportActivities()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+	})
+}
+
+func newActivityImportRequest(t *testing.T, target, filename, content string, fields url.Values) *http.Request {
+	var body bytes.Buffer
+	multipartWriter := multipart.NewWriter(&body)
+
+	for name, values := range fields {
+		for _, value := range values {
+			err := multipartWriter.WriteField(name, value)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	fileWriter, err := multipartWriter.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fileWriter.Write([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = multipartWriter.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := http.NewRequest("POST", target, &body)
+	r.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	return r
+}
+
+func TestHandleGetCalendarFeed(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	_, secret, err := a.CreateApiToken(context.Background(), principal, "Calendar", ApiTokenScopeCalendar, nil)
+	is.NoErr(err)
+
+	router := chi.NewRouter()
+	router.Get("/api/calendar/{calendar-token}.ics", a.HandleGetCalendarFeed())
+
+	t.Run("ValidToken", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/calendar/"+secret+".ics?t=month&v=2021-11", nil)
+
+		router.ServeHTTP(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+		is.Equal(httpRec.Result().Header.Get("Content-Type"), "text/calendar; charset=utf-8")
+		is.True(strings.Contains(httpRec.Body.String(), "BEGIN:VCALENDAR"))
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/calendar/bat_unknown.ics", nil)
+
+		router.ServeHTTP(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("InvalidTimespan", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/calendar/"+secret+".ics?t=month&v=invalid", nil)
+
+		router.ServeHTTP(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+	})
 }
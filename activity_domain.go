@@ -3,13 +3,17 @@ package main
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/baralga/util"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
+var ErrActivityEndNotAfterStart = errors.New("activity end not after start")
+
 // Activity represents a tracked time for a project
 type Activity struct {
 	ID             uuid.UUID
@@ -19,15 +23,42 @@ type Activity struct {
 	ProjectID      uuid.UUID
 	OrganizationID uuid.UUID
 	Username       string
+	Billable       bool
+	Tags           []string
+
+	// HourlyRate is the effective hourly rate of the activity's user for the project,
+	// it is only filled when reading activities and never stored with the activity
+	HourlyRate float64
+}
+
+// RunningActivity represents a started timer of a user which is not yet stopped
+type RunningActivity struct {
+	ID             uuid.UUID
+	Start          time.Time
+	Description    string
+	ProjectID      uuid.UUID
+	OrganizationID uuid.UUID
+	Username       string
 }
 
 // ActivityFilter reprensents a filter for activities
 type ActivityFilter struct {
-	Timespan  string
-	sortBy    string
-	sortOrder string
-	start     time.Time
-	end       time.Time
+	activityCriteria
+	Timespan        string
+	sortBy          string
+	sortOrder       string
+	start           time.Time
+	end             time.Time
+	splitAtMidnight bool
+}
+
+// activityCriteria are the criteria to filter activities by
+// which are kept when navigating to another timespan
+type activityCriteria struct {
+	tag         string
+	projectIDs  []uuid.UUID
+	usernames   []string
+	description string
 }
 
 const (
@@ -71,17 +102,237 @@ func (i *ActivityTimeReportItem) DurationFormatted() string {
 	return FormatMinutesAsDuration(float64(i.DurationInMinutesTotal))
 }
 
-type ActivityProjectReportItem struct {
+type ActivityTagReportItem struct {
+	Tag                    string
+	DurationInMinutesTotal int
+}
+
+// DurationFormatted is the activity duration as formatted string (e.g. 1:15 h)
+func (i *ActivityTagReportItem) DurationFormatted() string {
+	return FormatMinutesAsDuration(float64(i.DurationInMinutesTotal))
+}
+
+type ActivityUserReportItem struct {
+	Username               string
+	Name                   string
+	DurationInMinutesTotal int
+}
+
+// DurationFormatted is the activity duration as formatted string (e.g. 1:15 h)
+func (i *ActivityUserReportItem) DurationFormatted() string {
+	return FormatMinutesAsDuration(float64(i.DurationInMinutesTotal))
+}
+
+type ActivityProjectUserReportItem struct {
 	ProjectID              uuid.UUID
 	ProjectTitle           string
+	Username               string
 	DurationInMinutesTotal int
 }
 
+// ActivityProjectUserMatrix is the time spent by the users (columns) on the projects (rows)
+type ActivityProjectUserMatrix struct {
+	Users    []*ActivityUserReportItem
+	Projects []*ActivityProjectUserMatrixRow
+}
+
+type ActivityProjectUserMatrixRow struct {
+	ProjectID              uuid.UUID
+	ProjectTitle           string
+	DurationInMinutesTotal int
+	durationsByUsername    map[string]int
+}
+
+// NewActivityProjectUserMatrix creates the matrix of the users and the time they spent per project
+func NewActivityProjectUserMatrix(users []*ActivityUserReportItem, reportItems []*ActivityProjectUserReportItem) *ActivityProjectUserMatrix {
+	matrix := &ActivityProjectUserMatrix{
+		Users: users,
+	}
+
+	rowsByProject := make(map[uuid.UUID]*ActivityProjectUserMatrixRow)
+	for _, reportItem := range reportItems {
+		row, ok := rowsByProject[reportItem.ProjectID]
+		if !ok {
+			row = &ActivityProjectUserMatrixRow{
+				ProjectID:           reportItem.ProjectID,
+				ProjectTitle:        reportItem.ProjectTitle,
+				durationsByUsername: make(map[string]int),
+			}
+			rowsByProject[reportItem.ProjectID] = row
+			matrix.Projects = append(matrix.Projects, row)
+		}
+
+		row.durationsByUsername[reportItem.Username] += reportItem.DurationInMinutesTotal
+		row.DurationInMinutesTotal += reportItem.DurationInMinutesTotal
+	}
+
+	return matrix
+}
+
+// DurationInMinutes is the time the user spent on the project
+func (r *ActivityProjectUserMatrixRow) DurationInMinutes(username string) int {
+	return r.durationsByUsername[username]
+}
+
+// DurationFormatted is the time the user spent on the project as formatted string (e.g. 1:15 h)
+func (r *ActivityProjectUserMatrixRow) DurationFormatted(username string) string {
+	return FormatMinutesAsDuration(float64(r.durationsByUsername[username]))
+}
+
+// TotalFormatted is the time all users spent on the project as formatted string (e.g. 1:15 h)
+func (r *ActivityProjectUserMatrixRow) TotalFormatted() string {
+	return FormatMinutesAsDuration(float64(r.DurationInMinutesTotal))
+}
+
+type ActivityProjectReportItem struct {
+	ProjectID                      uuid.UUID
+	ProjectTitle                   string
+	DurationInMinutesTotal         int
+	BillableDurationInMinutesTotal int
+	Revenue                        float64
+	Currency                       string
+}
+
 // DurationFormatted is the activity duration as formatted string (e.g. 1:15 h)
 func (i *ActivityProjectReportItem) DurationFormatted() string {
 	return FormatMinutesAsDuration(float64(i.DurationInMinutesTotal))
 }
 
+// BillableDurationFormatted is the billable duration as formatted string (e.g. 1:15 h)
+func (i *ActivityProjectReportItem) BillableDurationFormatted() string {
+	return FormatMinutesAsDuration(float64(i.BillableDurationInMinutesTotal))
+}
+
+// RevenueFormatted is the revenue as formatted string (e.g. 150.00 EUR)
+func (i *ActivityProjectReportItem) RevenueFormatted() string {
+	return FormatAmount(i.Revenue, i.Currency)
+}
+
+// ActivityProjectRollUpItem sums up the project report items of a client or of a parent project and its sub-projects
+type ActivityProjectRollUpItem struct {
+	ID                             uuid.UUID // uuid.Nil for projects without client
+	Title                          string
+	DurationInMinutesTotal         int
+	BillableDurationInMinutesTotal int
+	revenues                       map[string]float64
+}
+
+// ActivityProjectRollUps are the project report items rolled up per client and per parent project
+type ActivityProjectRollUps struct {
+	Clients []*ActivityProjectRollUpItem
+	Parents []*ActivityProjectRollUpItem
+}
+
+// DurationFormatted is the rolled up duration as formatted string (e.g. 1:15 h)
+func (i *ActivityProjectRollUpItem) DurationFormatted() string {
+	return FormatMinutesAsDuration(float64(i.DurationInMinutesTotal))
+}
+
+// BillableDurationFormatted is the rolled up billable duration as formatted string (e.g. 1:15 h)
+func (i *ActivityProjectRollUpItem) BillableDurationFormatted() string {
+	return FormatMinutesAsDuration(float64(i.BillableDurationInMinutesTotal))
+}
+
+// RevenueFormatted is the rolled up revenue per currency as formatted string (e.g. 150.00 EUR, 20.00 USD)
+func (i *ActivityProjectRollUpItem) RevenueFormatted() string {
+	currencies := make([]string, 0, len(i.revenues))
+	for currency := range i.revenues {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	amounts := make([]string, len(currencies))
+	for j, currency := range currencies {
+		amounts[j] = FormatAmount(i.revenues[currency], currency)
+	}
+	return strings.Join(amounts, ", ")
+}
+
+func (i *ActivityProjectRollUpItem) add(item *ActivityProjectReportItem) {
+	i.DurationInMinutesTotal += item.DurationInMinutesTotal
+	i.BillableDurationInMinutesTotal += item.BillableDurationInMinutesTotal
+	if i.revenues == nil {
+		i.revenues = make(map[string]float64)
+	}
+	currency := item.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	i.revenues[currency] += item.Revenue
+}
+
+// RollUpProjectReport rolls up the project report items per client and per parent project,
+// clients are only rolled up if at least one project has a client and parent projects only if they have sub-projects
+func RollUpProjectReport(items []*ActivityProjectReportItem, projects []*Project) *ActivityProjectRollUps {
+	projectsByID := make(map[uuid.UUID]*Project)
+	for _, project := range projects {
+		projectsByID[project.ID] = project
+	}
+
+	var clients, parents []*ActivityProjectRollUpItem
+	clientsByID := make(map[uuid.UUID]*ActivityProjectRollUpItem)
+	parentsByID := make(map[uuid.UUID]*ActivityProjectRollUpItem)
+	hasClients, hasSubProjects := false, make(map[uuid.UUID]bool)
+
+	for _, item := range items {
+		project, ok := projectsByID[item.ProjectID]
+		if !ok {
+			project = &Project{ID: item.ProjectID, Title: item.ProjectTitle}
+		}
+
+		clientItem, ok := clientsByID[project.ClientID]
+		if !ok {
+			clientItem = &ActivityProjectRollUpItem{ID: project.ClientID, Title: project.ClientTitle}
+			if project.ClientID == uuid.Nil {
+				clientItem.Title = "No Client"
+			}
+			clientsByID[project.ClientID] = clientItem
+			clients = append(clients, clientItem)
+		}
+		clientItem.add(item)
+		hasClients = hasClients || project.ClientID != uuid.Nil
+
+		parentID, parentTitle := project.ID, project.Title
+		if project.IsSubProject() {
+			parentID, parentTitle = project.ParentID, project.ParentTitle
+			hasSubProjects[parentID] = true
+		}
+		parentItem, ok := parentsByID[parentID]
+		if !ok {
+			parentItem = &ActivityProjectRollUpItem{ID: parentID}
+			parentsByID[parentID] = parentItem
+			parents = append(parents, parentItem)
+		}
+		if parentTitle != "" {
+			parentItem.Title = parentTitle
+		}
+		parentItem.add(item)
+	}
+
+	rollUps := &ActivityProjectRollUps{}
+	if hasClients {
+		rollUps.Clients = clients
+	}
+	for _, parentItem := range parents {
+		if hasSubProjects[parentItem.ID] {
+			rollUps.Parents = append(rollUps.Parents, parentItem)
+		}
+	}
+
+	sortRollUps := func(rollUpItems []*ActivityProjectRollUpItem) {
+		sort.SliceStable(rollUpItems, func(i, j int) bool {
+			if (rollUpItems[i].ID == uuid.Nil) != (rollUpItems[j].ID == uuid.Nil) {
+				return rollUpItems[j].ID == uuid.Nil
+			}
+			return strings.ToLower(rollUpItems[i].Title) < strings.ToLower(rollUpItems[j].Title)
+		})
+	}
+	sortRollUps(rollUps.Clients)
+	sortRollUps(rollUps.Parents)
+
+	return rollUps
+}
+
 // AsTime returns the report item as time.Time
 func (i *ActivityTimeReportItem) AsTime() time.Time {
 	t, _ := time.Parse("2006-1-2", fmt.Sprintf("%v-%v-%v", i.Year, i.Month, i.Day))
@@ -123,18 +374,23 @@ func (f *ActivityFilter) End() time.Time {
 	}
 }
 
-func (f *ActivityFilter) Home() *ActivityFilter {
+// Home returns the filter of the current timespan in the location
+func (f *ActivityFilter) Home(loc *time.Location) *ActivityFilter {
 	return &ActivityFilter{
-		Timespan: f.Timespan,
-		start:    time.Now(),
+		activityCriteria: f.activityCriteria,
+		Timespan:         f.Timespan,
+		start:            time.Now().In(loc),
+		splitAtMidnight:  f.splitAtMidnight,
 	}
 }
 
 func (f *ActivityFilter) Next() *ActivityFilter {
 	nextFilter := &ActivityFilter{
-		Timespan: f.Timespan,
-		start:    f.start,
-		end:      f.end,
+		activityCriteria: f.activityCriteria,
+		Timespan:         f.Timespan,
+		start:            f.start,
+		end:              f.end,
+		splitAtMidnight:  f.splitAtMidnight,
 	}
 
 	switch nextFilter.Timespan {
@@ -160,9 +416,11 @@ func (f *ActivityFilter) Next() *ActivityFilter {
 
 func (f *ActivityFilter) Previous() *ActivityFilter {
 	previousFilter := &ActivityFilter{
-		Timespan: f.Timespan,
-		start:    f.start,
-		end:      f.end,
+		activityCriteria: f.activityCriteria,
+		Timespan:         f.Timespan,
+		start:            f.start,
+		end:              f.end,
+		splitAtMidnight:  f.splitAtMidnight,
 	}
 
 	switch previousFilter.Timespan {
@@ -188,10 +446,12 @@ func (f *ActivityFilter) Previous() *ActivityFilter {
 
 func (f *ActivityFilter) WithSortToggle(sortBy string) *ActivityFilter {
 	filterWithSort := &ActivityFilter{
-		Timespan: f.Timespan,
-		sortBy:   sortBy,
-		start:    f.start,
-		end:      f.end,
+		activityCriteria: f.activityCriteria,
+		Timespan:         f.Timespan,
+		sortBy:           sortBy,
+		start:            f.start,
+		end:              f.end,
+		splitAtMidnight:  f.splitAtMidnight,
 	}
 
 	if f.sortOrder == "desc" {
@@ -203,6 +463,57 @@ func (f *ActivityFilter) WithSortToggle(sortBy string) *ActivityFilter {
 	return filterWithSort
 }
 
+// SplitAtMidnight checks whether activities spanning midnight are split into their parts per day
+func (f *ActivityFilter) SplitAtMidnight() bool {
+	return f.splitAtMidnight
+}
+
+// WithSplitAtMidnight returns a copy of the filter which splits activities at midnight or not
+func (f *ActivityFilter) WithSplitAtMidnight(splitAtMidnight bool) *ActivityFilter {
+	filterWithSplit := *f
+	filterWithSplit.splitAtMidnight = splitAtMidnight
+	return &filterWithSplit
+}
+
+// Tag returns the tag the activities are filtered by
+func (f *ActivityFilter) Tag() string {
+	return f.tag
+}
+
+// WithTag returns a copy of the filter which filters by the given tag
+func (f *ActivityFilter) WithTag(tag string) *ActivityFilter {
+	filterWithTag := *f
+	filterWithTag.tag = NormalizeTag(tag)
+	return &filterWithTag
+}
+
+// ProjectIDs returns the projects the activities are filtered by
+func (f *ActivityFilter) ProjectIDs() []uuid.UUID {
+	return f.projectIDs
+}
+
+// Usernames returns the users the activities are filtered by
+func (f *ActivityFilter) Usernames() []string {
+	return f.usernames
+}
+
+// Description returns the text the descriptions of the activities are searched for
+func (f *ActivityFilter) Description() string {
+	return f.description
+}
+
+// WithoutCriteria returns a copy of the filter which only filters by the timespan
+func (f *ActivityFilter) WithoutCriteria() *ActivityFilter {
+	filterWithoutCriteria := *f
+	filterWithoutCriteria.activityCriteria = activityCriteria{}
+	return &filterWithoutCriteria
+}
+
+// HasCriteria checks whether the activities are filtered by more than the timespan
+func (f *ActivityFilter) HasCriteria() bool {
+	return f.tag != "" || len(f.projectIDs) > 0 || len(f.usernames) > 0 || f.description != ""
+}
+
 // End returns the filter's display name
 func (f *ActivityFilter) String() string {
 	switch f.Timespan {
@@ -244,8 +555,9 @@ func (f *ActivityFilter) StringFormatted() string {
 	}
 }
 
-func (f *ActivityFilter) NewValue() string {
-	now := time.Now()
+// NewValue returns the value of the current timespan in the location (e.g. 2021-45 for a week)
+func (f *ActivityFilter) NewValue(loc *time.Location) string {
+	now := time.Now().In(loc)
 	switch f.Timespan {
 	case TimespanDay:
 		return now.Format("2006-01-02")
@@ -255,7 +567,7 @@ func (f *ActivityFilter) NewValue() string {
 	case TimespanMonth:
 		return now.Format("2006-01")
 	case TimespanQuarter:
-		q := int(now.Month()) / 3
+		q := util.Quarter(now)
 		return fmt.Sprintf("%v-%v", now.Format("2006"), q)
 	case TimespanYear:
 		return now.Format("2006")
@@ -290,11 +602,270 @@ func (ad *Activity) DurationFormatted() string {
 	return FormatMinutesAsDuration(float64(ad.DurationMinutesTotal()))
 }
 
+// EndFormatted is the end time of the activity, with the date if
+// the activity ends on another day than it starts (e.g. 13.11. 06:00)
+func (ad *Activity) EndFormatted() string {
+	if ad.EndsOnOtherDay() {
+		return util.FormatDateDEShort(ad.End) + " " + util.FormatTime(ad.End)
+	}
+	return util.FormatTime(ad.End)
+}
+
+// EndsOnOtherDay checks whether the activity ends on another day than it starts
+func (ad *Activity) EndsOnOtherDay() bool {
+	return util.FormatDate(ad.Start) != util.FormatDate(ad.End)
+}
+
 // FormatMinutesAsDuration formates the duration in minutes as formatted string (e.g. 1:15 h)
 func FormatMinutesAsDuration(minutes float64) string {
 	return fmt.Sprintf("%v:%02d h", math.Floor(minutes/60), int(minutes)%60)
 }
 
+// Amount is the billed amount of the activity, which is zero for non billable activities
+func (ad *Activity) Amount() float64 {
+	if !ad.Billable {
+		return 0
+	}
+	return math.Round(ad.DurationDecimal()*ad.HourlyRate*100) / 100
+}
+
+// FormatAmount formats the amount with currency (e.g. 150.00 EUR)
+func FormatAmount(amount float64, currency string) string {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+// NormalizeTag normalizes the tag to lower case without surrounding spaces
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes the tags and removes empty and duplicate tags
+func NormalizeTags(tags []string) []string {
+	normalizedTags := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		t := NormalizeTag(tag)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalizedTags = append(normalizedTags, t)
+	}
+	return normalizedTags
+}
+
+// ParseTags parses comma separated tags (e.g. "meeting, review")
+func ParseTags(tags string) []string {
+	return NormalizeTags(strings.Split(tags, ","))
+}
+
+// In converts start and end of the activity to the given location
+func (ad *Activity) In(loc *time.Location) *Activity {
+	ad.Start = ad.Start.In(loc)
+	ad.End = ad.End.In(loc)
+	return ad
+}
+
 func (a *Activity) duration() time.Duration {
 	return a.End.Sub(a.Start)
 }
+
+// Overlaps checks whether the activity overlaps with the other activity, adjacent activities don't overlap
+func (a *Activity) Overlaps(other *Activity) bool {
+	return a.Start.Before(other.End) && other.Start.Before(a.End)
+}
+
+// OverlapsAny checks whether the activity overlaps with one of the other activities
+func OverlapsAny(activity *Activity, others []*Activity) bool {
+	for _, other := range others {
+		if other.Overlaps(activity) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the activity ends after it starts, activities may span several days
+func (a *Activity) Validate() error {
+	if !a.End.After(a.Start) {
+		return ErrActivityEndNotAfterStart
+	}
+	return nil
+}
+
+// StopAt converts the running activity to an activity ending at the given time,
+// the activity lasts at least one minute
+func (ra *RunningActivity) StopAt(end time.Time) *Activity {
+	if end.Sub(ra.Start) < time.Minute {
+		end = ra.Start.Add(time.Minute)
+	}
+	return &Activity{
+		Start:          ra.Start,
+		End:            end,
+		Description:    ra.Description,
+		ProjectID:      ra.ProjectID,
+		OrganizationID: ra.OrganizationID,
+		Username:       ra.Username,
+		Billable:       true,
+	}
+}
+
+// DurationFormattedAt is the duration until the given time as formatted string (e.g. 1:15 h)
+func (ra *RunningActivity) DurationFormattedAt(t time.Time) string {
+	return FormatMinutesAsDuration(math.Floor(t.Sub(ra.Start).Minutes()))
+}
+
+// ActivityImport is the result of importing activities from a spreadsheet
+type ActivityImport struct {
+	Rows []*ActivityImportRow
+
+	// NewProjects are the projects which are created by the import
+	NewProjects []*Project
+
+	// Imported is true if the activities were stored and false for a dry run
+	Imported bool
+}
+
+// ActivityImportRow is a row of an imported spreadsheet with the activity read from it
+type ActivityImportRow struct {
+	Line         int
+	ProjectTitle string
+	Activity     *Activity
+	Errors       []string
+}
+
+// Valid checks whether all rows of the import are valid
+func (i *ActivityImport) Valid() bool {
+	return len(i.Rows) > 0 && i.ErrorCount() == 0
+}
+
+// overlaps checks whether the activity overlaps with the activity of one of the rows
+func (i *ActivityImport) overlaps(activity *Activity) bool {
+	activities := make([]*Activity, 0, len(i.Rows))
+	for _, row := range i.Rows {
+		if row.Activity.Validate() == nil {
+			activities = append(activities, row.Activity)
+		}
+	}
+	return OverlapsAny(activity, activities)
+}
+
+// ErrorCount is the number of rows with errors
+func (i *ActivityImport) ErrorCount() int {
+	count := 0
+	for _, row := range i.Rows {
+		if !row.Valid() {
+			count++
+		}
+	}
+	return count
+}
+
+// Valid checks whether the row has no errors
+func (r *ActivityImportRow) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// CalendarEvent is an event of an imported calendar, which is a draft for an activity
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// DurationFormatted is the event duration as formatted string (e.g. 1:15 h)
+func (e *CalendarEvent) DurationFormatted() string {
+	return FormatMinutesAsDuration(math.Floor(e.End.Sub(e.Start).Minutes()))
+}
+
+// ActivityGap is a time span within the working hours of a day without any activity
+type ActivityGap struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DurationFormatted is the gap duration as formatted string (e.g. 1:15 h)
+func (g *ActivityGap) DurationFormatted() string {
+	return FormatMinutesAsDuration(math.Floor(g.End.Sub(g.Start).Minutes()))
+}
+
+// WorkingHours are the hours of a working day from monday to friday,
+// given as time since midnight
+type WorkingHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// DefaultWorkingHours are the working hours from 09:00 to 17:00
+var DefaultWorkingHours = WorkingHours{
+	Start: 9 * time.Hour,
+	End:   17 * time.Hour,
+}
+
+// String formats the working hours like 09:00 - 17:00
+func (w WorkingHours) String() string {
+	return fmt.Sprintf(
+		"%02d:%02d - %02d:%02d",
+		int(w.Start.Hours()), int(w.Start.Minutes())%60,
+		int(w.End.Hours()), int(w.End.Minutes())%60,
+	)
+}
+
+// FindActivityGaps finds the gaps between the activities within the working hours
+// of the working days from start to end in the location of start
+func FindActivityGaps(activities []*Activity, start, end time.Time, workingHours WorkingHours) []*ActivityGap {
+	sorted := make([]*Activity, len(activities))
+	copy(sorted, activities)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	loc := start.Location()
+	var gaps []*ActivityGap
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+
+		workStart := wallClockAfter(day, workingHours.Start)
+		workEnd := wallClockAfter(day, workingHours.End)
+		if workStart.Before(start) {
+			workStart = start
+		}
+		if workEnd.After(end) {
+			workEnd = end
+		}
+
+		covered := workStart
+		for _, activity := range sorted {
+			if !activity.End.After(covered) {
+				continue
+			}
+			if !activity.Start.Before(workEnd) {
+				break
+			}
+
+			if activity.Start.After(covered) {
+				gaps = append(gaps, &ActivityGap{Start: covered, End: activity.Start})
+			}
+			covered = activity.End
+		}
+
+		if covered.Before(workEnd) {
+			gaps = append(gaps, &ActivityGap{Start: covered, End: workEnd})
+		}
+	}
+
+	return gaps
+}
+
+// wallClockAfter is the wall clock time of the day after the given
+// time since midnight, also on days with daylight saving time changes
+func wallClockAfter(day time.Time, sinceMidnight time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(sinceMidnight.Minutes()), 0, 0, day.Location())
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
 
@@ -218,7 +219,7 @@ func TestActivityFilterHome(t *testing.T) {
 	}
 
 	t.Run("String with year filter", func(t *testing.T) {
-		homeFilter := f.Home()
+		homeFilter := f.Home(time.UTC)
 		is.Equal(homeFilter.String(), fmt.Sprintf("%v", time.Now().Year()))
 	})
 
@@ -273,3 +274,234 @@ func TestIsValidActivitySortField(t *testing.T) {
 	})
 
 }
+
+func TestActivityAmount(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(2021, 11, 12, 11, 0, 0, 0, time.UTC)
+	activity := &Activity{
+		Start:      start,
+		End:        start.Add(90 * time.Minute),
+		Billable:   true,
+		HourlyRate: 80,
+	}
+
+	t.Run("billable activity", func(t *testing.T) {
+		is.Equal(120.0, activity.Amount())
+	})
+
+	t.Run("non billable activity", func(t *testing.T) {
+		activity.Billable = false
+		is.Equal(0.0, activity.Amount())
+	})
+}
+
+func TestFormatAmount(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal("120.50 USD", FormatAmount(120.5, "USD"))
+	is.Equal("0.00 EUR", FormatAmount(0, ""))
+}
+
+func TestParseTags(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal([]string{"meeting", "support"}, ParseTags(" Meeting, support,,meeting "))
+	is.Equal([]string{}, ParseTags(""))
+}
+
+func TestActivityFilterWithTag(t *testing.T) {
+	is := is.New(t)
+
+	filter := &ActivityFilter{
+		Timespan: TimespanWeek,
+		start:    time.Now(),
+	}
+
+	filterWithTag := filter.WithTag(" Meeting ")
+
+	is.Equal("meeting", filterWithTag.Tag())
+	is.Equal("", filter.Tag())
+	is.Equal("meeting", filterWithTag.Next().Tag())
+}
+
+func TestNewActivityProjectUserMatrix(t *testing.T) {
+	is := is.New(t)
+
+	otherProjectID := uuid.New()
+	users := []*ActivityUserReportItem{
+		{Username: "user1", Name: "User 1", DurationInMinutesTotal: 120},
+		{Username: "user2", Name: "User 2", DurationInMinutesTotal: 30},
+	}
+	reportItems := []*ActivityProjectUserReportItem{
+		{ProjectID: projectIDSample, ProjectTitle: "A", Username: "user1", DurationInMinutesTotal: 90},
+		{ProjectID: projectIDSample, ProjectTitle: "A", Username: "user2", DurationInMinutesTotal: 30},
+		{ProjectID: otherProjectID, ProjectTitle: "B", Username: "user1", DurationInMinutesTotal: 30},
+	}
+
+	matrix := NewActivityProjectUserMatrix(users, reportItems)
+
+	is.Equal(2, len(matrix.Projects))
+	is.Equal(projectIDSample, matrix.Projects[0].ProjectID)
+	is.Equal(90, matrix.Projects[0].DurationInMinutes("user1"))
+	is.Equal("2:00 h", matrix.Projects[0].TotalFormatted())
+	is.Equal(0, matrix.Projects[1].DurationInMinutes("user2"))
+	is.Equal("0:30 h", matrix.Projects[1].DurationFormatted("user1"))
+}
+
+func TestRollUpProjectReport(t *testing.T) {
+	is := is.New(t)
+
+	clientID := uuid.New()
+	subProjectID := uuid.New()
+	otherProjectID := uuid.New()
+	projects := []*Project{
+		{ID: projectIDSample, Title: "Website", ClientID: clientID, ClientTitle: "ACME"},
+		{ID: subProjectID, Title: "Design", ParentID: projectIDSample, ParentTitle: "Website", ClientID: clientID, ClientTitle: "ACME"},
+		{ID: otherProjectID, Title: "Internal"},
+	}
+	items := []*ActivityProjectReportItem{
+		{ProjectID: subProjectID, ProjectTitle: "Design", DurationInMinutesTotal: 60, BillableDurationInMinutesTotal: 60, Revenue: 100, Currency: "EUR"},
+		{ProjectID: otherProjectID, ProjectTitle: "Internal", DurationInMinutesTotal: 30, Currency: "EUR"},
+		{ProjectID: projectIDSample, ProjectTitle: "Website", DurationInMinutesTotal: 90, BillableDurationInMinutesTotal: 30, Revenue: 50, Currency: "USD"},
+	}
+
+	rollUps := RollUpProjectReport(items, projects)
+
+	is.Equal(2, len(rollUps.Clients))
+	is.Equal("ACME", rollUps.Clients[0].Title)
+	is.Equal("2:30 h", rollUps.Clients[0].DurationFormatted())
+	is.Equal("1:30 h", rollUps.Clients[0].BillableDurationFormatted())
+	is.Equal("100.00 EUR, 50.00 USD", rollUps.Clients[0].RevenueFormatted())
+	is.Equal("No Client", rollUps.Clients[1].Title)
+	is.Equal(30, rollUps.Clients[1].DurationInMinutesTotal)
+
+	is.Equal(1, len(rollUps.Parents))
+	is.Equal(projectIDSample, rollUps.Parents[0].ID)
+	is.Equal("Website", rollUps.Parents[0].Title)
+	is.Equal(150, rollUps.Parents[0].DurationInMinutesTotal)
+}
+
+func TestRollUpProjectReportWithoutHierarchy(t *testing.T) {
+	is := is.New(t)
+
+	projects := []*Project{
+		{ID: projectIDSample, Title: "Website"},
+	}
+	items := []*ActivityProjectReportItem{
+		{ProjectID: projectIDSample, ProjectTitle: "Website", DurationInMinutesTotal: 60},
+	}
+
+	rollUps := RollUpProjectReport(items, projects)
+
+	is.Equal(0, len(rollUps.Clients))
+	is.Equal(0, len(rollUps.Parents))
+}
+
+func TestFindActivityGaps(t *testing.T) {
+	is := is.New(t)
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	// friday 12.11.2021 until monday 15.11.2021
+	start := time.Date(2021, 11, 12, 0, 0, 0, 0, loc)
+	end := time.Date(2021, 11, 16, 0, 0, 0, 0, loc)
+
+	activities := []*Activity{
+		{
+			Start: time.Date(2021, 11, 12, 12, 0, 0, 0, loc),
+			End:   time.Date(2021, 11, 12, 13, 0, 0, 0, loc),
+		},
+		{
+			Start: time.Date(2021, 11, 12, 8, 0, 0, 0, loc),
+			End:   time.Date(2021, 11, 12, 10, 0, 0, 0, loc),
+		},
+		{
+			Start: time.Date(2021, 11, 12, 9, 30, 0, 0, loc),
+			End:   time.Date(2021, 11, 12, 11, 0, 0, 0, loc),
+		},
+		{
+			Start: time.Date(2021, 11, 15, 9, 0, 0, 0, loc),
+			End:   time.Date(2021, 11, 15, 18, 0, 0, 0, loc),
+		},
+	}
+
+	gaps := FindActivityGaps(activities, start, end, DefaultWorkingHours)
+
+	is.Equal(len(gaps), 2)
+	is.Equal(gaps[0].Start, time.Date(2021, 11, 12, 11, 0, 0, 0, loc))
+	is.Equal(gaps[0].End, time.Date(2021, 11, 12, 12, 0, 0, 0, loc))
+	is.Equal(gaps[0].DurationFormatted(), "1:00 h")
+	is.Equal(gaps[1].Start, time.Date(2021, 11, 12, 13, 0, 0, 0, loc))
+	is.Equal(gaps[1].End, time.Date(2021, 11, 12, 17, 0, 0, 0, loc))
+}
+
+func TestFindActivityGapsUntilEnd(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 11, 12, 10, 30, 0, 0, time.UTC)
+
+	gaps := FindActivityGaps(nil, start, end, DefaultWorkingHours)
+
+	is.Equal(len(gaps), 1)
+	is.Equal(gaps[0].Start, time.Date(2021, 11, 12, 9, 0, 0, 0, time.UTC))
+	is.Equal(gaps[0].End, end)
+}
+
+func TestWorkingHoursString(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(DefaultWorkingHours.String(), "09:00 - 17:00")
+	is.Equal(WorkingHours{Start: 8*time.Hour + 30*time.Minute, End: 16 * time.Hour}.String(), "08:30 - 16:00")
+}
+
+func TestActivityEndFormatted(t *testing.T) {
+	is := is.New(t)
+
+	activity := &Activity{
+		Start: time.Date(2021, 11, 12, 22, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 11, 12, 23, 30, 0, 0, time.UTC),
+	}
+	is.Equal(activity.EndFormatted(), "23:30")
+	is.True(!activity.EndsOnOtherDay())
+
+	activity.End = time.Date(2021, 11, 14, 6, 0, 0, 0, time.UTC)
+	is.Equal(activity.EndFormatted(), "14.11. 06:00")
+	is.True(activity.EndsOnOtherDay())
+	is.Equal(activity.DurationFormatted(), "32:00 h")
+}
+
+func TestActivityFilterWithSplitAtMidnight(t *testing.T) {
+	is := is.New(t)
+
+	filter := &ActivityFilter{
+		Timespan: TimespanWeek,
+		start:    time.Now(),
+	}
+
+	filterWithSplit := filter.WithSplitAtMidnight(true)
+
+	is.True(filterWithSplit.SplitAtMidnight())
+	is.True(!filter.SplitAtMidnight())
+	is.True(filterWithSplit.Next().SplitAtMidnight())
+	is.True(filterWithSplit.Previous().SplitAtMidnight())
+	is.True(filterWithSplit.Home(time.UTC).SplitAtMidnight())
+}
+
+func TestRunningActivityStopAt(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	runningActivity := &RunningActivity{
+		Start:     start,
+		ProjectID: projectIDSample,
+	}
+
+	activity := runningActivity.StopAt(start.Add(90 * time.Minute))
+	is.Equal(start.Add(90*time.Minute), activity.End)
+	is.Equal(projectIDSample, activity.ProjectID)
+
+	activitySameMinute := runningActivity.StopAt(start)
+	is.Equal(start.Add(time.Minute), activitySameMinute.End)
+}
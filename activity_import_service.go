@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/baralga/util"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrActivityImportFormatInvalid = errors.New("activity import format invalid")
+	ErrActivityImportTooLarge      = errors.New("activity import too large")
+)
+
+// Formats of activity imports
+const (
+	ActivityImportFormatCSV   = "csv"
+	ActivityImportFormatExcel = "excel"
+)
+
+const (
+	maxActivityImportRows = 5000
+	maxActivityImportSize = 10 << 20
+)
+
+// columns of the activity exports which are read on import,
+// derived columns like duration or amount are ignored
+const (
+	importColumnDate        = "date"
+	importColumnStart       = "start"
+	importColumnEnd         = "end"
+	importColumnEndDate     = "end date"
+	importColumnProject     = "project"
+	importColumnDescription = "description"
+	importColumnBillable    = "billable"
+)
+
+var importDateFormats = []string{"2006-01-02", "02.01.2006", "01-02-06"}
+
+// ReadActivityImportRecords reads the records of a CSV (semicolon separated) or
+// Excel file, which uses the column layout of the activity exports
+func ReadActivityImportRecords(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case ActivityImportFormatCSV:
+		csvReader := csv.NewReader(r)
+		csvReader.Comma = ';'
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+
+		records, err := csvReader.ReadAll()
+		if err != nil {
+			return nil, errors.Wrap(ErrActivityImportFormatInvalid, err.Error())
+		}
+		return records, nil
+	case ActivityImportFormatExcel:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, errors.Wrap(ErrActivityImportFormatInvalid, err.Error())
+		}
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, ErrActivityImportFormatInvalid
+		}
+
+		records, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, errors.Wrap(ErrActivityImportFormatInvalid, err.Error())
+		}
+		return records, nil
+	default:
+		return nil, ErrActivityImportFormatInvalid
+	}
+}
+
+// PreviewActivityImport reads and validates the activities of the records without storing them
+func (a *app) PreviewActivityImport(ctx context.Context, principal *Principal, records [][]string, createProjects bool) (*ActivityImport, error) {
+	if len(records) == 0 {
+		return nil, errors.Wrap(ErrActivityImportFormatInvalid, "header missing")
+	}
+	if len(records)-1 > maxActivityImportRows {
+		return nil, ErrActivityImportTooLarge
+	}
+
+	columns, err := readActivityImportColumns(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var titles []string
+	for _, record := range records[1:] {
+		title := columns.value(record, importColumnProject)
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+
+	projects, err := a.ProjectRepository.FindProjectsByTitles(ctx, principal.OrganizationID, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	// active projects come first, so they win over archived projects with the same title
+	projectsByTitle := make(map[string]*Project)
+	for _, project := range projects {
+		if _, ok := projectsByTitle[strings.ToLower(project.Title)]; !ok {
+			projectsByTitle[strings.ToLower(project.Title)] = project
+		}
+	}
+
+	projectIDs := make([]uuid.UUID, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	members, err := a.ProjectRepository.FindProjectMembers(ctx, principal.OrganizationID, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	membersByProject := make(map[uuid.UUID][]*ProjectMember)
+	for _, member := range members {
+		membersByProject[member.ProjectID] = append(membersByProject[member.ProjectID], member)
+	}
+
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	// only admins may create projects
+	createProjects = createProjects && permissions.IsAdmin()
+
+	lockingOrganization, err := a.readLockingOrganization(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	// the approval of the timesheets is read once per week
+	approvedWeeks := make(map[[2]int]bool)
+
+	activityImport := &ActivityImport{}
+	loc := principal.Location()
+	for i, record := range records[1:] {
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		row := &ActivityImportRow{
+			Line:         i + 2,
+			ProjectTitle: columns.value(record, importColumnProject),
+		}
+		activity := &Activity{
+			ID:             uuid.New(),
+			Description:    columns.value(record, importColumnDescription),
+			OrganizationID: principal.OrganizationID,
+			Username:       principal.Username,
+			Billable:       true,
+		}
+		row.Activity = activity
+
+		date, err := parseImportDate(columns.value(record, importColumnDate))
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Date '%v' is invalid.", columns.value(record, importColumnDate)))
+		}
+
+		start, err := parseImportTime(columns.value(record, importColumnStart))
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Start '%v' is invalid.", columns.value(record, importColumnStart)))
+		}
+
+		end, err := parseImportTime(columns.value(record, importColumnEnd))
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("End '%v' is invalid.", columns.value(record, importColumnEnd)))
+		}
+
+		// activities spanning midnight need the optional end date
+		endDate := date
+		if columns.value(record, importColumnEndDate) != "" {
+			endDate, err = parseImportDate(columns.value(record, importColumnEndDate))
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("End date '%v' is invalid.", columns.value(record, importColumnEndDate)))
+			}
+		}
+
+		if date != nil && endDate != nil && start != nil && end != nil {
+			activity.Start = time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			activity.End = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+			if errors.Is(activity.Validate(), ErrActivityEndNotAfterStart) {
+				row.Errors = append(row.Errors, "End must be after start.")
+			} else {
+				err = a.checkOverlapsRejected(ctx, principal, activity)
+				if err != nil && !errors.Is(err, ErrActivityOverlaps) {
+					return nil, err
+				}
+				if errors.Is(err, ErrActivityOverlaps) || (a.Config.RejectOverlaps && activityImport.overlaps(activity)) {
+					row.Errors = append(row.Errors, "Overlaps with other activities.")
+				}
+			}
+			if lockingOrganization != nil && lockingOrganization.IsLockedAt(activity.Start, loc) {
+				row.Errors = append(row.Errors, "Date is in a closed period.")
+			}
+
+			year, week := activity.Start.ISOWeek()
+			approved, ok := approvedWeeks[[2]int{year, week}]
+			if !ok {
+				approved, err = a.isTimesheetApproved(ctx, principal, principal.Username, year, week)
+				if err != nil {
+					return nil, err
+				}
+				approvedWeeks[[2]int{year, week}] = approved
+			}
+			if approved {
+				row.Errors = append(row.Errors, "Week is part of an approved timesheet.")
+			}
+		}
+
+		if len(activity.Description) > 500 {
+			row.Errors = append(row.Errors, "Description must not be longer than 500 characters.")
+		}
+
+		billable, err := parseImportBillable(columns.value(record, importColumnBillable))
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Billable '%v' is invalid.", columns.value(record, importColumnBillable)))
+		}
+		activity.Billable = billable
+
+		project, ok := projectsByTitle[strings.ToLower(row.ProjectTitle)]
+		switch {
+		case row.ProjectTitle == "":
+			row.Errors = append(row.Errors, "Project is missing.")
+		case len(row.ProjectTitle) > 100:
+			row.Errors = append(row.Errors, "Project must not be longer than 100 characters.")
+		case ok && !project.Active:
+			row.Errors = append(row.Errors, fmt.Sprintf("Project '%v' is archived.", project.Title))
+		case ok && !permissions.ManagesProject(project.ID) && !IsAssignedToProject(membersByProject[project.ID], principal.Username):
+			row.Errors = append(row.Errors, fmt.Sprintf("You are not assigned to project '%v'.", project.Title))
+		case ok:
+			activity.ProjectID = project.ID
+		case createProjects:
+			project := &Project{
+				ID:             uuid.New(),
+				Title:          row.ProjectTitle,
+				Active:         true,
+				OrganizationID: principal.OrganizationID,
+			}
+			projectsByTitle[strings.ToLower(project.Title)] = project
+			activityImport.NewProjects = append(activityImport.NewProjects, project)
+			activity.ProjectID = project.ID
+		default:
+			row.Errors = append(row.Errors, fmt.Sprintf("Project '%v' not found.", row.ProjectTitle))
+		}
+
+		activityImport.Rows = append(activityImport.Rows, row)
+	}
+
+	return activityImport, nil
+}
+
+// ImportActivities imports the activities of the records in a single transaction,
+// nothing is stored if one of the rows is invalid
+func (a *app) ImportActivities(ctx context.Context, principal *Principal, records [][]string, createProjects bool) (*ActivityImport, error) {
+	activityImport, err := a.PreviewActivityImport(ctx, principal, records, createProjects)
+	if err != nil {
+		return nil, err
+	}
+
+	if !activityImport.Valid() {
+		return activityImport, nil
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			for _, project := range activityImport.NewProjects {
+				projectCreated, err := a.ProjectRepository.InsertProject(ctx, project)
+				if err != nil {
+					return err
+				}
+				err = a.recordChange(ctx, principal, AuditActionCreate, AuditEntityProject, projectCreated.ID, nil, projectCreated)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, row := range activityImport.Rows {
+				activityCreated, err := a.ActivityRepository.InsertActivity(ctx, row.Activity)
+				if err != nil {
+					return err
+				}
+				err = a.recordChange(ctx, principal, AuditActionCreate, AuditEntityActivity, activityCreated.ID, nil, activityCreated)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	activityImport.Imported = true
+
+	projectIDs := make(map[uuid.UUID]bool)
+	for _, row := range activityImport.Rows {
+		if projectIDs[row.Activity.ProjectID] {
+			continue
+		}
+		projectIDs[row.Activity.ProjectID] = true
+		a.checkBudgetAlerts(ctx, principal, row.Activity.ProjectID)
+	}
+
+	return activityImport, nil
+}
+
+// activityImportColumns maps the columns of an import to their index
+type activityImportColumns map[string]int
+
+func readActivityImportColumns(header []string) (activityImportColumns, error) {
+	columns := make(activityImportColumns)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+
+	for _, name := range []string{importColumnDate, importColumnStart, importColumnEnd, importColumnProject} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Wrapf(ErrActivityImportFormatInvalid, "column %v missing", name)
+		}
+	}
+
+	return columns, nil
+}
+
+func (c activityImportColumns) value(record []string, name string) string {
+	i, ok := c[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseImportDate(value string) (*time.Time, error) {
+	for _, format := range importDateFormats {
+		date, err := time.Parse(format, value)
+		if err == nil {
+			return &date, nil
+		}
+	}
+	return nil, fmt.Errorf("could not parse date from '%s'", value)
+}
+
+func parseImportTime(value string) (*time.Time, error) {
+	t, err := time.Parse("15:04", util.CompleteTimeValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse time from '%s'", value)
+	}
+	return &t, nil
+}
+
+// parseImportBillable parses the billable column, activities are billable unless stated otherwise
+func parseImportBillable(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "yes", "true", "1":
+		return true, nil
+	case "no", "false", "0":
+		return false, nil
+	default:
+		return true, fmt.Errorf("could not parse billable from '%s'", value)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestReadActivityImportRecordsFromExport(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{}
+
+	start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
+	end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000Z")
+
+	activities := []*Activity{
+		{
+			Start:       start,
+			End:         end,
+			ProjectID:   projectIDSample,
+			Description: "My Description",
+		},
+	}
+	projects := []*Project{
+		{
+			ID:    projectIDSample,
+			Title: "My Project",
+		},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buffer bytes.Buffer
+		err := a.WriteAsCSV(activities, projects, &buffer)
+		is.NoErr(err)
+
+		records, err := ReadActivityImportRecords(&buffer, ActivityImportFormatCSV)
+
+		is.NoErr(err)
+		is.Equal(len(records), 2)
+		is.Equal(records[0][0], "Date")
+		is.Equal(records[1][4], "My Project")
+	})
+
+	t.Run("Excel", func(t *testing.T) {
+		var buffer bytes.Buffer
+		err := a.WriteAsExcel(activities, projects, &buffer)
+		is.NoErr(err)
+
+		records, err := ReadActivityImportRecords(&buffer, ActivityImportFormatExcel)
+
+		is.NoErr(err)
+		is.Equal(len(records), 2)
+		is.Equal(records[0][0], "Project")
+		is.Equal(records[1][0], "My Project")
+	})
+
+	t.Run("InvalidExcel", func(t *testing.T) {
+		_, err := ReadActivityImportRecords(strings.NewReader("no excel"), ActivityImportFormatExcel)
+
+		is.True(errors.Is(err, ErrActivityImportFormatInvalid))
+	})
+}
+
+func TestPreviewActivityImport(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Timezone:       "Europe/Berlin",
+	}
+
+	records := [][]string{
+		{"Project", "Date", "Start", "End", "Hours", "Description", "Billable"},
+		{"my project", "2021-11-12", "09:00", "10:30", "1.5", "Planning", "No"},
+		{},
+		{"My Project", "12.11.2021", "11", "11:15", "", "", ""},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.True(activityImport.Valid())
+	is.True(!activityImport.Imported)
+	is.Equal(len(activityImport.Rows), 2)
+
+	activity := activityImport.Rows[0].Activity
+	is.Equal(activity.ProjectID, projectIDSample)
+	is.Equal(activity.Username, "user1")
+	is.Equal(activity.Description, "Planning")
+	is.True(!activity.Billable)
+	is.Equal(activity.Start, time.Date(2021, 11, 12, 9, 0, 0, 0, principal.Location()))
+	is.Equal(activity.DurationMinutesTotal(), 90)
+
+	is.Equal(activityImport.Rows[1].Line, 4)
+	is.True(activityImport.Rows[1].Activity.Billable)
+	is.Equal(activityImport.Rows[1].Activity.DurationMinutesTotal(), 15)
+
+	is.Equal(len(activityRepository.activities), 1)
+}
+
+func TestPreviewActivityImportWithInvalidRows(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = false
+
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-13-12", "09:00", "10:00", "Unknown"},
+		{"2021-11-12", "10:00", "09:00", ""},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.True(!activityImport.Valid())
+	is.Equal(activityImport.ErrorCount(), 3)
+	is.Equal(activityImport.Rows[0].Errors, []string{"Date '2021-13-12' is invalid.", "Project 'Unknown' not found."})
+	is.Equal(activityImport.Rows[1].Errors, []string{"End must be after start.", "Project is missing."})
+	is.Equal(activityImport.Rows[2].Errors, []string{"Project 'My Project' is archived."})
+}
+
+func TestPreviewActivityImportWithMissingColumn(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	records := [][]string{
+		{"Date", "Start", "Project"},
+	}
+
+	_, err := a.PreviewActivityImport(context.Background(), &Principal{}, records, false)
+
+	is.True(errors.Is(err, ErrActivityImportFormatInvalid))
+}
+
+func TestImportActivities(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+		{"2021-11-12", "10:00", "11:00", "My Project"},
+	}
+
+	activityImport, err := a.ImportActivities(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.True(activityImport.Imported)
+	is.Equal(len(activityRepository.activities), 3)
+}
+
+func TestImportActivitiesWithInvalidRow(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+		{"2021-11-12", "10:00", "11:00", "Other Project"},
+	}
+
+	activityImport, err := a.ImportActivities(context.Background(), principal, records, true)
+
+	is.NoErr(err)
+	is.True(!activityImport.Imported)
+	is.Equal(activityImport.ErrorCount(), 1)
+	is.Equal(len(activityRepository.activities), 1)
+	is.Equal(len(projectRepository.projects), 1)
+}
+
+func TestImportActivitiesCreatingProjectsAsAdmin(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ProjectRepository:   projectRepository,
+		ActivityRepository:  activityRepository,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "Other Project"},
+		{"2021-11-12", "10:00", "11:00", "other project"},
+	}
+
+	activityImport, err := a.ImportActivities(context.Background(), principal, records, true)
+
+	is.NoErr(err)
+	is.True(activityImport.Imported)
+	is.Equal(len(activityImport.NewProjects), 1)
+	is.Equal(len(projectRepository.projects), 2)
+
+	newProject := projectRepository.projects[1]
+	is.Equal(newProject.Title, "Other Project")
+	is.True(newProject.Active)
+	is.True(newProject.ID != uuid.Nil)
+	is.Equal(activityImport.Rows[0].Activity.ProjectID, newProject.ID)
+	is.Equal(activityImport.Rows[1].Activity.ProjectID, newProject.ID)
+	is.Equal(len(activityRepository.activities), 3)
+}
+
+func TestPreviewActivityImportWithEndDate(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project", "End Date"},
+		{"2021-11-12", "22:00", "06:00", "My Project", "2021-11-13"},
+		{"2021-11-12", "09:00", "10:00", "My Project", ""},
+		{"2021-11-12", "22:00", "06:00", "My Project", "13.13.2021"},
+		{"2021-11-12", "08:00", "12:00", "My Project", "2021-11-14"},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.Equal(activityImport.ErrorCount(), 1)
+	is.Equal(activityImport.Rows[0].Activity.DurationMinutesTotal(), 480)
+	is.Equal(activityImport.Rows[1].Activity.DurationMinutesTotal(), 60)
+	is.Equal(activityImport.Rows[2].Errors, []string{"End date '13.13.2021' is invalid."})
+	is.Equal(activityImport.Rows[3].Activity.DurationMinutesTotal(), 52*60)
+}
+
+func TestPreviewActivityImportInClosedPeriod(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	organizationRepository := NewInMemOrganizationRepository()
+	lockedUntil := time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC)
+	organizationRepository.organizations[0].LockedUntil = &lockedUntil
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: organizationRepository,
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+		{"2021-11-13", "09:00", "10:00", "My Project"},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.Equal(activityImport.ErrorCount(), 1)
+	is.Equal(activityImport.Rows[0].Errors, []string{"Date is in a closed period."})
+}
+
+func TestPreviewActivityImportOnUnassignedProject(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user2", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.Equal(activityImport.ErrorCount(), 1)
+	is.Equal(activityImport.Rows[0].Errors, []string{"You are not assigned to project 'My Project'."})
+}
+
+func TestImportActivitiesWithRejectedOverlaps(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities[0].Start = time.Date(2021, 11, 12, 9, 0, 0, 0, time.UTC)
+	activityRepository.activities[0].End = time.Date(2021, 11, 12, 10, 0, 0, 0, time.UTC)
+	a := &app{
+		Config:                 &config{RejectOverlaps: true},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:30", "10:30", "My Project"},
+		{"2021-11-12", "11:00", "12:00", "My Project"},
+		{"2021-11-12", "11:30", "12:30", "My Project"},
+		{"2021-11-12", "12:30", "13:00", "My Project"},
+	}
+
+	activityImport, err := a.ImportActivities(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.True(!activityImport.Imported)
+	is.Equal(activityImport.ErrorCount(), 2)
+	is.Equal(activityImport.Rows[0].Errors, []string{"Overlaps with other activities."})
+	is.Equal(activityImport.Rows[2].Errors, []string{"Overlaps with other activities."})
+	is.Equal(len(activityRepository.activities), 1)
+}
+
+func TestPreviewActivityImportInApprovedTimesheet(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusApproved
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    timesheetRepository,
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	records := [][]string{
+		{"Date", "Start", "End", "Project"},
+		{"2021-11-12", "09:00", "10:00", "My Project"},
+		{"2021-11-15", "09:00", "10:00", "My Project"},
+	}
+
+	activityImport, err := a.PreviewActivityImport(context.Background(), principal, records, false)
+
+	is.NoErr(err)
+	is.Equal(activityImport.ErrorCount(), 1)
+	is.Equal(activityImport.Rows[0].Errors, []string{"Week is part of an approved timesheet."})
+}
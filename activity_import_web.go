@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type activityImportFormModel struct {
+	CSRFToken      string
+	Action         string
+	CreateProjects bool
+}
+
+func (a *app) HandleActivityImportPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		formModel := activityImportFormModel{}
+		formModel.CSRFToken = csrf.Token(r)
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Import Activities",
+		}
+
+		util.RenderHTML(w, ActivityImportPage(pageContext, formModel))
+	}
+}
+
+func (a *app) HandleActivityImportForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		records, err := readActivityImportUpload(w, r)
+		if err != nil {
+			util.RenderHTML(w, ActivityImportResultView(nil, "Please select a CSV or Excel file with the columns of the export."))
+			return
+		}
+
+		var formModel activityImportFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			util.RenderHTML(w, ActivityImportResultView(nil, ""))
+			return
+		}
+
+		var activityImport *ActivityImport
+		if formModel.Action == "import" {
+			activityImport, err = a.ImportActivities(r.Context(), principal, records, formModel.CreateProjects)
+		} else {
+			activityImport, err = a.PreviewActivityImport(r.Context(), principal, records, formModel.CreateProjects)
+		}
+		if errors.Is(err, ErrActivityImportFormatInvalid) {
+			util.RenderHTML(w, ActivityImportResultView(nil, "The file needs the columns Date, Start, End and Project like the export."))
+			return
+		}
+		if errors.Is(err, ErrActivityImportTooLarge) {
+			util.RenderHTML(w, ActivityImportResultView(nil, fmt.Sprintf("Please import at most %v activities at once.", maxActivityImportRows)))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		util.RenderHTML(w, ActivityImportResultView(activityImport, ""))
+	}
+}
+
+func ActivityImportPage(pageContext *pageContext, formModel activityImportFormModel) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Import Activities")),
+						P(
+							Class("text-muted"),
+							g.Text("Import activities from a CSV (semicolon separated) or Excel file in the layout of the export. "),
+							g.Text("The columns Date, Start, End and Project are required, Description, Billable and End Date are optional. "),
+							g.Text("Preview the import first, the activities are only imported if all rows are valid."),
+						),
+						A(
+							Href("/activities/calendar"),
+							I(Class("bi-calendar-event me-2")),
+							g.Text("Import events of your calendar instead"),
+						),
+					),
+					ActivityImportForm(pageContext.principal, formModel),
+					Div(
+						ID("baralga__activity_import_result"),
+					),
+				),
+			),
+		},
+	)
+}
+
+func ActivityImportForm(principal *Principal, formModel activityImportFormModel) g.Node {
+	var createProjects g.Node
+	if principal.HasRole("ROLE_ADMIN") {
+		createProjects = Div(
+			Class("form-check mb-3"),
+			Input(
+				ID("CreateProjects"),
+				Type("checkbox"),
+				Name("CreateProjects"),
+				Value("true"),
+				Class("form-check-input"),
+				g.If(formModel.CreateProjects, g.Attr("checked", "checked")),
+			),
+			Label(
+				Class("form-check-label"),
+				g.Attr("for", "CreateProjects"),
+				g.Text("Create missing projects"),
+			),
+		)
+	}
+
+	return FormEl(
+		ID("activity_import_form"),
+		Class("mb-4"),
+		hx.Post("/activities/import"),
+		hx.Encoding("multipart/form-data"),
+		hx.Target("#baralga__activity_import_result"),
+		hx.Swap("innerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		Div(
+			Class("mb-3"),
+			Input(
+				ID("ImportFile"),
+				Type("file"),
+				Name("file"),
+				Accept(".csv,.xlsx"),
+				g.Attr("required", "required"),
+				Class("form-control"),
+			),
+		),
+		createProjects,
+		Button(
+			Type("submit"),
+			Name("Action"),
+			Value("preview"),
+			Class("btn btn-outline-primary me-2"),
+			TitleAttr("Preview Import"),
+			I(Class("bi-eye me-2")),
+			g.Text("Preview"),
+		),
+		Button(
+			Type("submit"),
+			Name("Action"),
+			Value("import"),
+			Class("btn btn-primary"),
+			TitleAttr("Import Activities"),
+			I(Class("bi-upload me-2")),
+			g.Text("Import"),
+		),
+	)
+}
+
+func ActivityImportResultView(activityImport *ActivityImport, errorMessage string) g.Node {
+	if activityImport == nil {
+		return Div(
+			Class("alert alert-warning"),
+			Role("alert"),
+			g.Text(errorMessage),
+		)
+	}
+
+	var newProjects g.Node
+	if len(activityImport.NewProjects) > 0 {
+		titles := make([]string, len(activityImport.NewProjects))
+		for i, project := range activityImport.NewProjects {
+			titles[i] = project.Title
+		}
+		newProjects = P(
+			Class("mb-0"),
+			g.Textf("New projects: %v", strings.Join(titles, ", ")),
+		)
+	}
+
+	var summary g.Node
+	switch {
+	case activityImport.Imported:
+		summary = Div(
+			Class("alert alert-success"),
+			Role("alert"),
+			P(
+				g.Textf("Imported %v activities. ", len(activityImport.Rows)),
+				A(Href("/reports"), g.Text("Show report")),
+			),
+			newProjects,
+		)
+	case activityImport.Valid():
+		summary = Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			P(g.Textf("%v activities are ready to import.", len(activityImport.Rows))),
+			newProjects,
+		)
+	case len(activityImport.Rows) == 0:
+		summary = Div(
+			Class("alert alert-warning"),
+			Role("alert"),
+			g.Text("The file contains no activities."),
+		)
+	default:
+		summary = Div(
+			Class("alert alert-warning"),
+			Role("alert"),
+			g.Textf(
+				"%v of %v rows have errors, nothing is imported until all rows are valid.",
+				activityImport.ErrorCount(),
+				len(activityImport.Rows),
+			),
+		)
+	}
+
+	return Div(
+		summary,
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("activity-import"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(g.Text("Line")),
+						Th(g.Text("Date")),
+						Th(g.Text("Time")),
+						Th(g.Text("Project")),
+						Th(g.Text("Description")),
+						Th(g.Text("Billable")),
+						Th(g.Text("Errors")),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(activityImport.Rows), func(i int) g.Node {
+						row := activityImport.Rows[i]
+						activity := row.Activity
+
+						date := ""
+						timeRange := ""
+						if !activity.Start.IsZero() {
+							date = util.FormatDateDE(activity.Start)
+							timeRange = fmt.Sprintf("%v - %v", util.FormatTime(activity.Start), activity.EndFormatted())
+						}
+
+						var rowClass g.Node
+						if !row.Valid() {
+							rowClass = Class("table-danger")
+						}
+
+						return Tr(
+							rowClass,
+							Td(g.Textf("%v", row.Line)),
+							Td(g.Text(date)),
+							Td(g.Text(timeRange)),
+							Td(g.Text(row.ProjectTitle)),
+							Td(g.Text(activity.Description)),
+							Td(g.Text(formatBillable(activity.Billable))),
+							Td(
+								Class("text-danger"),
+								g.Text(strings.Join(row.Errors, " ")),
+							),
+						)
+					})),
+				),
+			),
+		),
+	)
+}
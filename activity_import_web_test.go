@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestHandleActivityImportPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config: &config{},
+	}
+
+	principal := &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}
+
+	r, _ := http.NewRequest("GET", "/activities/import", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityImportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "activity_import_form"))
+	is.True(strings.Contains(htmlBody, "CreateProjects"))
+}
+
+func TestHandleActivityImportPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config: &config{},
+	}
+
+	r, _ := http.NewRequest("GET", "/activities/import", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleActivityImportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "activity_import_form"))
+	is.True(!strings.Contains(htmlBody, "CreateProjects"))
+}
+
+func TestHandleActivityImportFormPreview(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	fields := url.Values{}
+	fields.Add("Action", "preview")
+
+	r := newActivityImportRequest(t, "/activities/import", "activities.csv", "Date;Start;End;Project\n2021-11-12;09:00;10:00;My Project\n2021-11-12;09:00;10:00;Unknown\n", fields)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleActivityImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore, len(repo.activities))
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "1 of 2 rows have errors"))
+	is.True(strings.Contains(htmlBody, "Project &#39;Unknown&#39; not found."))
+}
+
+func TestHandleActivityImportFormImport(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ActivityRepository:  repo,
+		ProjectRepository:   projectRepository,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}
+
+	countBefore := len(repo.activities)
+
+	fields := url.Values{}
+	fields.Add("Action", "import")
+	fields.Add("CreateProjects", "true")
+
+	r := newActivityImportRequest(t, "/activities/import", "activities.csv", "Date;Start;End;Project\n2021-11-12;09:00;10:00;New Project\n", fields)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore+1, len(repo.activities))
+	is.Equal(len(projectRepository.projects), 2)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Imported 1 activities."))
+	is.True(strings.Contains(htmlBody, "New projects: New Project"))
+}
+
+func TestHandleActivityImportFormWithoutFile(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config: &config{},
+	}
+
+	data := url.Values{}
+	data.Set("Action", "preview")
+
+	r, _ := http.NewRequest("POST", "/activities/import", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleActivityImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "Please select a CSV or Excel file"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -108,6 +109,80 @@ func TestActivityRepository(t *testing.T) {
 		is.True(activityiesPage != nil)
 	})
 
+	t.Run("FindActivitiesOfManagedProjects", func(t *testing.T) {
+		filter := &ActivitiesFilter{
+			Start:          time.Now().AddDate(-1, 0, 0),
+			End:            time.Now(),
+			Username:       "user1",
+			OrganizationID: organizationIDSample,
+		}
+		activityiesPage, _, err := activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+
+		is.NoErr(err)
+		is.Equal(activityiesPage.Page.TotalElements, 0)
+
+		filter.ManagedProjectIDs = []uuid.UUID{projectIDSample}
+		activityiesPage, _, err = activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+
+		is.NoErr(err)
+		is.Equal(len(activityiesPage.Activities), 1)
+		is.Equal(activityiesPage.Page.TotalElements, 1)
+	})
+
+	t.Run("FindActivitiesByProjectsAndUsernamesAndDescription", func(t *testing.T) {
+		filter := &ActivitiesFilter{
+			Start:          time.Now().AddDate(-1, 0, 0),
+			End:            time.Now(),
+			ProjectIDs:     []uuid.UUID{projectIDSample},
+			Usernames:      []string{"admin", "user1"},
+			OrganizationID: organizationIDSample,
+		}
+		activityiesPage, _, err := activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+
+		is.NoErr(err)
+		is.Equal(len(activityiesPage.Activities), 1)
+		is.Equal(activityiesPage.Page.TotalElements, 1)
+
+		filter.Description = "-no_match%-"
+		activityiesPage, _, err = activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+
+		is.NoErr(err)
+		is.Equal(len(activityiesPage.Activities), 0)
+		is.Equal(activityiesPage.Page.TotalElements, 0)
+
+		reportItems, err := activityRepository.ProjectReport(context.Background(), filter)
+		is.NoErr(err)
+		is.Equal(len(reportItems), 0)
+	})
+
 	t.Run("InsertAndFindAndDeleteActivity", func(t *testing.T) {
 		start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
 		end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000Z")
@@ -175,6 +250,52 @@ func TestActivityRepository(t *testing.T) {
 		is.NoErr(err)
 	})
 
+	t.Run("InsertAndFindOverlappingActivities", func(t *testing.T) {
+		start, _ := time.Parse(time.RFC3339, "2021-11-15T11:00:00.000Z")
+		end, _ := time.Parse(time.RFC3339, "2021-11-15T12:00:00.000Z")
+
+		activtiy := &Activity{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Start:          start,
+			End:            end,
+			Username:       "user1",
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := activityRepository.InsertActivity(
+					ctx,
+					activtiy,
+				)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		overlaps, err := activityRepository.FindOverlappingActivities(context.Background(), organizationIDSample, "user1", start.Add(30*time.Minute), end.Add(30*time.Minute), uuid.Nil)
+		is.NoErr(err)
+		is.Equal(len(overlaps), 1)
+		is.Equal(overlaps[0].ID, activtiy.ID)
+
+		overlaps, err = activityRepository.FindOverlappingActivities(context.Background(), organizationIDSample, "user1", end, end.Add(30*time.Minute), uuid.Nil)
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+
+		overlaps, err = activityRepository.FindOverlappingActivities(context.Background(), organizationIDSample, "user1", start, end, activtiy.ID)
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+
+		overlaps, err = activityRepository.FindOverlappingActivities(context.Background(), organizationIDSample, "admin", start, end, uuid.Nil)
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+
+		err = activityRepository.DeleteActivityByID(context.Background(), activtiy.OrganizationID, activtiy.ID)
+		is.NoErr(err)
+	})
+
 	t.Run("FindNonExistingActivityByID", func(t *testing.T) {
 		_, err := activityRepository.FindActivityByID(
 			context.Background(),
@@ -212,6 +333,84 @@ func TestActivityRepository(t *testing.T) {
 		is.True(errors.Is(err, ErrActivityNotFound))
 	})
 
+	t.Run("InsertAndFindAndReportActivityWithTags", func(t *testing.T) {
+		start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
+		end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000Z")
+
+		activity := &Activity{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Start:          start,
+			End:            end,
+			Username:       "user1@baralga.com",
+			Tags:           []string{"meeting", "support"},
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := activityRepository.InsertActivity(ctx, activity)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		activityFound, err := activityRepository.FindActivityByID(
+			context.Background(),
+			activity.ID,
+			organizationIDSample,
+		)
+		is.NoErr(err)
+		is.Equal([]string{"meeting", "support"}, activityFound.Tags)
+
+		activity.Tags = []string{"support"}
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := activityRepository.UpdateActivity(ctx, organizationIDSample, activity)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		filter := &ActivitiesFilter{
+			Start:          start.AddDate(0, 0, -1),
+			End:            end.AddDate(0, 0, 1),
+			OrganizationID: organizationIDSample,
+			Tag:            "support",
+		}
+		activitiesPage, _, err := activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+		is.NoErr(err)
+		is.Equal(len(activitiesPage.Activities), 1)
+		is.Equal([]string{"support"}, activitiesPage.Activities[0].Tags)
+
+		reportItems, err := activityRepository.TagReport(context.Background(), filter)
+		is.NoErr(err)
+		is.Equal(len(reportItems), 1)
+		is.Equal("support", reportItems[0].Tag)
+		is.Equal(30, reportItems[0].DurationInMinutesTotal)
+
+		filter.Tag = "meeting"
+		activitiesPage, _, err = activityRepository.FindActivities(
+			context.Background(),
+			filter,
+			&paged.PageParams{
+				Page: 0,
+				Size: 50,
+			},
+		)
+		is.NoErr(err)
+		is.Equal(len(activitiesPage.Activities), 0)
+	})
+
 	t.Run("InsertAndUpdateActivity", func(t *testing.T) {
 		start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
 		end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000Z")
@@ -450,6 +649,87 @@ func TestActivityRepositoryReports(t *testing.T) {
 		is.Equal(len(reportItems), 1)
 		is.Equal(300, reportItems[0].DurationInMinutesTotal)
 	})
+
+	t.Run("UserReport", func(t *testing.T) {
+		// Arrange
+
+		// Act
+		reportItems, err := activityRepository.UserReport(
+			context.Background(),
+			filter,
+		)
+
+		// Assert
+		is.NoErr(err)
+		is.True(len(reportItems) > 0)
+
+		durationInMinutesTotal := 0
+		for _, reportItem := range reportItems {
+			durationInMinutesTotal += reportItem.DurationInMinutesTotal
+		}
+		is.Equal(300, durationInMinutesTotal)
+	})
+
+	t.Run("ProjectUserReport", func(t *testing.T) {
+		// Arrange
+
+		// Act
+		reportItems, err := activityRepository.ProjectUserReport(
+			context.Background(),
+			filter,
+		)
+
+		// Assert
+		is.NoErr(err)
+		is.True(len(reportItems) > 0)
+		is.Equal(projectIDSample, reportItems[0].ProjectID)
+	})
+
+	t.Run("TimeReportByDayForActivitySpanningMidnight", func(t *testing.T) {
+		// Arrange
+		_, err := connPool.Exec(
+			context.Background(),
+			fmt.Sprintf(
+				`INSERT INTO activities 
+				(activity_id, start_time, end_time, description, project_id, org_id, username) 
+				VALUES 
+				('%v', '2022-02-10 22:00:00-00', '2022-02-12 02:30:00-00', 'Night Shift', '%v', '%v', 'night')`,
+				uuid.New().String(),
+				projectIDSample,
+				organizationIDSample,
+			),
+		)
+		is.NoErr(err)
+
+		nightFilter := *filter
+		nightFilter.Username = "night"
+
+		// Act
+		reportItems, err := activityRepository.TimeReportByDay(
+			context.Background(),
+			&nightFilter,
+		)
+
+		// Assert
+		is.NoErr(err)
+		is.Equal(len(reportItems), 1)
+		is.Equal(1710, reportItems[0].DurationInMinutesTotal)
+
+		// Act
+		nightFilter.SplitAtMidnight = true
+		reportItems, err = activityRepository.TimeReportByDay(
+			context.Background(),
+			&nightFilter,
+		)
+
+		// Assert
+		is.NoErr(err)
+		is.Equal(len(reportItems), 3)
+		is.Equal(12, reportItems[0].Day)
+		is.Equal(150, reportItems[0].DurationInMinutesTotal)
+		is.Equal(1440, reportItems[1].DurationInMinutesTotal)
+		is.Equal(120, reportItems[2].DurationInMinutesTotal)
+	})
 }
 
 type InMemActivityRepository struct {
@@ -472,19 +752,50 @@ func NewInMemActivityRepository() *InMemActivityRepository {
 }
 
 func (r *InMemActivityRepository) TimeReportByDay(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
+	loc, err := time.LoadLocation(filter.timezone())
+	if err != nil {
+		return nil, err
+	}
+
 	var reportItems []*ActivityTimeReportItem
+	reportItemsByDay := make(map[time.Time]*ActivityTimeReportItem)
+	addPart := func(partStart, partEnd time.Time) {
+		day := time.Date(partStart.Year(), partStart.Month(), partStart.Day(), 0, 0, 0, 0, loc)
+		reportItem, ok := reportItemsByDay[day]
+		if !ok {
+			_, w := day.ISOWeek()
+			reportItem = &ActivityTimeReportItem{
+				Year:    day.Year(),
+				Month:   int(day.Month()),
+				Quarter: util.Quarter(day),
+				Week:    w,
+				Day:     day.Day(),
+			}
+			reportItemsByDay[day] = reportItem
+			reportItems = append(reportItems, reportItem)
+		}
+		reportItem.DurationInMinutesTotal += int(partEnd.Sub(partStart).Minutes())
+	}
+
 	for _, a := range r.activities {
-		_, w := a.Start.ISOWeek()
-		reportItem := &ActivityTimeReportItem{
-			Year:                   a.Start.Year(),
-			Month:                  int(a.Start.Month()),
-			Quarter:                util.Quarter(a.Start),
-			Week:                   w,
-			Day:                    a.Start.Day(),
-			DurationInMinutesTotal: 60,
+		partStart := a.Start.In(loc)
+		end := a.End.In(loc)
+		for {
+			partEnd := end
+			if filter.SplitAtMidnight {
+				midnight := time.Date(partStart.Year(), partStart.Month(), partStart.Day()+1, 0, 0, 0, 0, loc)
+				if midnight.Before(end) {
+					partEnd = midnight
+				}
+			}
+			addPart(partStart, partEnd)
+			if !partEnd.Before(end) {
+				break
+			}
+			partStart = partEnd
 		}
-		reportItems = append(reportItems, reportItem)
 	}
+
 	return reportItems, nil
 }
 
@@ -541,13 +852,132 @@ func (r *InMemActivityRepository) ProjectReport(ctx context.Context, filter *Act
 	return reportItems, nil
 }
 
+// matches checks whether the activity matches the criteria of the filter
+func (f *ActivitiesFilter) matches(a *Activity) bool {
+	if f.Username != "" && a.Username != f.Username && !containsUUID(f.ManagedProjectIDs, a.ProjectID) {
+		return false
+	}
+
+	if len(f.Usernames) > 0 && !containsString(f.Usernames, a.Username) {
+		return false
+	}
+
+	if len(f.ProjectIDs) > 0 {
+		found := false
+		for _, projectID := range f.ProjectIDs {
+			found = found || projectID == a.ProjectID
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Description != "" && !strings.Contains(strings.ToLower(a.Description), strings.ToLower(f.Description)) {
+		return false
+	}
+
+	if f.Tag != "" && !containsString(a.Tags, f.Tag) {
+		return false
+	}
+
+	return true
+}
+
+func containsUUID(values []uuid.UUID, value uuid.UUID) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemActivityRepository) TagReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTagReportItem, error) {
+	durationsByTag := make(map[string]int)
+	var tags []string
+	for _, a := range r.activities {
+		for _, tag := range a.Tags {
+			if _, ok := durationsByTag[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			durationsByTag[tag] += a.DurationMinutesTotal()
+		}
+	}
+
+	var reportItems []*ActivityTagReportItem
+	for _, tag := range tags {
+		reportItem := &ActivityTagReportItem{
+			Tag:                    tag,
+			DurationInMinutesTotal: durationsByTag[tag],
+		}
+		reportItems = append(reportItems, reportItem)
+	}
+	return reportItems, nil
+}
+
+func (r *InMemActivityRepository) UserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityUserReportItem, error) {
+	var reportItems []*ActivityUserReportItem
+	reportItemsByUsername := make(map[string]*ActivityUserReportItem)
+	for _, a := range r.activities {
+		if !filter.matches(a) {
+			continue
+		}
+
+		reportItem, ok := reportItemsByUsername[a.Username]
+		if !ok {
+			reportItem = &ActivityUserReportItem{
+				Username: a.Username,
+				Name:     a.Username,
+			}
+			reportItemsByUsername[a.Username] = reportItem
+			reportItems = append(reportItems, reportItem)
+		}
+		reportItem.DurationInMinutesTotal += a.DurationMinutesTotal()
+	}
+	return reportItems, nil
+}
+
+func (r *InMemActivityRepository) ProjectUserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityProjectUserReportItem, error) {
+	var reportItems []*ActivityProjectUserReportItem
+	for _, a := range r.activities {
+		if !filter.matches(a) {
+			continue
+		}
+
+		reportItem := &ActivityProjectUserReportItem{
+			ProjectID:              a.ProjectID,
+			ProjectTitle:           "My Project",
+			Username:               a.Username,
+			DurationInMinutesTotal: a.DurationMinutesTotal(),
+		}
+		reportItems = append(reportItems, reportItem)
+	}
+	return reportItems, nil
+}
+
 func (r *InMemActivityRepository) FindActivities(ctx context.Context, filter *ActivitiesFilter, pageParams *paged.PageParams) (*ActivitiesPaged, []*Project, error) {
+	activities := []*Activity{}
+	for _, a := range r.activities {
+		if filter.matches(a) {
+			activities = append(activities, a)
+		}
+	}
+
 	activitiesPage := &ActivitiesPaged{
-		Activities: r.activities,
+		Activities: activities,
 		Page: &paged.Page{
-			Size:          len(r.activities),
+			Size:          len(activities),
 			Number:        0,
-			TotalElements: len(r.activities),
+			TotalElements: len(activities),
 			TotalPages:    1,
 		},
 	}
@@ -571,6 +1001,17 @@ func (r *InMemActivityRepository) FindActivityByID(ctx context.Context, activity
 	return nil, ErrActivityNotFound
 }
 
+func (r *InMemActivityRepository) FindOverlappingActivities(ctx context.Context, organizationID uuid.UUID, username string, start, end time.Time, excludedActivityID uuid.UUID) ([]*Activity, error) {
+	var activities []*Activity
+	for _, a := range r.activities {
+		if a.OrganizationID == organizationID && a.Username == username && a.ID != excludedActivityID &&
+			a.Start.Before(end) && start.Before(a.End) {
+			activities = append(activities, a)
+		}
+	}
+	return activities, nil
+}
+
 func (r *InMemActivityRepository) InsertActivity(ctx context.Context, activity *Activity) (*Activity, error) {
 	r.activities = append(r.activities, activity)
 	return activity, nil
@@ -616,6 +1057,37 @@ func (r *InMemActivityRepository) UpdateActivityByUsername(ctx context.Context,
 	return nil, ErrActivityNotFound
 }
 
+func (r *InMemActivityRepository) FindProjectUsage(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectUsage, error) {
+	usage := &ProjectUsage{}
+	for _, a := range r.activities {
+		if a.ProjectID == projectID {
+			usage.ActivityCount++
+			usage.DurationInMinutesTotal += a.DurationMinutesTotal()
+		}
+	}
+	return usage, nil
+}
+
+func (r *InMemActivityRepository) FindProjectActivities(ctx context.Context, organizationID, projectID uuid.UUID) ([]*Activity, error) {
+	var activities []*Activity
+	for _, a := range r.activities {
+		if a.ProjectID == projectID {
+			activity := *a
+			activities = append(activities, &activity)
+		}
+	}
+	return activities, nil
+}
+
+func (r *InMemActivityRepository) ReassignProjectActivities(ctx context.Context, organizationID, projectID, targetProjectID uuid.UUID) error {
+	for _, a := range r.activities {
+		if a.ProjectID == projectID {
+			a.ProjectID = targetProjectID
+		}
+	}
+	return nil
+}
+
 func insertSampleActivitiesForReports(ctx context.Context, connPool *pgxpool.Pool) error {
 	_, err := connPool.Exec(
 		ctx,
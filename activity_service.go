@@ -1,31 +1,66 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/baralga/paged"
+	"github.com/baralga/util"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/xuri/excelize/v2"
 )
 
+var (
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	ErrActivityOverlaps     = errors.New("activity overlaps")
+)
+
+const maxCalendarFeedActivities = 1000
+
 // ReadActivitiesWithProjects reads activities with their associated projects
 func (a *app) ReadActivitiesWithProjects(ctx context.Context, principal *Principal, filter *ActivityFilter, pageParams *paged.PageParams) (*ActivitiesPaged, []*Project, error) {
-	activitiesFilter := toFilter(principal, filter)
+	activitiesFilter, err := a.readActivitiesFilter(ctx, principal, filter)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	activitiesPage, projects, err := a.ActivityRepository.FindActivities(ctx, activitiesFilter, pageParams)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	loc := principal.Location()
+	for _, activity := range activitiesPage.Activities {
+		activity.In(loc)
+	}
+
 	return activitiesPage, projects, err
 }
 
+// ReadActivity reads an activity in the time zone of the principal
+func (a *app) ReadActivity(ctx context.Context, principal *Principal, activityID uuid.UUID) (*Activity, error) {
+	activity, err := a.ActivityRepository.FindActivityByID(ctx, activityID, principal.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity.In(principal.Location())
+	return activity, nil
+}
+
 func (a *app) TimeReports(ctx context.Context, principal *Principal, filter *ActivityFilter, aggregateBy string) ([]*ActivityTimeReportItem, error) {
-	activitiesFilter := toFilter(principal, filter)
+	activitiesFilter, err := a.readActivitiesFilter(ctx, principal, filter)
+	if err != nil {
+		return nil, err
+	}
 
 	switch {
 	case aggregateBy == "week":
@@ -42,96 +77,403 @@ func (a *app) TimeReports(ctx context.Context, principal *Principal, filter *Act
 }
 
 func (a *app) ProjectReports(ctx context.Context, principal *Principal, filter *ActivityFilter) ([]*ActivityProjectReportItem, error) {
-	activitiesFilter := toFilter(principal, filter)
+	activitiesFilter, err := a.readActivitiesFilter(ctx, principal, filter)
+	if err != nil {
+		return nil, err
+	}
 	return a.ActivityRepository.ProjectReport(ctx, activitiesFilter)
 }
 
-// CreateActivity creates a new activity
+// ProjectReportRollUps rolls up the project report items per client and per parent project
+func (a *app) ProjectReportRollUps(ctx context.Context, principal *Principal, items []*ActivityProjectReportItem) (*ActivityProjectRollUps, error) {
+	projectIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		projectIDs[i] = item.ProjectID
+	}
+
+	projects, err := a.ProjectRepository.FindProjectsByIDs(ctx, principal.OrganizationID, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return RollUpProjectReport(items, projects), nil
+}
+
+func (a *app) TagReports(ctx context.Context, principal *Principal, filter *ActivityFilter) ([]*ActivityTagReportItem, error) {
+	activitiesFilter, err := a.readActivitiesFilter(ctx, principal, filter)
+	if err != nil {
+		return nil, err
+	}
+	return a.ActivityRepository.TagReport(ctx, activitiesFilter)
+}
+
+// UserReports reads the time spent per user and the time spent per project and user,
+// project managers only read the time spent on the projects they manage
+func (a *app) UserReports(ctx context.Context, principal *Principal, filter *ActivityFilter) ([]*ActivityUserReportItem, *ActivityProjectUserMatrix, error) {
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !permissions.CanReadReportsOfOthers() {
+		return nil, nil, ErrNoPermission
+	}
+
+	activitiesFilter := toFilter(principal, permissions, filter)
+
+	userReports, err := a.ActivityRepository.UserReport(ctx, activitiesFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projectUserReports, err := a.ActivityRepository.ProjectUserReport(ctx, activitiesFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return userReports, NewActivityProjectUserMatrix(userReports, projectUserReports), nil
+}
+
+// AuthenticateCalendarFeed authenticates the user of the calendar feed by its secret
+func (a *app) AuthenticateCalendarFeed(ctx context.Context, secret string) (*Principal, error) {
+	principal, apiToken, err := a.AuthenticateApiToken(ctx, secret)
+	if errors.Is(err, ErrApiTokenInvalid) {
+		return nil, ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if apiToken.Scope != ApiTokenScopeCalendar {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	return principal, nil
+}
+
+// ReadCalendarFeed reads the activities of the user of the calendar feed with their projects
+func (a *app) ReadCalendarFeed(ctx context.Context, principal *Principal, filter *ActivityFilter) ([]*Activity, []*Project, error) {
+	// the calendar feed only contains the own activities, also for admins
+	activitiesFilter := toFilter(principal, NewPermissions(principal, nil), filter)
+	activitiesFilter.Username = principal.Username
+	activitiesFilter.Usernames = nil
+
+	pageParams := &paged.PageParams{
+		Page: 0,
+		Size: maxCalendarFeedActivities,
+	}
+
+	activitiesPage, projects, err := a.ActivityRepository.FindActivities(ctx, activitiesFilter, pageParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return activitiesPage.Activities, projects, nil
+}
+
+// CreateActivity creates a new activity, activities in closed periods, approved timesheets or
+// on projects the principal isn't assigned to are rejected
 func (a *app) CreateActivity(ctx context.Context, principal *Principal, activity *Activity) (*Activity, error) {
+	err := a.checkActivityCreatable(ctx, principal, activity)
+	if err != nil {
+		return nil, err
+	}
+
 	activity.ID = uuid.New()
 	activity.OrganizationID = principal.OrganizationID
 	activity.Username = principal.Username
 
 	var newActivity *Activity
-	err := a.RepositoryTxer.InTx(
+	err = a.RepositoryTxer.InTx(
 		ctx,
 		func(ctx context.Context) error {
-			a, err := a.ActivityRepository.InsertActivity(ctx, activity)
+			activityCreated, err := a.ActivityRepository.InsertActivity(ctx, activity)
 			if err != nil {
 				return err
 			}
-			newActivity = a
-			return nil
+			newActivity = activityCreated
+			return a.recordChange(ctx, principal, AuditActionCreate, AuditEntityActivity, activityCreated.ID, nil, activityCreated)
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	a.checkBudgetAlerts(ctx, principal, newActivity.ProjectID)
+
 	return newActivity, nil
 }
 
-// DeleteActivityByID deletes an activity
+// CreateActivities creates the activities in a single transaction, nothing is created
+// if one of the activities is rejected like by CreateActivity
+func (a *app) CreateActivities(ctx context.Context, principal *Principal, activities []*Activity) ([]*Activity, error) {
+	for i, activity := range activities {
+		err := a.checkActivityCreatable(ctx, principal, activity)
+		if err != nil {
+			return nil, err
+		}
+
+		if a.Config.RejectOverlaps && OverlapsAny(activity, activities[:i]) {
+			return nil, ErrActivityOverlaps
+		}
+
+		activity.ID = uuid.New()
+		activity.OrganizationID = principal.OrganizationID
+		activity.Username = principal.Username
+	}
+
+	var newActivities []*Activity
+	err := a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			for _, activity := range activities {
+				activityCreated, err := a.ActivityRepository.InsertActivity(ctx, activity)
+				if err != nil {
+					return err
+				}
+				newActivities = append(newActivities, activityCreated)
+				err = a.recordChange(ctx, principal, AuditActionCreate, AuditEntityActivity, activityCreated.ID, nil, activityCreated)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs := make(map[uuid.UUID]bool)
+	for _, activity := range newActivities {
+		if projectIDs[activity.ProjectID] {
+			continue
+		}
+		projectIDs[activity.ProjectID] = true
+		a.checkBudgetAlerts(ctx, principal, activity.ProjectID)
+	}
+
+	return newActivities, nil
+}
+
+// checkActivityCreatable checks that the principal may create the activity,
+// i.e. it's bookable and doesn't overlap if overlaps are rejected
+func (a *app) checkActivityCreatable(ctx context.Context, principal *Principal, activity *Activity) error {
+	err := a.checkActivityBookable(ctx, principal, activity)
+	if err != nil {
+		return err
+	}
+
+	return a.checkOverlapsRejected(ctx, principal, activity)
+}
+
+// checkActivityBookable checks that the principal may book a new activity, i.e. it's not in a closed
+// period or an approved timesheet and on a project the principal is assigned to
+func (a *app) checkActivityBookable(ctx context.Context, principal *Principal, activity *Activity) error {
+	err := a.checkActivityLocked(ctx, principal, principal.Username, activity.Start)
+	if err != nil {
+		return err
+	}
+
+	return a.checkProjectAssigned(ctx, principal, activity.ProjectID)
+}
+
+// ReadOverlappingActivities reads the other activities of the activity's user which overlap with the activity
+func (a *app) ReadOverlappingActivities(ctx context.Context, principal *Principal, activity *Activity) ([]*Activity, error) {
+	username := principal.Username
+
+	// admins and project managers may update the activities of other users
+	if activity.ID != uuid.Nil {
+		existingActivity, err := a.ActivityRepository.FindActivityByID(ctx, activity.ID, principal.OrganizationID)
+		if err != nil && !errors.Is(err, ErrActivityNotFound) {
+			return nil, err
+		}
+		if existingActivity != nil && existingActivity.Username != principal.Username {
+			permissions, err := a.ReadPermissions(ctx, principal)
+			if err != nil {
+				return nil, err
+			}
+			if permissions.CanChangeActivity(existingActivity) {
+				username = existingActivity.Username
+			}
+		}
+	}
+
+	overlaps, err := a.ActivityRepository.FindOverlappingActivities(ctx, principal.OrganizationID, username, activity.Start, activity.End, activity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := principal.Location()
+	for _, overlap := range overlaps {
+		overlap.In(loc)
+	}
+
+	return overlaps, nil
+}
+
+// checkOverlapsRejected checks that the activity doesn't overlap with other activities
+// of its user, if overlaps are rejected by the configuration
+func (a *app) checkOverlapsRejected(ctx context.Context, principal *Principal, activity *Activity) error {
+	if !a.Config.RejectOverlaps {
+		return nil
+	}
+
+	overlaps, err := a.ReadOverlappingActivities(ctx, principal, activity)
+	if err != nil {
+		return err
+	}
+
+	if len(overlaps) > 0 {
+		return ErrActivityOverlaps
+	}
+
+	return nil
+}
+
+// ReadActivityGaps reads the gaps between the own activities of the principal
+// within the working hours of the filter's timespan until now
+func (a *app) ReadActivityGaps(ctx context.Context, principal *Principal, filter *ActivityFilter, workingHours WorkingHours) ([]*ActivityGap, error) {
+	loc := principal.Location()
+	start := util.WallClockIn(filter.Start(), loc)
+	end := util.WallClockIn(filter.End(), loc)
+
+	now := time.Now().In(loc)
+	if end.After(now) {
+		end = now
+	}
+	if !start.Before(end) {
+		return nil, nil
+	}
+
+	activities, err := a.ActivityRepository.FindOverlappingActivities(ctx, principal.OrganizationID, principal.Username, start, end, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, activity := range activities {
+		activity.In(loc)
+	}
+
+	return FindActivityGaps(activities, start, end, workingHours), nil
+}
+
+// DeleteActivityByID deletes an activity, activities in closed periods or approved timesheets are locked
 func (a *app) DeleteActivityByID(ctx context.Context, principal *Principal, activityID uuid.UUID) error {
-	if principal.HasRole("ROLE_ADMIN") {
-		return a.RepositoryTxer.InTx(
-			ctx,
-			func(ctx context.Context) error {
-				return a.ActivityRepository.DeleteActivityByID(ctx, principal.OrganizationID, activityID)
-			},
-		)
+	existingActivity, permissions, err := a.readUnlockedActivity(ctx, principal, activityID)
+	if err != nil {
+		return err
 	}
+
 	return a.RepositoryTxer.InTx(
 		ctx,
 		func(ctx context.Context) error {
-			return a.ActivityRepository.DeleteActivityByIDAndUsername(ctx, principal.OrganizationID, activityID, principal.Username)
+			var err error
+			if permissions.ManagesProject(existingActivity.ProjectID) {
+				err = a.ActivityRepository.DeleteActivityByID(ctx, principal.OrganizationID, activityID)
+			} else {
+				err = a.ActivityRepository.DeleteActivityByIDAndUsername(ctx, principal.OrganizationID, activityID, principal.Username)
+			}
+			if err != nil {
+				return err
+			}
+			return a.recordChange(ctx, principal, AuditActionDelete, AuditEntityActivity, activityID, existingActivity, nil)
 		},
 	)
 }
 
-// UpdateActivity updates an activity
+// UpdateActivity updates an activity, activities in closed periods or approved timesheets
+// are locked and activities can't be moved into them or onto projects the principal isn't assigned to
 func (a *app) UpdateActivity(ctx context.Context, principal *Principal, activity *Activity) (*Activity, error) {
-	var activityUpdate *Activity
-	if principal.HasRole("ROLE_ADMIN") {
-		err := a.RepositoryTxer.InTx(
-			ctx,
-			func(ctx context.Context) error {
-				a, err := a.ActivityRepository.UpdateActivity(ctx, principal.OrganizationID, activity)
-				if err != nil {
-					return err
-				}
-				activityUpdate = a
-				return nil
-			},
-		)
+	existingActivity, permissions, err := a.readUnlockedActivity(ctx, principal, activity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.checkActivityLocked(ctx, principal, existingActivity.Username, activity.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	if activity.ProjectID != existingActivity.ProjectID {
+		// activities of other users can only be moved onto managed projects
+		if existingActivity.Username != principal.Username && !permissions.ManagesProject(activity.ProjectID) {
+			return nil, ErrProjectNotAssigned
+		}
+
+		err = a.checkProjectAssigned(ctx, principal, activity.ProjectID)
 		if err != nil {
 			return nil, err
 		}
-		return activityUpdate, nil
 	}
-	err := a.RepositoryTxer.InTx(
+
+	err = a.checkOverlapsRejected(ctx, principal, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	var activityUpdate *Activity
+	err = a.RepositoryTxer.InTx(
 		ctx,
 		func(ctx context.Context) error {
-			a, err := a.ActivityRepository.UpdateActivityByUsername(ctx, principal.OrganizationID, activity, principal.Username)
+			var (
+				activityUpdated *Activity
+				err             error
+			)
+			if permissions.ManagesProject(existingActivity.ProjectID) {
+				activityUpdated, err = a.ActivityRepository.UpdateActivity(ctx, principal.OrganizationID, activity)
+			} else {
+				activityUpdated, err = a.ActivityRepository.UpdateActivityByUsername(ctx, principal.OrganizationID, activity, principal.Username)
+			}
 			if err != nil {
 				return err
 			}
-			activityUpdate = a
-			return nil
+			activityUpdate = activityUpdated
+			return a.recordChange(ctx, principal, AuditActionUpdate, AuditEntityActivity, activityUpdated.ID, existingActivity, activityUpdated)
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	a.checkBudgetAlerts(ctx, principal, activityUpdate.ProjectID)
+
 	return activityUpdate, nil
 }
 
+// readUnlockedActivity reads the existing activity to change, which must not be locked,
+// together with the permissions of the principal to change it
+func (a *app) readUnlockedActivity(ctx context.Context, principal *Principal, activityID uuid.UUID) (*Activity, *Permissions, error) {
+	existingActivity, err := a.ActivityRepository.FindActivityByID(ctx, activityID, principal.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// users may only change their own activities and those of the projects they manage
+	if !permissions.CanChangeActivity(existingActivity) {
+		return nil, nil, ErrActivityNotFound
+	}
+
+	err = a.checkActivityLocked(ctx, principal, existingActivity.Username, existingActivity.Start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return existingActivity, permissions, nil
+}
+
 func (a *app) WriteAsCSV(activities []*Activity, projects []*Project, w io.Writer) error {
 	csvWriter := csv.NewWriter(w)
 	csvWriter.Comma = ';'
 
 	defer csvWriter.Flush()
 
-	headers := []string{"Date", "Start", "End", "Duration", "Project", "Description"}
+	headers := []string{"Date", "Start", "End", "Duration", "Project", "Description", "Billable", "Amount", "Currency", "End Date"}
 
 	err := csvWriter.Write(headers)
 	if err != nil {
@@ -146,13 +488,18 @@ func (a *app) WriteAsCSV(activities []*Activity, projects []*Project, w io.Write
 
 	// write records for activities
 	for _, activity := range activities {
+		project := projectsById[activity.ProjectID]
 		record := []string{
 			activity.Start.Format("2006-01-02"),
 			activity.Start.Format("15:04"),
 			activity.End.Format("15:04"),
 			activity.DurationFormatted(),
-			projectsById[activity.ProjectID].Title,
+			project.Title,
 			activity.Description,
+			formatBillable(activity.Billable),
+			fmt.Sprintf("%.2f", activity.Amount()),
+			project.CurrencyOrDefault(),
+			activity.End.Format("2006-01-02"),
 		}
 		err := csvWriter.Write(record)
 		if err != nil {
@@ -180,6 +527,10 @@ func (a *app) WriteAsExcel(activities []*Activity, projects []*Project, w io.Wri
 	_ = f.SetCellValue("Activities", "D1", "End")
 	_ = f.SetCellValue("Activities", "E1", "Hours")
 	_ = f.SetCellValue("Activities", "F1", "Description")
+	_ = f.SetCellValue("Activities", "G1", "Billable")
+	_ = f.SetCellValue("Activities", "H1", "Amount")
+	_ = f.SetCellValue("Activities", "I1", "Currency")
+	_ = f.SetCellValue("Activities", "J1", "End Date")
 
 	style, _ := f.NewStyle(&excelize.Style{
 		Font: &excelize.Font{
@@ -194,7 +545,7 @@ func (a *app) WriteAsExcel(activities []*Activity, projects []*Project, w io.Wri
 	styleDuration, _ := f.NewStyle(&excelize.Style{
 		NumFmt: 4,
 	})
-	_ = f.SetCellStyle("Activities", "A1", "F1", style)
+	_ = f.SetCellStyle("Activities", "A1", "J1", style)
 
 	descriptionStyle, _ := f.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{
@@ -205,9 +556,10 @@ func (a *app) WriteAsExcel(activities []*Activity, projects []*Project, w io.Wri
 	for i, activity := range activities {
 		idx := i + 2
 
+		project := projectsById[activity.ProjectID]
 		duration, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", activity.DurationDecimal()), 64)
 
-		_ = f.SetCellValue("Activities", fmt.Sprintf("A%v", idx), projectsById[activity.ProjectID].Title)
+		_ = f.SetCellValue("Activities", fmt.Sprintf("A%v", idx), project.Title)
 		_ = f.SetCellValue("Activities", fmt.Sprintf("B%v", idx), activity.Start.Format("2006-01-02"))
 		_ = f.SetCellValue("Activities", fmt.Sprintf("C%v", idx), activity.Start.Format("15:04"))
 		_ = f.SetCellValue("Activities", fmt.Sprintf("D%v", idx), activity.End.Format("15:04"))
@@ -217,21 +569,145 @@ func (a *app) WriteAsExcel(activities []*Activity, projects []*Project, w io.Wri
 
 		_ = f.SetCellValue("Activities", fmt.Sprintf("F%v", idx), activity.Description)
 		_ = f.SetCellStyle("Activities", fmt.Sprintf("F%v", idx), fmt.Sprintf("F%v", idx), descriptionStyle)
+
+		_ = f.SetCellValue("Activities", fmt.Sprintf("G%v", idx), formatBillable(activity.Billable))
+		_ = f.SetCellValue("Activities", fmt.Sprintf("H%v", idx), activity.Amount())
+		_ = f.SetCellStyle("Activities", fmt.Sprintf("H%v", idx), fmt.Sprintf("H%v", idx), styleDuration)
+		_ = f.SetCellValue("Activities", fmt.Sprintf("I%v", idx), project.CurrencyOrDefault())
+		_ = f.SetCellValue("Activities", fmt.Sprintf("J%v", idx), activity.End.Format("2006-01-02"))
 	}
 
 	return f.Write(w)
 }
 
-func toFilter(principal *Principal, filter *ActivityFilter) *ActivitiesFilter {
+// WriteAsICS writes the activities as iCalendar with an event per activity
+func (a *app) WriteAsICS(activities []*Activity, projects []*Project, w io.Writer) error {
+	projectsById := make(map[uuid.UUID]*Project)
+	for _, project := range projects {
+		projectsById[project.ID] = project
+	}
+
+	icsWriter := bufio.NewWriter(w)
+	now := time.Now().UTC().Format(icsDateTimeFormat)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Baralga//Baralga//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Baralga",
+	}
+
+	for _, activity := range activities {
+		summary := ""
+		if project, ok := projectsById[activity.ProjectID]; ok {
+			summary = project.Title
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%v@baralga", activity.ID),
+			fmt.Sprintf("DTSTAMP:%v", now),
+			fmt.Sprintf("DTSTART:%v", activity.Start.UTC().Format(icsDateTimeFormat)),
+			fmt.Sprintf("DTEND:%v", activity.End.UTC().Format(icsDateTimeFormat)),
+			fmt.Sprintf("SUMMARY:%v", escapeICSText(summary)),
+		)
+		if activity.Description != "" {
+			lines = append(lines, fmt.Sprintf("DESCRIPTION:%v", escapeICSText(activity.Description)))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := icsWriter.WriteString(foldICSLine(line))
+		if err != nil {
+			return err
+		}
+	}
+
+	return icsWriter.Flush()
+}
+
+const icsDateTimeFormat = "20060102T150405Z"
+
+// escapeICSText escapes a text value of iCalendar (RFC 5545)
+func escapeICSText(text string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	).Replace(text)
+}
+
+// foldICSLine folds the content line after 75 octets without splitting
+// multi-byte characters and terminates it with CRLF as required by iCalendar
+func foldICSLine(line string) string {
+	var folded strings.Builder
+
+	length := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if length+size > 75 {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+	folded.WriteString("\r\n")
+
+	return folded.String()
+}
+
+func formatBillable(billable bool) string {
+	if billable {
+		return "Yes"
+	}
+	return "No"
+}
+
+// readActivitiesFilter maps the filter to the activities the principal is permitted to read
+func (a *app) readActivitiesFilter(ctx context.Context, principal *Principal, filter *ActivityFilter) (*ActivitiesFilter, error) {
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFilter(principal, permissions, filter), nil
+}
+
+func toFilter(principal *Principal, permissions *Permissions, filter *ActivityFilter) *ActivitiesFilter {
+	// the boundaries of the filter are calendar dates, so they start
+	// at midnight in the time zone of the principal
+	loc := principal.Location()
 	activitiesFilter := &ActivitiesFilter{
-		Start:          filter.Start(),
-		End:            filter.End(),
+		Start:          util.WallClockIn(filter.Start(), loc),
+		End:            util.WallClockIn(filter.End(), loc),
 		SortBy:         filter.sortBy,
 		SortOrder:      filter.sortOrder,
+		ProjectIDs:     filter.projectIDs,
+		Description:    filter.description,
+		Tag:            filter.tag,
+		Timezone:       loc.String(),
 		OrganizationID: principal.OrganizationID,
+
+		SplitAtMidnight: filter.splitAtMidnight,
 	}
 
-	if !principal.HasRole("ROLE_ADMIN") {
+	switch {
+	case permissions.IsAdmin():
+		activitiesFilter.Usernames = filter.usernames
+	case permissions.IsProjectManager():
+		// project managers read their own activities and all activities of the projects they manage
+		activitiesFilter.Username = principal.Username
+		activitiesFilter.Usernames = filter.usernames
+		activitiesFilter.ManagedProjectIDs = permissions.managedProjectIDs
+	default:
 		activitiesFilter.Username = principal.Username
 	}
 
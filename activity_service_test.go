@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/matryer/is"
+	"github.com/pkg/errors"
 )
 
 func TestTimeReportsByDay(t *testing.T) {
@@ -18,6 +19,7 @@ func TestTimeReportsByDay(t *testing.T) {
 	activityRepository := NewInMemActivityRepository()
 	a := &app{
 		ActivityRepository: activityRepository,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	start1, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00.000Z")
@@ -65,6 +67,7 @@ func TestTimeReportsByWeek(t *testing.T) {
 	activityRepository := NewInMemActivityRepository()
 	a := &app{
 		ActivityRepository: activityRepository,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	start1, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00.000Z")
@@ -112,6 +115,7 @@ func TestTimeReportsByMonth(t *testing.T) {
 	activityRepository := NewInMemActivityRepository()
 	a := &app{
 		ActivityRepository: activityRepository,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	start1, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00.000Z")
@@ -159,6 +163,7 @@ func TestTimeReportsByQuarter(t *testing.T) {
 	activityRepository := NewInMemActivityRepository()
 	a := &app{
 		ActivityRepository: activityRepository,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	start1, _ := time.Parse(time.RFC3339, "2021-01-01T10:00:00.000Z")
@@ -206,6 +211,7 @@ func TestProjectReports(t *testing.T) {
 	activityRepository := NewInMemActivityRepository()
 	a := &app{
 		ActivityRepository: activityRepository,
+		ProjectRepository:  NewInMemProjectRepository(),
 	}
 
 	projectId1 := uuid.New()
@@ -282,6 +288,39 @@ func TestWriteAsCSV(t *testing.T) {
 	is.True(strings.Contains(csv, "My Project"))
 	is.True(strings.Contains(csv, "11:00"))
 	is.True(strings.Contains(csv, "11:30"))
+	is.True(strings.Contains(csv, "Amount"))
+}
+
+func TestWriteAsCSVWithAmount(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{}
+
+	start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
+	end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000Z")
+
+	activity := &Activity{
+		Start:      start,
+		End:        end,
+		ProjectID:  uuid.New(),
+		Billable:   true,
+		HourlyRate: 90,
+	}
+	activities := []*Activity{activity}
+
+	project := &Project{
+		ID:       activity.ProjectID,
+		Title:    "My Project",
+		Currency: "USD",
+	}
+	projects := []*Project{project}
+
+	var buffer bytes.Buffer
+
+	err := a.WriteAsCSV(activities, projects, &buffer)
+
+	is.NoErr(err)
+	is.True(strings.Contains(buffer.String(), ";Yes;45.00;USD"))
 }
 
 func TestWriteAsExcel(t *testing.T) {
@@ -311,3 +350,346 @@ func TestWriteAsExcel(t *testing.T) {
 
 	is.NoErr(err)
 }
+
+func TestToFilterInTimezone(t *testing.T) {
+	is := is.New(t)
+
+	principal := &Principal{
+		OrganizationID: organizationIDSample,
+		Timezone:       "America/New_York",
+	}
+	filter := &ActivityFilter{
+		Timespan: TimespanDay,
+		start:    time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC),
+	}
+
+	activitiesFilter := toFilter(principal, NewPermissions(principal, nil), filter)
+
+	is.Equal("America/New_York", activitiesFilter.Timezone)
+	is.Equal(time.Date(2021, 11, 12, 5, 0, 0, 0, time.UTC), activitiesFilter.Start.UTC())
+	is.Equal(time.Date(2021, 11, 13, 5, 0, 0, 0, time.UTC), activitiesFilter.End.UTC())
+}
+
+func TestToFilterWithCriteria(t *testing.T) {
+	is := is.New(t)
+
+	filter := &ActivityFilter{
+		activityCriteria: activityCriteria{
+			projectIDs:  []uuid.UUID{projectIDSample},
+			usernames:   []string{"user2"},
+			description: "invoice",
+		},
+		Timespan: TimespanDay,
+		start:    time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("as admin", func(t *testing.T) {
+		principal := &Principal{
+			Username: "admin",
+			Roles:    []string{"ROLE_ADMIN"},
+		}
+
+		activitiesFilter := toFilter(principal, NewPermissions(principal, nil), filter)
+
+		is.Equal([]uuid.UUID{projectIDSample}, activitiesFilter.ProjectIDs)
+		is.Equal([]string{"user2"}, activitiesFilter.Usernames)
+		is.Equal("", activitiesFilter.Username)
+		is.Equal("invoice", activitiesFilter.Description)
+	})
+
+	t.Run("as user", func(t *testing.T) {
+		principal := &Principal{
+			Username: "user1",
+			Roles:    []string{"ROLE_USER"},
+		}
+
+		activitiesFilter := toFilter(principal, NewPermissions(principal, nil), filter)
+
+		is.Equal([]uuid.UUID{projectIDSample}, activitiesFilter.ProjectIDs)
+		is.Equal(0, len(activitiesFilter.Usernames))
+		is.Equal("user1", activitiesFilter.Username)
+		is.Equal(0, len(activitiesFilter.ManagedProjectIDs))
+	})
+
+	t.Run("as project manager", func(t *testing.T) {
+		principal := &Principal{
+			Username: "user1",
+			Roles:    []string{"ROLE_USER"},
+		}
+
+		activitiesFilter := toFilter(principal, NewPermissions(principal, []uuid.UUID{projectIDSample}), filter)
+
+		is.Equal([]string{"user2"}, activitiesFilter.Usernames)
+		is.Equal("user1", activitiesFilter.Username)
+		is.Equal([]uuid.UUID{projectIDSample}, activitiesFilter.ManagedProjectIDs)
+	})
+}
+
+func TestWriteAsICS(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{}
+
+	start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000+01:00")
+	end, _ := time.Parse(time.RFC3339, "2021-11-12T11:30:00.000+01:00")
+
+	activity := &Activity{
+		ID:          uuid.MustParse("00000000-0000-0000-2222-000000000001"),
+		Start:       start,
+		End:         end,
+		ProjectID:   projectIDSample,
+		Description: "Review; planning, retro\nand " + strings.Repeat("more ", 20),
+	}
+	activities := []*Activity{activity}
+
+	project := &Project{
+		ID:    activity.ProjectID,
+		Title: "My Project",
+	}
+	projects := []*Project{project}
+
+	var buffer bytes.Buffer
+
+	err := a.WriteAsICS(activities, projects, &buffer)
+
+	is.NoErr(err)
+	ics := buffer.String()
+
+	is.True(strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	is.True(strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	is.True(strings.Contains(ics, "UID:00000000-0000-0000-2222-000000000001@baralga\r\n"))
+	is.True(strings.Contains(ics, "DTSTART:20211112T100000Z\r\n"))
+	is.True(strings.Contains(ics, "DTEND:20211112T103000Z\r\n"))
+	is.True(strings.Contains(ics, "SUMMARY:My Project\r\n"))
+	is.True(strings.Contains(ics, `DESCRIPTION:Review\; planning\, retro\nand more`))
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		is.True(len(line) <= 75)
+	}
+}
+
+func TestReadCalendarFeed(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+		ActivityRepository: activityRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	activityRepository.activities = append(activityRepository.activities, &Activity{
+		ID:             uuid.New(),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       "admin@baralga.com",
+	})
+
+	_, calendarSecret, err := a.CreateApiToken(context.Background(), principal, "Calendar", ApiTokenScopeCalendar, nil)
+	is.NoErr(err)
+
+	_, readSecret, err := a.CreateApiToken(context.Background(), principal, "Script", ApiTokenScopeRead, nil)
+	is.NoErr(err)
+
+	filter := &ActivityFilter{
+		Timespan: TimespanWeek,
+		start:    time.Date(2021, 11, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("CalendarToken", func(t *testing.T) {
+		calendarPrincipal, err := a.AuthenticateCalendarFeed(context.Background(), calendarSecret)
+		is.NoErr(err)
+
+		activities, projects, err := a.ReadCalendarFeed(context.Background(), calendarPrincipal, filter)
+
+		is.NoErr(err)
+		is.Equal(len(activities), 1)
+		is.Equal(activities[0].Username, "admin@baralga.com")
+		is.Equal(len(projects), 1)
+	})
+
+	t.Run("ReadToken", func(t *testing.T) {
+		_, err := a.AuthenticateCalendarFeed(context.Background(), readSecret)
+
+		is.True(errors.Is(err, ErrCalendarFeedNotFound))
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		_, err := a.AuthenticateCalendarFeed(context.Background(), "bat_unknown")
+
+		is.True(errors.Is(err, ErrCalendarFeedNotFound))
+	})
+}
+
+func TestReadOverlappingActivities(t *testing.T) {
+	is := is.New(t)
+
+	repo := NewInMemActivityRepository()
+	repo.activities[0].Start = time.Date(2021, 11, 12, 9, 0, 0, 0, time.UTC)
+	repo.activities[0].End = time.Date(2021, 11, 12, 10, 0, 0, 0, time.UTC)
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: repo,
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	t.Run("Overlapping", func(t *testing.T) {
+		activity := &Activity{
+			Start: time.Date(2021, 11, 12, 9, 30, 0, 0, time.UTC),
+			End:   time.Date(2021, 11, 12, 10, 30, 0, 0, time.UTC),
+		}
+
+		overlaps, err := a.ReadOverlappingActivities(context.Background(), principal, activity)
+
+		is.NoErr(err)
+		is.Equal(len(overlaps), 1)
+		is.Equal(overlaps[0].ID, repo.activities[0].ID)
+	})
+
+	t.Run("Adjacent", func(t *testing.T) {
+		activity := &Activity{
+			Start: time.Date(2021, 11, 12, 10, 0, 0, 0, time.UTC),
+			End:   time.Date(2021, 11, 12, 11, 0, 0, 0, time.UTC),
+		}
+
+		overlaps, err := a.ReadOverlappingActivities(context.Background(), principal, activity)
+
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+	})
+
+	t.Run("SameActivity", func(t *testing.T) {
+		activity := &Activity{
+			ID:    repo.activities[0].ID,
+			Start: time.Date(2021, 11, 12, 9, 30, 0, 0, time.UTC),
+			End:   time.Date(2021, 11, 12, 10, 30, 0, 0, time.UTC),
+		}
+
+		overlaps, err := a.ReadOverlappingActivities(context.Background(), principal, activity)
+
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+	})
+
+	t.Run("OtherUserAsAdmin", func(t *testing.T) {
+		activity := &Activity{
+			Start: time.Date(2021, 11, 12, 9, 30, 0, 0, time.UTC),
+			End:   time.Date(2021, 11, 12, 10, 30, 0, 0, time.UTC),
+		}
+		admin := &Principal{
+			Username:       "admin",
+			OrganizationID: organizationIDSample,
+			Roles:          []string{"ROLE_ADMIN"},
+		}
+
+		overlaps, err := a.ReadOverlappingActivities(context.Background(), admin, activity)
+
+		is.NoErr(err)
+		is.Equal(len(overlaps), 0)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		a := &app{
+			Config:                 &config{RejectOverlaps: true},
+			RepositoryTxer:         NewInMemRepositoryTxer(),
+			ActivityRepository:     repo,
+			ProjectRepository:      NewInMemProjectRepository(),
+			OrganizationRepository: NewInMemOrganizationRepository(),
+			AuditLogRepository:     NewInMemAuditLogRepository(),
+			WebhookRepository:      NewInMemWebhookRepository(),
+			TimesheetRepository:    NewInMemTimesheetRepository(),
+		}
+		activity := &Activity{
+			Start:     time.Date(2021, 11, 12, 8, 0, 0, 0, time.UTC),
+			End:       time.Date(2021, 11, 12, 12, 0, 0, 0, time.UTC),
+			ProjectID: projectIDSample,
+		}
+		countBefore := len(repo.activities)
+
+		_, err := a.CreateActivity(context.Background(), principal, activity)
+
+		is.True(errors.Is(err, ErrActivityOverlaps))
+		is.Equal(len(repo.activities), countBefore)
+	})
+}
+
+func TestReadActivityGaps(t *testing.T) {
+	is := is.New(t)
+
+	repo := NewInMemActivityRepository()
+	repo.activities[0].Start = time.Date(2021, 11, 12, 9, 0, 0, 0, time.UTC)
+	repo.activities[0].End = time.Date(2021, 11, 12, 16, 0, 0, 0, time.UTC)
+	a := &app{
+		ActivityRepository: repo,
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	filter := &ActivityFilter{
+		Timespan: TimespanDay,
+		start:    time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC),
+	}
+
+	gaps, err := a.ReadActivityGaps(context.Background(), principal, filter, DefaultWorkingHours)
+
+	is.NoErr(err)
+	is.Equal(len(gaps), 1)
+	is.Equal(gaps[0].Start, time.Date(2021, 11, 12, 16, 0, 0, 0, time.UTC))
+	is.Equal(gaps[0].End, time.Date(2021, 11, 12, 17, 0, 0, 0, time.UTC))
+}
+
+func TestCreateActivitiesWithOneInClosedPeriod(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	organizationRepository := NewInMemOrganizationRepository()
+	lockedUntil := time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC)
+	organizationRepository.organizations[0].LockedUntil = &lockedUntil
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     activityRepository,
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: organizationRepository,
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	activities := []*Activity{
+		{
+			ProjectID: projectIDSample,
+			Start:     time.Date(2021, 11, 13, 9, 0, 0, 0, time.UTC),
+			End:       time.Date(2021, 11, 13, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			ProjectID: projectIDSample,
+			Start:     time.Date(2021, 11, 11, 9, 0, 0, 0, time.UTC),
+			End:       time.Date(2021, 11, 11, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	_, err := a.CreateActivities(context.Background(), principal, activities)
+
+	is.True(errors.Is(err, ErrPeriodLocked))
+	is.Equal(len(activityRepository.activities), 1)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	hx "github.com/baralga/htmx"
@@ -23,9 +24,12 @@ type activityFormModel struct {
 	ID          string
 	ProjectID   string `validate:"required"`
 	Date        string `validate:"required"`
+	EndDate     string `validate:"max=10"`
 	StartTime   string `validate:"required,min=5,max=5"`
 	EndTime     string `validate:"required,min=5,max=5"`
 	Description string `validate:"min=0,max=500"`
+	Tags        string `validate:"max=500"`
+	Billable    bool
 }
 
 type activityTrackFormModel struct {
@@ -34,19 +38,18 @@ type activityTrackFormModel struct {
 	Action   string
 	Duration string
 
-	ProjectID    string
-	ProjectTitle string
-	Date         string
-	StartTime    string
-	Description  string
+	ProjectID   string
+	StartTime   string
+	Description string
 }
 
-func newActivityFormModel() activityFormModel {
-	now := time.Now()
+func newActivityFormModel(now time.Time) activityFormModel {
 	return activityFormModel{
 		Date:      util.FormatDateDE(now),
+		EndDate:   util.FormatDateDE(now),
 		StartTime: util.FormatTime(now),
 		EndTime:   util.FormatTime(now),
+		Billable:  true,
 	}
 }
 
@@ -60,7 +63,7 @@ func (a *app) HandleActivityAddPage() http.HandlerFunc {
 			Size: 50,
 		}
 
-		projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, err)
 			return
@@ -71,7 +74,7 @@ func (a *app) HandleActivityAddPage() http.HandlerFunc {
 			currentPath: r.URL.Path,
 			title:       "Add Activity",
 		}
-		activityFormModel := newActivityFormModel()
+		activityFormModel := newActivityFormModel(time.Now().In(principal.Location()))
 		activityFormModel.CSRFToken = csrf.Token(r)
 
 		if !hx.IsHXRequest(r) {
@@ -99,7 +102,7 @@ func (a *app) HandleActivityEditPage() http.HandlerFunc {
 			return
 		}
 
-		activity, err := a.ActivityRepository.FindActivityByID(r.Context(), activityID, principal.OrganizationID)
+		activity, err := a.ReadActivity(r.Context(), principal, activityID)
 		if errors.Is(err, ErrActivityNotFound) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -110,7 +113,7 @@ func (a *app) HandleActivityEditPage() http.HandlerFunc {
 			Size: 50,
 		}
 
-		projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, err)
 			return
@@ -156,85 +159,69 @@ func (a *app) HandleActivityTrackForm() http.HandlerFunc {
 
 		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 
-		pageParams := &paged.PageParams{
-			Page: 0,
-			Size: 50,
+		runningActivity, err := a.ReadRunningActivity(r.Context(), principal)
+		if err != nil && !errors.Is(err, ErrRunningActivityNotFound) {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
 		}
 
-		var projects []*Project
-
-		if formModel.Action == "start" {
-			projectsPage, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		var trackErr error
+		switch {
+		case runningActivity != nil && formModel.Action == "running" && actionParam == "reload":
+			runningActivity, trackErr = a.UpdateTimer(r.Context(), principal, formModel.Description)
+		case runningActivity != nil && formModel.Action == "running" && actionParam == "discard":
+			trackErr = a.DiscardTimer(r.Context(), principal)
+			runningActivity = nil
+		case runningActivity == nil && formModel.Action == "start" && actionParam != "reload":
+			projectID, err := uuid.Parse(formModel.ProjectID)
 			if err != nil {
 				util.RenderProblemHTML(w, isProduction, err)
 				return
 			}
-			projects = projectsPage.Projects
-
-			if actionParam == "reload" {
-				util.RenderHTML(w, TrackPanel(projects, formModel))
-				return
-			}
-
-			now := time.Now()
-			formModel.Action = "running"
-			formModel.Date = util.FormatDateDE(now)
-			formModel.StartTime = util.FormatTime(now)
-			formModel.Duration = "0:00 h"
-
-			for _, project := range projectsPage.Projects {
-				if project.ID.String() == formModel.ProjectID {
-					formModel.ProjectTitle = project.Title
-					break
-				}
-			}
-
-			formModel.CSRFToken = csrf.Token(r)
-			util.RenderHTML(w, TrackPanel(projects, formModel))
-		} else if formModel.Action == "running" {
-			projects = []*Project{
-				{
-					ID:             uuid.MustParse(formModel.ProjectID),
-					OrganizationID: principal.OrganizationID,
-					Title:          formModel.ProjectTitle,
+			runningActivity, trackErr = a.StartTimer(
+				r.Context(),
+				principal,
+				&RunningActivity{
+					ProjectID:   projectID,
+					Description: formModel.Description,
 				},
+			)
+		case runningActivity != nil && formModel.Action == "running" && actionParam != "reload":
+			runningActivity, trackErr = a.UpdateTimer(r.Context(), principal, formModel.Description)
+			if trackErr == nil {
+				_, trackErr = a.StopTimer(r.Context(), principal)
 			}
-
-			activityFormModel := activityFormModel{
-				Date:        formModel.Date,
-				StartTime:   formModel.StartTime,
-				EndTime:     util.FormatTime(time.Now()),
-				ProjectID:   formModel.ProjectID,
-				Description: formModel.Description,
-			}
-			activityToCreate, _ := mapFormToActivity(activityFormModel)
-			formModel.Duration = activityToCreate.DurationFormatted()
-
-			if actionParam == "reload" {
-				util.RenderHTML(w, TrackPanel(projects, formModel))
-				return
+			if trackErr == nil {
+				runningActivity = nil
+				w.Header().Set("HX-Trigger", "baralga__activities-changed")
 			}
+		}
 
-			_, err := a.CreateActivity(r.Context(), principal, activityToCreate)
-			if err != nil {
-				util.RenderProblemHTML(w, isProduction, err)
-				return
-			}
+		var errorMessage string
+		if errors.Is(trackErr, ErrTimerNotStoppable) {
+			errorMessage = "The tracked time can't be booked, e.g. since its period is closed or you are no longer assigned to the project. Please discard the timer."
+			trackErr = nil
+		}
+		if trackErr != nil {
+			util.RenderProblemHTML(w, isProduction, trackErr)
+			return
+		}
 
-			w.Header().Set("HX-Trigger", "baralga__activities-changed")
+		pageParams := &paged.PageParams{
+			Page: 0,
+			Size: 50,
+		}
 
-			projectsPage, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
-			if err != nil {
-				util.RenderProblemHTML(w, isProduction, err)
-				return
-			}
-			projects = projectsPage.Projects
+		projectsPage, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
 
-			formModel = activityTrackFormModel{Action: "start"}
-			formModel.CSRFToken = csrf.Token(r)
+		trackFormModel := mapRunningActivityToTrackForm(runningActivity, time.Now().In(principal.Location()))
+		trackFormModel.CSRFToken = csrf.Token(r)
 
-			util.RenderHTML(w, TrackPanel(projects, formModel))
-		}
+		util.RenderHTML(w, TrackPanel(projectsPage.Projects, trackFormModel, errorMessage))
 	}
 }
 
@@ -252,6 +239,7 @@ func (a *app) HandleActivityForm() http.HandlerFunc {
 				principal,
 				isProduction,
 				activityFormModel{},
+				"",
 			)
 			return
 		}
@@ -265,6 +253,7 @@ func (a *app) HandleActivityForm() http.HandlerFunc {
 				principal,
 				isProduction,
 				activityFormModel{},
+				"",
 			)
 			return
 		}
@@ -277,11 +266,23 @@ func (a *app) HandleActivityForm() http.HandlerFunc {
 				principal,
 				isProduction,
 				formModel,
+				"",
 			)
 			return
 		}
 
-		activityNew, err := mapFormToActivity(formModel)
+		activityNew, err := mapFormToActivity(formModel, principal.Location())
+		if errors.Is(err, ErrActivityEndNotAfterStart) {
+			a.renderActivityAddView(
+				w,
+				r,
+				principal,
+				isProduction,
+				formModel,
+				"The end of the activity must be after its start.",
+			)
+			return
+		}
 		if err != nil {
 			a.renderActivityAddView(
 				w,
@@ -289,6 +290,7 @@ func (a *app) HandleActivityForm() http.HandlerFunc {
 				principal,
 				isProduction,
 				formModel,
+				"",
 			)
 			return
 		}
@@ -298,6 +300,56 @@ func (a *app) HandleActivityForm() http.HandlerFunc {
 		} else {
 			_, err = a.UpdateActivity(r.Context(), principal, activityNew)
 		}
+		if errors.Is(err, ErrActivityOverlaps) {
+			overlaps, err := a.ReadOverlappingActivities(r.Context(), principal, activityNew)
+			if err != nil {
+				util.RenderProblemHTML(w, isProduction, err)
+				return
+			}
+
+			a.renderActivityAddView(
+				w,
+				r,
+				principal,
+				isProduction,
+				formModel,
+				formatOverlapWarning(overlaps),
+			)
+			return
+		}
+		if errors.Is(err, ErrActivityLocked) {
+			a.renderActivityAddView(
+				w,
+				r,
+				principal,
+				isProduction,
+				formModel,
+				"The week of the activity is approved, so activities can't be booked or changed in it.",
+			)
+			return
+		}
+		if errors.Is(err, ErrPeriodLocked) {
+			a.renderActivityAddView(
+				w,
+				r,
+				principal,
+				isProduction,
+				formModel,
+				"The date of the activity is in a closed period.",
+			)
+			return
+		}
+		if errors.Is(err, ErrProjectNotAssigned) {
+			a.renderActivityAddView(
+				w,
+				r,
+				principal,
+				isProduction,
+				formModel,
+				"You are not assigned to the project of the activity.",
+			)
+			return
+		}
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, err)
 			return
@@ -322,7 +374,7 @@ func (a *app) HandleStartTimeValidation() http.HandlerFunc {
 
 		formModel.StartTime = util.CompleteTimeValue(formModel.StartTime)
 
-		util.RenderHTML(w, StartTimeInputView(formModel))
+		util.RenderHTML(w, StartTimeInputView(formModel, a.overlapWarning(r, formModel)))
 	}
 }
 
@@ -341,10 +393,41 @@ func (a *app) HandleEndTimeValidation() http.HandlerFunc {
 
 		formModel.EndTime = util.CompleteTimeValue(formModel.EndTime)
 
-		util.RenderHTML(w, EndTimeInputView(formModel))
+		util.RenderHTML(w, EndTimeInputView(formModel, a.overlapWarning(r, formModel)))
 	}
 }
 
+// overlapWarning is a warning about the activities which overlap with the
+// activity of the form, if the form is complete
+func (a *app) overlapWarning(r *http.Request, formModel activityFormModel) string {
+	principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+	activity, err := mapFormToActivity(formModel, principal.Location())
+	if err != nil {
+		return ""
+	}
+
+	overlaps, err := a.ReadOverlappingActivities(r.Context(), principal, activity)
+	if err != nil {
+		return ""
+	}
+
+	return formatOverlapWarning(overlaps)
+}
+
+func formatOverlapWarning(overlaps []*Activity) string {
+	if len(overlaps) == 0 {
+		return ""
+	}
+
+	timeRanges := make([]string, len(overlaps))
+	for i, overlap := range overlaps {
+		timeRanges[i] = fmt.Sprintf("%v - %v", util.FormatTime(overlap.Start), overlap.EndFormatted())
+	}
+
+	return fmt.Sprintf("Overlaps with other activities (%v).", strings.Join(timeRanges, ", "))
+}
+
 func ActivityAddPage(pageContext *pageContext, activityFormModel activityFormModel, projects *ProjectsPaged) g.Node {
 	return Page(
 		pageContext.title,
@@ -365,7 +448,7 @@ func ActivityAddPage(pageContext *pageContext, activityFormModel activityFormMod
 	)
 }
 
-func StartTimeInputView(formModel activityFormModel) g.Node {
+func StartTimeInputView(formModel activityFormModel, overlapWarning string) g.Node {
 	return Div(
 		ID("activity_start_time"),
 		Class("mb-3"),
@@ -388,10 +471,18 @@ func StartTimeInputView(formModel activityFormModel) g.Node {
 			Class("form-control"),
 			g.Attr("placeholder", "10:00"),
 		),
+		g.If(
+			overlapWarning != "",
+			Div(
+				Class("form-text text-warning"),
+				I(Class("bi-exclamation-triangle me-1")),
+				g.Text(overlapWarning),
+			),
+		),
 	)
 }
 
-func EndTimeInputView(formModel activityFormModel) g.Node {
+func EndTimeInputView(formModel activityFormModel, overlapWarning string) g.Node {
 	return Div(
 		ID("activity_end_time"),
 		Class("mb-3"),
@@ -414,6 +505,14 @@ func EndTimeInputView(formModel activityFormModel) g.Node {
 			Class("form-control"),
 			g.Attr("placeholder", "10:00"),
 		),
+		g.If(
+			overlapWarning != "",
+			Div(
+				Class("form-text text-warning"),
+				I(Class("bi-exclamation-triangle me-1")),
+				g.Text(overlapWarning),
+			),
+		),
 	)
 }
 
@@ -475,16 +574,7 @@ func ActivityForm(formModel activityFormModel, projects *ProjectsPaged, errorMes
 					Class("form-select"),
 					ID("ProjectID"),
 					Name("ProjectID"),
-					g.Group(
-						g.Map(len(projects.Projects), func(i int) g.Node {
-							project := projects.Projects[i]
-							return Option(
-								Value(project.ID.String()),
-								g.Text(project.Title),
-								g.If(formModel.ProjectID == project.ID.String(), Selected()),
-							)
-						}),
-					),
+					ProjectOptions(projects.Projects, formModel.ProjectID),
 					Value(formModel.ProjectID),
 				),
 			),
@@ -493,7 +583,7 @@ func ActivityForm(formModel activityFormModel, projects *ProjectsPaged, errorMes
 				Label(
 					Class("form-label"),
 					g.Attr("for", "Date"),
-					g.Text("Date"),
+					g.Text("Start Date"),
 				),
 				Input(
 					ID("Date"),
@@ -508,8 +598,27 @@ func ActivityForm(formModel activityFormModel, projects *ProjectsPaged, errorMes
 					g.Attr("placeholder", "16.11.2021"),
 				),
 			),
-			StartTimeInputView(formModel),
-			EndTimeInputView(formModel),
+			StartTimeInputView(formModel, ""),
+			Div(
+				Class("mb-3"),
+				Label(
+					Class("form-label"),
+					g.Attr("for", "EndDate"),
+					g.Text("End Date"),
+				),
+				Input(
+					ID("EndDate"),
+					Type("text"),
+					Name("EndDate"),
+					Value(formModel.EndDate),
+					Pattern("[0-3][0-9]\\.[0-1][0-9]\\.20[0-9]{2}"),
+					MinLength("10"),
+					MaxLength("10"),
+					Class("form-control"),
+					g.Attr("placeholder", "16.11.2021"),
+				),
+			),
+			EndTimeInputView(formModel, ""),
 			Div(
 				Class("mb-3"),
 				Label(
@@ -526,6 +635,38 @@ func ActivityForm(formModel activityFormModel, projects *ProjectsPaged, errorMes
 					g.Text(formModel.Description),
 				),
 			),
+			Div(
+				Class("mb-3"),
+				Label(
+					Class("form-label"),
+					g.Attr("for", "Tags"),
+					g.Text("Tags"),
+				),
+				Input(
+					ID("Tags"),
+					Type("text"),
+					Name("Tags"),
+					Class("form-control"),
+					g.Attr("placeholder", "meeting, support"),
+					Value(formModel.Tags),
+				),
+			),
+			Div(
+				Class("form-check"),
+				Input(
+					ID("Billable"),
+					Type("checkbox"),
+					Name("Billable"),
+					Value("true"),
+					Class("form-check-input"),
+					g.If(formModel.Billable, g.Attr("checked", "checked")),
+				),
+				Label(
+					Class("form-check-label"),
+					g.Attr("for", "Billable"),
+					g.Text("Billable"),
+				),
+			),
 		),
 		Div(
 			Class("modal-footer"),
@@ -549,7 +690,7 @@ func ActivityForm(formModel activityFormModel, projects *ProjectsPaged, errorMes
 	)
 }
 
-func TrackPanel(projects []*Project, formModel activityTrackFormModel) g.Node {
+func TrackPanel(projects []*Project, formModel activityTrackFormModel, errorMessage string) g.Node {
 	return FormEl(
 		ID("baralga__track_panel"),
 		Class("container p-3 rounded-3"),
@@ -585,35 +726,6 @@ func TrackPanel(projects []*Project, formModel activityTrackFormModel) g.Node {
 			),
 		),
 
-		g.If(formModel.Action == "running",
-			Input(
-				Type("hidden"),
-				Name("Date"),
-				Value(formModel.Date),
-			),
-		),
-		g.If(formModel.Action == "running",
-			Input(
-				Type("hidden"),
-				Name("StartTime"),
-				Value(formModel.StartTime),
-			),
-		),
-		g.If(formModel.Action == "running",
-			Input(
-				Type("hidden"),
-				Name("ProjectID"),
-				Value(formModel.ProjectID),
-			),
-		),
-		g.If(formModel.Action == "running",
-			Input(
-				Type("hidden"),
-				Name("ProjectTitle"),
-				Value(formModel.ProjectTitle),
-			),
-		),
-
 		Input(
 			Type("hidden"),
 			Name("Action"),
@@ -651,16 +763,7 @@ func TrackPanel(projects []*Project, formModel activityTrackFormModel) g.Node {
 					g.If(formModel.Action == "running",
 						Disabled(),
 					),
-					g.Group(
-						g.Map(len(projects), func(i int) g.Node {
-							project := projects[i]
-							return Option(
-								Value(project.ID.String()),
-								g.Text(project.Title),
-								g.If(formModel.ProjectID == project.ID.String(), Selected()),
-							)
-						}),
-					),
+					ProjectOptions(projects, formModel.ProjectID),
 				),
 			),
 		),
@@ -695,16 +798,41 @@ func TrackPanel(projects []*Project, formModel activityTrackFormModel) g.Node {
 				),
 			),
 		),
+		g.If(formModel.Action == "running",
+			Div(
+				Class("row mt-2"),
+				Div(
+					Class("col-sm-12 text-end"),
+					Button(
+						Type("button"),
+						Class("btn btn-outline-secondary btn-sm"),
+						hx.Post("/activities/track?action=discard"),
+						hx.Confirm("Do you really want to discard the tracked time?"),
+						TitleAttr("Discard tracked time"),
+						I(Class("bi-x-circle me-2")),
+						g.Text("Discard"),
+					),
+				),
+			),
+		),
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-danger text-center mt-2 mb-0"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
 	)
 }
 
-func (a *app) renderActivityAddView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel activityFormModel) {
+func (a *app) renderActivityAddView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel activityFormModel, errorMessage string) {
 	pageParams := &paged.PageParams{
 		Page: 0,
 		Size: 50,
 	}
 
-	projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+	projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
 	if err != nil {
 		util.RenderProblemHTML(w, isProduction, err)
 		return
@@ -712,7 +840,7 @@ func (a *app) renderActivityAddView(w http.ResponseWriter, r *http.Request, prin
 
 	if hx.IsHXRequest(r) {
 		formModel.CSRFToken = csrf.Token(r)
-		util.RenderHTML(w, ActivityForm(formModel, projects, ""))
+		util.RenderHTML(w, ActivityForm(formModel, projects, errorMessage))
 		return
 	}
 
@@ -722,13 +850,13 @@ func (a *app) renderActivityAddView(w http.ResponseWriter, r *http.Request, prin
 		title:       "Add Activity",
 	}
 
-	activityFormModel := newActivityFormModel()
+	activityFormModel := newActivityFormModel(time.Now().In(principal.Location()))
 	activityFormModel.CSRFToken = csrf.Token(r)
 
 	util.RenderHTML(w, ActivityAddPage(pageContext, activityFormModel, projects))
 }
 
-func mapFormToActivity(formModel activityFormModel) (*Activity, error) {
+func mapFormToActivity(formModel activityFormModel, loc *time.Location) (*Activity, error) {
 	var activityID uuid.UUID
 
 	if formModel.ID != "" {
@@ -739,12 +867,18 @@ func mapFormToActivity(formModel activityFormModel) (*Activity, error) {
 		activityID = aID
 	}
 
-	start, err := util.ParseDateTimeForm(fmt.Sprintf("%v %v", formModel.Date, formModel.StartTime))
+	start, err := util.ParseDateTimeFormIn(fmt.Sprintf("%v %v", formModel.Date, formModel.StartTime), loc)
 	if err != nil {
 		return nil, err
 	}
 
-	end, err := util.ParseDateTimeForm(fmt.Sprintf("%v %v", formModel.Date, formModel.EndTime))
+	// the end date is optional for activities ending on the day they start
+	endDate := formModel.EndDate
+	if endDate == "" {
+		endDate = formModel.Date
+	}
+
+	end, err := util.ParseDateTimeFormIn(fmt.Sprintf("%v %v", endDate, formModel.EndTime), loc)
 	if err != nil {
 		return nil, err
 	}
@@ -754,12 +888,29 @@ func mapFormToActivity(formModel activityFormModel) (*Activity, error) {
 		return nil, err
 	}
 
+	tags := ParseTags(formModel.Tags)
+	if len(tags) > 10 {
+		return nil, errors.New("too many tags")
+	}
+	for _, tag := range tags {
+		if len(tag) > 50 {
+			return nil, errors.Errorf("tag %v too long", tag)
+		}
+	}
+
 	activity := &Activity{
 		ID:          activityID,
 		Start:       *start,
 		End:         *end,
 		ProjectID:   projectID,
 		Description: formModel.Description,
+		Tags:        tags,
+		Billable:    formModel.Billable,
+	}
+
+	err = activity.Validate()
+	if err != nil {
+		return nil, err
 	}
 
 	return activity, nil
@@ -769,9 +920,26 @@ func mapActivityToForm(activity Activity) activityFormModel {
 	return activityFormModel{
 		ID:          activity.ID.String(),
 		Date:        util.FormatDateDE(activity.Start),
+		EndDate:     util.FormatDateDE(activity.End),
 		StartTime:   util.FormatTime(activity.Start),
 		EndTime:     util.FormatTime(activity.End),
 		ProjectID:   activity.ProjectID.String(),
 		Description: activity.Description,
+		Tags:        strings.Join(activity.Tags, ", "),
+		Billable:    activity.Billable,
+	}
+}
+
+func mapRunningActivityToTrackForm(runningActivity *RunningActivity, now time.Time) activityTrackFormModel {
+	if runningActivity == nil {
+		return activityTrackFormModel{Action: "start"}
+	}
+
+	return activityTrackFormModel{
+		Action:      "running",
+		ProjectID:   runningActivity.ProjectID.String(),
+		StartTime:   util.FormatTime(runningActivity.Start.In(now.Location())),
+		Duration:    runningActivity.DurationFormattedAt(now),
+		Description: runningActivity.Description,
 	}
 }
@@ -7,8 +7,10 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
 
@@ -31,6 +33,65 @@ func TestHandleActivityAddPage(t *testing.T) {
 	is.True(strings.Contains(htmlBody, "<form"))
 }
 
+func TestHandleActivityAddPageWithProjectHierarchy(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].ClientTitle = "ACME"
+	projectRepository.projects = append(projectRepository.projects, &Project{
+		ID:             uuid.New(),
+		Title:          "My Work Package",
+		Active:         true,
+		ParentID:       projectIDSample,
+		ParentTitle:    "My Project",
+		ClientTitle:    "ACME",
+		OrganizationID: organizationIDSample,
+	})
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: projectRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/activities/new", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleActivityAddPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, `<optgroup label="ACME">`))
+	is.True(strings.Contains(htmlBody, "My Project › My Work Package"))
+}
+
+func TestHandleActivityAddPageWithoutArchivedProjects(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, &Project{
+		ID:             uuid.New(),
+		Title:          "My archived Project",
+		OrganizationID: organizationIDSample,
+	})
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: projectRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/activities/new", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleActivityAddPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "My Project"))
+	is.True(!strings.Contains(htmlBody, "My archived Project"))
+}
+
 func TestHandleActivityEditPage(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -59,22 +120,188 @@ func TestHandleCreateActivtiyWithValidActivtiy(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
 
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ProjectRepository:   NewInMemProjectRepository(),
+		ActivityRepository:  repo,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Date"] = []string{"21.12.2021"}
+	data["StartTime"] = []string{"10:00"}
+	data["EndTime"] = []string{"11:00"}
+	data["Description"] = []string{"My description"}
+
+	r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleActivityForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore+1, len(repo.activities))
+}
+
+func TestHandleCreateActivtiySpanningMidnight(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ProjectRepository:   NewInMemProjectRepository(),
+		ActivityRepository:  repo,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Date"] = []string{"21.12.2021"}
+	data["EndDate"] = []string{"22.12.2021"}
+	data["StartTime"] = []string{"22:00"}
+	data["EndTime"] = []string{"06:00"}
+	data["Description"] = []string{"Night shift"}
+
+	r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleActivityForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	activity := repo.activities[len(repo.activities)-1]
+	is.Equal(activity.Description, "Night shift")
+	is.Equal(activity.DurationMinutesTotal(), 480)
+}
+
+func TestHandleCreateActivtiySpanningSeveralDays(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ProjectRepository:   NewInMemProjectRepository(),
+		ActivityRepository:  repo,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Date"] = []string{"21.12.2021"}
+	data["EndDate"] = []string{"23.12.2021"}
+	data["StartTime"] = []string{"08:00"}
+	data["EndTime"] = []string{"12:00"}
+	data["Description"] = []string{"Offsite"}
+
+	r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleActivityForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	activity := repo.activities[len(repo.activities)-1]
+	is.Equal(activity.Description, "Offsite")
+	is.Equal(activity.DurationMinutesTotal(), 52*60)
+}
+
+func TestHandleCreateActivtiyWithInvalidEnd(t *testing.T) {
+	is := is.New(t)
+
 	repo := NewInMemActivityRepository()
 	a := &app{
 		Config:             &config{},
 		RepositoryTxer:     NewInMemRepositoryTxer(),
 		ProjectRepository:  NewInMemProjectRepository(),
 		ActivityRepository: repo,
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
 	}
 
 	countBefore := len(repo.activities)
 
+	testCases := []struct {
+		endDate string
+		endTime string
+		message string
+	}{
+		{"21.12.2021", "08:00", "The end of the activity must be after its start."},
+		{"21.12.2021", "10:00", "The end of the activity must be after its start."},
+		{"20.12.2021", "12:00", "The end of the activity must be after its start."},
+	}
+
+	for _, testCase := range testCases {
+		httpRec := httptest.NewRecorder()
+
+		data := url.Values{}
+		data["ProjectID"] = []string{projectIDSample.String()}
+		data["Date"] = []string{"21.12.2021"}
+		data["EndDate"] = []string{testCase.endDate}
+		data["StartTime"] = []string{"10:00"}
+		data["EndTime"] = []string{testCase.endTime}
+		data["Description"] = []string{"Invalid end"}
+
+		r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Add("HX-Request", "true")
+
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+			Roles: []string{"ROLE_ADMIN"},
+		}))
+
+		a.HandleActivityForm()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+		htmlBody := httpRec.Body.String()
+		is.True(strings.Contains(htmlBody, testCase.message))
+		is.Equal(countBefore, len(repo.activities))
+	}
+}
+
+func TestHandleCreateActivtiyWithTags(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ProjectRepository:   NewInMemProjectRepository(),
+		ActivityRepository:  repo,
+		AuditLogRepository:  NewInMemAuditLogRepository(),
+		WebhookRepository:   NewInMemWebhookRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
 	data := url.Values{}
 	data["ProjectID"] = []string{projectIDSample.String()}
 	data["Date"] = []string{"21.12.2021"}
 	data["StartTime"] = []string{"10:00"}
 	data["EndTime"] = []string{"11:00"}
-	data["Description"] = []string{"My description"}
+	data["Tags"] = []string{"Meeting, support"}
 
 	r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
 	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -85,7 +312,7 @@ func TestHandleCreateActivtiyWithValidActivtiy(t *testing.T) {
 
 	a.HandleActivityForm()(httpRec, r)
 	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
-	is.Equal(countBefore+1, len(repo.activities))
+	is.Equal([]string{"meeting", "support"}, repo.activities[len(repo.activities)-1].Tags)
 }
 
 func TestHandleCreateActivtiyWithInvalidActivtiy(t *testing.T) {
@@ -164,3 +391,224 @@ func TestHandleEndTimeValidation(t *testing.T) {
 	htmlBody := httpRec.Body.String()
 	is.True(strings.Contains(htmlBody, "10:00"))
 }
+
+func TestHandleEndTimeValidationWithOverlap(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	repo.activities[0].Start = time.Date(2021, 12, 21, 9, 0, 0, 0, time.UTC)
+	repo.activities[0].End = time.Date(2021, 12, 21, 10, 30, 0, 0, time.UTC)
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: repo,
+	}
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Date"] = []string{"21.12.2021"}
+	data["StartTime"] = []string{"10:00"}
+	data["EndTime"] = []string{"11"}
+
+	r, _ := http.NewRequest("POST", "/activities/validation-end-time", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleEndTimeValidation()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "11:00"))
+	is.True(strings.Contains(htmlBody, "Overlaps with other activities (09:00 - 10:30)."))
+}
+
+func TestHandleCreateActivtiyWithRejectedOverlap(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	repo.activities[0].Start = time.Date(2021, 12, 21, 9, 0, 0, 0, time.UTC)
+	repo.activities[0].End = time.Date(2021, 12, 21, 10, 30, 0, 0, time.UTC)
+	a := &app{
+		Config:                 &config{RejectOverlaps: true},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		ActivityRepository:     repo,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Date"] = []string{"21.12.2021"}
+	data["StartTime"] = []string{"10:00"}
+	data["EndTime"] = []string{"11:00"}
+
+	r, _ := http.NewRequest("POST", "/activities/new", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("HX-Request", "true")
+
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleActivityForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore, len(repo.activities))
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Overlaps with other activities (09:00 - 10:30)."))
+}
+
+func TestHandleActivityTrackFormStartAndStop(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+		OrganizationRepository:    NewInMemOrganizationRepository(),
+		TimesheetRepository:       NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(activityRepository.activities)
+	principal := &Principal{Username: "user1", OrganizationID: organizationIDSample}
+
+	data := url.Values{}
+	data["Action"] = []string{"start"}
+	data["ProjectID"] = []string{projectIDSample.String()}
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/activities/track", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityTrackForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(runningActivityRepository.runningActivities))
+	is.True(strings.Contains(httpRec.Body.String(), "value=\"running\""))
+
+	data = url.Values{}
+	data["Action"] = []string{"running"}
+	data["Description"] = []string{"My description"}
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "/activities/track", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityTrackForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(runningActivityRepository.runningActivities))
+	is.Equal(countBefore+1, len(activityRepository.activities))
+	is.Equal("My description", activityRepository.activities[countBefore].Description)
+	is.True(strings.Contains(httpRec.Body.String(), "value=\"start\""))
+}
+
+func TestHandleActivityTrackFormStopInClosedPeriodAndDiscard(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:             uuid.New(),
+		Start:          time.Now().Add(-2 * time.Hour).Truncate(time.Minute),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       "user1",
+	})
+	organizationRepository := NewInMemOrganizationRepository()
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+		OrganizationRepository:    organizationRepository,
+	}
+
+	admin := &Principal{Username: "admin", OrganizationID: organizationIDSample, Roles: []string{"ROLE_ADMIN"}}
+	lockedUntil := time.Now().Add(24 * time.Hour)
+	_, err := a.UpdateLockedUntil(context.Background(), admin, &lockedUntil)
+	is.NoErr(err)
+
+	countBefore := len(activityRepository.activities)
+	principal := &Principal{Username: "user1", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+
+	data := url.Values{}
+	data["Action"] = []string{"running"}
+	data["Description"] = []string{"My description"}
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/activities/track", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityTrackForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(runningActivityRepository.runningActivities))
+	is.Equal(countBefore, len(activityRepository.activities))
+	is.True(strings.Contains(httpRec.Body.String(), "Please discard the timer."))
+	is.True(strings.Contains(httpRec.Body.String(), "/activities/track?action=discard"))
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "/activities/track?action=discard", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleActivityTrackForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(runningActivityRepository.runningActivities))
+	is.Equal(countBefore, len(activityRepository.activities))
+	is.True(strings.Contains(httpRec.Body.String(), "value=\"start\""))
+}
+
+func TestHandleActivityTrackFormReloadRestoresRunningTimer(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:          uuid.New(),
+		Start:       time.Now(),
+		ProjectID:   projectIDSample,
+		Description: "Started on other device",
+		Username:    "user1",
+	})
+
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		RunningActivityRepository: runningActivityRepository,
+	}
+
+	data := url.Values{}
+	data["Action"] = []string{"start"}
+
+	r, _ := http.NewRequest("POST", "/activities/track?action=reload", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1"}))
+
+	a.HandleActivityTrackForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "value=\"running\""))
+	is.True(strings.Contains(htmlBody, "Started on other device"))
+}
@@ -27,7 +27,20 @@ type ActivitiesFilter struct {
 	SortBy         string
 	SortOrder      string
 	Username       string
+	Usernames      []string
+	ProjectIDs     []uuid.UUID
+	Description    string
+	Tag            string
+	Timezone       string
 	OrganizationID uuid.UUID
+
+	// ManagedProjectIDs extends the activities of Username
+	// by all activities of these projects
+	ManagedProjectIDs []uuid.UUID
+
+	// SplitAtMidnight splits activities spanning midnight
+	// into their parts per day for the time reports
+	SplitAtMidnight bool
 }
 
 type ActivityRepository interface {
@@ -36,13 +49,81 @@ type ActivityRepository interface {
 	TimeReportByMonth(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error)
 	TimeReportByQuarter(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error)
 	ProjectReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityProjectReportItem, error)
+	TagReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTagReportItem, error)
+	UserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityUserReportItem, error)
+	ProjectUserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityProjectUserReportItem, error)
 	FindActivities(ctx context.Context, filter *ActivitiesFilter, pageParams *paged.PageParams) (*ActivitiesPaged, []*Project, error)
 	InsertActivity(ctx context.Context, activity *Activity) (*Activity, error)
 	FindActivityByID(ctx context.Context, activityID uuid.UUID, organizationID uuid.UUID) (*Activity, error)
+	FindOverlappingActivities(ctx context.Context, organizationID uuid.UUID, username string, start, end time.Time, excludedActivityID uuid.UUID) ([]*Activity, error)
 	DeleteActivityByID(ctx context.Context, organizationID, activityID uuid.UUID) error
 	DeleteActivityByIDAndUsername(ctx context.Context, organizationID, activityID uuid.UUID, username string) error
 	UpdateActivity(ctx context.Context, organizationID uuid.UUID, activity *Activity) (*Activity, error)
 	UpdateActivityByUsername(ctx context.Context, organizationID uuid.UUID, activity *Activity, username string) (*Activity, error)
+	FindProjectUsage(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectUsage, error)
+	FindProjectActivities(ctx context.Context, organizationID, projectID uuid.UUID) ([]*Activity, error)
+	ReassignProjectActivities(ctx context.Context, organizationID, projectID, targetProjectID uuid.UUID) error
+}
+
+// conditions returns the sql conditions for the optional criteria of the filter
+// and the params extended by the values of the conditions
+func (f *ActivitiesFilter) conditions(params []interface{}, alias string) (string, []interface{}) {
+	var conditions strings.Builder
+
+	if f.Username != "" && len(f.ManagedProjectIDs) > 0 {
+		params = append(params, f.Username, f.ManagedProjectIDs)
+		conditions.WriteString(fmt.Sprintf(" AND (%susername = $%v OR %sproject_id = any($%v))", alias, len(params)-1, alias, len(params)))
+	} else if f.Username != "" {
+		params = append(params, f.Username)
+		conditions.WriteString(fmt.Sprintf(" AND %susername = $%v", alias, len(params)))
+	}
+
+	if len(f.Usernames) > 0 {
+		params = append(params, f.Usernames)
+		conditions.WriteString(fmt.Sprintf(" AND %susername = any($%v)", alias, len(params)))
+	}
+
+	if len(f.ProjectIDs) > 0 {
+		params = append(params, f.ProjectIDs)
+		conditions.WriteString(fmt.Sprintf(" AND %sproject_id = any($%v)", alias, len(params)))
+	}
+
+	if f.Description != "" {
+		params = append(params, "%"+escapeLike(f.Description)+"%")
+		conditions.WriteString(fmt.Sprintf(
+			` AND %sactivity_id IN (
+			   SELECT activities.activity_id 
+			   FROM activities 
+			   WHERE activities.description ILIKE $%v)`,
+			alias, len(params),
+		))
+	}
+
+	if f.Tag != "" {
+		params = append(params, f.Tag)
+		conditions.WriteString(fmt.Sprintf(
+			` AND %sactivity_id IN (
+			   SELECT activity_tags.activity_id 
+			   FROM activity_tags 
+			   INNER JOIN tags ON tags.tag_id = activity_tags.tag_id
+			   WHERE tags.name = $%v)`,
+			alias, len(params),
+		))
+	}
+
+	return conditions.String(), params
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (f *ActivitiesFilter) timezone() string {
+	if f.Timezone == "" {
+		return "UTC"
+	}
+	return f.Timezone
 }
 
 // DbUserRepository is a SQL database repository for users
@@ -59,19 +140,51 @@ func NewDbActivityRepository(connPool *pgxpool.Pool) *DbActivityRepository {
 	}
 }
 
-func (r *DbActivityRepository) TimeReportByDay(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
-	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $4"
+// activitiesInTimezoneSql selects the activities of the filter with the
+// calendar fields evaluated in the time zone of the filter
+const activitiesInTimezoneSql = `
+		   SELECT EXTRACT(day from start_time AT TIME ZONE $4) as day, 
+		     EXTRACT(week from start_time AT TIME ZONE $4) as week, 
+		     EXTRACT(month from start_time AT TIME ZONE $4) as month, 
+		     EXTRACT(quarter from start_time AT TIME ZONE $4) as quarter, 
+		     EXTRACT(year from start_time AT TIME ZONE $4) as year, 
+		     duration_minutes_total
+		   FROM activities_agg
+	       WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s`
+
+// activityPartsInTimezoneSql selects the parts per day of the activities of the filter
+// with the calendar fields evaluated in the time zone of the filter
+const activityPartsInTimezoneSql = `
+		   SELECT EXTRACT(day from part_start AT TIME ZONE $4) as day, 
+		     EXTRACT(week from part_start AT TIME ZONE $4) as week, 
+		     EXTRACT(month from part_start AT TIME ZONE $4) as month, 
+		     EXTRACT(quarter from part_start AT TIME ZONE $4) as quarter, 
+		     EXTRACT(year from part_start AT TIME ZONE $4) as year, 
+		     FLOOR(EXTRACT(epoch from part_end - part_start) / 60)::integer as duration_minutes_total
+		   FROM (
+		     SELECT GREATEST(start_time, midnight AT TIME ZONE $4, $2) as part_start,
+		       LEAST(end_time, (midnight + interval '1 day') AT TIME ZONE $4, $3) as part_end
+		     FROM activities,
+		       generate_series(date_trunc('day', start_time AT TIME ZONE $4), end_time AT TIME ZONE $4, interval '1 day') as midnight
+		     WHERE org_id = $1 AND start_time < $3 AND $2 < end_time %s
+		   ) parts
+		   WHERE part_start < part_end`
+
+// timeReportSql is the sql to select the activities of the time reports
+func (f *ActivitiesFilter) timeReportSql() string {
+	if f.SplitAtMidnight {
+		return activityPartsInTimezoneSql
 	}
+	return activitiesInTimezoneSql
+}
+
+func (r *DbActivityRepository) TimeReportByDay(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End, filter.timezone()}
+	filterSql, params := filter.conditions(params, "")
 
 	sql := fmt.Sprintf(
 		`SELECT year, quarter, month, week, day, sum(duration_minutes_total) as duration_minutes_total  
-		 FROM activities_agg
-	     WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s
+		 FROM (`+filter.timeReportSql()+`) ag
 		 GROUP BY year, quarter, month, week, day
          ORDER BY (year, quarter, month, week, day) desc`,
 		filterSql,
@@ -114,18 +227,12 @@ func (r *DbActivityRepository) TimeReportByDay(ctx context.Context, filter *Acti
 }
 
 func (r *DbActivityRepository) TimeReportByWeek(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
-	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $4"
-	}
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End, filter.timezone()}
+	filterSql, params := filter.conditions(params, "")
 
 	sql := fmt.Sprintf(
 		`SELECT year, week, sum(duration_minutes_total) as duration_minutes_total  
-		 FROM activities_agg
-	     WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s
+		 FROM (`+filter.timeReportSql()+`) ag
 		 GROUP BY year, week
          ORDER BY (year, week) desc`,
 		filterSql,
@@ -162,18 +269,12 @@ func (r *DbActivityRepository) TimeReportByWeek(ctx context.Context, filter *Act
 }
 
 func (r *DbActivityRepository) TimeReportByMonth(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
-	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $4"
-	}
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End, filter.timezone()}
+	filterSql, params := filter.conditions(params, "")
 
 	sql := fmt.Sprintf(
 		`SELECT year, month, sum(duration_minutes_total) as duration_minutes_total  
-		 FROM activities_agg
-	     WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s
+		 FROM (`+filter.timeReportSql()+`) ag
 		 GROUP BY year, month
          ORDER BY (year, month) desc`,
 		filterSql,
@@ -211,18 +312,12 @@ func (r *DbActivityRepository) TimeReportByMonth(ctx context.Context, filter *Ac
 }
 
 func (r *DbActivityRepository) TimeReportByQuarter(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTimeReportItem, error) {
-	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $4"
-	}
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End, filter.timezone()}
+	filterSql, params := filter.conditions(params, "")
 
 	sql := fmt.Sprintf(
 		`SELECT year, quarter, sum(duration_minutes_total) as duration_minutes_total  
-		 FROM activities_agg
-	     WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s
+		 FROM (`+filter.timeReportSql()+`) ag
 		 GROUP BY year, quarter
          ORDER BY (year, quarter) desc`,
 		filterSql,
@@ -261,19 +356,21 @@ func (r *DbActivityRepository) TimeReportByQuarter(ctx context.Context, filter *
 
 func (r *DbActivityRepository) ProjectReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityProjectReportItem, error) {
 	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $4"
-	}
+	filterSql, params := filter.conditions(params, "a.")
 
 	sql := fmt.Sprintf(
-		`SELECT ag.project_id, projects.title as title, ag.duration_minutes_total FROM 
-		  (SELECT project_id, sum(duration_minutes_total) as duration_minutes_total  
-		   FROM activities_agg
-	       WHERE org_id = $1 AND $2 <= start_time AND start_time < $3 %s
-		   GROUP BY project_id
+		`SELECT ag.project_id, projects.title as title, projects.currency, 
+		   ag.duration_minutes_total, ag.billable_minutes_total, ag.revenue FROM 
+		  (SELECT a.project_id, sum(a.duration_minutes_total) as duration_minutes_total,
+		     sum(CASE WHEN a.billable THEN a.duration_minutes_total ELSE 0 END) as billable_minutes_total,
+		     round(sum(CASE WHEN a.billable THEN a.duration_minutes_total * COALESCE(r.hourly_rate, p.hourly_rate) / 60 ELSE 0 END), 2) as revenue
+		   FROM activities_agg a
+		   INNER JOIN projects p
+		   ON p.project_id = a.project_id
+		   LEFT JOIN project_user_rates r
+		   ON r.project_id = a.project_id AND r.username = a.username
+	       WHERE a.org_id = $1 AND $2 <= a.start_time AND a.start_time < $3 %s
+		   GROUP BY a.project_id
 		  ) ag
 		INNER JOIN projects
 		ON projects.project_id = ag.project_id
@@ -288,37 +385,179 @@ func (r *DbActivityRepository) ProjectReport(ctx context.Context, filter *Activi
 	defer rows.Close()
 
 	var activities []*ActivityProjectReportItem
+	for rows.Next() {
+		var (
+			projectID                 uuid.UUID
+			projectTitle              string
+			currency                  string
+			durationInMinutes         int
+			billableDurationInMinutes int
+			revenue                   float64
+		)
+
+		err = rows.Scan(&projectID, &projectTitle, &currency, &durationInMinutes, &billableDurationInMinutes, &revenue)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := &ActivityProjectReportItem{
+			ProjectID:                      projectID,
+			ProjectTitle:                   projectTitle,
+			DurationInMinutesTotal:         durationInMinutes,
+			BillableDurationInMinutesTotal: billableDurationInMinutes,
+			Revenue:                        revenue,
+			Currency:                       currency,
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
+
+func (r *DbActivityRepository) TagReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityTagReportItem, error) {
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
+	filterSql, params := filter.conditions(params, "a.")
+
+	sql := fmt.Sprintf(
+		`SELECT tags.name, sum(a.duration_minutes_total) as duration_minutes_total 
+		 FROM activities_agg a
+		 INNER JOIN activity_tags
+		 ON activity_tags.activity_id = a.activity_id
+		 INNER JOIN tags
+		 ON tags.tag_id = activity_tags.tag_id
+	     WHERE a.org_id = $1 AND $2 <= a.start_time AND a.start_time < $3 %s
+		 GROUP BY tags.name
+		 ORDER BY tags.name asc`,
+		filterSql,
+	)
+
+	rows, err := r.connPool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reportItems []*ActivityTagReportItem
+	for rows.Next() {
+		var (
+			tag               string
+			durationInMinutes int
+		)
+
+		err = rows.Scan(&tag, &durationInMinutes)
+		if err != nil {
+			return nil, err
+		}
+
+		reportItem := &ActivityTagReportItem{
+			Tag:                    tag,
+			DurationInMinutesTotal: durationInMinutes,
+		}
+		reportItems = append(reportItems, reportItem)
+	}
+
+	return reportItems, nil
+}
+
+func (r *DbActivityRepository) UserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityUserReportItem, error) {
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
+	filterSql, params := filter.conditions(params, "a.")
+
+	sql := fmt.Sprintf(
+		`SELECT ag.username, COALESCE(users.name, ag.username) as name, ag.duration_minutes_total FROM 
+		  (SELECT a.username, sum(a.duration_minutes_total) as duration_minutes_total
+		   FROM activities_agg a
+	       WHERE a.org_id = $1 AND $2 <= a.start_time AND a.start_time < $3 %s
+		   GROUP BY a.username
+		  ) ag
+		LEFT JOIN users
+		ON users.username = ag.username
+		ORDER BY (name) asc`,
+		filterSql,
+	)
+
+	rows, err := r.connPool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reportItems []*ActivityUserReportItem
+	for rows.Next() {
+		var (
+			username          string
+			name              string
+			durationInMinutes int
+		)
+
+		err = rows.Scan(&username, &name, &durationInMinutes)
+		if err != nil {
+			return nil, err
+		}
+
+		reportItem := &ActivityUserReportItem{
+			Username:               username,
+			Name:                   name,
+			DurationInMinutesTotal: durationInMinutes,
+		}
+		reportItems = append(reportItems, reportItem)
+	}
+
+	return reportItems, nil
+}
+
+func (r *DbActivityRepository) ProjectUserReport(ctx context.Context, filter *ActivitiesFilter) ([]*ActivityProjectUserReportItem, error) {
+	params := []interface{}{filter.OrganizationID, filter.Start, filter.End}
+	filterSql, params := filter.conditions(params, "a.")
+
+	sql := fmt.Sprintf(
+		`SELECT ag.project_id, projects.title as title, ag.username, ag.duration_minutes_total FROM 
+		  (SELECT a.project_id, a.username, sum(a.duration_minutes_total) as duration_minutes_total
+		   FROM activities_agg a
+	       WHERE a.org_id = $1 AND $2 <= a.start_time AND a.start_time < $3 %s
+		   GROUP BY a.project_id, a.username
+		  ) ag
+		INNER JOIN projects
+		ON projects.project_id = ag.project_id
+		ORDER BY (title, ag.username) asc`,
+		filterSql,
+	)
+
+	rows, err := r.connPool.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reportItems []*ActivityProjectUserReportItem
 	for rows.Next() {
 		var (
 			projectID         uuid.UUID
 			projectTitle      string
+			username          string
 			durationInMinutes int
 		)
 
-		err = rows.Scan(&projectID, &projectTitle, &durationInMinutes)
+		err = rows.Scan(&projectID, &projectTitle, &username, &durationInMinutes)
 		if err != nil {
 			return nil, err
 		}
 
-		activity := &ActivityProjectReportItem{
+		reportItem := &ActivityProjectUserReportItem{
 			ProjectID:              projectID,
 			ProjectTitle:           projectTitle,
+			Username:               username,
 			DurationInMinutesTotal: durationInMinutes,
 		}
-		activities = append(activities, activity)
+		reportItems = append(reportItems, reportItem)
 	}
 
-	return activities, nil
+	return reportItems, nil
 }
 
 func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *ActivitiesFilter, pageParams *paged.PageParams) (*ActivitiesPaged, []*Project, error) {
 	params := []interface{}{filter.OrganizationID, filter.Start, filter.End, pageParams.Size, pageParams.Offset()}
-	filterSql := ""
-
-	if filter.Username != "" {
-		params = append(params, filter.Username)
-		filterSql = " AND username = $6"
-	}
+	filterSql, params := filter.conditions(params, "")
 
 	sortBy := "start"
 	if filter.SortBy != "" {
@@ -331,13 +570,16 @@ func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *Activ
 	}
 
 	sql := fmt.Sprintf(
-		`SELECT a.*, projects.title as project FROM
-		   (SELECT activity_id as id, description, start_time as start, end_time as end, username, org_id, project_id
+		`SELECT a.*, projects.title as project, projects.currency, 
+		   COALESCE(project_user_rates.hourly_rate, projects.hourly_rate) as hourly_rate FROM
+		   (SELECT activity_id as id, description, start_time as start, end_time as end, username, org_id, project_id, billable
 			FROM activities 
 			WHERE org_id = $1 %s AND $2 <= start_time AND start_time < $3
 		   ) a
          INNER JOIN projects
 	     ON projects.project_id = a.project_id
+         LEFT JOIN project_user_rates
+	     ON project_user_rates.project_id = a.project_id AND project_user_rates.username = a.username
 		 ORDER by %s %s 
 	     LIMIT $4 OFFSET $5`,
 		filterSql,
@@ -362,10 +604,13 @@ func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *Activ
 			username       string
 			organizationID string
 			projectID      string
+			billable       bool
 			projectTitle   string
+			currency       string
+			hourlyRate     float64
 		)
 
-		err = rows.Scan(&id, &description, &startTime, &endTime, &username, &organizationID, &projectID, &projectTitle)
+		err = rows.Scan(&id, &description, &startTime, &endTime, &username, &organizationID, &projectID, &billable, &projectTitle, &currency, &hourlyRate)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -380,6 +625,8 @@ func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *Activ
 			Username:       username,
 			OrganizationID: uuid.MustParse(organizationID),
 			ProjectID:      projectUUID,
+			Billable:       billable,
+			HourlyRate:     hourlyRate,
 		}
 		activities = append(activities, activity)
 
@@ -388,24 +635,24 @@ func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *Activ
 				ID:             projectUUID,
 				OrganizationID: uuid.MustParse(organizationID),
 				Title:          projectTitle,
+				Currency:       currency,
 			}
 			projectsById[projectUUID] = project
 		}
 
 	}
 
+	err = r.readTags(ctx, activities)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	projects := make([]*Project, 0, len(projectsById))
 	for _, project := range projectsById {
 		projects = append(projects, project)
 	}
 
-	countParams := []interface{}{filter.OrganizationID, filter.Start, filter.End}
-	countFilter := ""
-
-	if filter.Username != "" {
-		countParams = append(countParams, filter.Username)
-		countFilter = " AND username = $4"
-	}
+	countFilter, countParams := filter.conditions([]interface{}{filter.OrganizationID, filter.Start, filter.End}, "")
 
 	countSql := fmt.Sprintf(`
      	SELECT count(*) as total 
@@ -429,7 +676,7 @@ func (r *DbActivityRepository) FindActivities(ctx context.Context, filter *Activ
 
 func (r *DbActivityRepository) FindActivityByID(ctx context.Context, activityID, organizationID uuid.UUID) (*Activity, error) {
 	row := r.connPool.QueryRow(ctx,
-		`SELECT activity_id as id, description, start_time, end_time, username, org_id, project_id 
+		`SELECT activity_id as id, description, start_time, end_time, username, org_id, project_id, billable 
          FROM activities 
 	     WHERE activity_id = $1 AND org_id = $2`,
 		activityID, organizationID)
@@ -442,9 +689,10 @@ func (r *DbActivityRepository) FindActivityByID(ctx context.Context, activityID,
 		username    string
 		orgID       string
 		projectID   string
+		billable    bool
 	)
 
-	err := row.Scan(&id, &description, &startTime, &endTime, &username, &orgID, &projectID)
+	err := row.Scan(&id, &description, &startTime, &endTime, &username, &orgID, &projectID, &billable)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrActivityNotFound
@@ -461,11 +709,162 @@ func (r *DbActivityRepository) FindActivityByID(ctx context.Context, activityID,
 		Username:       username,
 		OrganizationID: uuid.MustParse(orgID),
 		ProjectID:      uuid.MustParse(projectID),
+		Billable:       billable,
+	}
+
+	err = r.readTags(ctx, []*Activity{activity})
+	if err != nil {
+		return nil, err
 	}
 
 	return activity, nil
 }
 
+// FindOverlappingActivities finds the activities of the user which overlap with the time span
+// from start to end, except the excluded activity
+func (r *DbActivityRepository) FindOverlappingActivities(ctx context.Context, organizationID uuid.UUID, username string, start, end time.Time, excludedActivityID uuid.UUID) ([]*Activity, error) {
+	rows, err := r.connPool.Query(ctx,
+		`SELECT activity_id as id, description, start_time, end_time, project_id, billable 
+         FROM activities 
+	     WHERE org_id = $1 AND username = $2 AND start_time < $4 AND $3 < end_time AND activity_id <> $5
+		 ORDER BY start_time`,
+		organizationID, username, start, end, excludedActivityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []*Activity
+	for rows.Next() {
+		var (
+			id          string
+			description pgtype.Varchar
+			startTime   time.Time
+			endTime     time.Time
+			projectID   string
+			billable    bool
+		)
+
+		err = rows.Scan(&id, &description, &startTime, &endTime, &projectID, &billable)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := &Activity{
+			ID:             uuid.MustParse(id),
+			Description:    description.String,
+			Start:          startTime,
+			End:            endTime,
+			Username:       username,
+			OrganizationID: organizationID,
+			ProjectID:      uuid.MustParse(projectID),
+			Billable:       billable,
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
+
+// FindProjectUsage finds the number and duration of the activities and running activities of the project
+func (r *DbActivityRepository) FindProjectUsage(ctx context.Context, organizationID, projectID uuid.UUID) (*ProjectUsage, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`SELECT count(*), COALESCE(sum(duration_minutes_total), 0),
+		   (SELECT count(*) FROM running_activities WHERE org_id = $1 AND project_id = $2)
+         FROM activities_agg
+	     WHERE org_id = $1 AND project_id = $2`,
+		organizationID, projectID)
+
+	usage := &ProjectUsage{}
+	err := row.Scan(&usage.ActivityCount, &usage.DurationInMinutesTotal, &usage.RunningActivityCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// FindProjectActivities finds all activities of the project and locks them until the end of the transaction
+func (r *DbActivityRepository) FindProjectActivities(ctx context.Context, organizationID, projectID uuid.UUID) ([]*Activity, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	rows, err := tx.Query(ctx,
+		`SELECT activity_id as id, description, start_time, end_time, username, billable 
+         FROM activities 
+	     WHERE org_id = $1 AND project_id = $2
+		 ORDER BY start_time
+		 FOR UPDATE`,
+		organizationID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []*Activity
+	for rows.Next() {
+		var (
+			id          string
+			description pgtype.Varchar
+			startTime   time.Time
+			endTime     time.Time
+			username    string
+			billable    bool
+		)
+
+		err = rows.Scan(&id, &description, &startTime, &endTime, &username, &billable)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := &Activity{
+			ID:             uuid.MustParse(id),
+			Description:    description.String,
+			Start:          startTime,
+			End:            endTime,
+			Username:       username,
+			OrganizationID: organizationID,
+			ProjectID:      projectID,
+			Billable:       billable,
+		}
+		activities = append(activities, activity)
+	}
+	rows.Close()
+
+	err = r.readTags(ctx, activities)
+	if err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// ReassignProjectActivities moves all activities and running activities of the project to the target project
+func (r *DbActivityRepository) ReassignProjectActivities(ctx context.Context, organizationID, projectID, targetProjectID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE activities
+		 SET project_id = $3
+		 WHERE project_id = $1 AND org_id = $2`,
+		projectID, organizationID, targetProjectID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE running_activities
+		 SET project_id = $3
+		 WHERE project_id = $1 AND org_id = $2`,
+		projectID, organizationID, targetProjectID,
+	)
+	return err
+}
+
 func (r *DbActivityRepository) DeleteActivityByID(ctx context.Context, organizationID, activityID uuid.UUID) error {
 	row := r.connPool.QueryRow(ctx,
 		`DELETE 
@@ -523,11 +922,11 @@ func (r *DbActivityRepository) UpdateActivity(ctx context.Context, organizationI
 
 	row := tx.QueryRow(ctx,
 		`UPDATE activities 
-		 SET start_time = $3, end_time = $4, description = $5, project_id = $6 
+		 SET start_time = $3, end_time = $4, description = $5, project_id = $6, billable = $7 
 		 WHERE activity_id = $1 AND org_id = $2
 		 RETURNING activity_id`,
 		activity.ID, organizationID,
-		activity.Start, activity.End, activity.Description, activity.ProjectID,
+		activity.Start, activity.End, activity.Description, activity.ProjectID, activity.Billable,
 	)
 
 	var id string
@@ -540,6 +939,11 @@ func (r *DbActivityRepository) UpdateActivity(ctx context.Context, organizationI
 		return nil, err
 	}
 
+	err = writeTags(ctx, tx, organizationID, activity)
+	if err != nil {
+		return nil, err
+	}
+
 	return activity, nil
 }
 
@@ -548,11 +952,11 @@ func (r *DbActivityRepository) UpdateActivityByUsername(ctx context.Context, org
 
 	row := tx.QueryRow(ctx,
 		`UPDATE activities 
-		 SET start_time = $4, end_time = $5, description = $6, project_id = $7 
+		 SET start_time = $4, end_time = $5, description = $6, project_id = $7, billable = $8 
 		 WHERE activity_id = $1 AND org_id = $2 AND username = $3
 		 RETURNING activity_id`,
 		activity.ID, organizationID, username,
-		activity.Start, activity.End, activity.Description, activity.ProjectID,
+		activity.Start, activity.End, activity.Description, activity.ProjectID, activity.Billable,
 	)
 
 	var id string
@@ -565,6 +969,11 @@ func (r *DbActivityRepository) UpdateActivityByUsername(ctx context.Context, org
 		return nil, err
 	}
 
+	err = writeTags(ctx, tx, organizationID, activity)
+	if err != nil {
+		return nil, err
+	}
+
 	return activity, nil
 }
 
@@ -574,9 +983,9 @@ func (r *DbActivityRepository) InsertActivity(ctx context.Context, activity *Act
 	_, err := tx.Exec(
 		ctx,
 		`INSERT INTO activities 
-		   (activity_id, start_time, end_time, description, project_id, org_id, username) 
+		   (activity_id, start_time, end_time, description, project_id, org_id, username, billable) 
 		 VALUES 
-		   ($1, $2, $3, $4, $5, $6, $7)`,
+		   ($1, $2, $3, $4, $5, $6, $7, $8)`,
 		activity.ID,
 		activity.Start,
 		activity.End,
@@ -584,10 +993,102 @@ func (r *DbActivityRepository) InsertActivity(ctx context.Context, activity *Act
 		activity.ProjectID,
 		activity.OrganizationID,
 		activity.Username,
+		activity.Billable,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	err = writeTags(ctx, tx, activity.OrganizationID, activity)
+	if err != nil {
+		return nil, err
+	}
+
 	return activity, nil
 }
+
+// readTags reads the tags of the activities
+func (r *DbActivityRepository) readTags(ctx context.Context, activities []*Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	activitiesByID := make(map[uuid.UUID]*Activity)
+	activityIDs := make([]uuid.UUID, len(activities))
+	for i, activity := range activities {
+		activity.Tags = []string{}
+		activitiesByID[activity.ID] = activity
+		activityIDs[i] = activity.ID
+	}
+
+	rows, err := r.connPool.Query(ctx,
+		`SELECT activity_tags.activity_id, tags.name 
+		 FROM activity_tags
+		 INNER JOIN tags
+		 ON tags.tag_id = activity_tags.tag_id
+		 WHERE activity_tags.activity_id = any($1)
+		 ORDER BY tags.name asc`,
+		activityIDs,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			activityID uuid.UUID
+			tag        string
+		)
+
+		err = rows.Scan(&activityID, &tag)
+		if err != nil {
+			return err
+		}
+
+		activity := activitiesByID[activityID]
+		activity.Tags = append(activity.Tags, tag)
+	}
+
+	return nil
+}
+
+// writeTags replaces the tags of the activity, unknown tags are created
+func writeTags(ctx context.Context, tx pgx.Tx, organizationID uuid.UUID, activity *Activity) error {
+	_, err := tx.Exec(ctx,
+		`DELETE FROM activity_tags 
+		 WHERE activity_id = $1 AND org_id = $2`,
+		activity.ID, organizationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range activity.Tags {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO tags 
+			   (tag_id, name, org_id) 
+			 VALUES 
+			   ($1, $2, $3)
+			 ON CONFLICT (org_id, name) DO NOTHING`,
+			uuid.New(), tag, organizationID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO activity_tags 
+			   (activity_id, tag_id, org_id) 
+			 SELECT $1, tag_id, org_id 
+			 FROM tags 
+			 WHERE org_id = $2 AND name = $3`,
+			activity.ID, organizationID, tag,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var ErrApiTokenNotFound = errors.New("api token not found")
+
+type ApiTokenRepository interface {
+	FindApiTokensByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]*ApiToken, error)
+	FindApiTokenByHash(ctx context.Context, hash string) (*ApiToken, error)
+	InsertApiToken(ctx context.Context, apiToken *ApiToken) (*ApiToken, error)
+	UpdateApiTokenLastUsedAt(ctx context.Context, apiTokenID uuid.UUID, lastUsedAt time.Time) error
+	DeleteApiTokenByID(ctx context.Context, organizationID, userID, apiTokenID uuid.UUID) error
+}
+
+// DbApiTokenRepository is a SQL database repository for api tokens
+type DbApiTokenRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ ApiTokenRepository = (*DbApiTokenRepository)(nil)
+
+// NewDbApiTokenRepository creates a new SQL database repository for api tokens
+func NewDbApiTokenRepository(connPool *pgxpool.Pool) *DbApiTokenRepository {
+	return &DbApiTokenRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbApiTokenRepository) FindApiTokensByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]*ApiToken, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT api_token_id, name, token_hash, token_prefix, scope, user_id, org_id, created_at, last_used_at, expires_at
+		 FROM api_tokens
+		 WHERE org_id = $1 AND user_id = $2
+		 ORDER BY created_at DESC`,
+		organizationID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiTokens []*ApiToken
+	for rows.Next() {
+		apiToken, err := scanApiToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		apiTokens = append(apiTokens, apiToken)
+	}
+
+	return apiTokens, nil
+}
+
+func (r *DbApiTokenRepository) FindApiTokenByHash(ctx context.Context, hash string) (*ApiToken, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT api_token_id, name, token_hash, token_prefix, scope, user_id, org_id, created_at, last_used_at, expires_at
+		 FROM api_tokens
+		 WHERE token_hash = $1`,
+		hash,
+	)
+
+	apiToken, err := scanApiToken(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrApiTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return apiToken, nil
+}
+
+func (r *DbApiTokenRepository) InsertApiToken(ctx context.Context, apiToken *ApiToken) (*ApiToken, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO api_tokens
+		   (api_token_id, name, token_hash, token_prefix, scope, user_id, org_id, created_at, expires_at)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		apiToken.ID,
+		apiToken.Name,
+		apiToken.Hash,
+		apiToken.Prefix,
+		apiToken.Scope,
+		apiToken.UserID,
+		apiToken.OrganizationID,
+		apiToken.CreatedAt,
+		apiToken.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiToken, nil
+}
+
+func (r *DbApiTokenRepository) UpdateApiTokenLastUsedAt(ctx context.Context, apiTokenID uuid.UUID, lastUsedAt time.Time) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE api_tokens
+		 SET last_used_at = $2
+		 WHERE api_token_id = $1`,
+		apiTokenID, lastUsedAt,
+	)
+
+	return err
+}
+
+func (r *DbApiTokenRepository) DeleteApiTokenByID(ctx context.Context, organizationID, userID, apiTokenID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`DELETE
+         FROM api_tokens
+	     WHERE api_token_id = $1 AND org_id = $2 AND user_id = $3
+		 RETURNING api_token_id`,
+		apiTokenID, organizationID, userID)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrApiTokenNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func scanApiToken(row pgx.Row) (*ApiToken, error) {
+	apiToken := &ApiToken{}
+
+	err := row.Scan(
+		&apiToken.ID,
+		&apiToken.Name,
+		&apiToken.Hash,
+		&apiToken.Prefix,
+		&apiToken.Scope,
+		&apiToken.UserID,
+		&apiToken.OrganizationID,
+		&apiToken.CreatedAt,
+		&apiToken.LastUsedAt,
+		&apiToken.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiToken, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestApiTokenRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	apiTokenRepository := NewDbApiTokenRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("FindNotExistingApiToken", func(t *testing.T) {
+		_, err := apiTokenRepository.FindApiTokenByHash(
+			context.Background(),
+			HashApiToken("-not existing-"),
+		)
+
+		is.True(errors.Is(err, ErrApiTokenNotFound))
+	})
+
+	t.Run("InsertAndFindAndUpdateAndDeleteApiToken", func(t *testing.T) {
+		apiToken := &ApiToken{
+			ID:             uuid.New(),
+			Name:           "My Token",
+			Hash:           HashApiToken("bat_secret"),
+			Prefix:         "bat_secret",
+			Scope:          ApiTokenScopeRead,
+			UserID:         userIDAdminSample,
+			OrganizationID: organizationIDSample,
+			CreatedAt:      time.Now(),
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := apiTokenRepository.InsertApiToken(ctx, apiToken)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		apiTokenFound, err := apiTokenRepository.FindApiTokenByHash(context.Background(), apiToken.Hash)
+		is.NoErr(err)
+		is.Equal(apiToken.ID, apiTokenFound.ID)
+		is.Equal(ApiTokenScopeRead, apiTokenFound.Scope)
+		is.True(apiTokenFound.LastUsedAt == nil)
+		is.True(apiTokenFound.ExpiresAt == nil)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return apiTokenRepository.UpdateApiTokenLastUsedAt(ctx, apiToken.ID, time.Now())
+			},
+		)
+		is.NoErr(err)
+
+		apiTokens, err := apiTokenRepository.FindApiTokensByUserID(context.Background(), organizationIDSample, userIDAdminSample)
+		is.NoErr(err)
+		is.Equal(1, len(apiTokens))
+		is.True(apiTokens[0].LastUsedAt != nil)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return apiTokenRepository.DeleteApiTokenByID(ctx, organizationIDSample, userIDAdminSample, apiToken.ID)
+			},
+		)
+		is.NoErr(err)
+
+		_, err = apiTokenRepository.FindApiTokenByHash(context.Background(), apiToken.Hash)
+		is.True(errors.Is(err, ErrApiTokenNotFound))
+	})
+
+	t.Run("DeleteNotExistingApiToken", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return apiTokenRepository.DeleteApiTokenByID(ctx, organizationIDSample, userIDAdminSample, uuid.New())
+			},
+		)
+		is.True(errors.Is(err, ErrApiTokenNotFound))
+	})
+}
+
+type InMemApiTokenRepository struct {
+	apiTokens []*ApiToken
+}
+
+var _ ApiTokenRepository = (*InMemApiTokenRepository)(nil)
+
+func NewInMemApiTokenRepository() *InMemApiTokenRepository {
+	return &InMemApiTokenRepository{
+		apiTokens: []*ApiToken{},
+	}
+}
+
+func (r *InMemApiTokenRepository) FindApiTokensByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]*ApiToken, error) {
+	var apiTokens []*ApiToken
+	for _, t := range r.apiTokens {
+		if t.OrganizationID == organizationID && t.UserID == userID {
+			apiTokens = append(apiTokens, t)
+		}
+	}
+	return apiTokens, nil
+}
+
+func (r *InMemApiTokenRepository) FindApiTokenByHash(ctx context.Context, hash string) (*ApiToken, error) {
+	for _, t := range r.apiTokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return nil, ErrApiTokenNotFound
+}
+
+func (r *InMemApiTokenRepository) InsertApiToken(ctx context.Context, apiToken *ApiToken) (*ApiToken, error) {
+	r.apiTokens = append(r.apiTokens, apiToken)
+	return apiToken, nil
+}
+
+func (r *InMemApiTokenRepository) UpdateApiTokenLastUsedAt(ctx context.Context, apiTokenID uuid.UUID, lastUsedAt time.Time) error {
+	for _, t := range r.apiTokens {
+		if t.ID == apiTokenID {
+			t.LastUsedAt = &lastUsedAt
+			return nil
+		}
+	}
+	return ErrApiTokenNotFound
+}
+
+func (r *InMemApiTokenRepository) DeleteApiTokenByID(ctx context.Context, organizationID, userID, apiTokenID uuid.UUID) error {
+	for i, t := range r.apiTokens {
+		if t.ID == apiTokenID && t.OrganizationID == organizationID && t.UserID == userID {
+			r.apiTokens = append(r.apiTokens[:i], r.apiTokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrApiTokenNotFound
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrApiTokenScopeInvalid = errors.New("api token scope invalid")
+	ErrApiTokenInvalid      = errors.New("api token invalid")
+)
+
+// ReadApiTokens reads the api tokens of the principal
+func (a *app) ReadApiTokens(ctx context.Context, principal *Principal) ([]*ApiToken, error) {
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.ApiTokenRepository.FindApiTokensByUserID(ctx, principal.OrganizationID, user.ID)
+}
+
+// CreateApiToken creates a new api token for the principal, the returned secret
+// is the token to use and can't be read again later on
+func (a *app) CreateApiToken(ctx context.Context, principal *Principal, name, scope string, expiresAt *time.Time) (*ApiToken, string, error) {
+	if !IsValidApiTokenScope(scope) {
+		return nil, "", ErrApiTokenScopeInvalid
+	}
+
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := NewApiTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiToken := &ApiToken{
+		ID:             uuid.New(),
+		Name:           name,
+		Hash:           HashApiToken(secret),
+		Prefix:         secret[:len(apiTokenPrefix)+8],
+		Scope:          scope,
+		UserID:         user.ID,
+		OrganizationID: principal.OrganizationID,
+		CreatedAt:      time.Now(),
+		ExpiresAt:      expiresAt,
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			_, err := a.ApiTokenRepository.InsertApiToken(ctx, apiToken)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return apiToken, secret, nil
+}
+
+// DeleteApiToken revokes the api token of the principal
+func (a *app) DeleteApiToken(ctx context.Context, principal *Principal, apiTokenID uuid.UUID) error {
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return err
+	}
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.ApiTokenRepository.DeleteApiTokenByID(ctx, principal.OrganizationID, user.ID, apiTokenID)
+		},
+	)
+}
+
+// AuthenticateApiToken authenticates the user of the api token
+func (a *app) AuthenticateApiToken(ctx context.Context, secret string) (*Principal, *ApiToken, error) {
+	apiToken, err := a.ApiTokenRepository.FindApiTokenByHash(ctx, HashApiToken(secret))
+	if errors.Is(err, ErrApiTokenNotFound) {
+		return nil, nil, ErrApiTokenInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if apiToken.IsExpiredAt(now) {
+		return nil, nil, ErrApiTokenInvalid
+	}
+
+	user, err := a.UserRepository.FindUserByID(ctx, apiToken.OrganizationID, apiToken.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, nil, ErrApiTokenInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.Enabled {
+		return nil, nil, ErrApiTokenInvalid
+	}
+
+	roles, err := a.UserRepository.FindRolesByUserID(ctx, user.OrganizationID, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.ApiTokenRepository.UpdateApiTokenLastUsedAt(ctx, apiToken.ID, now)
+		},
+	)
+	if err != nil {
+		log.Printf("could not update last usage of api token %v: %v", apiToken.ID, err)
+	}
+
+	return mapUserToPrincipal(user, roles), apiToken, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestCreateAndAuthenticateAndDeleteApiToken(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	apiTokenRepository := NewInMemApiTokenRepository()
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: apiTokenRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	apiToken, secret, err := a.CreateApiToken(context.Background(), principal, "My Token", ApiTokenScopeWrite, nil)
+	is.NoErr(err)
+
+	principalAuthenticated, apiTokenAuthenticated, err := a.AuthenticateApiToken(context.Background(), secret)
+	is.NoErr(err)
+
+	err = a.DeleteApiToken(context.Background(), principal, apiToken.ID)
+	is.NoErr(err)
+
+	_, _, errAfterDelete := a.AuthenticateApiToken(context.Background(), secret)
+
+	// Assert
+	is.True(strings.HasPrefix(secret, "bat_"))
+	is.True(strings.HasPrefix(secret, apiToken.Prefix))
+	is.True(apiToken.Hash != secret)
+	is.Equal(principal.Username, principalAuthenticated.Username)
+	is.Equal(apiToken.ID, apiTokenAuthenticated.ID)
+	is.True(apiTokenAuthenticated.LastUsedAt != nil)
+	is.True(errors.Is(errAfterDelete, ErrApiTokenInvalid))
+}
+
+func TestAuthenticateExpiredApiToken(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+	expiresAt := time.Now().Add(-1 * time.Minute)
+
+	_, secret, err := a.CreateApiToken(context.Background(), principal, "My Token", ApiTokenScopeRead, &expiresAt)
+	is.NoErr(err)
+
+	// Act
+	_, _, err = a.AuthenticateApiToken(context.Background(), secret)
+
+	// Assert
+	is.True(errors.Is(err, ErrApiTokenInvalid))
+}
+
+func TestCreateApiTokenWithInvalidScope(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, _, err := a.CreateApiToken(context.Background(), principal, "My Token", "admin", nil)
+
+	// Assert
+	is.True(errors.Is(err, ErrApiTokenScopeInvalid))
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type apiTokenFormModel struct {
+	CSRFToken     string
+	Name          string `validate:"required,min=1,max=100"`
+	Scope         string `validate:"required,oneof=read write calendar"`
+	ExpiresInDays string `validate:"omitempty,oneof=30 90 365"`
+}
+
+type apiTokensParams struct {
+	secretCreated   string
+	calendarFeedURL string
+	errorMessage    string
+}
+
+func (a *app) HandleApiTokensPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		apiTokens, err := a.ReadApiTokens(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		formModel := apiTokenFormModel{
+			Scope: ApiTokenScopeRead,
+		}
+		formModel.CSRFToken = csrf.Token(r)
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "API Tokens",
+		}
+
+		util.RenderHTML(w, ApiTokensPage(pageContext, formModel, apiTokens))
+	}
+}
+
+func (a *app) HandleApiTokenForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		err := r.ParseForm()
+		if err != nil {
+			a.renderApiTokensView(w, r, principal, isProduction, apiTokenFormModel{}, &apiTokensParams{})
+			return
+		}
+
+		var formModel apiTokenFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			a.renderApiTokensView(w, r, principal, isProduction, apiTokenFormModel{}, &apiTokensParams{})
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			a.renderApiTokensView(w, r, principal, isProduction, formModel, &apiTokensParams{
+				errorMessage: "Please enter a name and select the scope of the token.",
+			})
+			return
+		}
+
+		var expiresAt *time.Time
+		if formModel.ExpiresInDays != "" {
+			days, _ := strconv.Atoi(formModel.ExpiresInDays)
+			e := time.Now().AddDate(0, 0, days)
+			expiresAt = &e
+		}
+
+		apiToken, secret, err := a.CreateApiToken(r.Context(), principal, formModel.Name, formModel.Scope, expiresAt)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		params := &apiTokensParams{
+			secretCreated: secret,
+		}
+		if apiToken.Scope == ApiTokenScopeCalendar {
+			params.calendarFeedURL = fmt.Sprintf("%v/api/calendar/%v.ics", a.Config.Webroot, secret)
+		}
+
+		a.renderApiTokensView(w, r, principal, isProduction, apiTokenFormModel{Scope: ApiTokenScopeRead}, params)
+	}
+}
+
+func (a *app) HandleRevokeApiToken() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		apiTokenID, err := uuid.Parse(chi.URLParam(r, "api-token-id"))
+		if err != nil {
+			http.Error(w, "Invalid api token.", http.StatusBadRequest)
+			return
+		}
+
+		err = a.DeleteApiToken(r.Context(), principal, apiTokenID)
+		if errors.Is(err, ErrApiTokenNotFound) {
+			http.Error(w, "Api token not found.", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderApiTokensView(w, r, principal, isProduction, apiTokenFormModel{Scope: ApiTokenScopeRead}, &apiTokensParams{})
+	}
+}
+
+func (a *app) renderApiTokensView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel apiTokenFormModel, params *apiTokensParams) {
+	apiTokens, err := a.ReadApiTokens(r.Context(), principal)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	formModel.CSRFToken = csrf.Token(r)
+
+	util.RenderHTML(w, ApiTokensView(principal, formModel, apiTokens, params))
+}
+
+func ApiTokensPage(pageContext *pageContext, formModel apiTokenFormModel, apiTokens []*ApiToken) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("API Tokens")),
+						P(
+							Class("text-muted"),
+							g.Text("Personal API tokens authenticate scripts and integrations against the API. "),
+							g.Text("Send them as bearer token in the Authorization header. "),
+							g.Text("Calendar feed tokens give you a secret URL to subscribe to your activities in your calendar app."),
+						),
+					),
+					Div(
+						ID("baralga__api_tokens"),
+						ApiTokensView(pageContext.principal, formModel, apiTokens, &apiTokensParams{}),
+					),
+				),
+			),
+		},
+	)
+}
+
+func ApiTokensView(principal *Principal, formModel apiTokenFormModel, apiTokens []*ApiToken, params *apiTokensParams) g.Node {
+	now := time.Now()
+
+	var secretCreated g.Node
+	switch {
+	case params.calendarFeedURL != "":
+		secretCreated = Div(
+			Class("alert alert-success"),
+			Role("alert"),
+			P(g.Text("Your new calendar feed. Subscribe to it in your calendar app, the URL won't be shown again.")),
+			Code(
+				ID("calendar-feed-url"),
+				g.Text(params.calendarFeedURL),
+			),
+			P(
+				Class("mt-2 mb-0"),
+				g.Text("The feed contains the activities of the current week, add ?t=month or ?t=year to the URL for a longer timespan."),
+			),
+		)
+	case params.secretCreated != "":
+		secretCreated = Div(
+			Class("alert alert-success"),
+			Role("alert"),
+			P(g.Text("Your new API token. Copy it now, it won't be shown again.")),
+			Code(
+				ID("api-token-secret"),
+				g.Text(params.secretCreated),
+			),
+		)
+	}
+
+	return Div(
+		secretCreated,
+		ApiTokenForm(formModel, params.errorMessage),
+		g.If(
+			len(apiTokens) > 0,
+			H5(
+				Class("mt-4"),
+				g.Text("Active Tokens"),
+			),
+		),
+		g.Group(
+			g.Map(len(apiTokens), func(i int) g.Node {
+				apiToken := apiTokens[i]
+
+				lastUsed := "never used"
+				if apiToken.LastUsedAt != nil {
+					lastUsed = fmt.Sprintf("last used on %v", util.FormatDateDE(apiToken.LastUsedAt.In(principal.Location())))
+				}
+
+				expires := "never expires"
+				if apiToken.ExpiresAt != nil {
+					expires = fmt.Sprintf("expires on %v", util.FormatDateDE(apiToken.ExpiresAt.In(principal.Location())))
+				}
+				if apiToken.IsExpiredAt(now) {
+					expires = "expired"
+				}
+
+				return Div(
+					Class("card mt-2"),
+					Div(
+						Class("card-body"),
+						Div(
+							Class("d-flex justify-content-between"),
+							Span(
+								Class("flex-grow-1"),
+								g.Text(apiToken.Name),
+								Span(
+									Class("badge bg-secondary ms-2"),
+									g.Text(apiToken.Scope),
+								),
+								Code(
+									Class("ms-2"),
+									g.Textf("%v...", apiToken.Prefix),
+								),
+								Small(
+									Class("text-muted ms-2"),
+									g.Textf(
+										"created on %v, %v, %v",
+										util.FormatDateDE(apiToken.CreatedAt.In(principal.Location())),
+										lastUsed,
+										expires,
+									),
+								),
+							),
+							FormEl(
+								hx.Post(fmt.Sprintf("/settings/tokens/%v/revoke", apiToken.ID)),
+								hx.Target("#baralga__api_tokens"),
+								hx.Swap("innerHTML"),
+								hx.Confirm(fmt.Sprintf("Do you really want to revoke the token %v?", apiToken.Name)),
+
+								Input(
+									Type("hidden"),
+									Name("CSRFToken"),
+									Value(formModel.CSRFToken),
+								),
+								Button(
+									Type("submit"),
+									Class("btn btn-outline-secondary btn-sm ms-1"),
+									TitleAttr("Revoke Token"),
+									I(Class("bi-trash2")),
+								),
+							),
+						),
+					),
+				)
+			}),
+		),
+	)
+}
+
+func ApiTokenForm(formModel apiTokenFormModel, errorMessage string) g.Node {
+	return FormEl(
+		ID("api_token_form"),
+		Class("mb-4 mt-2"),
+		hx.Post("/settings/tokens"),
+		hx.Target("#baralga__api_tokens"),
+		hx.Swap("innerHTML"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-warning text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		Div(
+			Class("input-group mb-3"),
+			Input(
+				ID("ApiTokenName"),
+				Type("text"),
+				Name("Name"),
+				MaxLength("100"),
+				Value(formModel.Name),
+				g.Attr("required", "required"),
+				Class("form-control"),
+				g.Attr("placeholder", "CI Script"),
+			),
+			Select(
+				Name("Scope"),
+				Class("form-select"),
+				TitleAttr("Scope"),
+				Option(
+					Value(ApiTokenScopeRead),
+					g.Text("Read only"),
+					g.If(formModel.Scope == ApiTokenScopeRead, Selected()),
+				),
+				Option(
+					Value(ApiTokenScopeWrite),
+					g.Text("Read and write"),
+					g.If(formModel.Scope == ApiTokenScopeWrite, Selected()),
+				),
+				Option(
+					Value(ApiTokenScopeCalendar),
+					g.Text("Calendar feed"),
+					g.If(formModel.Scope == ApiTokenScopeCalendar, Selected()),
+				),
+			),
+			Select(
+				Name("ExpiresInDays"),
+				Class("form-select"),
+				TitleAttr("Expiration"),
+				Option(
+					Value(""),
+					g.Text("No expiration"),
+					g.If(formModel.ExpiresInDays == "", Selected()),
+				),
+				g.Group(g.Map(3, func(i int) g.Node {
+					days := []string{"30", "90", "365"}[i]
+					return Option(
+						Value(days),
+						g.Textf("%v days", days),
+						g.If(formModel.ExpiresInDays == days, Selected()),
+					)
+				})),
+			),
+			Button(
+				Class("btn btn-outline-primary"),
+				TitleAttr("Create Token"),
+				I(Class("bi-key me-2")),
+				g.Text("Create"),
+			),
+		),
+	)
+}
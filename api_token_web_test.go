@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/matryer/is"
+)
+
+func TestHandleApiTokensPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/settings/tokens", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleApiTokensPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "API Tokens # Baralga"))
+}
+
+func TestHandleApiTokenFormAndRevoke(t *testing.T) {
+	is := is.New(t)
+
+	apiTokenRepository := NewInMemApiTokenRepository()
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: apiTokenRepository,
+	}
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	data := url.Values{}
+	data["Name"] = []string{"CI Script"}
+	data["Scope"] = []string{"write"}
+	data["ExpiresInDays"] = []string{"30"}
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/settings/tokens", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleApiTokenForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(apiTokenRepository.apiTokens))
+	is.Equal(ApiTokenScopeWrite, apiTokenRepository.apiTokens[0].Scope)
+	is.True(apiTokenRepository.apiTokens[0].ExpiresAt != nil)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"api-token-secret\""))
+	is.True(strings.Contains(htmlBody, "CI Script"))
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "/settings/tokens/revoke", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("api-token-id", apiTokenRepository.apiTokens[0].ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleRevokeApiToken()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(apiTokenRepository.apiTokens))
+}
+
+func TestHandleApiTokenFormWithInvalidScope(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	apiTokenRepository := NewInMemApiTokenRepository()
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: apiTokenRepository,
+	}
+
+	data := url.Values{}
+	data["Name"] = []string{"CI Script"}
+	data["Scope"] = []string{"admin"}
+
+	r, _ := http.NewRequest("POST", "/settings/tokens", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleApiTokenForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(apiTokenRepository.apiTokens))
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Please enter a name"))
+}
+
+func TestHandleApiTokenFormWithCalendarScope(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	apiTokenRepository := NewInMemApiTokenRepository()
+	a := &app{
+		Config: &config{
+			Webroot: "https://baralga.example.com",
+		},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: apiTokenRepository,
+	}
+
+	data := url.Values{}
+	data["Name"] = []string{"My Calendar"}
+	data["Scope"] = []string{"calendar"}
+
+	r, _ := http.NewRequest("POST", "/settings/tokens", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleApiTokenForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(apiTokenRepository.apiTokens))
+	is.Equal(ApiTokenScopeCalendar, apiTokenRepository.apiTokens[0].Scope)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"calendar-feed-url\""))
+	is.True(strings.Contains(htmlBody, "https://baralga.example.com/api/calendar/bat_"))
+}
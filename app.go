@@ -45,6 +45,12 @@ type config struct {
 
 	DataProtectionURL string `default:"#"`
 
+	RejectOverlaps bool `default:"false"`
+
+	WebhookAllowInsecure bool `default:"false"`
+
+	CalendarAllowInsecure bool `default:"false"`
+
 	GithubClientId     string `default:""`
 	GithubClientSecret string `default:""`
 	GithubRedirectURL  string `default:"http://localhost:8080/github/callback"`
@@ -74,7 +80,14 @@ type app struct {
 	UserRepository         UserRepository
 	OrganizationRepository OrganizationRepository
 	ProjectRepository      ProjectRepository
+	ClientRepository       ClientRepository
 	ActivityRepository     ActivityRepository
+
+	RunningActivityRepository RunningActivityRepository
+	ApiTokenRepository        ApiTokenRepository
+	TimesheetRepository       TimesheetRepository
+	AuditLogRepository        AuditLogRepository
+	WebhookRepository         WebhookRepository
 }
 
 //go:embed migrations
@@ -125,7 +138,15 @@ func (a *app) run() error {
 	a.UserRepository = NewDbUserRepository(connPool)
 	a.OrganizationRepository = NewDbOrganizationRepository(connPool)
 	a.ProjectRepository = NewDbProjectRepository(connPool)
+	a.ClientRepository = NewDbClientRepository(connPool)
 	a.ActivityRepository = NewDbActivityRepository(connPool)
+	a.RunningActivityRepository = NewDbRunningActivityRepository(connPool)
+	a.ApiTokenRepository = NewDbApiTokenRepository(connPool)
+	a.TimesheetRepository = NewDbTimesheetRepository(connPool)
+	a.AuditLogRepository = NewDbAuditLogRepository(connPool)
+	a.WebhookRepository = NewDbWebhookRepository(connPool)
+
+	a.StartWebhookWorker(context.Background(), webhookWorkerInterval)
 
 	return http.ListenAndServe(":"+a.Config.BindPort, a.Router)
 }
@@ -166,22 +187,54 @@ func (a *app) apiRouter(tokenAuth *jwtauth.JWTAuth) http.Handler {
 	r := chi.NewRouter()
 
 	r.Post("/auth/login", a.HandleLogin(tokenAuth))
+	r.Get("/calendar/{calendar-token}.ics", a.HandleGetCalendarFeed())
 
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(tokenAuth))
-		r.Use(a.JWTPrincipalHandler())
+		r.Use(a.ApiTokenPrincipalHandler(
+			chi.Chain(jwtauth.Verifier(tokenAuth), a.JWTPrincipalHandler()).Handler,
+		))
 
 		r.Get("/projects", a.HandleGetProjects())
 		r.Post("/projects", a.HandleCreateProject())
 		r.Get("/projects/{project-id}", a.HandleGetProject())
 		r.Delete("/projects/{project-id}", a.HandleDeleteProject())
 		r.Patch("/projects/{project-id}", a.HandleUpdateProject())
+		r.Get("/projects/{project-id}/rates", a.HandleGetProjectUserRates())
+		r.Put("/projects/{project-id}/rates/{username}", a.HandleUpdateProjectUserRate())
+		r.Delete("/projects/{project-id}/rates/{username}", a.HandleDeleteProjectUserRate())
+		r.Get("/projects/{project-id}/members", a.HandleGetProjectMembers())
+		r.Put("/projects/{project-id}/members/{username}", a.HandleUpdateProjectMember())
+		r.Delete("/projects/{project-id}/members/{username}", a.HandleDeleteProjectMember())
+
+		r.Get("/clients", a.HandleGetClients())
+		r.Post("/clients", a.HandleCreateClient())
+		r.Delete("/clients/{client-id}", a.HandleDeleteClient())
 
 		r.Get("/activities", a.HandleGetActivities())
 		r.Post("/activities", a.HandleCreateActivity())
+		r.Post("/activities/import", a.HandleImportActivities())
 		r.Get("/activities/{activity-id}", a.HandleGetActivity())
 		r.Delete("/activities/{activity-id}", a.HandleDeleteActivity())
 		r.Patch("/activities/{activity-id}", a.HandleUpdateActivity())
+
+		r.Get("/reports/users", a.HandleGetUserReport())
+
+		r.Get("/timer", a.HandleGetTimer())
+		r.Patch("/timer", a.HandleUpdateTimer())
+		r.Delete("/timer", a.HandleDiscardTimer())
+		r.Post("/timer/start", a.HandleStartTimer())
+		r.Post("/timer/stop", a.HandleStopTimer())
+
+		r.Get("/invitations", a.HandleGetInvitations())
+		r.Post("/invitations", a.HandleCreateInvitation())
+		r.Delete("/invitations/{invitation-id}", a.HandleDeleteInvitation())
+
+		r.Get("/users", a.HandleGetUsers())
+		r.Get("/users/{user-id}", a.HandleGetUser())
+		r.Patch("/users/{user-id}", a.HandleUpdateUser())
+		r.Post("/users/{user-id}/password", a.HandleResetUserPassword())
+
+		r.Get("/audit", a.HandleGetAuditLog())
 	})
 
 	return r
@@ -223,7 +276,18 @@ func (a *app) webRouter(tokenAuth *jwtauth.JWTAuth) {
 		r.Get("/reports", a.HandleReportPage())
 		r.Get("/projects", a.HandleProjectsPage())
 		r.Post("/projects/new", a.HandleProjectForm())
-		r.Get("/projects/{project-id}/archive", a.HandleArchiveProject())
+		r.Get("/projects/archived", a.HandleArchivedProjectsPage())
+		r.Post("/projects/{project-id}/archive", a.HandleArchiveProject())
+		r.Post("/projects/{project-id}/unarchive", a.HandleUnarchiveProject())
+		r.Get("/projects/{project-id}/delete", a.HandleDeleteProjectPage())
+		r.Post("/projects/{project-id}/delete", a.HandleDeleteProjectForm())
+		r.Post("/projects/{project-id}/rate", a.HandleProjectRateForm())
+		r.Post("/projects/{project-id}/budget", a.HandleProjectBudgetForm())
+		r.Post("/projects/{project-id}/hierarchy", a.HandleProjectHierarchyForm())
+		r.Post("/projects/{project-id}/members", a.HandleProjectMemberForm())
+		r.Post("/projects/{project-id}/members/{username}/delete", a.HandleDeleteProjectMemberForm())
+		r.Post("/clients/new", a.HandleClientForm())
+		r.Post("/clients/{client-id}/delete", a.HandleDeleteClientForm())
 		r.Get("/activities/new", a.HandleActivityAddPage())
 		r.Post("/activities/validate-start-time", a.HandleStartTimeValidation())
 		r.Post("/activities/validate-end-time", a.HandleEndTimeValidation())
@@ -231,6 +295,35 @@ func (a *app) webRouter(tokenAuth *jwtauth.JWTAuth) {
 		r.Post("/activities/new", a.HandleActivityForm())
 		r.Post("/activities/{activity-id}", a.HandleActivityForm())
 		r.Post("/activities/track", a.HandleActivityTrackForm())
+		r.Get("/activities/import", a.HandleActivityImportPage())
+		r.Post("/activities/import", a.HandleActivityImportForm())
+		r.Get("/activities/calendar", a.HandleCalendarImportPage())
+		r.Post("/activities/calendar", a.HandleCalendarImportForm())
+		r.Post("/activities/calendar/confirm", a.HandleActivityDraftsForm())
+		r.Get("/invitations", a.HandleInvitationsPage())
+		r.Post("/invitations/new", a.HandleInvitationForm())
+		r.Get("/invitations/{invitation-id}/join", a.HandleInvitationJoinSignedInPage())
+		r.Post("/invitations/{invitation-id}/join", a.HandleInvitationJoinSignedInForm())
+		r.Get("/users", a.HandleUsersPage())
+		r.Post("/users/{user-id}", a.HandleUserAdminForm())
+		r.Get("/settings", a.HandleSettingsPage())
+		r.Post("/settings", a.HandleSettingsForm(tokenAuth))
+		r.Get("/settings/tokens", a.HandleApiTokensPage())
+		r.Post("/settings/tokens", a.HandleApiTokenForm())
+		r.Post("/settings/tokens/{api-token-id}/revoke", a.HandleRevokeApiToken())
+		r.Get("/organizations/switcher", a.HandleOrganizationSwitcher())
+		r.Post("/organizations/switch", a.HandleOrganizationSwitchForm(tokenAuth))
+		r.Get("/settings/periods", a.HandlePeriodLockPage())
+		r.Post("/settings/periods", a.HandlePeriodLockForm())
+		r.Get("/timesheets", a.HandleTimesheetPage())
+		r.Post("/timesheets/submit", a.HandleTimesheetSubmitForm())
+		r.Get("/timesheets/approvals", a.HandleTimesheetApprovalsPage())
+		r.Post("/timesheets/{timesheet-id}/review", a.HandleTimesheetReviewForm())
+		r.Get("/audit", a.HandleAuditLogPage())
+		r.Get("/webhooks", a.HandleWebhooksPage())
+		r.Post("/webhooks", a.HandleWebhookForm())
+		r.Post("/webhooks/{webhook-id}/delete", a.HandleDeleteWebhook())
+		r.Get("/webhooks/{webhook-id}/deliveries", a.HandleWebhookDeliveriesPage())
 		r.Get("/logout", a.HandleLogoutPage())
 	})
 
@@ -244,6 +337,9 @@ func (a *app) webRouter(tokenAuth *jwtauth.JWTAuth) {
 		r.Post("/signup", a.HandleSignUpForm())
 		r.Post("/signup/validate", a.HandleSignUpFormValidate())
 		r.Get("/signup/confirm/{confirmation-id}", a.HandleSignUpConfirm())
+		r.Get("/signup/invitation/{invitation-id}", a.HandleInvitationAcceptPage())
+		r.Post("/signup/invitation/{invitation-id}", a.HandleInvitationAcceptForm())
+		r.Post("/signup/invitation/{invitation-id}/join", a.HandleInvitationJoinForm())
 
 		r.Handle("/github/login", a.GithubLoginHandler())
 		r.Handle("/github/callback", a.GithubCallbackHandler(tokenAuth))
@@ -16,6 +16,7 @@ import (
 	g "github.com/maragudk/gomponents"
 	c "github.com/maragudk/gomponents/components"
 	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
 	"github.com/snabb/isoweek"
 )
 
@@ -64,18 +65,18 @@ func (a *app) HandleWebManifest() http.HandlerFunc {
 func (a *app) HandleIndexPage() http.HandlerFunc {
 	isProduction := a.isProduction()
 	return func(w http.ResponseWriter, r *http.Request) {
-		now := time.Now()
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		now := time.Now().In(principal.Location())
 		wyear, week := isoweek.FromDate(now.Year(), now.Month(), now.Day())
 		filter := &ActivityFilter{
 			Timespan: TimespanWeek,
-			start:    isoweek.StartTime(wyear, week, time.UTC),
+			start:    isoweek.StartTime(wyear, week, principal.Location()),
 		}
 		pageParams := &paged.PageParams{
 			Page: 0,
 			Size: 100,
 		}
-
-		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 		activitiesPage, projectsOfActivities, err := a.ReadActivitiesWithProjects(
 			r.Context(),
 			principal,
@@ -87,7 +88,7 @@ func (a *app) HandleIndexPage() http.HandlerFunc {
 			return
 		}
 
-		projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, err)
 			return
@@ -103,7 +104,13 @@ func (a *app) HandleIndexPage() http.HandlerFunc {
 			currentPath: r.URL.Path,
 		}
 
-		formModel := activityTrackFormModel{Action: "start"}
+		runningActivity, err := a.ReadRunningActivity(r.Context(), principal)
+		if err != nil && !errors.Is(err, ErrRunningActivityNotFound) {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		formModel := mapRunningActivityToTrackForm(runningActivity, now)
 		formModel.CSRFToken = csrf.Token(r)
 
 		util.RenderHTML(w, IndexPage(pageContext, formModel, filter, activitiesPage, projectsOfActivities, projects))
@@ -133,7 +140,7 @@ func IndexPage(pageContext *pageContext, formModel activityTrackFormModel, filte
 						ActivitiesInWeekView(filter, activitiesPage, projectsOfActivities),
 					),
 					Div(Class("col-lg-4 col-sm-12 order-1 order-lg-2 mt-lg-4 mt-2"),
-						TrackPanel(projects.Projects, formModel),
+						TrackPanel(projects.Projects, formModel, ""),
 					),
 				),
 			),
@@ -230,7 +237,7 @@ func ActivitiesInWeekView(filter *ActivityFilter, activitiesPage *ActivitiesPage
 				),
 			),
 		),
-		ActivitiesSumByDayView(activitiesPage, projects),
+		ActivitiesSumByDayView(activitiesPage, projects, filter.Start().Location()),
 		g.If(
 			len(activitiesPage.Activities) == 0,
 			Div(
@@ -252,7 +259,7 @@ func ActivitiesInWeekView(filter *ActivityFilter, activitiesPage *ActivitiesPage
 	return g.Group(nodes)
 }
 
-func ActivitiesSumByDayView(activitiesPage *ActivitiesPaged, projects []*Project) g.Node {
+func ActivitiesSumByDayView(activitiesPage *ActivitiesPaged, projects []*Project, loc *time.Location) g.Node {
 	// prepare projects
 	projectsById := make(map[uuid.UUID]*Project)
 	for _, project := range projects {
@@ -281,7 +288,7 @@ func ActivitiesSumByDayView(activitiesPage *ActivitiesPaged, projects []*Project
 
 	sort.Slice(dayNodes, func(i, j int) bool { return dayNodes[i] > dayNodes[j] })
 
-	today := time.Now().Day()
+	today := time.Now().In(loc).Day()
 
 	return g.Group(g.Map(len(activitiesByDay), func(i int) g.Node {
 		activities := activitiesByDay[dayNodes[i]]
@@ -331,7 +338,7 @@ func ActivitiesSumByDayView(activitiesPage *ActivitiesPaged, projects []*Project
 						TitleAttr(activity.Description),
 						Span(
 							Class("flex-fill"),
-							g.Text(util.FormatTime(activity.Start)+" - "+util.FormatTime(activity.End)),
+							g.Text(util.FormatTime(activity.Start)+" - "+activity.EndFormatted()),
 						),
 						Span(
 							Class("flex-fill"),
@@ -447,6 +454,11 @@ func Navbar(pageContext *pageContext) g.Node {
 			),
 			Ul(
 				Class("navbar-nav flex-row flex-wrap ms-md-auto"),
+				Li(
+					hx.Get("/organizations/switcher"),
+					hx.Trigger("load"),
+					hx.Swap("outerHTML"),
+				),
 				Li(
 					Class("nav-item dropdown col-6 col-md-auto"),
 					A(
@@ -460,6 +472,78 @@ func Navbar(pageContext *pageContext) g.Node {
 					),
 					Ul(
 						Class("dropdown-menu dropdown-menu-end"),
+						g.If(
+							pageContext.principal.HasRole("ROLE_ADMIN"),
+							Li(
+								A(
+									Href("/invitations"),
+									hx.Boost(),
+									Class("dropdown-item"),
+									I(Class("bi-people me-2")),
+									TitleAttr("Team"),
+									g.Text("Team"),
+								),
+							),
+						),
+						g.If(
+							pageContext.principal.HasRole("ROLE_ADMIN"),
+							Li(
+								A(
+									Href("/users"),
+									hx.Boost(),
+									Class("dropdown-item"),
+									I(Class("bi-person-badge me-2")),
+									TitleAttr("Users"),
+									g.Text("Users"),
+								),
+							),
+						),
+						Li(
+							A(
+								Href("/timesheets"),
+								hx.Boost(),
+								Class("dropdown-item"),
+								I(Class("bi-calendar-check me-2")),
+								TitleAttr("Timesheet"),
+								g.Text("Timesheet"),
+							),
+						),
+						g.If(
+							pageContext.principal.HasRole("ROLE_ADMIN"),
+							Li(
+								A(
+									Href("/timesheets/approvals"),
+									hx.Boost(),
+									Class("dropdown-item"),
+									I(Class("bi-check2-square me-2")),
+									TitleAttr("Approvals"),
+									g.Text("Approvals"),
+								),
+							),
+						),
+						g.If(
+							pageContext.principal.HasRole("ROLE_ADMIN"),
+							Li(
+								A(
+									Href("/audit"),
+									hx.Boost(),
+									Class("dropdown-item"),
+									I(Class("bi-journal-text me-2")),
+									TitleAttr("Audit Log"),
+									g.Text("Audit Log"),
+								),
+							),
+						),
+						Li(
+							A(
+								Href("/settings"),
+								hx.Boost(),
+								Class("dropdown-item"),
+								I(Class("bi-gear me-2")),
+								TitleAttr("Settings"),
+								g.Text("Settings"),
+							),
+						),
 						Li(
 							A(
 								Href("/logout"),
@@ -15,9 +15,10 @@ func TestHandleIndexPage(t *testing.T) {
 	httpRec := httptest.NewRecorder()
 
 	a := &app{
-		Config:             &config{},
-		ProjectRepository:  NewInMemProjectRepository(),
-		ActivityRepository: NewInMemActivityRepository(),
+		Config:                    &config{},
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        NewInMemActivityRepository(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
 	}
 
 	r, _ := http.NewRequest("GET", "/", nil)
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/paged"
+	"github.com/baralga/util"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type auditLogEntryModel struct {
+	ID         string          `json:"id"`
+	Username   string          `json:"username"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  string          `json:"createdAt"`
+}
+
+type EmbeddedAuditLogEntries struct {
+	AuditLogEntryModels []*auditLogEntryModel `json:"entries"`
+}
+
+type auditLogModel struct {
+	*EmbeddedAuditLogEntries `json:"_embedded"`
+	*paged.Page              `json:"page"`
+	Links                    *hal.Links `json:"_links"`
+}
+
+// HandleGetAuditLog reads the audit log of the organization
+func (a *app) HandleGetAuditLog() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		filter, err := auditLogFilterFromQueryParams(r.URL.Query())
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("audit log filter not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		entriesPaged, err := a.ReadAuditLog(r.Context(), principal, filter, paged.PageParamsOf(r))
+		if errors.Is(err, ErrAuditLogFilterInvalid) {
+			http.Error(w, problem.New(problem.Title("audit log filter not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		entryModels := make([]*auditLogEntryModel, len(entriesPaged.Entries))
+		for i, entry := range entriesPaged.Entries {
+			entryModels[i] = mapToAuditLogEntryModel(entry)
+		}
+
+		auditLogModel := &auditLogModel{
+			EmbeddedAuditLogEntries: &EmbeddedAuditLogEntries{
+				AuditLogEntryModels: entryModels,
+			},
+			Page: entriesPaged.Page,
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+			),
+		}
+
+		util.RenderJSON(w, auditLogModel)
+	}
+}
+
+func mapToAuditLogEntryModel(entry *AuditLogEntry) *auditLogEntryModel {
+	return &auditLogEntryModel{
+		ID:         entry.ID.String(),
+		Username:   entry.Username,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID.String(),
+		Before:     entry.Before,
+		After:      entry.After,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// auditLogFilterFromQueryParams reads the filter of the audit log from the url query params
+func auditLogFilterFromQueryParams(params url.Values) (*AuditLogFilter, error) {
+	filter := &AuditLogFilter{
+		Username:   params.Get("username"),
+		Action:     params.Get("action"),
+		EntityType: params.Get("entityType"),
+	}
+
+	if params.Get("entityId") != "" {
+		entityID, err := uuid.Parse(params.Get("entityId"))
+		if err != nil {
+			return nil, err
+		}
+		filter.EntityID = entityID
+	}
+
+	return filter, nil
+}
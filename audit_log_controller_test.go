@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleGetAuditLog(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	auditLogRepository := NewInMemAuditLogRepository()
+	auditLogRepository.entries = append(auditLogRepository.entries, &AuditLogEntry{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		Username:       "admin",
+		Action:         AuditActionUpdate,
+		EntityType:     AuditEntityActivity,
+		EntityID:       uuid.New(),
+		Before:         []byte(`{"Description":"before"}`),
+		After:          []byte(`{"Description":"after"}`),
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:             &config{},
+		AuditLogRepository: auditLogRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/api/audit?entityType=activity", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleGetAuditLog()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	body := httpRec.Body.String()
+	is.True(strings.Contains(body, `"entityType":"activity"`))
+	is.True(strings.Contains(body, `"before":{"Description":"before"}`))
+}
+
+func TestHandleGetAuditLogAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/audit", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandleGetAuditLog()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleGetAuditLogWithInvalidFilter(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/audit?entityId=not-a-uuid", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleGetAuditLog()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions of audit log entries
+const (
+	AuditActionCreate    string = "create"
+	AuditActionUpdate    string = "update"
+	AuditActionDelete    string = "delete"
+	AuditActionArchive   string = "archive"
+	AuditActionUnarchive string = "unarchive"
+)
+
+// Types of the entities changed in audit log entries
+const (
+	AuditEntityActivity string = "activity"
+	AuditEntityProject  string = "project"
+	AuditEntityUser     string = "user"
+)
+
+// AuditLogEntry records who changed an entity when, with the state
+// of the entity before and after the change as JSON
+type AuditLogEntry struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Username       string
+	Action         string
+	EntityType     string
+	EntityID       uuid.UUID
+	Before         json.RawMessage
+	After          json.RawMessage
+	CreatedAt      time.Time
+}
+
+// AuditLogFilter filters the audit log, empty values match all entries
+type AuditLogFilter struct {
+	Username   string
+	Action     string
+	EntityType string
+	EntityID   uuid.UUID
+}
+
+// IsValidAuditAction checks whether the action is known, an empty action is valid
+func IsValidAuditAction(action string) bool {
+	switch action {
+	case "", AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionArchive, AuditActionUnarchive:
+		return true
+	}
+	return false
+}
+
+// IsValidAuditEntityType checks whether the entity type is known, an empty type is valid
+func IsValidAuditEntityType(entityType string) bool {
+	switch entityType {
+	case "", AuditEntityActivity, AuditEntityProject, AuditEntityUser:
+		return true
+	}
+	return false
+}
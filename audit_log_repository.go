@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+
+	"github.com/baralga/paged"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuditLogEntriesPaged struct {
+	Entries []*AuditLogEntry
+	Page    *paged.Page
+}
+
+// AuditLogRepository stores the audit log, entries are only appended and never changed
+type AuditLogRepository interface {
+	FindAuditLogEntries(ctx context.Context, organizationID uuid.UUID, filter *AuditLogFilter, pageParams *paged.PageParams) (*AuditLogEntriesPaged, error)
+	InsertAuditLogEntry(ctx context.Context, entry *AuditLogEntry) (*AuditLogEntry, error)
+}
+
+// DbAuditLogRepository is a SQL database repository for the audit log
+type DbAuditLogRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ AuditLogRepository = (*DbAuditLogRepository)(nil)
+
+// NewDbAuditLogRepository creates a new SQL database repository for the audit log
+func NewDbAuditLogRepository(connPool *pgxpool.Pool) *DbAuditLogRepository {
+	return &DbAuditLogRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbAuditLogRepository) FindAuditLogEntries(ctx context.Context, organizationID uuid.UUID, filter *AuditLogFilter, pageParams *paged.PageParams) (*AuditLogEntriesPaged, error) {
+	entityID := ""
+	if filter.EntityID != uuid.Nil {
+		entityID = filter.EntityID.String()
+	}
+
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT audit_log_id, username, action, entity_type, entity_id, before, after, created_at, org_id
+		 FROM audit_logs
+		 WHERE org_id = $1
+		   AND ($2 = '' OR username = $2)
+		   AND ($3 = '' OR action = $3)
+		   AND ($4 = '' OR entity_type = $4)
+		   AND ($5 = '' OR entity_id::text = $5)
+		 ORDER BY created_at DESC
+		 LIMIT $6 OFFSET $7`,
+		organizationID, filter.Username, filter.Action, filter.EntityType, entityID,
+		pageParams.Size, pageParams.Offset(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		entry := &AuditLogEntry{}
+
+		var before, after []byte
+		err = rows.Scan(
+			&entry.ID,
+			&entry.Username,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+			&entry.OrganizationID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT count(*) as total
+		 FROM audit_logs
+		 WHERE org_id = $1
+		   AND ($2 = '' OR username = $2)
+		   AND ($3 = '' OR action = $3)
+		   AND ($4 = '' OR entity_type = $4)
+		   AND ($5 = '' OR entity_id::text = $5)`,
+		organizationID, filter.Username, filter.Action, filter.EntityType, entityID,
+	)
+	var total int
+	err = row.Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditLogEntriesPaged{
+		Entries: entries,
+		Page:    pageParams.PageOfTotal(total),
+	}, nil
+}
+
+func (r *DbAuditLogRepository) InsertAuditLogEntry(ctx context.Context, entry *AuditLogEntry) (*AuditLogEntry, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO audit_logs
+		   (audit_log_id, username, action, entity_type, entity_id, before, after, created_at, org_id)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		entry.ID,
+		entry.Username,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		[]byte(entry.Before),
+		[]byte(entry.After),
+		entry.CreatedAt,
+		entry.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
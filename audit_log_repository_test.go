@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/baralga/paged"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestAuditLogRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	auditLogRepository := NewDbAuditLogRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("InsertAndFindAuditLogEntries", func(t *testing.T) {
+		entry := &AuditLogEntry{
+			ID:             uuid.New(),
+			OrganizationID: organizationIDSample,
+			Username:       "admin",
+			Action:         AuditActionUpdate,
+			EntityType:     AuditEntityActivity,
+			EntityID:       uuid.New(),
+			Before:         json.RawMessage(`{"Description":"before"}`),
+			After:          json.RawMessage(`{"Description":"after"}`),
+			CreatedAt:      time.Now(),
+		}
+		entryDeleted := &AuditLogEntry{
+			ID:             uuid.New(),
+			OrganizationID: organizationIDSample,
+			Username:       "user1",
+			Action:         AuditActionDelete,
+			EntityType:     AuditEntityProject,
+			EntityID:       uuid.New(),
+			Before:         json.RawMessage(`{"Title":"My Project"}`),
+			CreatedAt:      time.Now(),
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := auditLogRepository.InsertAuditLogEntry(ctx, entry)
+				if err != nil {
+					return err
+				}
+				_, err = auditLogRepository.InsertAuditLogEntry(ctx, entryDeleted)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		pageParams := &paged.PageParams{Size: 10}
+
+		entriesPaged, err := auditLogRepository.FindAuditLogEntries(context.Background(), organizationIDSample, &AuditLogFilter{}, pageParams)
+		is.NoErr(err)
+		is.Equal(2, len(entriesPaged.Entries))
+		is.Equal(2, entriesPaged.Page.TotalElements)
+
+		entriesPaged, err = auditLogRepository.FindAuditLogEntries(context.Background(), organizationIDSample, &AuditLogFilter{EntityID: entry.EntityID}, pageParams)
+		is.NoErr(err)
+		is.Equal(1, len(entriesPaged.Entries))
+		is.Equal("admin", entriesPaged.Entries[0].Username)
+		is.True(len(entriesPaged.Entries[0].Before) > 0)
+
+		entriesPaged, err = auditLogRepository.FindAuditLogEntries(context.Background(), organizationIDSample, &AuditLogFilter{Action: AuditActionDelete, Username: "user1"}, pageParams)
+		is.NoErr(err)
+		is.Equal(1, len(entriesPaged.Entries))
+		is.Equal(entryDeleted.ID, entriesPaged.Entries[0].ID)
+		is.Equal(0, len(entriesPaged.Entries[0].After))
+	})
+}
+
+type InMemAuditLogRepository struct {
+	entries []*AuditLogEntry
+}
+
+var _ AuditLogRepository = (*InMemAuditLogRepository)(nil)
+
+func NewInMemAuditLogRepository() *InMemAuditLogRepository {
+	return &InMemAuditLogRepository{
+		entries: []*AuditLogEntry{},
+	}
+}
+
+func (r *InMemAuditLogRepository) FindAuditLogEntries(ctx context.Context, organizationID uuid.UUID, filter *AuditLogFilter, pageParams *paged.PageParams) (*AuditLogEntriesPaged, error) {
+	var entries []*AuditLogEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if e.OrganizationID != organizationID ||
+			(filter.Username != "" && e.Username != filter.Username) ||
+			(filter.Action != "" && e.Action != filter.Action) ||
+			(filter.EntityType != "" && e.EntityType != filter.EntityType) ||
+			(filter.EntityID != uuid.Nil && e.EntityID != filter.EntityID) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return &AuditLogEntriesPaged{
+		Entries: entries,
+		Page:    pageParams.PageOfTotal(len(entries)),
+	}, nil
+}
+
+func (r *InMemAuditLogRepository) InsertAuditLogEntry(ctx context.Context, entry *AuditLogEntry) (*AuditLogEntry, error) {
+	r.entries = append(r.entries, entry)
+	return entry, nil
+}
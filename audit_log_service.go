@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/baralga/paged"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrAuditLogFilterInvalid = errors.New("audit log filter invalid")
+
+// ReadAuditLog reads the entries of the principal's organization matching the filter, latest first
+func (a *app) ReadAuditLog(ctx context.Context, principal *Principal, filter *AuditLogFilter, pageParams *paged.PageParams) (*AuditLogEntriesPaged, error) {
+	if !IsValidAuditAction(filter.Action) || !IsValidAuditEntityType(filter.EntityType) {
+		return nil, ErrAuditLogFilterInvalid
+	}
+
+	return a.AuditLogRepository.FindAuditLogEntries(ctx, principal.OrganizationID, filter, pageParams)
+}
+
+// recordChange records the change of the principal in the audit log and queues the webhook event
+// of the change, it must be called within the transaction of the change
+func (a *app) recordChange(ctx context.Context, principal *Principal, action, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	err := a.writeAuditLog(ctx, principal, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	eventType, ok := WebhookEventOf(action, entityType)
+	if !ok {
+		return nil
+	}
+
+	// deleted entities are pushed with their last state
+	data := after
+	if data == nil {
+		data = before
+	}
+	return a.enqueueWebhookEvent(ctx, principal, eventType, data)
+}
+
+// writeAuditLog appends the change of the principal to the audit log, it must be called within
+// the transaction of the change so the change is only stored together with its entry
+func (a *app) writeAuditLog(ctx context.Context, principal *Principal, action, entityType string, entityID uuid.UUID, before, after interface{}) error {
+	beforeJSON, err := marshalAuditState(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAuditState(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.AuditLogRepository.InsertAuditLogEntry(ctx, &AuditLogEntry{
+		ID:             uuid.New(),
+		OrganizationID: principal.OrganizationID,
+		Username:       principal.Username,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Before:         beforeJSON,
+		After:          afterJSON,
+		CreatedAt:      time.Now(),
+	})
+	return err
+}
+
+func marshalAuditState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/baralga/paged"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestUpdateActivityOfOtherUserWritesAuditLog(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	activity := activityRepository.activities[0]
+	activity.Description = "My Description"
+
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     activityRepository,
+		ProjectRepository:      NewInMemProjectRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     auditLogRepository,
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	activityUpdate := *activity
+	activityUpdate.Description = "Changed by admin"
+	activityUpdate.End = time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+
+	// Act
+	_, err := a.UpdateActivity(context.Background(), admin, &activityUpdate)
+	is.NoErr(err)
+
+	entriesPaged, err := a.ReadAuditLog(context.Background(), admin, &AuditLogFilter{EntityType: AuditEntityActivity}, &paged.PageParams{Size: 10})
+	is.NoErr(err)
+
+	// Assert
+	is.Equal(1, len(entriesPaged.Entries))
+
+	entry := entriesPaged.Entries[0]
+	is.Equal("admin", entry.Username)
+	is.Equal(AuditActionUpdate, entry.Action)
+	is.Equal(activity.ID, entry.EntityID)
+
+	var before, after Activity
+	is.NoErr(json.Unmarshal(entry.Before, &before))
+	is.NoErr(json.Unmarshal(entry.After, &after))
+	is.Equal("My Description", before.Description)
+	is.Equal("Changed by admin", after.Description)
+}
+
+func TestDeleteProjectWritesAuditLog(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		ActivityRepository:     &InMemActivityRepository{},
+		AuditLogRepository:     auditLogRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	err := a.DeleteProjectByID(context.Background(), admin, projectIDSample, uuid.Nil)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(1, len(auditLogRepository.entries))
+	is.Equal(AuditActionDelete, auditLogRepository.entries[0].Action)
+	is.True(len(auditLogRepository.entries[0].Before) > 0)
+	is.Equal(0, len(auditLogRepository.entries[0].After))
+}
+
+func TestReadAuditLogWithInvalidFilter(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	_, err := a.ReadAuditLog(context.Background(), &Principal{}, &AuditLogFilter{Action: "explode"}, &paged.PageParams{Size: 10})
+
+	is.True(errors.Is(err, ErrAuditLogFilterInvalid))
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/paged"
+	"github.com/baralga/util"
+	"github.com/google/uuid"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+const auditLogPageSize = 50
+
+func (a *app) HandleAuditLogPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		filter, err := auditLogFilterFromQueryParams(r.URL.Query())
+		if err != nil {
+			http.Error(w, "Invalid filter.", http.StatusBadRequest)
+			return
+		}
+
+		pageParams := paged.PageParamsFromQuery(r.URL.Query(), auditLogPageSize)
+		entriesPaged, err := a.ReadAuditLog(r.Context(), principal, filter, &pageParams)
+		if errors.Is(err, ErrAuditLogFilterInvalid) {
+			http.Error(w, "Invalid filter.", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Audit Log",
+		}
+
+		util.RenderHTML(w, AuditLogPage(pageContext, filter, entriesPaged))
+	}
+}
+
+func AuditLogPage(pageContext *pageContext, filter *AuditLogFilter, entriesPaged *AuditLogEntriesPaged) g.Node {
+	loc := pageContext.principal.Location()
+
+	var emptyNote g.Node
+	if len(entriesPaged.Entries) == 0 {
+		emptyNote = P(g.Text("No changes found."))
+	}
+
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Audit Log")),
+						P(
+							Class("text-muted"),
+							g.Text("Changes to activities, projects and user roles with their state before and after the change."),
+						),
+					),
+					AuditLogFilterForm(filter),
+					emptyNote,
+					Table(
+						ID("audit-log"),
+						Class("table table-borderless table-striped"),
+						THead(
+							Tr(
+								Th(g.Text("When")),
+								Th(g.Text("User")),
+								Th(g.Text("Action")),
+								Th(g.Text("Entity")),
+								Th(g.Text("Changes")),
+							),
+						),
+						TBody(
+							g.Group(
+								g.Map(len(entriesPaged.Entries), func(i int) g.Node {
+									return AuditLogEntryRow(entriesPaged.Entries[i], loc)
+								}),
+							),
+						),
+					),
+					AuditLogPagination(filter, entriesPaged.Page),
+				),
+			),
+		},
+	)
+}
+
+func AuditLogFilterForm(filter *AuditLogFilter) g.Node {
+	entityID := ""
+	if filter.EntityID != uuid.Nil {
+		entityID = filter.EntityID.String()
+	}
+
+	return FormEl(
+		ID("audit_log_filter"),
+		Action("/audit"),
+		Method("GET"),
+		hx.Boost(),
+		Class("row g-2 mb-3"),
+		Div(
+			Class("col-md-3"),
+			Input(
+				Type("text"),
+				Name("username"),
+				Class("form-control form-control-sm"),
+				g.Attr("placeholder", "User"),
+				Value(filter.Username),
+			),
+		),
+		Div(
+			Class("col-md-2"),
+			Select(
+				Name("action"),
+				Class("form-select form-select-sm"),
+				auditLogOption("", "All Actions", filter.Action),
+				auditLogOption(AuditActionCreate, "Create", filter.Action),
+				auditLogOption(AuditActionUpdate, "Update", filter.Action),
+				auditLogOption(AuditActionDelete, "Delete", filter.Action),
+				auditLogOption(AuditActionArchive, "Archive", filter.Action),
+				auditLogOption(AuditActionUnarchive, "Unarchive", filter.Action),
+			),
+		),
+		Div(
+			Class("col-md-2"),
+			Select(
+				Name("entityType"),
+				Class("form-select form-select-sm"),
+				auditLogOption("", "All Entities", filter.EntityType),
+				auditLogOption(AuditEntityActivity, "Activity", filter.EntityType),
+				auditLogOption(AuditEntityProject, "Project", filter.EntityType),
+				auditLogOption(AuditEntityUser, "User", filter.EntityType),
+			),
+		),
+		Div(
+			Class("col-md-4"),
+			Input(
+				Type("text"),
+				Name("entityId"),
+				Class("form-control form-control-sm"),
+				g.Attr("placeholder", "Entity ID"),
+				Value(entityID),
+			),
+		),
+		Div(
+			Class("col-md-1"),
+			Button(
+				Type("submit"),
+				Class("btn btn-sm btn-primary w-100"),
+				TitleAttr("Filter"),
+				I(Class("bi-funnel")),
+			),
+		),
+	)
+}
+
+func auditLogOption(value, title, selected string) g.Node {
+	return Option(
+		Value(value),
+		g.Text(title),
+		g.If(value == selected, Selected()),
+	)
+}
+
+func AuditLogEntryRow(entry *AuditLogEntry, loc *time.Location) g.Node {
+	return Tr(
+		Td(g.Text(fmt.Sprintf("%v %v", util.FormatDateDE(entry.CreatedAt.In(loc)), util.FormatTime(entry.CreatedAt.In(loc))))),
+		Td(g.Text(entry.Username)),
+		Td(g.Text(entry.Action)),
+		Td(
+			A(
+				Href(fmt.Sprintf("/audit?entityId=%v", entry.EntityID)),
+				hx.Boost(),
+				TitleAttr("Show all changes of the entity"),
+				g.Text(entry.EntityType),
+			),
+		),
+		Td(
+			Details(
+				Summary(g.Text("Show")),
+				auditLogState("Before", entry.Before),
+				auditLogState("After", entry.After),
+			),
+		),
+	)
+}
+
+func auditLogState(title string, state []byte) g.Node {
+	if len(state) == 0 {
+		return nil
+	}
+
+	return Div(
+		Small(Class("text-muted"), g.Text(title)),
+		Pre(Class("small mb-1"), g.Text(string(state))),
+	)
+}
+
+func AuditLogPagination(filter *AuditLogFilter, page *paged.Page) g.Node {
+	if page.TotalPages <= 1 {
+		return nil
+	}
+
+	var previousLink, nextLink g.Node
+	if page.Number > 0 {
+		previousLink = A(
+			Href(auditLogPageHref(filter, page.Number-1)),
+			hx.Boost(),
+			Class("btn btn-outline-secondary btn-sm me-2"),
+			I(Class("bi-chevron-left")),
+		)
+	}
+	if page.Number+1 < page.TotalPages {
+		nextLink = A(
+			Href(auditLogPageHref(filter, page.Number+1)),
+			hx.Boost(),
+			Class("btn btn-outline-secondary btn-sm"),
+			I(Class("bi-chevron-right")),
+		)
+	}
+
+	return Div(
+		Class("text-center mb-4"),
+		previousLink,
+		nextLink,
+	)
+}
+
+func auditLogPageHref(filter *AuditLogFilter, page int) string {
+	params := url.Values{}
+	if filter.Username != "" {
+		params.Set("username", filter.Username)
+	}
+	if filter.Action != "" {
+		params.Set("action", filter.Action)
+	}
+	if filter.EntityType != "" {
+		params.Set("entityType", filter.EntityType)
+	}
+	if filter.EntityID != uuid.Nil {
+		params.Set("entityId", filter.EntityID.String())
+	}
+	params.Set("p", fmt.Sprint(page))
+	return fmt.Sprintf("/audit?%v", params.Encode())
+}
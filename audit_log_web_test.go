@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleAuditLogPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	auditLogRepository := NewInMemAuditLogRepository()
+	auditLogRepository.entries = append(auditLogRepository.entries, &AuditLogEntry{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		Username:       "admin",
+		Action:         AuditActionArchive,
+		EntityType:     AuditEntityProject,
+		EntityID:       projectIDSample,
+		Before:         []byte(`{"Title":"My Project"}`),
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:             &config{},
+		AuditLogRepository: auditLogRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/audit?action=archive", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleAuditLogPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Audit Log # Baralga"))
+	is.True(strings.Contains(htmlBody, "My Project"))
+}
+
+func TestHandleAuditLogPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/audit", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandleAuditLogPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
@@ -46,6 +46,36 @@ func (a *app) HandleLogin(tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
 	}
 }
 
+// ApiTokenPrincipalHandler sets up the user principal from a personal api token,
+// requests without api token are passed on to the fallback (e.g. the JWT handlers)
+func (a *app) ApiTokenPrincipalHandler(fallback func(next http.Handler) http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fallbackHandler := fallback(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString := jwtauth.TokenFromHeader(r)
+			if !IsApiToken(tokenString) {
+				fallbackHandler.ServeHTTP(w, r)
+				return
+			}
+
+			principal, apiToken, err := a.AuthenticateApiToken(r.Context(), tokenString)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if !apiToken.Allows(r.Method) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), contextKeyPrincipal, principal)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // JWTPrincipalHandler sets up the user principal from the JWT
 func (a *app) JWTPrincipalHandler() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -70,14 +100,19 @@ func mapPrincipalToClaims(principal *Principal) map[string]interface{} {
 		"username":       principal.Username,
 		"organizationId": principal.OrganizationID.String(),
 		"roles":          strings.Join(principal.Roles, ","),
+		"timezone":       principal.Timezone,
 	}
 }
 
 func mapPrincipalFromClaims(claims map[string]interface{}) *Principal {
-	return &Principal{
+	principal := &Principal{
 		Name:           claims["name"].(string),
 		Username:       claims["username"].(string),
 		OrganizationID: uuid.MustParse(claims["organizationId"].(string)),
 		Roles:          strings.Split(claims["roles"].(string), ","),
 	}
+	if timezone, ok := claims["timezone"].(string); ok {
+		principal.Timezone = timezone
+	}
+	return principal
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/matryer/is"
 )
@@ -96,6 +97,23 @@ func TestMapPrincipalFromClaims(t *testing.T) {
 	is.Equal(organizationIDSample, p.OrganizationID)
 	is.Equal(1, len(p.Roles))
 	is.Equal("ROLE_ADMIN", p.Roles[0])
+	is.Equal("", p.Timezone)
+}
+
+func TestMapPrincipalClaimsWithTimezone(t *testing.T) {
+	is := is.New(t)
+
+	principal := &Principal{
+		Name:           "Ado Admin",
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+		Timezone:       "Europe/Berlin",
+	}
+
+	p := mapPrincipalFromClaims(mapPrincipalToClaims(principal))
+
+	is.Equal("Europe/Berlin", p.Timezone)
 }
 
 func TestJWTPrincipalHandlerWithoutJWT(t *testing.T) {
@@ -115,3 +133,68 @@ func TestJWTPrincipalHandlerWithoutJWT(t *testing.T) {
 
 	is.Equal(httpRec.Result().StatusCode, http.StatusUnauthorized)
 }
+
+func TestApiTokenPrincipalHandler(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+		ApiTokenRepository: NewInMemApiTokenRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+	_, readSecret, err := a.CreateApiToken(context.Background(), principal, "Read Token", ApiTokenScopeRead, nil)
+	is.NoErr(err)
+
+	var principalFound *Principal
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	fallback := chi.Chain(jwtauth.Verifier(tokenAuth), a.JWTPrincipalHandler()).Handler
+	handler := a.ApiTokenPrincipalHandler(fallback)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principalFound = r.Context().Value(contextKeyPrincipal).(*Principal)
+	}))
+
+	t.Run("read with read token", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/projects", nil)
+		r.Header.Set("Authorization", "Bearer "+readSecret)
+
+		handler.ServeHTTP(httpRec, r)
+
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+		is.Equal("admin@baralga.com", principalFound.Username)
+	})
+
+	t.Run("write with read token", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/api/projects", nil)
+		r.Header.Set("Authorization", "Bearer "+readSecret)
+
+		handler.ServeHTTP(httpRec, r)
+
+		is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/projects", nil)
+		r.Header.Set("Authorization", "Bearer bat_unknown")
+
+		handler.ServeHTTP(httpRec, r)
+
+		is.Equal(httpRec.Result().StatusCode, http.StatusUnauthorized)
+	})
+
+	t.Run("without token", func(t *testing.T) {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/api/projects", nil)
+
+		handler.ServeHTTP(httpRec, r)
+
+		is.Equal(httpRec.Result().StatusCode, http.StatusUnauthorized)
+	})
+}
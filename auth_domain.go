@@ -1,6 +1,15 @@
 package main
 
-import "github.com/google/uuid"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type contextKey int
 
@@ -11,6 +20,7 @@ type Principal struct {
 	Username       string
 	OrganizationID uuid.UUID
 	Roles          []string
+	Timezone       string
 }
 
 func (p *Principal) HasRole(role string) bool {
@@ -21,3 +31,142 @@ func (p *Principal) HasRole(role string) bool {
 	}
 	return false
 }
+
+// Location returns the time zone location of the principal, defaulting to UTC
+func (p *Principal) Location() *time.Location {
+	if !IsValidTimezone(p.Timezone) {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// IsValidTimezone checks whether the time zone is the name of an IANA time zone,
+// which excludes the names "" and "Local" Go accepts for UTC and the server's time zone
+func IsValidTimezone(timezone string) bool {
+	if timezone == "" || timezone == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
+
+// Permissions are the rights of a principal, admins have all rights while project
+// managers have admin rights only for the activities of the projects they manage
+type Permissions struct {
+	principal         *Principal
+	managedProjectIDs []uuid.UUID
+}
+
+// NewPermissions creates the permissions of the principal managing the given projects
+func NewPermissions(principal *Principal, managedProjectIDs []uuid.UUID) *Permissions {
+	return &Permissions{
+		principal:         principal,
+		managedProjectIDs: managedProjectIDs,
+	}
+}
+
+// IsAdmin checks whether the principal has admin rights for the whole organization
+func (p *Permissions) IsAdmin() bool {
+	return p.principal.HasRole("ROLE_ADMIN")
+}
+
+// IsProjectManager checks whether the principal manages at least one project
+func (p *Permissions) IsProjectManager() bool {
+	return len(p.managedProjectIDs) > 0
+}
+
+// ManagesProject checks whether the principal has admin rights for the project
+func (p *Permissions) ManagesProject(projectID uuid.UUID) bool {
+	if p.IsAdmin() {
+		return true
+	}
+	for _, managedProjectID := range p.managedProjectIDs {
+		if managedProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanChangeActivity checks whether the principal may change the activity,
+// which are the own activities and those of the managed projects
+func (p *Permissions) CanChangeActivity(activity *Activity) bool {
+	return activity.Username == p.principal.Username || p.ManagesProject(activity.ProjectID)
+}
+
+// CanReadReportsOfOthers checks whether the principal may read the reports of other users
+func (p *Permissions) CanReadReportsOfOthers() bool {
+	return p.IsAdmin() || p.IsProjectManager()
+}
+
+// Scopes of api tokens
+const (
+	ApiTokenScopeRead  string = "read"
+	ApiTokenScopeWrite string = "write"
+
+	// ApiTokenScopeCalendar only allows to read the calendar feed, but not the api
+	ApiTokenScopeCalendar string = "calendar"
+)
+
+// apiTokenPrefix marks personal api tokens to tell them apart from JWTs
+const apiTokenPrefix = "bat_"
+
+// ApiToken is a personal access token of a user for the api
+type ApiToken struct {
+	ID             uuid.UUID
+	Name           string
+	Hash           string
+	Prefix         string
+	Scope          string
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	CreatedAt      time.Time
+	LastUsedAt     *time.Time
+	ExpiresAt      *time.Time
+}
+
+// IsExpiredAt checks whether the api token is expired at the given time
+func (t *ApiToken) IsExpiredAt(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Allows checks whether the scope of the api token allows requests with the given http method
+func (t *ApiToken) Allows(method string) bool {
+	switch t.Scope {
+	case ApiTokenScopeWrite:
+		return true
+	case ApiTokenScopeRead:
+		return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	default:
+		return false
+	}
+}
+
+func IsValidApiTokenScope(scope string) bool {
+	return scope == ApiTokenScopeRead || scope == ApiTokenScopeWrite || scope == ApiTokenScopeCalendar
+}
+
+// IsApiToken checks whether the bearer token is a personal api token
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// NewApiTokenSecret generates the secret of a new api token
+func NewApiTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// HashApiToken hashes the secret of an api token, so only the hash needs to be stored
+func HashApiToken(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
 
@@ -23,3 +25,121 @@ func TestHasRole(t *testing.T) {
 		is.True(!hasClaim)
 	})
 }
+
+func TestLocation(t *testing.T) {
+	is := is.New(t)
+
+	t.Run("with timezone", func(t *testing.T) {
+		p := &Principal{Timezone: "America/New_York"}
+		is.Equal("America/New_York", p.Location().String())
+	})
+
+	t.Run("without timezone", func(t *testing.T) {
+		p := &Principal{}
+		is.Equal(time.UTC, p.Location())
+	})
+
+	t.Run("with invalid timezone", func(t *testing.T) {
+		p := &Principal{Timezone: "Mars/Olympus_Mons"}
+		is.Equal(time.UTC, p.Location())
+	})
+
+	t.Run("with local timezone", func(t *testing.T) {
+		p := &Principal{Timezone: "Local"}
+		is.Equal(time.UTC, p.Location())
+	})
+}
+
+func TestIsValidTimezone(t *testing.T) {
+	is := is.New(t)
+
+	is.True(IsValidTimezone("Europe/Berlin"))
+	is.True(IsValidTimezone("UTC"))
+	is.True(!IsValidTimezone(""))
+	is.True(!IsValidTimezone("Local"))
+	is.True(!IsValidTimezone("Mars/Olympus_Mons"))
+}
+
+func TestApiTokenAllows(t *testing.T) {
+	is := is.New(t)
+
+	readToken := &ApiToken{Scope: ApiTokenScopeRead}
+	writeToken := &ApiToken{Scope: ApiTokenScopeWrite}
+	calendarToken := &ApiToken{Scope: ApiTokenScopeCalendar}
+
+	is.True(readToken.Allows("GET"))
+	is.True(!readToken.Allows("POST"))
+	is.True(!readToken.Allows("DELETE"))
+	is.True(writeToken.Allows("GET"))
+	is.True(writeToken.Allows("PATCH"))
+	is.True(!calendarToken.Allows("GET"))
+}
+
+func TestApiTokenIsExpiredAt(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	apiToken := &ApiToken{}
+
+	is.True(!apiToken.IsExpiredAt(now))
+
+	apiToken.ExpiresAt = &expiresAt
+	is.True(!apiToken.IsExpiredAt(now))
+	is.True(apiToken.IsExpiredAt(now.Add(2 * time.Hour)))
+}
+
+func TestNewApiTokenSecret(t *testing.T) {
+	is := is.New(t)
+
+	secret, err := NewApiTokenSecret()
+	is.NoErr(err)
+
+	otherSecret, err := NewApiTokenSecret()
+	is.NoErr(err)
+
+	is.True(IsApiToken(secret))
+	is.True(secret != otherSecret)
+	is.Equal(64, len(HashApiToken(secret)))
+	is.True(!IsApiToken("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"))
+}
+
+func TestPermissions(t *testing.T) {
+	is := is.New(t)
+
+	otherProjectID := uuid.New()
+	ownActivity := &Activity{ProjectID: otherProjectID, Username: "user1"}
+	managedActivity := &Activity{ProjectID: projectIDSample, Username: "user2"}
+	otherActivity := &Activity{ProjectID: otherProjectID, Username: "user2"}
+
+	t.Run("admin", func(t *testing.T) {
+		permissions := NewPermissions(&Principal{Username: "admin", Roles: []string{"ROLE_ADMIN"}}, nil)
+
+		is.True(permissions.IsAdmin())
+		is.True(permissions.ManagesProject(otherProjectID))
+		is.True(permissions.CanChangeActivity(otherActivity))
+		is.True(permissions.CanReadReportsOfOthers())
+	})
+
+	t.Run("project manager", func(t *testing.T) {
+		permissions := NewPermissions(&Principal{Username: "user1", Roles: []string{"ROLE_USER"}}, []uuid.UUID{projectIDSample})
+
+		is.True(!permissions.IsAdmin())
+		is.True(permissions.IsProjectManager())
+		is.True(permissions.ManagesProject(projectIDSample))
+		is.True(!permissions.ManagesProject(otherProjectID))
+		is.True(permissions.CanChangeActivity(ownActivity))
+		is.True(permissions.CanChangeActivity(managedActivity))
+		is.True(!permissions.CanChangeActivity(otherActivity))
+		is.True(permissions.CanReadReportsOfOthers())
+	})
+
+	t.Run("user", func(t *testing.T) {
+		permissions := NewPermissions(&Principal{Username: "user1", Roles: []string{"ROLE_USER"}}, nil)
+
+		is.True(!permissions.IsProjectManager())
+		is.True(permissions.CanChangeActivity(ownActivity))
+		is.True(!permissions.CanChangeActivity(managedActivity))
+		is.True(!permissions.CanReadReportsOfOthers())
+	})
+}
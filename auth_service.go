@@ -11,6 +11,11 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrNoPermission    = errors.New("no permission")
+	ErrPasswordInvalid = errors.New("password invalid")
+)
+
 func (a *app) EncryptPassword(password string) string {
 	encryptedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), 10)
 	return string(encryptedPassword)
@@ -27,7 +32,7 @@ func (a *app) Authenticate(ctx context.Context, username, password string) (*Pri
 
 	passwdErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if passwdErr != nil {
-		return nil, errors.New("password invalid")
+		return nil, ErrPasswordInvalid
 	}
 
 	roles, err := a.UserRepository.FindRolesByUserID(ctx, user.OrganizationID, user.ID)
@@ -39,12 +44,28 @@ func (a *app) Authenticate(ctx context.Context, username, password string) (*Pri
 	return principal, nil
 }
 
+// ReadPermissions reads the permissions of the principal, which for users
+// are scoped by the projects they manage
+func (a *app) ReadPermissions(ctx context.Context, principal *Principal) (*Permissions, error) {
+	if principal.HasRole("ROLE_ADMIN") {
+		return NewPermissions(principal, nil), nil
+	}
+
+	managedProjectIDs, err := a.ProjectRepository.FindManagedProjectIDs(ctx, principal.OrganizationID, principal.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPermissions(principal, managedProjectIDs), nil
+}
+
 func mapUserToPrincipal(user *User, roles []string) *Principal {
 	principal := &Principal{
 		Name:           user.Name,
 		Username:       user.Username,
 		OrganizationID: user.OrganizationID,
 		Roles:          roles,
+		Timezone:       user.Timezone,
 	}
 	if principal.Name == "" {
 		principal.Name = user.Username
@@ -96,6 +96,9 @@ func loginParamsFromQueryParams(params url.Values) *loginParams {
 	if len(params["info"]) == 1 && params["info"][0] == "confirm_successfull" {
 		loginParams.infoMessage = "You've been confirmed, so happy time tracking!"
 	}
+	if len(params["info"]) == 1 && params["info"][0] == "invitation_accepted" {
+		loginParams.infoMessage = "Welcome to your team, so happy time tracking!"
+	}
 	if len(params["redirect"]) == 1 && strings.HasPrefix(params["redirect"][0], "/") {
 		loginParams.redirect = params["redirect"][0]
 	}
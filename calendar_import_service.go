@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCalendarInvalid     = errors.New("calendar invalid")
+	ErrCalendarURLInvalid  = errors.New("calendar url invalid")
+	ErrCalendarFetchFailed = errors.New("calendar fetch failed")
+)
+
+const (
+	maxCalendarSize   = 5 << 20
+	maxActivityDrafts = 200
+)
+
+// calendarClient only connects to public addresses, since users enter the calendar urls
+var calendarClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: controlWebhookDial,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+var insecureCalendarClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+var icsDurationPattern = regexp.MustCompile(`^([+-]?)P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ReadCalendarEvents reads the events with start and end time of an iCalendar, all-day
+// and cancelled events are skipped and recurring events are read without their recurrences
+func ReadCalendarEvents(r io.Reader, loc *time.Location) ([]*CalendarEvent, error) {
+	scanner := bufio.NewScanner(io.LimitReader(r, maxCalendarSize))
+	scanner.Buffer(make([]byte, 64*1024), maxCalendarSize)
+
+	// unfold lines which are continued on the next line
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(ErrCalendarInvalid, err.Error())
+	}
+
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimPrefix(lines[0], "\ufeff"), "BEGIN:VCALENDAR") {
+		return nil, ErrCalendarInvalid
+	}
+
+	var (
+		events    []*CalendarEvent
+		event     *CalendarEvent
+		depth     int
+		allDay    bool
+		cancelled bool
+		duration  time.Duration
+	)
+	for _, line := range lines {
+		name, params, value := parseICSLine(line)
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && event == nil:
+			event = &CalendarEvent{}
+			depth, allDay, cancelled, duration = 0, false, false, 0
+		case event == nil:
+			continue
+		case name == "BEGIN":
+			// nested components like alarms
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event.End.IsZero() && duration > 0 {
+				event.End = event.Start.Add(duration)
+			}
+			if !allDay && !cancelled && !event.Start.IsZero() && event.End.After(event.Start) {
+				events = append(events, event)
+			}
+			event = nil
+		case depth > 0:
+			continue
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescapeICSText(value)
+		case name == "DESCRIPTION":
+			event.Description = unescapeICSText(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART":
+			start, isDate, err := parseICSDateTime(value, params, loc)
+			if err != nil {
+				return nil, errors.Wrap(ErrCalendarInvalid, err.Error())
+			}
+			event.Start = start
+			allDay = allDay || isDate
+		case name == "DTEND":
+			end, isDate, err := parseICSDateTime(value, params, loc)
+			if err != nil {
+				return nil, errors.Wrap(ErrCalendarInvalid, err.Error())
+			}
+			event.End = end
+			allDay = allDay || isDate
+		case name == "DURATION":
+			d, err := parseICSDuration(value)
+			if err != nil {
+				return nil, errors.Wrap(ErrCalendarInvalid, err.Error())
+			}
+			duration = d
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+
+	return events, nil
+}
+
+// FetchCalendarEvents fetches the iCalendar of the url and reads its events,
+// a CalDAV calendar needs to provide its events as iCalendar on a GET request.
+// The reason of a failure is only logged, so the error doesn't tell which hosts are reachable.
+func (a *app) FetchCalendarEvents(ctx context.Context, calendarURL string, loc *time.Location) ([]*CalendarEvent, error) {
+	u, err := parseCalendarURL(calendarURL, a.Config.CalendarAllowInsecure)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(ErrCalendarURLInvalid, err.Error())
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	res, err := a.calendarHTTPClient().Do(req)
+	if err != nil {
+		log.Printf("could not fetch calendar %v: %v", u.Host, err)
+		return nil, ErrCalendarFetchFailed
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		log.Printf("could not fetch calendar %v: status %v", u.Host, res.StatusCode)
+		return nil, ErrCalendarFetchFailed
+	}
+
+	events, err := ReadCalendarEvents(res.Body, loc)
+	if err != nil {
+		log.Printf("could not read calendar %v: %v", u.Host, err)
+		return nil, ErrCalendarFetchFailed
+	}
+
+	return events, nil
+}
+
+func (a *app) calendarHTTPClient() *http.Client {
+	if a.Config.CalendarAllowInsecure {
+		return insecureCalendarClient
+	}
+	return calendarClient
+}
+
+// ReadCalendarURL reads the calendar url of the principal
+func (a *app) ReadCalendarURL(ctx context.Context, principal *Principal) (string, error) {
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return "", err
+	}
+
+	return user.CalendarURL, nil
+}
+
+// UpdateCalendarURL updates the calendar url of the principal, an empty url removes the calendar
+func (a *app) UpdateCalendarURL(ctx context.Context, principal *Principal, calendarURL string) error {
+	if calendarURL != "" {
+		_, err := parseCalendarURL(calendarURL, a.Config.CalendarAllowInsecure)
+		if err != nil {
+			return err
+		}
+	}
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.UserRepository.UpdateUserCalendarURL(ctx, principal.OrganizationID, principal.Username, calendarURL)
+		},
+	)
+}
+
+// FilterCalendarEvents filters the events which start within the given timespan
+func FilterCalendarEvents(events []*CalendarEvent, start, end time.Time) []*CalendarEvent {
+	var filtered []*CalendarEvent
+	for _, event := range events {
+		if event.Start.Before(start) || !event.Start.Before(end) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered
+}
+
+// parseCalendarURL parses the url of a calendar, webcal urls are fetched via https.
+// Unless insecure, urls of localhost and of ips which are not public are invalid.
+func parseCalendarURL(calendarURL string, insecure bool) (*url.URL, error) {
+	u, err := url.Parse(calendarURL)
+	if err != nil {
+		return nil, errors.Wrap(ErrCalendarURLInvalid, err.Error())
+	}
+
+	if u.Scheme == "webcal" {
+		u.Scheme = "https"
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrCalendarURLInvalid
+	}
+
+	if insecure {
+		return u, nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, ErrCalendarURLInvalid
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && !IsPublicWebhookIP(ip) {
+		return nil, ErrCalendarURLInvalid
+	}
+
+	return u, nil
+}
+
+// parseICSLine parses a content line like DTSTART;TZID=Europe/Berlin:20211112T100000
+// into its upper case name, its parameters and its value
+func parseICSLine(line string) (string, map[string]string, string) {
+	inQuotes := false
+	separator := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			separator = i
+			break
+		}
+	}
+	if separator < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:separator], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		keyValue := strings.SplitN(param, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		params[strings.ToUpper(keyValue[0])] = strings.Trim(keyValue[1], "\"")
+	}
+
+	return strings.ToUpper(parts[0]), params, line[separator+1:]
+}
+
+// parseICSDateTime parses a date time value, which is either in UTC, in the time zone of
+// the TZID parameter or a floating time in the given location, dates are all-day values
+func parseICSDateTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsDateTimeFormat, value)
+		return t, false, err
+	}
+
+	if tzid, ok := params["TZID"]; ok {
+		tzLoc, err := time.LoadLocation(tzid)
+		if err == nil {
+			loc = tzLoc
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseICSDuration parses a duration like PT1H30M
+func parseICSDuration(value string) (time.Duration, error) {
+	matches := icsDurationPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("could not parse duration from '%s'", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+	for i, unit := range units {
+		if matches[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(matches[i+2])
+		if err != nil {
+			return 0, err
+		}
+		duration += time.Duration(n) * unit
+	}
+
+	if matches[1] == "-" {
+		return -duration, nil
+	}
+	return duration, nil
+}
+
+func unescapeICSText(text string) string {
+	return strings.NewReplacer(
+		"\\\\", "\\",
+		"\\;", ";",
+		"\\,", ",",
+		"\\n", "\n",
+		"\\N", "\n",
+	).Replace(text)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+const calendarSample = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Berlin\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19701025T030000\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20211112T093000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20211112T094500\r\n" +
+	"SUMMARY:Daily Standup\\, Team A\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review\r\n" +
+	"DTSTART:20211112T130000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Sprint Review with a very long title that is folded over more than\r\n" +
+	"  one line\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:planning\r\n" +
+	"DTSTART:20211111T080000\r\n" +
+	"DTEND:20211111T090000\r\n" +
+	"SUMMARY:Planning\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday\r\n" +
+	"DTSTART;VALUE=DATE:20211115\r\n" +
+	"DTEND;VALUE=DATE:20211116\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"DTSTART:20211112T150000Z\r\n" +
+	"DTEND:20211112T160000Z\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestReadCalendarEvents(t *testing.T) {
+	is := is.New(t)
+
+	loc, _ := time.LoadLocation("Europe/Berlin")
+
+	events, err := ReadCalendarEvents(strings.NewReader(calendarSample), loc)
+
+	is.NoErr(err)
+	is.Equal(len(events), 3)
+
+	is.Equal(events[0].UID, "planning")
+	is.Equal(events[0].Start, time.Date(2021, 11, 11, 8, 0, 0, 0, loc))
+
+	is.Equal(events[1].UID, "standup")
+	is.Equal(events[1].Summary, "Daily Standup, Team A")
+	is.Equal(events[1].Start, time.Date(2021, 11, 12, 9, 30, 0, 0, loc))
+	is.Equal(events[1].End, time.Date(2021, 11, 12, 9, 45, 0, 0, loc))
+
+	is.Equal(events[2].UID, "review")
+	is.Equal(events[2].Summary, "Sprint Review with a very long title that is folded over more than one line")
+	is.True(events[2].Start.Equal(time.Date(2021, 11, 12, 13, 0, 0, 0, time.UTC)))
+	is.Equal(events[2].End.Sub(events[2].Start), 90*time.Minute)
+}
+
+func TestReadCalendarEventsInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := ReadCalendarEvents(strings.NewReader("no calendar"), time.UTC)
+
+	is.True(errors.Is(err, ErrCalendarInvalid))
+}
+
+func TestFilterCalendarEvents(t *testing.T) {
+	is := is.New(t)
+
+	events, err := ReadCalendarEvents(strings.NewReader(calendarSample), time.UTC)
+	is.NoErr(err)
+
+	filtered := FilterCalendarEvents(
+		events,
+		time.Date(2021, 11, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 11, 13, 0, 0, 0, 0, time.UTC),
+	)
+
+	is.Equal(len(filtered), 2)
+	is.Equal(filtered[0].UID, "standup")
+}
+
+func TestFetchCalendarEvents(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "calendar.ics"), []byte(calendarSample), 0600)
+	is.NoErr(err)
+	err = os.WriteFile(filepath.Join(dir, "invalid.ics"), []byte("no calendar"), 0600)
+	is.NoErr(err)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	a := &app{
+		Config: &config{CalendarAllowInsecure: true},
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		events, err := a.FetchCalendarEvents(context.Background(), server.URL+"/calendar.ics", time.UTC)
+
+		is.NoErr(err)
+		is.Equal(len(events), 3)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := a.FetchCalendarEvents(context.Background(), server.URL+"/unknown.ics", time.UTC)
+
+		is.Equal(err, ErrCalendarFetchFailed)
+	})
+
+	t.Run("NoCalendar", func(t *testing.T) {
+		_, err := a.FetchCalendarEvents(context.Background(), server.URL+"/invalid.ics", time.UTC)
+
+		is.Equal(err, ErrCalendarFetchFailed)
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		_, err := a.FetchCalendarEvents(context.Background(), "ftp://calendar.example.com/calendar.ics", time.UTC)
+
+		is.True(errors.Is(err, ErrCalendarURLInvalid))
+	})
+
+	t.Run("InternalAddress", func(t *testing.T) {
+		secureApp := &app{
+			Config: &config{},
+		}
+
+		_, err := secureApp.FetchCalendarEvents(context.Background(), server.URL+"/calendar.ics", time.UTC)
+
+		is.True(errors.Is(err, ErrCalendarURLInvalid))
+	})
+}
+
+func TestParseCalendarURL(t *testing.T) {
+	is := is.New(t)
+
+	testCases := []struct {
+		calendarURL string
+		insecure    bool
+		valid       bool
+	}{
+		{"https://calendar.example.com/my.ics", false, true},
+		{"webcal://calendar.example.com/my.ics", false, true},
+		{"http://calendar.example.com/my.ics", false, true},
+		{"ftp://calendar.example.com/my.ics", false, false},
+		{"https://localhost/my.ics", false, false},
+		{"https://calendar.localhost/my.ics", false, false},
+		{"http://127.0.0.1:8080/my.ics", false, false},
+		{"http://10.0.0.1/my.ics", false, false},
+		{"http://169.254.169.254/latest/meta-data", false, false},
+		{"http://[::1]/my.ics", false, false},
+		{"http://127.0.0.1:8080/my.ics", true, true},
+	}
+
+	for _, testCase := range testCases {
+		_, err := parseCalendarURL(testCase.calendarURL, testCase.insecure)
+		is.Equal(err == nil, testCase.valid)
+	}
+}
+
+func TestUpdateCalendarURL(t *testing.T) {
+	is := is.New(t)
+
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	err := a.UpdateCalendarURL(context.Background(), principal, "webcal://calendar.example.com/my.ics")
+	is.NoErr(err)
+
+	calendarURL, err := a.ReadCalendarURL(context.Background(), principal)
+	is.NoErr(err)
+	is.Equal(calendarURL, "webcal://calendar.example.com/my.ics")
+
+	err = a.UpdateCalendarURL(context.Background(), principal, "file:///etc/passwd")
+	is.True(errors.Is(err, ErrCalendarURLInvalid))
+
+	err = a.UpdateCalendarURL(context.Background(), principal, "http://169.254.169.254/latest/meta-data")
+	is.True(errors.Is(err, ErrCalendarURLInvalid))
+}
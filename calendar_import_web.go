@@ -0,0 +1,602 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/paged"
+	"github.com/baralga/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type calendarImportFormModel struct {
+	CSRFToken   string
+	From        string `validate:"required"`
+	To          string `validate:"required"`
+	CalendarURL string `validate:"max=500"`
+}
+
+type activityDraftsFormModel struct {
+	CSRFToken string
+	ProjectID string
+	Drafts    []activityDraftFormModel
+}
+
+type activityDraftFormModel struct {
+	Selected    bool
+	ProjectID   string
+	Date        string
+	EndDate     string
+	StartTime   string
+	EndTime     string
+	Description string
+}
+
+func (a *app) HandleCalendarImportPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		calendarURL, err := a.ReadCalendarURL(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		// the current week from monday to sunday
+		now := time.Now().In(principal.Location())
+		monday := now.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+
+		formModel := calendarImportFormModel{
+			From:        util.FormatDate(monday),
+			To:          util.FormatDate(monday.AddDate(0, 0, 6)),
+			CalendarURL: calendarURL,
+		}
+		formModel.CSRFToken = csrf.Token(r)
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Import Calendar Events",
+		}
+
+		util.RenderHTML(w, CalendarImportPage(pageContext, formModel))
+	}
+}
+
+// HandleCalendarImportForm reads the events of an uploaded iCalendar file or of the calendar url
+// and renders them as drafts for activities
+func (a *app) HandleCalendarImportForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+		loc := principal.Location()
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxCalendarSize)
+		err := r.ParseMultipartForm(maxCalendarSize)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			util.RenderHTML(w, calendarImportMessage("Please select an ICS file of at most 5 MB."))
+			return
+		}
+
+		var formModel calendarImportFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			util.RenderHTML(w, calendarImportMessage("Please check the timespan and the calendar URL."))
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			util.RenderHTML(w, calendarImportMessage("Please check the timespan and the calendar URL."))
+			return
+		}
+
+		from, errFrom := util.ParseDate(formModel.From)
+		to, errTo := util.ParseDate(formModel.To)
+		if errFrom != nil || errTo != nil || to.Before(*from) {
+			util.RenderHTML(w, calendarImportMessage("Please check the timespan and the calendar URL."))
+			return
+		}
+
+		var events []*CalendarEvent
+		file, _, errFile := r.FormFile("file")
+		switch {
+		case errFile == nil:
+			defer file.Close()
+			events, err = ReadCalendarEvents(file, loc)
+		case formModel.CalendarURL != "":
+			err = a.UpdateCalendarURL(r.Context(), principal, formModel.CalendarURL)
+			if err == nil {
+				events, err = a.FetchCalendarEvents(r.Context(), formModel.CalendarURL, loc)
+			}
+		default:
+			util.RenderHTML(w, calendarImportMessage("Please select an ICS file or enter the URL of your calendar."))
+			return
+		}
+		if errors.Is(err, ErrCalendarInvalid) {
+			util.RenderHTML(w, calendarImportMessage("The calendar is no valid iCalendar (ICS)."))
+			return
+		}
+		if errors.Is(err, ErrCalendarURLInvalid) {
+			util.RenderHTML(w, calendarImportMessage("Please enter a valid http, https or webcal URL of your calendar on a public host."))
+			return
+		}
+		if errors.Is(err, ErrCalendarFetchFailed) {
+			util.RenderHTML(w, calendarImportMessage("The calendar could not be loaded from the URL."))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		events = FilterCalendarEvents(
+			events,
+			util.WallClockIn(*from, loc),
+			util.WallClockIn(to.AddDate(0, 0, 1), loc),
+		)
+		if len(events) > maxActivityDrafts {
+			events = events[:maxActivityDrafts]
+		}
+
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, &paged.PageParams{Page: 0, Size: 50})
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		draftsFormModel := mapCalendarEventsToDraftsForm(events, loc)
+		draftsFormModel.CSRFToken = csrf.Token(r)
+
+		util.RenderHTML(w, ActivityDraftsView(draftsFormModel, projects.Projects, nil))
+	}
+}
+
+// HandleActivityDraftsForm creates activities for the selected drafts, if all of them are valid
+func (a *app) HandleActivityDraftsForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		err := r.ParseForm()
+		if err != nil {
+			util.RenderHTML(w, calendarImportMessage("Please load the events of your calendar again."))
+			return
+		}
+
+		var formModel activityDraftsFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil || len(formModel.Drafts) > maxActivityDrafts {
+			util.RenderHTML(w, calendarImportMessage("Please load the events of your calendar again."))
+			return
+		}
+
+		var activities []*Activity
+		draftErrors := make(map[int]string)
+		for i, draft := range formModel.Drafts {
+			if !draft.Selected {
+				continue
+			}
+
+			projectID := draft.ProjectID
+			if projectID == "" {
+				projectID = formModel.ProjectID
+			}
+
+			activityFormModel := activityFormModel{
+				ProjectID:   projectID,
+				Date:        draft.Date,
+				EndDate:     draft.EndDate,
+				StartTime:   draft.StartTime,
+				EndTime:     draft.EndTime,
+				Description: draft.Description,
+				Billable:    true,
+			}
+
+			err = validator.Struct(activityFormModel)
+			if err != nil {
+				draftErrors[i] = "Please select a project and check the description."
+				continue
+			}
+
+			activity, err := mapFormToActivity(activityFormModel, principal.Location())
+			if errors.Is(err, ErrActivityEndNotAfterStart) {
+				draftErrors[i] = "End must be after start."
+				continue
+			}
+			if err != nil {
+				draftErrors[i] = "Please select a project and check the description."
+				continue
+			}
+
+			err = a.checkActivityCreatable(r.Context(), principal, activity)
+			if err == nil && a.Config.RejectOverlaps && OverlapsAny(activity, activities) {
+				err = ErrActivityOverlaps
+			}
+			if err != nil {
+				message, ok := activityDraftErrorMessage(err)
+				if !ok {
+					util.RenderProblemHTML(w, isProduction, err)
+					return
+				}
+				draftErrors[i] = message
+				continue
+			}
+
+			activities = append(activities, activity)
+		}
+
+		if len(draftErrors) > 0 || len(activities) == 0 {
+			projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, &paged.PageParams{Page: 0, Size: 50})
+			if err != nil {
+				util.RenderProblemHTML(w, isProduction, err)
+				return
+			}
+
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, ActivityDraftsView(formModel, projects.Projects, draftErrors))
+			return
+		}
+
+		_, err = a.CreateActivities(r.Context(), principal, activities)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__activities-changed")
+		util.RenderHTML(w, Div(
+			Class("alert alert-success"),
+			Role("alert"),
+			g.Textf("Created %v activities from your calendar. ", len(activities)),
+			A(Href("/reports"), g.Text("Show report")),
+		))
+	}
+}
+
+// activityDraftErrorMessage is the message of a draft which can't be created as activity
+func activityDraftErrorMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrPeriodLocked):
+		return "The date is in a closed period.", true
+	case errors.Is(err, ErrActivityLocked):
+		return "The week is approved, so no activities can be booked in it.", true
+	case errors.Is(err, ErrProjectNotAssigned):
+		return "You are not assigned to the project.", true
+	case errors.Is(err, ErrActivityOverlaps):
+		return "The activity overlaps with other activities.", true
+	default:
+		return "", false
+	}
+}
+
+// timeRange formats the time of the draft, with the end date if it ends on another day
+func (d activityDraftFormModel) timeRange() string {
+	if d.EndDate != "" && d.EndDate != d.Date {
+		return fmt.Sprintf("%v - %v %v", d.StartTime, d.EndDate, d.EndTime)
+	}
+	return fmt.Sprintf("%v - %v", d.StartTime, d.EndTime)
+}
+
+func mapCalendarEventsToDraftsForm(events []*CalendarEvent, loc *time.Location) activityDraftsFormModel {
+	formModel := activityDraftsFormModel{
+		Drafts: make([]activityDraftFormModel, len(events)),
+	}
+
+	for i, event := range events {
+		start := event.Start.In(loc)
+		end := event.End.In(loc)
+
+		formModel.Drafts[i] = activityDraftFormModel{
+			Selected:    true,
+			Date:        util.FormatDateDE(start),
+			EndDate:     util.FormatDateDE(end),
+			StartTime:   util.FormatTime(start),
+			EndTime:     util.FormatTime(end),
+			Description: truncateText(event.Summary, 500),
+		}
+	}
+
+	return formModel
+}
+
+// truncateText truncates the text to at most maxBytes without splitting a character
+func truncateText(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	truncated := text[:maxBytes]
+	for !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
+	return truncated
+}
+
+func calendarImportMessage(message string) g.Node {
+	return Div(
+		Class("alert alert-warning"),
+		Role("alert"),
+		g.Text(message),
+	)
+}
+
+func CalendarImportPage(pageContext *pageContext, formModel calendarImportFormModel) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Import Calendar Events")),
+						P(
+							Class("text-muted"),
+							g.Text("Turn the events of your calendar into activities. "),
+							g.Text("Upload an ICS file or enter the URL of your calendar, then assign the events to projects and create the activities."),
+						),
+					),
+					CalendarImportForm(formModel),
+					Div(
+						ID("baralga__activity_drafts"),
+					),
+				),
+			),
+		},
+	)
+}
+
+func CalendarImportForm(formModel calendarImportFormModel) g.Node {
+	return FormEl(
+		ID("calendar_import_form"),
+		Class("mb-4"),
+		hx.Post("/activities/calendar"),
+		hx.Encoding("multipart/form-data"),
+		hx.Target("#baralga__activity_drafts"),
+		hx.Swap("innerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		Div(
+			Class("row mb-3"),
+			Div(
+				Class("col"),
+				Label(
+					Class("form-label"),
+					g.Attr("for", "From"),
+					g.Text("From"),
+				),
+				Input(
+					ID("From"),
+					Type("date"),
+					Name("From"),
+					Value(formModel.From),
+					g.Attr("required", "required"),
+					Class("form-control"),
+				),
+			),
+			Div(
+				Class("col"),
+				Label(
+					Class("form-label"),
+					g.Attr("for", "To"),
+					g.Text("To"),
+				),
+				Input(
+					ID("To"),
+					Type("date"),
+					Name("To"),
+					Value(formModel.To),
+					g.Attr("required", "required"),
+					Class("form-control"),
+				),
+			),
+		),
+		Div(
+			Class("mb-3"),
+			Label(
+				Class("form-label"),
+				g.Attr("for", "CalendarFile"),
+				g.Text("ICS File"),
+			),
+			Input(
+				ID("CalendarFile"),
+				Type("file"),
+				Name("file"),
+				Accept(".ics,text/calendar"),
+				Class("form-control"),
+			),
+		),
+		Div(
+			Class("mb-3"),
+			Label(
+				Class("form-label"),
+				g.Attr("for", "CalendarURL"),
+				g.Text("or Calendar URL"),
+			),
+			Input(
+				ID("CalendarURL"),
+				Type("url"),
+				Name("CalendarURL"),
+				MaxLength("500"),
+				Value(formModel.CalendarURL),
+				Class("form-control"),
+				g.Attr("placeholder", "https://calendar.example.com/my-calendar.ics"),
+			),
+			Div(
+				Class("form-text"),
+				g.Text("The URL of an ICS or CalDAV calendar is remembered for the next import."),
+			),
+		),
+		Button(
+			Type("submit"),
+			Class("btn btn-outline-primary"),
+			TitleAttr("Load Events"),
+			I(Class("bi-calendar-event me-2")),
+			g.Text("Load Events"),
+		),
+	)
+}
+
+func ActivityDraftsView(formModel activityDraftsFormModel, projects []*Project, draftErrors map[int]string) g.Node {
+	if len(formModel.Drafts) == 0 {
+		return Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			g.Text("No events found in the timespan."),
+		)
+	}
+
+	var errorMessage g.Node
+	if len(draftErrors) > 0 {
+		errorMessage = Div(
+			Class("alert alert-warning"),
+			Role("alert"),
+			g.Text("Please correct the marked events, no activities were created."),
+		)
+	}
+
+	projectOptions := func(selectedProjectID string) g.Node {
+		return g.Group(g.Map(len(projects), func(i int) g.Node {
+			project := projects[i]
+			return Option(
+				Value(project.ID.String()),
+				g.Text(project.Title),
+				g.If(selectedProjectID == project.ID.String(), Selected()),
+			)
+		}))
+	}
+
+	return FormEl(
+		ID("activity_drafts_form"),
+		hx.Post("/activities/calendar/confirm"),
+		hx.Target("#baralga__activity_drafts"),
+		hx.Swap("innerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		errorMessage,
+
+		Div(
+			Class("input-group mb-3"),
+			Label(
+				Class("input-group-text"),
+				g.Attr("for", "DraftsProjectID"),
+				g.Text("Project"),
+			),
+			Select(
+				ID("DraftsProjectID"),
+				Name("ProjectID"),
+				Class("form-select"),
+				TitleAttr("Project of all events without own project"),
+				projectOptions(formModel.ProjectID),
+			),
+			Button(
+				Type("submit"),
+				Class("btn btn-primary"),
+				TitleAttr("Create Activities"),
+				I(Class("bi-check2-all me-2")),
+				g.Text("Create Activities"),
+			),
+		),
+
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("activity-drafts"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(),
+						Th(g.Text("Date")),
+						Th(g.Text("Time")),
+						Th(g.Text("Description")),
+						Th(g.Text("Project")),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(formModel.Drafts), func(i int) g.Node {
+						draft := formModel.Drafts[i]
+						field := func(name string) string {
+							return fmt.Sprintf("Drafts.%v.%v", i, name)
+						}
+
+						var rowClass, draftError g.Node
+						if message, ok := draftErrors[i]; ok {
+							rowClass = Class("table-danger")
+							draftError = Div(
+								Class("text-danger small"),
+								g.Text(message),
+							)
+						}
+
+						return Tr(
+							rowClass,
+							Td(
+								Input(
+									Type("checkbox"),
+									Name(field("Selected")),
+									Value("true"),
+									Class("form-check-input"),
+									TitleAttr("Create activity"),
+									g.If(draft.Selected, g.Attr("checked", "checked")),
+								),
+								Input(Type("hidden"), Name(field("Date")), Value(draft.Date)),
+								Input(Type("hidden"), Name(field("EndDate")), Value(draft.EndDate)),
+								Input(Type("hidden"), Name(field("StartTime")), Value(draft.StartTime)),
+								Input(Type("hidden"), Name(field("EndTime")), Value(draft.EndTime)),
+							),
+							Td(g.Text(draft.Date)),
+							Td(g.Text(draft.timeRange())),
+							Td(
+								Input(
+									Type("text"),
+									Name(field("Description")),
+									Value(draft.Description),
+									MaxLength("500"),
+									Class("form-control form-control-sm"),
+								),
+								draftError,
+							),
+							Td(
+								Select(
+									Name(field("ProjectID")),
+									Class("form-select form-select-sm"),
+									Option(
+										Value(""),
+										g.Text("Project above"),
+										g.If(draft.ProjectID == "", Selected()),
+									),
+									projectOptions(draft.ProjectID),
+								),
+							),
+						)
+					})),
+				),
+			),
+		),
+	)
+}
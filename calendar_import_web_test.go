@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestHandleCalendarImportPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	r, _ := http.NewRequest("GET", "/activities/calendar", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleCalendarImportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "calendar_import_form"))
+}
+
+func TestHandleCalendarImportFormWithFile(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+		UserRepository:    NewInMemUserRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Timezone:       "Europe/Berlin",
+	}
+
+	fields := url.Values{}
+	fields.Add("From", "2021-11-12")
+	fields.Add("To", "2021-11-12")
+
+	r := newActivityImportRequest(t, "/activities/calendar", "calendar.ics", calendarSample, fields)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleCalendarImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "activity_drafts_form"))
+	is.True(strings.Contains(htmlBody, "Daily Standup, Team A"))
+	is.True(strings.Contains(htmlBody, "09:30 - 09:45"))
+	is.True(strings.Contains(htmlBody, "14:00 - 15:30"))
+	is.True(!strings.Contains(htmlBody, "Planning"))
+}
+
+func TestHandleCalendarImportFormWithURL(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "calendar.ics"), []byte(calendarSample), 0600)
+	is.NoErr(err)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		Config:            &config{CalendarAllowInsecure: true},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ProjectRepository: NewInMemProjectRepository(),
+		UserRepository:    userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	data := url.Values{}
+	data["From"] = []string{"2021-11-08"}
+	data["To"] = []string{"2021-11-14"}
+	data["CalendarURL"] = []string{server.URL + "/calendar.ics"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleCalendarImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "activity_drafts_form"))
+	is.True(strings.Contains(htmlBody, "Planning"))
+	is.Equal(userRepository.users[0].CalendarURL, server.URL+"/calendar.ics")
+}
+
+func TestHandleCalendarImportFormWithInternalURL(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ProjectRepository: NewInMemProjectRepository(),
+		UserRepository:    NewInMemUserRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	data := url.Values{}
+	data["From"] = []string{"2021-11-08"}
+	data["To"] = []string{"2021-11-14"}
+	data["CalendarURL"] = []string{"http://169.254.169.254/latest/meta-data"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleCalendarImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(!strings.Contains(htmlBody, "activity_drafts_form"))
+	is.True(strings.Contains(htmlBody, "URL of your calendar on a public host"))
+}
+
+func TestHandleCalendarImportFormWithoutCalendar(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config: &config{},
+	}
+
+	data := url.Values{}
+	data["From"] = []string{"2021-11-08"}
+	data["To"] = []string{"2021-11-14"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleCalendarImportForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Please select an ICS file"))
+}
+
+func TestHandleActivityDraftsForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = true
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Drafts.0.Selected"] = []string{"true"}
+	data["Drafts.0.Date"] = []string{"12.11.2021"}
+	data["Drafts.0.StartTime"] = []string{"09:30"}
+	data["Drafts.0.EndTime"] = []string{"09:45"}
+	data["Drafts.0.Description"] = []string{"Daily Standup"}
+	data["Drafts.1.Date"] = []string{"12.11.2021"}
+	data["Drafts.1.StartTime"] = []string{"14:00"}
+	data["Drafts.1.EndTime"] = []string{"15:30"}
+	data["Drafts.1.Description"] = []string{"Sprint Review"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar/confirm", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleActivityDraftsForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(httpRec.Result().Header.Get("HX-Trigger"), "baralga__activities-changed")
+	is.Equal(countBefore+1, len(repo.activities))
+
+	activity := repo.activities[len(repo.activities)-1]
+	is.Equal(activity.Description, "Daily Standup")
+	is.Equal(activity.ProjectID, projectIDSample)
+	is.True(activity.Billable)
+}
+
+func TestHandleActivityDraftsFormWithInvalidDraft(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Drafts.0.Selected"] = []string{"true"}
+	data["Drafts.0.Date"] = []string{"12.11.2021"}
+	data["Drafts.0.StartTime"] = []string{"09:30"}
+	data["Drafts.0.EndTime"] = []string{"09:45"}
+	data["Drafts.0.Description"] = []string{"Daily Standup"}
+	data["Drafts.1.Selected"] = []string{"true"}
+	data["Drafts.1.Date"] = []string{"12.11.2021"}
+	data["Drafts.1.StartTime"] = []string{"15:30"}
+	data["Drafts.1.EndTime"] = []string{"14:00"}
+	data["Drafts.1.Description"] = []string{"Sprint Review"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar/confirm", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleActivityDraftsForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore, len(repo.activities))
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "End must be after start."))
+	is.True(strings.Contains(htmlBody, "table-danger"))
+}
+
+func TestHandleActivityDraftsFormWithOverlappingDrafts(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{RejectOverlaps: true},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     repo,
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(repo.activities)
+
+	data := url.Values{}
+	data["ProjectID"] = []string{projectIDSample.String()}
+	data["Drafts.0.Selected"] = []string{"true"}
+	data["Drafts.0.Date"] = []string{"12.11.2021"}
+	data["Drafts.0.StartTime"] = []string{"09:30"}
+	data["Drafts.0.EndTime"] = []string{"10:30"}
+	data["Drafts.0.Description"] = []string{"Planning"}
+	data["Drafts.1.Selected"] = []string{"true"}
+	data["Drafts.1.Date"] = []string{"12.11.2021"}
+	data["Drafts.1.StartTime"] = []string{"10:00"}
+	data["Drafts.1.EndTime"] = []string{"11:00"}
+	data["Drafts.1.Description"] = []string{"Sprint Review"}
+
+	r, _ := http.NewRequest("POST", "/activities/calendar/confirm", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleActivityDraftsForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(countBefore, len(repo.activities))
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "The activity overlaps with other activities."))
+	is.True(strings.Contains(htmlBody, "table-danger"))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type clientModel struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title" validate:"required,min=2,max=100"`
+	Description string     `json:"description" validate:"max=500"`
+	Links       *hal.Links `json:"_links"`
+}
+
+type EmbeddedClients struct {
+	ClientModels []*clientModel `json:"clients"`
+}
+
+type clientsModel struct {
+	*EmbeddedClients `json:"_embedded"`
+	Links            *hal.Links `json:"_links"`
+}
+
+// HandleGetClients reads the clients
+func (a *app) HandleGetClients() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		clients, err := a.ReadClients(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		clientModels := make([]*clientModel, len(clients))
+		for i, client := range clients {
+			clientModels[i] = mapToClientModel(permissions, client)
+		}
+
+		clientsModel := &clientsModel{
+			EmbeddedClients: &EmbeddedClients{
+				ClientModels: clientModels,
+			},
+		}
+
+		selfLink := hal.NewSelfLink(r.RequestURI)
+		if permissions.IsAdmin() {
+			clientsModel.Links = hal.NewLinks(
+				selfLink,
+				hal.NewLink("create", "/api/clients"),
+			)
+		} else {
+			clientsModel.Links = hal.NewLinks(
+				selfLink,
+			)
+		}
+
+		util.RenderJSON(w, clientsModel)
+	}
+}
+
+// HandleCreateClient creates a client
+func (a *app) HandleCreateClient() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		var clientModel clientModel
+		err := json.NewDecoder(r.Body).Decode(&clientModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = validator.Struct(clientModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("client not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		client, err := a.CreateClient(r.Context(), principal, &Client{
+			Title:       clientModel.Title,
+			Description: clientModel.Description,
+		})
+		if errors.Is(err, ErrClientInvalid) {
+			http.Error(w, problem.New(problem.Title("client not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		util.RenderJSON(w, mapToClientModel(permissions, client))
+	}
+}
+
+// HandleDeleteClient deletes a client, its projects are kept without client
+func (a *app) HandleDeleteClient() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientIDParam := chi.URLParam(r, "client-id")
+		clientID, err := uuid.Parse(clientIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = a.DeleteClient(r.Context(), principal, clientID)
+		if errors.Is(err, ErrClientNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
+	}
+}
+
+func mapToClientModel(permissions *Permissions, client *Client) *clientModel {
+	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/clients/%s", client.ID))
+	clientModel := &clientModel{
+		ID:          client.ID.String(),
+		Title:       client.Title,
+		Description: client.Description,
+	}
+	if permissions.IsAdmin() {
+		clientModel.Links = hal.NewLinks(
+			selfLink,
+			hal.NewLink("delete", selfLink.Href()),
+		)
+	} else {
+		clientModel.Links = hal.NewLinks(
+			selfLink,
+		)
+	}
+	return clientModel
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleGetClients(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	clientRepository := NewInMemClientRepository()
+	clientRepository.clients = append(clientRepository.clients, &Client{
+		ID:             uuid.New(),
+		Title:          "ACME Inc.",
+		OrganizationID: organizationIDSample,
+	})
+
+	a := &app{
+		Config:            &config{},
+		ClientRepository:  clientRepository,
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/clients", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandleGetClients()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "ACME Inc."))
+}
+
+func TestHandleCreateClient(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	clientRepository := NewInMemClientRepository()
+	a := &app{
+		Config:           &config{},
+		ClientRepository: clientRepository,
+		RepositoryTxer:   NewInMemRepositoryTxer(),
+	}
+
+	body := `{ "title": "ACME Inc." }`
+
+	r, _ := http.NewRequest("POST", "/api/clients", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleCreateClient()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+	is.Equal(1, len(clientRepository.clients))
+}
+
+func TestHandleCreateClientAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ClientRepository:  NewInMemClientRepository(),
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	body := `{ "title": "ACME Inc." }`
+
+	r, _ := http.NewRequest("POST", "/api/clients", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	a.HandleCreateClient()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleDeleteNonExistingClient(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:           &config{},
+		ClientRepository: NewInMemClientRepository(),
+		RepositoryTxer:   NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("DELETE", "/api/clients/"+uuid.New().String(), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("client-id", uuid.New().String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteClient()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+}
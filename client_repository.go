@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var ErrClientNotFound = errors.New("client not found")
+
+type ClientRepository interface {
+	FindClients(ctx context.Context, organizationID uuid.UUID) ([]*Client, error)
+	FindClientByID(ctx context.Context, organizationID, clientID uuid.UUID) (*Client, error)
+	InsertClient(ctx context.Context, client *Client) (*Client, error)
+	DeleteClientByID(ctx context.Context, organizationID, clientID uuid.UUID) error
+}
+
+// DbClientRepository is a SQL database repository for clients
+type DbClientRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ ClientRepository = (*DbClientRepository)(nil)
+
+// NewDbClientRepository creates a new SQL database repository for clients
+func NewDbClientRepository(connPool *pgxpool.Pool) *DbClientRepository {
+	return &DbClientRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbClientRepository) FindClients(ctx context.Context, organizationID uuid.UUID) ([]*Client, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT client_id, title, description, org_id
+		 FROM clients
+		 WHERE org_id = $1
+		 ORDER BY title ASC`,
+		organizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []*Client
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+func (r *DbClientRepository) FindClientByID(ctx context.Context, organizationID, clientID uuid.UUID) (*Client, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT client_id, title, description, org_id
+		 FROM clients
+		 WHERE client_id = $1 AND org_id = $2`,
+		clientID, organizationID,
+	)
+
+	client, err := scanClient(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (r *DbClientRepository) InsertClient(ctx context.Context, client *Client) (*Client, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO clients
+		   (client_id, title, description, org_id)
+		 VALUES
+		   ($1, $2, $3, $4)`,
+		client.ID,
+		client.Title,
+		client.Description,
+		client.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// DeleteClientByID deletes the client, its projects are kept without client
+func (r *DbClientRepository) DeleteClientByID(ctx context.Context, organizationID, clientID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`DELETE
+		 FROM clients
+		 WHERE client_id = $1 AND org_id = $2
+		 RETURNING client_id`,
+		clientID, organizationID,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrClientNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func scanClient(row pgx.Row) (*Client, error) {
+	var (
+		id          string
+		title       string
+		description sql.NullString
+		orgID       string
+	)
+
+	err := row.Scan(&id, &title, &description, &orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		ID:             uuid.MustParse(id),
+		Title:          title,
+		Description:    description.String,
+		OrganizationID: uuid.MustParse(orgID),
+	}, nil
+}
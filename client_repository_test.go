@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestClientRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	clientRepository := NewDbClientRepository(connPool)
+	projectRepository := NewDbProjectRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("FindNotExistingClient", func(t *testing.T) {
+		_, err := clientRepository.FindClientByID(context.Background(), organizationIDSample, uuid.New())
+		is.True(errors.Is(err, ErrClientNotFound))
+	})
+
+	t.Run("InsertAndFindAndDeleteClient", func(t *testing.T) {
+		client := &Client{
+			ID:             uuid.New(),
+			Title:          "ACME Inc.",
+			Description:    "Our first client",
+			OrganizationID: organizationIDSample,
+		}
+		project := &Project{
+			ID:             uuid.New(),
+			Title:          "ACME Website",
+			Active:         true,
+			ClientID:       client.ID,
+			OrganizationID: organizationIDSample,
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := clientRepository.InsertClient(ctx, client)
+				if err != nil {
+					return err
+				}
+				_, err = projectRepository.InsertProject(ctx, project)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		clientFound, err := clientRepository.FindClientByID(context.Background(), organizationIDSample, client.ID)
+		is.NoErr(err)
+		is.Equal("ACME Inc.", clientFound.Title)
+		is.Equal("Our first client", clientFound.Description)
+
+		clients, err := clientRepository.FindClients(context.Background(), organizationIDSample)
+		is.NoErr(err)
+		is.Equal(1, len(clients))
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return clientRepository.DeleteClientByID(ctx, organizationIDSample, client.ID)
+			},
+		)
+		is.NoErr(err)
+
+		projectFound, err := projectRepository.FindProjectByID(context.Background(), organizationIDSample, project.ID)
+		is.NoErr(err)
+		is.Equal(uuid.Nil, projectFound.ClientID)
+	})
+
+	t.Run("DeleteNotExistingClient", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return clientRepository.DeleteClientByID(ctx, organizationIDSample, uuid.New())
+			},
+		)
+		is.True(errors.Is(err, ErrClientNotFound))
+	})
+}
+
+type InMemClientRepository struct {
+	clients []*Client
+}
+
+var _ ClientRepository = (*InMemClientRepository)(nil)
+
+func NewInMemClientRepository() *InMemClientRepository {
+	return &InMemClientRepository{
+		clients: []*Client{},
+	}
+}
+
+func (r *InMemClientRepository) FindClients(ctx context.Context, organizationID uuid.UUID) ([]*Client, error) {
+	var clients []*Client
+	for _, c := range r.clients {
+		if c.OrganizationID == organizationID {
+			clients = append(clients, c)
+		}
+	}
+	return clients, nil
+}
+
+func (r *InMemClientRepository) FindClientByID(ctx context.Context, organizationID, clientID uuid.UUID) (*Client, error) {
+	for _, c := range r.clients {
+		if c.ID == clientID && c.OrganizationID == organizationID {
+			return c, nil
+		}
+	}
+	return nil, ErrClientNotFound
+}
+
+func (r *InMemClientRepository) InsertClient(ctx context.Context, client *Client) (*Client, error) {
+	r.clients = append(r.clients, client)
+	return client, nil
+}
+
+func (r *InMemClientRepository) DeleteClientByID(ctx context.Context, organizationID, clientID uuid.UUID) error {
+	for i, c := range r.clients {
+		if c.ID == clientID && c.OrganizationID == organizationID {
+			r.clients = append(r.clients[:i], r.clients[i+1:]...)
+			return nil
+		}
+	}
+	return ErrClientNotFound
+}
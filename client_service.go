@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrClientInvalid = errors.New("client invalid")
+
+// ReadClients reads the clients of the organization
+func (a *app) ReadClients(ctx context.Context, principal *Principal) ([]*Client, error) {
+	return a.ClientRepository.FindClients(ctx, principal.OrganizationID)
+}
+
+// CreateClient creates a client projects can be assigned to
+func (a *app) CreateClient(ctx context.Context, principal *Principal, client *Client) (*Client, error) {
+	client.Title = strings.TrimSpace(client.Title)
+	if client.Title == "" {
+		return nil, ErrClientInvalid
+	}
+
+	client.ID = uuid.New()
+	client.OrganizationID = principal.OrganizationID
+
+	var clientCreated *Client
+	err := a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			c, err := a.ClientRepository.InsertClient(ctx, client)
+			if err != nil {
+				return err
+			}
+			clientCreated = c
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return clientCreated, nil
+}
+
+// DeleteClient deletes the client, its projects are kept without client
+func (a *app) DeleteClient(ctx context.Context, principal *Principal, clientID uuid.UUID) error {
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.ClientRepository.DeleteClientByID(ctx, principal.OrganizationID, clientID)
+		},
+	)
+}
@@ -46,6 +46,10 @@ func Confirm(message string) g.Node {
 	return g.Attr("hx-confirm", message)
 }
 
+func Encoding(encoding string) g.Node {
+	return g.Attr("hx-encoding", encoding)
+}
+
 func IsHXRequest(r *http.Request) bool {
 	return r.Header.Get("HX-Request") == "true"
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type invitationModel struct {
+	ID        string     `json:"id"`
+	EMail     string     `json:"email" validate:"required,email,max=100"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt string     `json:"createdAt"`
+	Links     *hal.Links `json:"_links"`
+}
+
+type EmbeddedInvitations struct {
+	InvitationModels []*invitationModel `json:"invitations"`
+}
+
+type invitationsModel struct {
+	*EmbeddedInvitations `json:"_embedded"`
+	Links                *hal.Links `json:"_links"`
+}
+
+// HandleGetInvitations reads the pending invitations
+func (a *app) HandleGetInvitations() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		invitations, err := a.ReadInvitations(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		invitationModels := make([]*invitationModel, len(invitations))
+		for i, invitation := range invitations {
+			invitationModels[i] = mapToInvitationModel(principal, invitation)
+		}
+
+		invitationsModel := &invitationsModel{
+			EmbeddedInvitations: &EmbeddedInvitations{
+				InvitationModels: invitationModels,
+			},
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+				hal.NewLink("create", "/api/invitations"),
+			),
+		}
+
+		util.RenderJSON(w, invitationsModel)
+	}
+}
+
+// HandleCreateInvitation invites a user into the organization
+func (a *app) HandleCreateInvitation() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		var invitationModel invitationModel
+		err := json.NewDecoder(r.Body).Decode(&invitationModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = validator.Struct(invitationModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("invitation not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		invitation, err := a.InviteUser(r.Context(), principal, invitationModel.EMail)
+		if errors.Is(err, ErrUserAlreadyExists) {
+			http.Error(w, problem.New(problem.Title("user already exists")).JSONString(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		invitationModelCreated := mapToInvitationModel(principal, invitation)
+
+		w.WriteHeader(http.StatusCreated)
+		util.RenderJSON(w, invitationModelCreated)
+	}
+}
+
+// HandleDeleteInvitation revokes a pending invitation
+func (a *app) HandleDeleteInvitation() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+		invitationID, err := uuid.Parse(invitationIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = a.DeleteInvitation(r.Context(), principal, invitationID)
+		if errors.Is(err, ErrInvitationNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__invitations-changed")
+	}
+}
+
+func mapToInvitationModel(principal *Principal, invitation *Invitation) *invitationModel {
+	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/invitations/%s", invitation.ID))
+	return &invitationModel{
+		ID:        invitation.ID.String(),
+		EMail:     invitation.EMail,
+		CreatedBy: invitation.CreatedBy,
+		CreatedAt: util.FormatDateTime(invitation.CreatedAt.In(principal.Location())),
+		Links: hal.NewLinks(
+			selfLink,
+			hal.NewLink("delete", selfLink.Href()),
+		),
+	}
+}
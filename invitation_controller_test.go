@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleCreateAndGetInvitations(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   NewInMemMailResource(),
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: NewInMemUserRepository(),
+	}
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/api/invitations", strings.NewReader(`{"email":"ina.invitee@baralga.com"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleCreateInvitation()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/api/invitations", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleGetInvitations()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	invitationsModel := &invitationsModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(invitationsModel)
+	is.NoErr(err)
+	is.Equal(1, len(invitationsModel.InvitationModels))
+	is.Equal("ina.invitee@baralga.com", invitationsModel.InvitationModels[0].EMail)
+}
+
+func TestHandleCreateInvitationAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", "/api/invitations", strings.NewReader(`{"email":"ina.invitee@baralga.com"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	a.HandleCreateInvitation()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleCreateInvitationForExistingUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   NewInMemMailResource(),
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", "/api/invitations", strings.NewReader(`{"email":"admin@baralga.com"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleCreateInvitation()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+}
+
+func TestHandleDeleteInvitation(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	invitationID := uuid.New()
+	userRepository.invitations = append(userRepository.invitations, &Invitation{
+		ID:             invitationID,
+		EMail:          "ina.invitee@baralga.com",
+		OrganizationID: organizationIDSample,
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	r, _ := http.NewRequest("DELETE", "/api/invitations/"+invitationID.String(), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("invitation-id", invitationID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteInvitation()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(userRepository.invitations))
+}
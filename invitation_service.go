@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUserAlreadyExists = errors.New("user already exists")
+
+const invitationExpiryDuration = 7 * 24 * time.Hour
+
+// InviteUser invites the user with the given email into the organization of the principal,
+// users of other organizations are invited to join the organization as well. Whether the user
+// of another organization is disabled is only logged, so invitations don't reveal accounts.
+func (a *app) InviteUser(ctx context.Context, principal *Principal, email string) (*Invitation, error) {
+	user, err := a.UserRepository.FindUserByUsernameIncludingDisabled(ctx, email)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	if user != nil {
+		_, err = a.UserRepository.FindUserByID(ctx, principal.OrganizationID, user.ID)
+		if err == nil {
+			return nil, ErrUserAlreadyExists
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		if !user.Enabled {
+			log.Printf("invited user %v is not enabled", user.ID)
+		}
+	}
+
+	invitation := &Invitation{
+		ID:             uuid.New(),
+		EMail:          email,
+		OrganizationID: principal.OrganizationID,
+		CreatedBy:      principal.Username,
+		CreatedAt:      time.Now(),
+	}
+
+	// Send email invitation link
+	subject := "You've been invited to Baralga"
+	body := fmt.Sprintf(
+		`%v invited you to track time with Baralga. Accept the invitation at %v/signup/invitation/%v within the next 7 days.`,
+		principal.Name,
+		a.Config.Webroot,
+		invitation.ID,
+	)
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		// Replace pending invitation
+		func(ctx context.Context) error {
+			return a.UserRepository.DeleteInvitationByEMail(ctx, principal.OrganizationID, email)
+		},
+		// Create Invitation
+		func(ctx context.Context) error {
+			_, err := a.UserRepository.InsertInvitation(ctx, invitation)
+			return err
+		},
+		// Send email invitation link
+		func(ctx context.Context) error {
+			return a.MailResource.SendMail(email, subject, body)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ReadInvitations reads the pending invitations of the principal's organization
+func (a *app) ReadInvitations(ctx context.Context, principal *Principal) ([]*Invitation, error) {
+	return a.UserRepository.FindInvitations(ctx, principal.OrganizationID)
+}
+
+// ReadInvitation reads an invitation which is not yet expired
+func (a *app) ReadInvitation(ctx context.Context, invitationID uuid.UUID) (*Invitation, error) {
+	invitation, err := a.UserRepository.FindInvitationByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.IsExpiredAt(time.Now()) {
+		return nil, ErrInvitationNotFound
+	}
+
+	return invitation, nil
+}
+
+// DeleteInvitation revokes a pending invitation
+func (a *app) DeleteInvitation(ctx context.Context, principal *Principal, invitationID uuid.UUID) error {
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.UserRepository.DeleteInvitationByID(ctx, principal.OrganizationID, invitationID)
+		},
+	)
+}
+
+// AcceptInvitation creates the invited user within the inviting organization
+func (a *app) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, user *User) error {
+	invitation, err := a.ReadInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.UserRepository.FindUserByUsernameIncludingDisabled(ctx, invitation.EMail)
+	if err == nil {
+		return ErrUserAlreadyExists
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	user.ID = uuid.New()
+	user.Username = invitation.EMail
+	user.EMail = invitation.EMail
+	user.OrganizationID = invitation.OrganizationID
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		// Create User
+		func(ctx context.Context) error {
+			_, err := a.UserRepository.InsertUserWithRole(ctx, user, "ROLE_USER")
+			return err
+		},
+		// Remove accepted invitation
+		func(ctx context.Context) error {
+			return a.UserRepository.DeleteInvitationByID(ctx, invitation.OrganizationID, invitation.ID)
+		},
+	)
+}
+
+// IsInvitedUserRegistered checks whether the invited user is already registered in another organization
+func (a *app) IsInvitedUserRegistered(ctx context.Context, invitation *Invitation) (bool, error) {
+	_, err := a.UserRepository.FindUserByUsernameIncludingDisabled(ctx, invitation.EMail)
+	if errors.Is(err, ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// JoinOrganization lets a registered user accept the invitation into another organization
+// with role ROLE_USER, the user confirms the invitation with the password. Disabled users
+// are rejected like invalid passwords, the reason is only logged.
+func (a *app) JoinOrganization(ctx context.Context, invitationID uuid.UUID, password string) error {
+	invitation, err := a.ReadInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.UserRepository.FindUserByUsernameIncludingDisabled(ctx, invitation.EMail)
+	if err != nil {
+		return err
+	}
+	if !user.Enabled {
+		log.Printf("user %v is not enabled to join organization %v", user.ID, invitation.OrganizationID)
+		return ErrPasswordInvalid
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return ErrPasswordInvalid
+	}
+
+	return a.joinOrganization(ctx, invitation, user)
+}
+
+// JoinOrganizationSignedIn lets the signed in principal accept the invitation into another
+// organization with role ROLE_USER, users signed in with GitHub or Google have no password
+// to confirm the invitation with
+func (a *app) JoinOrganizationSignedIn(ctx context.Context, principal *Principal, invitationID uuid.UUID) error {
+	invitation, err := a.ReadInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return err
+	}
+
+	return a.joinOrganization(ctx, invitation, user)
+}
+
+func (a *app) joinOrganization(ctx context.Context, invitation *Invitation, user *User) error {
+	_, err := a.UserRepository.FindUserByID(ctx, invitation.OrganizationID, user.ID)
+	if err == nil {
+		return ErrUserAlreadyExists
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		// Add membership
+		func(ctx context.Context) error {
+			return a.OrganizationRepository.InsertOrganizationMember(ctx, &OrganizationMember{
+				OrganizationID: invitation.OrganizationID,
+				UserID:         user.ID,
+				Role:           "ROLE_USER",
+			})
+		},
+		// Remove accepted invitation
+		func(ctx context.Context) error {
+			return a.UserRepository.DeleteInvitationByID(ctx, invitation.OrganizationID, invitation.ID)
+		},
+	)
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestInviteAndAcceptInvitation(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	mailResource := NewInMemMailResource()
+	userRepository := NewInMemUserRepository()
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   mailResource,
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Name:           "Ado Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	invitation, err := a.InviteUser(context.Background(), principal, "ina.invitee@baralga.com")
+	is.NoErr(err)
+
+	err = a.AcceptInvitation(context.Background(), invitation.ID, &User{
+		Name:     "Ina Invitee",
+		Password: "myPassword?!§!",
+	})
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(len(mailResource.mails), 1)
+	is.Equal(len(userRepository.invitations), 0)
+
+	user, err := userRepository.FindUserByUsername(context.Background(), "ina.invitee@baralga.com")
+	is.NoErr(err)
+	is.Equal(user.OrganizationID, organizationIDSample)
+
+	roles, err := userRepository.FindRolesByUserID(context.Background(), organizationIDSample, user.ID)
+	is.NoErr(err)
+	is.Equal(roles, []string{"ROLE_USER"})
+}
+
+func TestInviteExistingUser(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	mailResource := NewInMemMailResource()
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   mailResource,
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.InviteUser(context.Background(), principal, "admin@baralga.com")
+
+	// Assert
+	is.True(errors.Is(err, ErrUserAlreadyExists))
+	is.Equal(len(mailResource.mails), 0)
+}
+
+func TestInviteUserOfOtherOrganizationAndJoin(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	mailResource := NewInMemMailResource()
+	userRepository := NewInMemUserRepository()
+
+	otherOrganization := &Organization{
+		ID:    uuid.New(),
+		Title: "Other Organization",
+	}
+	organizationRepository := NewInMemOrganizationRepository()
+	organizationRepository.organizations = append(organizationRepository.organizations, otherOrganization)
+
+	a := &app{
+		Config:                 &config{},
+		MailResource:           mailResource,
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	principal := &Principal{
+		Name:           "Otto Other",
+		Username:       "otto@other.com",
+		OrganizationID: otherOrganization.ID,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	invitation, err := a.InviteUser(context.Background(), principal, "admin@baralga.com")
+	is.NoErr(err)
+
+	registered, errRegistered := a.IsInvitedUserRegistered(context.Background(), invitation)
+	errWrongPassword := a.JoinOrganization(context.Background(), invitation.ID, "wrong")
+	errJoin := a.JoinOrganization(context.Background(), invitation.ID, "adm1n")
+
+	// Assert
+	is.NoErr(errRegistered)
+	is.True(registered)
+	is.True(errors.Is(errWrongPassword, ErrPasswordInvalid))
+	is.NoErr(errJoin)
+	is.Equal(len(userRepository.invitations), 0)
+	is.Equal(1, len(organizationRepository.members))
+	is.Equal(otherOrganization.ID, organizationRepository.members[0].OrganizationID)
+	is.Equal("ROLE_USER", organizationRepository.members[0].Role)
+}
+
+func TestInviteUserTwice(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   NewInMemMailResource(),
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.InviteUser(context.Background(), principal, "ina.invitee@baralga.com")
+	is.NoErr(err)
+	invitation, err := a.InviteUser(context.Background(), principal, "ina.invitee@baralga.com")
+	is.NoErr(err)
+
+	// Assert
+	is.Equal(len(userRepository.invitations), 1)
+	is.Equal(userRepository.invitations[0].ID, invitation.ID)
+}
+
+func TestAcceptExpiredInvitation(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+	invitation := &Invitation{
+		ID:             uuid.New(),
+		EMail:          "ina.invitee@baralga.com",
+		OrganizationID: organizationIDSample,
+		CreatedBy:      "admin@baralga.com",
+		CreatedAt:      time.Now().Add(-8 * 24 * time.Hour),
+	}
+	userRepository.invitations = append(userRepository.invitations, invitation)
+
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	// Act
+	err := a.AcceptInvitation(context.Background(), invitation.ID, &User{Name: "Ina Invitee"})
+
+	// Assert
+	is.True(errors.Is(err, ErrInvitationNotFound))
+}
+
+func TestInviteDisabledUserOfOtherOrganization(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+	userRepository.users = append(userRepository.users, &User{
+		ID:             uuid.New(),
+		Username:       "dora.disabled@baralga.com",
+		EMail:          "dora.disabled@baralga.com",
+		Enabled:        false,
+		OrganizationID: uuid.New(),
+	})
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   NewInMemMailResource(),
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	invitation, err := a.InviteUser(context.Background(), principal, "dora.disabled@baralga.com")
+	is.NoErr(err)
+
+	errJoin := a.JoinOrganization(context.Background(), invitation.ID, "secret")
+
+	// Assert
+	is.True(errors.Is(errJoin, ErrPasswordInvalid))
+	is.Equal(len(userRepository.invitations), 1)
+}
+
+func TestAcceptInvitationOfDisabledUser(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+
+	a := &app{
+		Config:         &config{},
+		MailResource:   NewInMemMailResource(),
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	invitation, err := a.InviteUser(context.Background(), principal, "una.unconfirmed@baralga.com")
+	is.NoErr(err)
+
+	userRepository.users = append(userRepository.users, &User{
+		ID:             uuid.New(),
+		Username:       "una.unconfirmed@baralga.com",
+		EMail:          "una.unconfirmed@baralga.com",
+		Enabled:        false,
+		OrganizationID: uuid.New(),
+	})
+	userCount := len(userRepository.users)
+
+	// Act
+	err = a.AcceptInvitation(context.Background(), invitation.ID, &User{
+		Name:     "Una Unconfirmed",
+		Password: "myPassword?!§!",
+	})
+
+	// Assert
+	is.True(errors.Is(err, ErrUserAlreadyExists))
+	is.Equal(len(userRepository.users), userCount)
+}
+
+func TestJoinOrganizationSignedInWithGithub(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+	githubUser := &User{
+		ID:             uuid.New(),
+		Username:       "4711",
+		Name:           "Gina Github",
+		Enabled:        true,
+		Origin:         "github",
+		OrganizationID: uuid.New(),
+	}
+	userRepository.users = append(userRepository.users, githubUser)
+	invitation := &Invitation{
+		ID:             uuid.New(),
+		EMail:          "gina@github.com",
+		OrganizationID: organizationIDSample,
+		CreatedBy:      "admin@baralga.com",
+		CreatedAt:      time.Now(),
+	}
+	userRepository.invitations = append(userRepository.invitations, invitation)
+	organizationRepository := NewInMemOrganizationRepository()
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	principal := &Principal{
+		Name:           githubUser.Name,
+		Username:       githubUser.Username,
+		OrganizationID: githubUser.OrganizationID,
+	}
+
+	// Act
+	err := a.JoinOrganizationSignedIn(context.Background(), principal, invitation.ID)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(len(userRepository.invitations), 0)
+	is.Equal(1, len(organizationRepository.members))
+	is.Equal(organizationIDSample, organizationRepository.members[0].OrganizationID)
+	is.Equal(githubUser.ID, organizationRepository.members[0].UserID)
+}
+
+func TestJoinOrganizationSignedInAsMember(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	userRepository := NewInMemUserRepository()
+	invitation := &Invitation{
+		ID:             uuid.New(),
+		EMail:          "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		CreatedBy:      "admin@baralga.com",
+		CreatedAt:      time.Now(),
+	}
+	userRepository.invitations = append(userRepository.invitations, invitation)
+	organizationRepository := NewInMemOrganizationRepository()
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	err := a.JoinOrganizationSignedIn(context.Background(), principal, invitation.ID)
+
+	// Assert
+	is.True(errors.Is(err, ErrUserAlreadyExists))
+	is.Equal(0, len(organizationRepository.members))
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type invitationFormModel struct {
+	CSRFToken string
+	EMail     string `validate:"required,email,max=100"`
+}
+
+type invitationJoinFormModel struct {
+	CSRFToken string
+	Password  string `validate:"required,max=100"`
+}
+
+type invitationAcceptFormModel struct {
+	CSRFToken        string
+	Name             string `validate:"required,min=5,max=50"`
+	Password         string `validate:"required,min=8,max=100"`
+	AcceptConditions bool
+}
+
+func (a *app) HandleInvitationsPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		invitations, err := a.ReadInvitations(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		formModel := invitationFormModel{}
+		formModel.CSRFToken = csrf.Token(r)
+
+		if hx.IsHXTargetRequest(r, "baralga__invitations") {
+			util.RenderHTML(w, InvitationsView(principal, formModel, invitations, ""))
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Team",
+		}
+
+		util.RenderHTML(w, InvitationsPage(pageContext, formModel, invitations))
+	}
+}
+
+func (a *app) HandleInvitationForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			a.renderInvitationsView(w, r, principal, isProduction, invitationFormModel{}, "")
+			return
+		}
+
+		var formModel invitationFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			a.renderInvitationsView(w, r, principal, isProduction, invitationFormModel{}, "")
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			a.renderInvitationsView(w, r, principal, isProduction, formModel, "Please enter a valid email.")
+			return
+		}
+
+		_, err = a.InviteUser(r.Context(), principal, formModel.EMail)
+		if errors.Is(err, ErrUserAlreadyExists) {
+			a.renderInvitationsView(w, r, principal, isProduction, formModel, "A user with this email is already a member.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderInvitationsView(w, r, principal, isProduction, invitationFormModel{}, "")
+	}
+}
+
+func (a *app) renderInvitationsView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel invitationFormModel, errorMessage string) {
+	invitations, err := a.ReadInvitations(r.Context(), principal)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	formModel.CSRFToken = csrf.Token(r)
+
+	util.RenderHTML(w, InvitationsView(principal, formModel, invitations, errorMessage))
+}
+
+func (a *app) HandleInvitationAcceptPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+
+		invitation, err := a.readInvitationByParam(r, invitationIDParam)
+		if err != nil {
+			http.Redirect(w, r, "/signup", http.StatusFound)
+			return
+		}
+
+		registered, err := a.IsInvitedUserRegistered(r.Context(), invitation)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if registered {
+			formModel := invitationJoinFormModel{}
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, InvitationJoinPage(r.URL.Path, invitation, formModel, ""))
+			return
+		}
+
+		formModel := invitationAcceptFormModel{}
+		formModel.CSRFToken = csrf.Token(r)
+		util.RenderHTML(w, a.InvitationAcceptPage(r.URL.Path, invitation, formModel, ""))
+	}
+}
+
+// HandleInvitationJoinForm lets a registered user join the organization of the invitation
+func (a *app) HandleInvitationJoinForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+		invitationPath := fmt.Sprintf("/signup/invitation/%v", invitationIDParam)
+
+		invitation, err := a.readInvitationByParam(r, invitationIDParam)
+		if err != nil {
+			http.Redirect(w, r, "/signup", http.StatusFound)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			formModel := invitationJoinFormModel{}
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, InvitationJoinPage(invitationPath, invitation, formModel, ""))
+			return
+		}
+
+		var formModel invitationJoinFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, InvitationJoinPage(invitationPath, invitation, formModel, "Please enter your password."))
+			return
+		}
+
+		err = a.JoinOrganization(r.Context(), invitation.ID, formModel.Password)
+		if errors.Is(err, ErrPasswordInvalid) {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, InvitationJoinPage(invitationPath, invitation, formModel, "Please check your password."))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login?info=invitation_accepted", http.StatusFound)
+	}
+}
+
+// HandleInvitationJoinSignedInPage lets the signed in user confirm to join the organization of the invitation
+func (a *app) HandleInvitationJoinSignedInPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		invitation, err := a.readInvitationByParam(r, invitationIDParam)
+		if err != nil {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+
+		formModel := invitationJoinFormModel{}
+		formModel.CSRFToken = csrf.Token(r)
+		util.RenderHTML(w, InvitationJoinSignedInPage(r.URL.Path, principal, invitation, formModel, ""))
+	}
+}
+
+// HandleInvitationJoinSignedInForm lets the signed in user join the organization of the invitation,
+// users signed in with GitHub or Google have no password to confirm the invitation with
+func (a *app) HandleInvitationJoinSignedInForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		invitation, err := a.readInvitationByParam(r, invitationIDParam)
+		if err != nil {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+
+		err = a.JoinOrganizationSignedIn(r.Context(), principal, invitation.ID)
+		if errors.Is(err, ErrUserAlreadyExists) {
+			formModel := invitationJoinFormModel{}
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, InvitationJoinSignedInPage(r.URL.Path, principal, invitation, formModel, "You are already a member of this team."))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		http.Redirect(w, r, "/", http.StatusFound)
+	}
+}
+
+func (a *app) HandleInvitationAcceptForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		invitationIDParam := chi.URLParam(r, "invitation-id")
+
+		invitation, err := a.readInvitationByParam(r, invitationIDParam)
+		if err != nil {
+			http.Redirect(w, r, "/signup", http.StatusFound)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			formModel := invitationAcceptFormModel{}
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, a.InvitationAcceptPage(r.URL.Path, invitation, formModel, ""))
+			return
+		}
+
+		var formModel invitationAcceptFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, a.InvitationAcceptPage(r.URL.Path, invitation, formModel, ""))
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, a.InvitationAcceptPage(r.URL.Path, invitation, formModel, "Please check your name and password."))
+			return
+		}
+
+		user := &User{
+			Name:     formModel.Name,
+			Password: a.EncryptPassword(formModel.Password),
+			Origin:   "baralga",
+		}
+		err = a.AcceptInvitation(r.Context(), invitation.ID, user)
+		if errors.Is(err, ErrUserAlreadyExists) {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, a.InvitationAcceptPage(r.URL.Path, invitation, formModel, "A user with this email already exists."))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login?info=invitation_accepted", http.StatusFound)
+	}
+}
+
+func (a *app) readInvitationByParam(r *http.Request, invitationIDParam string) (*Invitation, error) {
+	invitationID, err := uuid.Parse(invitationIDParam)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.ReadInvitation(r.Context(), invitationID)
+}
+
+func InvitationsPage(pageContext *pageContext, formModel invitationFormModel, invitations []*Invitation) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Team")),
+					),
+					Div(
+						ID("baralga__invitations"),
+
+						hx.Target("#baralga__invitations"),
+						hx.Swap("innerHTML"),
+
+						hx.Trigger("baralga__invitations-changed from:body"),
+						hx.Get("/invitations"),
+
+						InvitationsView(pageContext.principal, formModel, invitations, ""),
+					),
+				),
+			),
+		},
+	)
+}
+
+func InvitationsView(principal *Principal, formModel invitationFormModel, invitations []*Invitation, errorMessage string) g.Node {
+	return Div(
+		InvitationForm(formModel, errorMessage),
+		g.If(
+			len(invitations) > 0,
+			H5(
+				Class("mt-4"),
+				g.Text("Pending Invitations"),
+			),
+		),
+		g.Group(
+			g.Map(len(invitations), func(i int) g.Node {
+				invitation := invitations[i]
+				return Div(
+					Class("card mt-2"),
+					Div(
+						Class("card-body"),
+						Div(
+							Class("d-flex justify-content-between"),
+							Span(
+								Class("flex-grow-1"),
+								g.Text(invitation.EMail),
+								Small(
+									Class("text-muted ms-2"),
+									g.Textf(
+										"invited by %v on %v",
+										invitation.CreatedBy,
+										util.FormatDateDE(invitation.CreatedAt.In(principal.Location())),
+									),
+								),
+							),
+							A(
+								hx.Confirm(fmt.Sprintf("Do you really want to revoke the invitation of %v?", invitation.EMail)),
+								hx.Delete(fmt.Sprintf("/api/invitations/%v", invitation.ID)),
+								Class("btn btn-outline-secondary btn-sm ms-1"),
+								TitleAttr("Revoke Invitation"),
+								I(Class("bi-trash2")),
+							),
+						),
+					),
+				)
+			}),
+		),
+	)
+}
+
+func InvitationForm(formModel invitationFormModel, errorMessage string) g.Node {
+	return FormEl(
+		ID("invitation_form"),
+		Class("mb-4 mt-2"),
+		hx.Post("/invitations/new"),
+		hx.Target("#baralga__invitations"),
+		hx.Swap("innerHTML"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-warning text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		Div(
+			Class("input-group mb-3"),
+			Input(
+				ID("InvitationEMail"),
+				Type("email"),
+				Name("EMail"),
+				MaxLength("100"),
+				Value(formModel.EMail),
+				g.Attr("required", "required"),
+				Class("form-control"),
+				g.Attr("placeholder", "colleague@mail.com"),
+			),
+			Button(
+				Class("btn btn-outline-primary"),
+				g.Attr("for", "InvitationEMail"),
+				TitleAttr("Invite User"),
+				I(Class("bi-envelope me-2")),
+				g.Text("Invite"),
+			),
+		),
+	)
+}
+
+func (a *app) InvitationAcceptPage(currentPath string, invitation *Invitation, formModel invitationAcceptFormModel, errorMessage string) g.Node {
+	return invitationPage(currentPath, a.InvitationAcceptForm(currentPath, invitation, formModel, errorMessage))
+}
+
+func InvitationJoinPage(currentPath string, invitation *Invitation, formModel invitationJoinFormModel, errorMessage string) g.Node {
+	return invitationPage(currentPath, InvitationJoinForm(currentPath, invitation, formModel, errorMessage))
+}
+
+func InvitationJoinSignedInPage(currentPath string, principal *Principal, invitation *Invitation, formModel invitationJoinFormModel, errorMessage string) g.Node {
+	return invitationPage(currentPath, InvitationJoinSignedInForm(currentPath, principal, invitation, formModel, errorMessage))
+}
+
+func invitationPage(currentPath string, form g.Node) g.Node {
+	return Page(
+		"Join Team",
+		currentPath,
+		[]g.Node{
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("d-flex justify-content-center align-items-center mt-2 mb-3"),
+						Img(
+							Alt("Baralga"),
+							Class("img-responsive"),
+							Src("/assets/baralga_192.png"),
+						),
+						Div(
+							Class("ms-4"),
+							H2(
+								g.Text("Baralga"),
+								Small(
+									Class("text-muted"),
+									StyleAttr("display: block; font-size: 70%;"),
+									g.Text("project time tracking"),
+								),
+							),
+						),
+					),
+					form,
+				),
+			),
+		},
+	)
+}
+
+func (a *app) InvitationAcceptForm(currentPath string, invitation *Invitation, formModel invitationAcceptFormModel, errorMessage string) g.Node {
+	return FormEl(
+		ID("invitation_accept_form"),
+		Action(currentPath),
+		Method("POST"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-danger text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("email"),
+				Type("email"),
+				Class("form-control"),
+				Value(invitation.EMail),
+				g.Attr("readonly", "readonly"),
+			),
+			Label(
+				g.Attr("for", "email"),
+				g.Text("E-Mail"),
+			),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("name"),
+				Required(),
+				MinLength("5"),
+				MaxLength("50"),
+				Type("text"),
+				Name("Name"),
+				Class("form-control"),
+				g.Attr("placeholder", "John Doe"),
+				Value(formModel.Name),
+			),
+			Label(
+				g.Attr("for", "name"),
+				g.Text("Name"),
+			),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("password"),
+				Required(),
+				Type("password"),
+				Name("Password"),
+				MinLength("8"),
+				MaxLength("100"),
+				Class("form-control"),
+				g.Attr("placeholder", "***"),
+			),
+			Label(
+				g.Attr("for", "password"),
+				g.Text("Password"),
+			),
+		),
+		Div(
+			Class("form-check mb-3"),
+			Input(
+				ID("acceptConditions"),
+				Required(),
+				Type("checkbox"),
+				Name("AcceptConditions"),
+				Class("form-check-input"),
+				g.If(formModel.AcceptConditions, Value("true")),
+			),
+			Label(
+				g.Attr("for", "acceptConditions"),
+				g.Raw(
+					fmt.Sprintf("Ich bin mit den <a href=\"%v\">Datenschutzbestimmungen</a> einverstanden. I accept the <a href=\"%v\">data protection rules</a>.",
+						a.Config.DataProtectionURL,
+						a.Config.DataProtectionURL,
+					),
+				),
+			),
+		),
+		Div(
+			Class("container-fluid text-center"),
+			Button(
+				Type("submit"),
+				Class("btn btn-primary w-100"),
+				g.Text("Join your team"),
+			),
+		),
+		invitationJoinSignedInLink(invitation),
+	)
+}
+
+// InvitationJoinForm asks a registered user for the password to join the organization of the invitation
+func InvitationJoinForm(currentPath string, invitation *Invitation, formModel invitationJoinFormModel, errorMessage string) g.Node {
+	return FormEl(
+		ID("invitation_join_form"),
+		Action(currentPath+"/join"),
+		Method("POST"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-danger text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+		P(
+			Class("text-muted"),
+			g.Text("You already use Baralga. Sign in with your password to join the team, you switch between your teams in the menu."),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("email"),
+				Type("email"),
+				Class("form-control"),
+				Value(invitation.EMail),
+				g.Attr("readonly", "readonly"),
+			),
+			Label(
+				g.Attr("for", "email"),
+				g.Text("E-Mail"),
+			),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("password"),
+				Required(),
+				Type("password"),
+				Name("Password"),
+				MaxLength("100"),
+				Class("form-control"),
+				g.Attr("placeholder", "***"),
+			),
+			Label(
+				g.Attr("for", "password"),
+				g.Text("Password"),
+			),
+		),
+		Div(
+			Class("container-fluid text-center"),
+			Button(
+				Type("submit"),
+				Class("btn btn-primary w-100"),
+				g.Text("Join your team"),
+			),
+		),
+		invitationJoinSignedInLink(invitation),
+	)
+}
+
+// InvitationJoinSignedInForm lets the signed in user join the team without a password
+func InvitationJoinSignedInForm(currentPath string, principal *Principal, invitation *Invitation, formModel invitationJoinFormModel, errorMessage string) g.Node {
+	return FormEl(
+		ID("invitation_join_form"),
+		Action(currentPath),
+		Method("POST"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-danger text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+		P(
+			Class("text-muted"),
+			g.Textf("You are signed in as %v. Join the team with this account, you switch between your teams in the menu.", principal.Name),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("email"),
+				Type("email"),
+				Class("form-control"),
+				Value(invitation.EMail),
+				g.Attr("readonly", "readonly"),
+			),
+			Label(
+				g.Attr("for", "email"),
+				g.Text("E-Mail"),
+			),
+		),
+		Div(
+			Class("container-fluid text-center"),
+			Button(
+				Type("submit"),
+				Class("btn btn-primary w-100"),
+				g.Text("Join your team"),
+			),
+		),
+	)
+}
+
+// invitationJoinSignedInLink links to joining with the account the user is signed in with,
+// e.g. users of GitHub or Google who have no password
+func invitationJoinSignedInLink(invitation *Invitation) g.Node {
+	return P(
+		Class("text-center text-muted mt-3"),
+		g.Text("Signed in with GitHub or Google? "),
+		A(
+			Href(fmt.Sprintf("/invitations/%v/join", invitation.ID)),
+			Class("link-secondary"),
+			g.Text("Join with your account"),
+		),
+	)
+}
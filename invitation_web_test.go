@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleInvitationsPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	userRepository.invitations = append(userRepository.invitations, &Invitation{
+		ID:             uuid.New(),
+		EMail:          "ina.invitee@baralga.com",
+		OrganizationID: organizationIDSample,
+		CreatedBy:      "admin@baralga.com",
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: userRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/invitations", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Name:           "Ado Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleInvitationsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Team # Baralga"))
+	is.True(strings.Contains(htmlBody, "ina.invitee@baralga.com"))
+}
+
+func TestHandleInvitationsPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/invitations", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1@baralga.com",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	a.HandleInvitationsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleInvitationForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	mailResource := NewInMemMailResource()
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		Config:         &config{},
+		MailResource:   mailResource,
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	data := url.Values{}
+	data["EMail"] = []string{"ina.invitee@baralga.com"}
+
+	r, _ := http.NewRequest("POST", "/invitations/new", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Name:           "Ado Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleInvitationForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(userRepository.invitations))
+	is.Equal(1, len(mailResource.mails))
+}
+
+func TestHandleInvitationAcceptForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	invitationID := uuid.New()
+	userRepository.invitations = append(userRepository.invitations, &Invitation{
+		ID:             invitationID,
+		EMail:          "ina.invitee@baralga.com",
+		OrganizationID: organizationIDSample,
+		CreatedBy:      "admin@baralga.com",
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	data := url.Values{}
+	data["Name"] = []string{"Ina Invitee"}
+	data["Password"] = []string{"myPassword?!§!"}
+	data["AcceptConditions"] = []string{"true"}
+
+	r, _ := http.NewRequest("POST", "/signup/invitation/"+invitationID.String(), strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("invitation-id", invitationID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleInvitationAcceptForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusFound)
+	is.Equal(httpRec.Header()["Location"][0], "/login?info=invitation_accepted")
+	is.Equal(0, len(userRepository.invitations))
+	is.Equal(2, len(userRepository.users))
+}
+
+func TestHandleInvitationJoinForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	otherOrganizationID := uuid.New()
+	userRepository := NewInMemUserRepository()
+	invitationID := uuid.New()
+	userRepository.invitations = append(userRepository.invitations, &Invitation{
+		ID:             invitationID,
+		EMail:          "admin@baralga.com",
+		OrganizationID: otherOrganizationID,
+		CreatedBy:      "otto@other.com",
+		CreatedAt:      time.Now(),
+	})
+	organizationRepository := NewInMemOrganizationRepository()
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	data := url.Values{}
+	data["Password"] = []string{"adm1n"}
+
+	r, _ := http.NewRequest("POST", "/signup/invitation/"+invitationID.String()+"/join", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("invitation-id", invitationID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleInvitationJoinForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusFound)
+	is.Equal(httpRec.Header()["Location"][0], "/login?info=invitation_accepted")
+	is.Equal(0, len(userRepository.invitations))
+	is.Equal(1, len(organizationRepository.members))
+	is.Equal(otherOrganizationID, organizationRepository.members[0].OrganizationID)
+}
+
+func TestHandleInvitationAcceptPageWithUnknownInvitation(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/signup/invitation/-unknown-", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("invitation-id", "-unknown-")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleInvitationAcceptPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusFound)
+}
+
+func TestHandleInvitationJoinSignedInForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	otherOrganizationID := uuid.New()
+	userRepository := NewInMemUserRepository()
+	invitationID := uuid.New()
+	userRepository.invitations = append(userRepository.invitations, &Invitation{
+		ID:             invitationID,
+		EMail:          "admin@baralga.com",
+		OrganizationID: otherOrganizationID,
+		CreatedBy:      "otto@other.com",
+		CreatedAt:      time.Now(),
+	})
+	organizationRepository := NewInMemOrganizationRepository()
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	principal := &Principal{
+		Name:           "Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	r, _ := http.NewRequest("POST", "/invitations/"+invitationID.String()+"/join", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("invitation-id", invitationID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+	a.HandleInvitationJoinSignedInForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusFound)
+	is.Equal(httpRec.Header()["Location"][0], "/")
+	is.Equal(0, len(userRepository.invitations))
+	is.Equal(1, len(organizationRepository.members))
+	is.Equal(otherOrganizationID, organizationRepository.members[0].OrganizationID)
+}
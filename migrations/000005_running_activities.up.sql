@@ -0,0 +1,23 @@
+-- Table running_activities
+CREATE TABLE running_activities (
+     running_activity_id  uuid not null,
+     description          varchar(4000),
+     username             varchar(50) not null,
+     start_time           timestamptz not null,
+     project_id           uuid not null,
+     org_id               uuid not null
+);
+
+ALTER TABLE running_activities
+ADD CONSTRAINT pk_running_activities PRIMARY KEY (running_activity_id);
+
+ALTER TABLE running_activities
+ADD CONSTRAINT fk_running_activities_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+ALTER TABLE running_activities
+ADD CONSTRAINT fk_running_activities_project
+FOREIGN KEY (project_id) REFERENCES projects (project_id);
+
+CREATE UNIQUE INDEX running_activities_idx_user
+ON running_activities (org_id, username);
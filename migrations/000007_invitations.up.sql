@@ -0,0 +1,18 @@
+-- Table invitations
+CREATE TABLE invitations (
+     invitation_id  uuid not null,
+     email          varchar(100) not null,
+     org_id         uuid not null,
+     created_by     varchar(50) not null,
+     created_at     timestamptz not null
+);
+
+ALTER TABLE invitations
+ADD CONSTRAINT pk_invitations PRIMARY KEY (invitation_id);
+
+ALTER TABLE invitations
+ADD CONSTRAINT fk_invitations_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE UNIQUE INDEX invitations_idx_email
+ON invitations (org_id, email);
@@ -0,0 +1,45 @@
+-- Hourly rates of projects
+ALTER TABLE projects ADD hourly_rate numeric(12,2) not null DEFAULT 0;
+ALTER TABLE projects ADD currency varchar(3) not null DEFAULT 'EUR';
+
+-- Table project_user_rates
+CREATE TABLE project_user_rates (
+     project_id   uuid not null,
+     username     varchar(50) not null,
+     hourly_rate  numeric(12,2) not null,
+     org_id       uuid not null
+);
+
+ALTER TABLE project_user_rates
+ADD CONSTRAINT pk_project_user_rates PRIMARY KEY (project_id, username);
+
+ALTER TABLE project_user_rates
+ADD CONSTRAINT fk_project_user_rates_project
+FOREIGN KEY (project_id) REFERENCES projects (project_id) ON DELETE CASCADE;
+
+ALTER TABLE project_user_rates
+ADD CONSTRAINT fk_project_user_rates_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+-- Billable activities
+ALTER TABLE activities ADD billable boolean not null DEFAULT true;
+
+CREATE OR REPLACE VIEW activities_agg as
+SELECT
+  activities.activity_id,
+  activities.project_id,
+  activities.org_id,
+  activities.username,
+  activities.start_time,
+  activities.end_time,
+  EXTRACT(day from start_time) as day, 
+  EXTRACT(week from start_time) as week, 
+  EXTRACT(month from start_time) as month, 
+  EXTRACT(quarter from start_time) as quarter, 
+  EXTRACT(year from start_time) as year, 
+  EXTRACT(minute from end_time - start_time) as duration_minutes, 
+  EXTRACT(hour from end_time - start_time) as duration_hours,
+  EXTRACT(hour from end_time - start_time) * 60 + EXTRACT(minute from end_time - start_time) as duration_minutes_total,
+  activities.billable
+FROM 
+  activities
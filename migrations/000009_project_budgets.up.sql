@@ -0,0 +1,24 @@
+-- Budgets of projects
+ALTER TABLE projects ADD budget_type varchar(10);
+ALTER TABLE projects ADD budget numeric(12,2) not null DEFAULT 0;
+ALTER TABLE projects ADD budget_period varchar(10) not null DEFAULT 'total';
+
+-- Table project_budget_alerts
+CREATE TABLE project_budget_alerts (
+     project_id    uuid not null,
+     period_start  timestamptz not null,
+     threshold     integer not null,
+     org_id        uuid not null,
+     created_at    timestamptz not null
+);
+
+ALTER TABLE project_budget_alerts
+ADD CONSTRAINT pk_project_budget_alerts PRIMARY KEY (project_id, period_start, threshold);
+
+ALTER TABLE project_budget_alerts
+ADD CONSTRAINT fk_project_budget_alerts_project
+FOREIGN KEY (project_id) REFERENCES projects (project_id) ON DELETE CASCADE;
+
+ALTER TABLE project_budget_alerts
+ADD CONSTRAINT fk_project_budget_alerts_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
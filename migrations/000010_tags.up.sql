@@ -0,0 +1,37 @@
+-- Table tags
+CREATE TABLE tags (
+     tag_id   uuid not null,
+     name     varchar(50) not null,
+     org_id   uuid not null
+);
+
+ALTER TABLE tags
+ADD CONSTRAINT pk_tags PRIMARY KEY (tag_id);
+
+ALTER TABLE tags
+ADD CONSTRAINT fk_tags_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+ALTER TABLE tags
+ADD CONSTRAINT uq_tags_name UNIQUE (org_id, name);
+
+-- Table activity_tags
+CREATE TABLE activity_tags (
+     activity_id  uuid not null,
+     tag_id       uuid not null,
+     org_id       uuid not null
+);
+
+ALTER TABLE activity_tags
+ADD CONSTRAINT pk_activity_tags PRIMARY KEY (activity_id, tag_id);
+
+ALTER TABLE activity_tags
+ADD CONSTRAINT fk_activity_tags_activity
+FOREIGN KEY (activity_id) REFERENCES activities (activity_id) ON DELETE CASCADE;
+
+ALTER TABLE activity_tags
+ADD CONSTRAINT fk_activity_tags_tag
+FOREIGN KEY (tag_id) REFERENCES tags (tag_id) ON DELETE CASCADE;
+
+CREATE INDEX activity_tags_idx_tag_id
+ON activity_tags (tag_id);
@@ -0,0 +1,30 @@
+-- Table api_tokens
+CREATE TABLE api_tokens (
+     api_token_id   uuid not null,
+     name           varchar(100) not null,
+     token_hash     varchar(64) not null,
+     token_prefix   varchar(12) not null,
+     scope          varchar(10) not null,
+     user_id        uuid not null,
+     org_id         uuid not null,
+     created_at     timestamptz not null,
+     last_used_at   timestamptz,
+     expires_at     timestamptz
+);
+
+ALTER TABLE api_tokens
+ADD CONSTRAINT pk_api_tokens PRIMARY KEY (api_token_id);
+
+ALTER TABLE api_tokens
+ADD CONSTRAINT fk_api_tokens_users
+FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE;
+
+ALTER TABLE api_tokens
+ADD CONSTRAINT fk_api_tokens_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE UNIQUE INDEX api_tokens_idx_token_hash
+ON api_tokens (token_hash);
+
+CREATE INDEX api_tokens_idx_user_id
+ON api_tokens (org_id, user_id);
@@ -0,0 +1,2 @@
+-- Calendar (ICS or CalDAV) of a user to import events as activities from
+ALTER TABLE users ADD calendar_url varchar(500);
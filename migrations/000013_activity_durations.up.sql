@@ -0,0 +1,22 @@
+-- Durations of activities spanning midnight and multiple days
+DROP VIEW activities_agg;
+
+CREATE VIEW activities_agg as
+SELECT
+  activities.activity_id,
+  activities.project_id,
+  activities.org_id,
+  activities.username,
+  activities.start_time,
+  activities.end_time,
+  EXTRACT(day from start_time) as day, 
+  EXTRACT(week from start_time) as week, 
+  EXTRACT(month from start_time) as month, 
+  EXTRACT(quarter from start_time) as quarter, 
+  EXTRACT(year from start_time) as year, 
+  FLOOR(EXTRACT(epoch from end_time - start_time) / 60)::integer % 60 as duration_minutes, 
+  FLOOR(EXTRACT(epoch from end_time - start_time) / 3600)::integer as duration_hours,
+  FLOOR(EXTRACT(epoch from end_time - start_time) / 60)::integer as duration_minutes_total,
+  activities.billable
+FROM 
+  activities
@@ -0,0 +1,26 @@
+-- Table timesheets with the approval of the activities of a user per ISO week
+CREATE TABLE timesheets (
+     timesheet_id   uuid not null,
+     username       varchar(50) not null,
+     year           integer not null,
+     week           integer not null,
+     status         varchar(10) not null,
+     comment        varchar(500),
+     submitted_at   timestamptz,
+     reviewed_by    varchar(50),
+     reviewed_at    timestamptz,
+     org_id         uuid not null
+);
+
+ALTER TABLE timesheets
+ADD CONSTRAINT pk_timesheets PRIMARY KEY (timesheet_id);
+
+ALTER TABLE timesheets
+ADD CONSTRAINT fk_timesheets_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE UNIQUE INDEX timesheets_idx_week
+ON timesheets (org_id, username, year, week);
+
+CREATE INDEX timesheets_idx_status
+ON timesheets (org_id, status);
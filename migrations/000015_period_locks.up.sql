@@ -0,0 +1,2 @@
+-- Lock date of organizations, activities up to and including it are closed
+ALTER TABLE organizations ADD locked_until date;
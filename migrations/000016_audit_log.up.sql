@@ -0,0 +1,25 @@
+-- Table audit_logs with the append-only log of changes to activities, projects and user roles
+CREATE TABLE audit_logs (
+     audit_log_id   uuid not null,
+     username       varchar(50) not null,
+     action         varchar(20) not null,
+     entity_type    varchar(20) not null,
+     entity_id      uuid not null,
+     before         jsonb,
+     after          jsonb,
+     created_at     timestamptz not null,
+     org_id         uuid not null
+);
+
+ALTER TABLE audit_logs
+ADD CONSTRAINT pk_audit_logs PRIMARY KEY (audit_log_id);
+
+ALTER TABLE audit_logs
+ADD CONSTRAINT fk_audit_logs_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE INDEX audit_logs_idx_created
+ON audit_logs (org_id, created_at);
+
+CREATE INDEX audit_logs_idx_entity
+ON audit_logs (org_id, entity_type, entity_id);
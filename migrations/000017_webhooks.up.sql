@@ -0,0 +1,49 @@
+-- Table webhooks with the urls the events of an organization are pushed to
+CREATE TABLE webhooks (
+     webhook_id     uuid not null,
+     url            varchar(500) not null,
+     secret         varchar(100) not null,
+     event_types    text[] not null,
+     created_at     timestamptz not null,
+     org_id         uuid not null
+);
+
+ALTER TABLE webhooks
+ADD CONSTRAINT pk_webhooks PRIMARY KEY (webhook_id);
+
+ALTER TABLE webhooks
+ADD CONSTRAINT fk_webhooks_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+-- Table webhook_deliveries is the queue of events to deliver to webhooks and their delivery history
+CREATE TABLE webhook_deliveries (
+     delivery_id      uuid not null,
+     webhook_id       uuid not null,
+     event_type       varchar(50) not null,
+     payload          jsonb not null,
+     status           varchar(10) not null,
+     attempts         integer not null default 0,
+     next_attempt_at  timestamptz,
+     last_attempt_at  timestamptz,
+     response_status  integer,
+     last_error       varchar(500),
+     created_at       timestamptz not null,
+     org_id           uuid not null
+);
+
+ALTER TABLE webhook_deliveries
+ADD CONSTRAINT pk_webhook_deliveries PRIMARY KEY (delivery_id);
+
+ALTER TABLE webhook_deliveries
+ADD CONSTRAINT fk_webhook_deliveries_webhooks
+FOREIGN KEY (webhook_id) REFERENCES webhooks (webhook_id) ON DELETE CASCADE;
+
+ALTER TABLE webhook_deliveries
+ADD CONSTRAINT fk_webhook_deliveries_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE INDEX webhook_deliveries_idx_due
+ON webhook_deliveries (status, next_attempt_at);
+
+CREATE INDEX webhook_deliveries_idx_webhook
+ON webhook_deliveries (org_id, webhook_id, created_at);
@@ -0,0 +1,31 @@
+-- Table clients with the customers projects are done for
+CREATE TABLE clients (
+     client_id      uuid not null,
+     title          varchar(100) not null,
+     description    varchar(500) null,
+     org_id         uuid not null
+);
+
+ALTER TABLE clients
+ADD CONSTRAINT pk_clients PRIMARY KEY (client_id);
+
+ALTER TABLE clients
+ADD CONSTRAINT fk_clients_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE INDEX idx_clients_org_id ON clients (org_id);
+
+-- Projects optionally belong to a client and to a parent project
+ALTER TABLE projects
+ADD COLUMN client_id uuid null;
+
+ALTER TABLE projects
+ADD COLUMN parent_id uuid null;
+
+ALTER TABLE projects
+ADD CONSTRAINT fk_projects_clients
+FOREIGN KEY (client_id) REFERENCES clients (client_id) ON DELETE SET NULL;
+
+ALTER TABLE projects
+ADD CONSTRAINT fk_projects_parent
+FOREIGN KEY (parent_id) REFERENCES projects (project_id) ON DELETE SET NULL;
@@ -0,0 +1,21 @@
+-- Table project_members
+CREATE TABLE project_members (
+     project_id   uuid not null,
+     username     varchar(50) not null,
+     role         varchar(50) not null DEFAULT 'member',
+     org_id       uuid not null
+);
+
+ALTER TABLE project_members
+ADD CONSTRAINT pk_project_members PRIMARY KEY (project_id, username);
+
+ALTER TABLE project_members
+ADD CONSTRAINT fk_project_members_project
+FOREIGN KEY (project_id) REFERENCES projects (project_id) ON DELETE CASCADE;
+
+ALTER TABLE project_members
+ADD CONSTRAINT fk_project_members_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE INDEX project_members_idx_username
+ON project_members (org_id, username);
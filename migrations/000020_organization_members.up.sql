@@ -0,0 +1,24 @@
+-- Table organization_members with the organizations of users, their roles
+-- per organization stay in roles and users.org_id is the one they sign in to
+CREATE TABLE organization_members (
+     user_id      uuid not null,
+     org_id       uuid not null,
+     created_at   timestamptz not null DEFAULT now()
+);
+
+ALTER TABLE organization_members
+ADD CONSTRAINT pk_organization_members PRIMARY KEY (user_id, org_id);
+
+ALTER TABLE organization_members
+ADD CONSTRAINT fk_organization_members_users
+FOREIGN KEY (user_id) REFERENCES users (user_id);
+
+ALTER TABLE organization_members
+ADD CONSTRAINT fk_organization_members_orgs
+FOREIGN KEY (org_id) REFERENCES organizations (org_id);
+
+CREATE INDEX organization_members_idx_org_id
+ON organization_members (org_id);
+
+INSERT INTO organization_members (user_id, org_id)
+  SELECT user_id, org_id FROM users;
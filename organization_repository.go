@@ -2,13 +2,22 @@ package main
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
 )
 
+var ErrOrganizationNotFound = errors.New("organization not found")
+
 type OrganizationRepository interface {
 	InsertOrganization(ctx context.Context, organization *Organization) (*Organization, error)
+	FindOrganizationByID(ctx context.Context, organizationID uuid.UUID) (*Organization, error)
+	UpdateOrganizationLockedUntil(ctx context.Context, organizationID uuid.UUID, lockedUntil *time.Time) error
+	FindOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]*Organization, error)
+	InsertOrganizationMember(ctx context.Context, member *OrganizationMember) error
 }
 
 // DbOrganizationRepository is a SQL database repository for users
@@ -39,3 +48,118 @@ func (r *DbOrganizationRepository) InsertOrganization(ctx context.Context, organ
 	)
 	return organization, err
 }
+
+func (r *DbOrganizationRepository) FindOrganizationByID(ctx context.Context, organizationID uuid.UUID) (*Organization, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT org_id, title, locked_until
+		 FROM organizations
+		 WHERE org_id = $1`,
+		organizationID,
+	)
+
+	organization := &Organization{}
+	err := row.Scan(
+		&organization.ID,
+		&organization.Title,
+		&organization.LockedUntil,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrganizationNotFound
+		}
+
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (r *DbOrganizationRepository) UpdateOrganizationLockedUntil(ctx context.Context, organizationID uuid.UUID, lockedUntil *time.Time) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`UPDATE organizations
+		 SET locked_until = $2
+		 WHERE org_id = $1
+		 RETURNING org_id`,
+		organizationID, lockedUntil,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrganizationNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *DbOrganizationRepository) FindOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]*Organization, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT o.org_id, o.title, o.locked_until
+		 FROM organizations o
+		 INNER JOIN organization_members m ON m.org_id = o.org_id
+		 WHERE m.user_id = $1
+		 ORDER BY o.title ASC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var organizations []*Organization
+	for rows.Next() {
+		organization := &Organization{}
+		err = rows.Scan(
+			&organization.ID,
+			&organization.Title,
+			&organization.LockedUntil,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		organizations = append(organizations, organization)
+	}
+
+	return organizations, nil
+}
+
+func (r *DbOrganizationRepository) InsertOrganizationMember(ctx context.Context, member *OrganizationMember) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO organization_members
+		   (user_id, org_id)
+		 VALUES
+		   ($1, $2)
+		 ON CONFLICT (user_id, org_id) DO NOTHING`,
+		member.UserID,
+		member.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO roles
+		   (user_id, role, org_id)
+		 VALUES
+		   ($1, $2, $3)
+		 ON CONFLICT (user_id, role, org_id) DO NOTHING`,
+		member.UserID,
+		member.Role,
+		member.OrganizationID,
+	)
+	return err
+}
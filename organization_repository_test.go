@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -51,10 +52,73 @@ func TestOrganizationRepository(t *testing.T) {
 		)
 		is.NoErr(err)
 	})
+
+	t.Run("UpdateAndFindOrganizationLockedUntil", func(t *testing.T) {
+		lockedUntil := time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC)
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return organizationRepository.UpdateOrganizationLockedUntil(ctx, organizationIDSample, &lockedUntil)
+			},
+		)
+		is.NoErr(err)
+
+		organization, err := organizationRepository.FindOrganizationByID(context.Background(), organizationIDSample)
+		is.NoErr(err)
+		is.True(organization.LockedUntil != nil)
+		is.True(lockedUntil.Equal(*organization.LockedUntil))
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return organizationRepository.UpdateOrganizationLockedUntil(ctx, organizationIDSample, nil)
+			},
+		)
+		is.NoErr(err)
+
+		organization, err = organizationRepository.FindOrganizationByID(context.Background(), organizationIDSample)
+		is.NoErr(err)
+		is.True(organization.LockedUntil == nil)
+	})
+
+	t.Run("InsertAndFindOrganizationMember", func(t *testing.T) {
+		userID := uuid.MustParse("04b4adc8-2b7f-4ec0-aeb8-407ce164484e")
+		organization := &Organization{
+			ID:    uuid.New(),
+			Title: "My Other Organization" + time.Now().String(),
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := organizationRepository.InsertOrganization(ctx, organization)
+				if err != nil {
+					return err
+				}
+				return organizationRepository.InsertOrganizationMember(ctx, &OrganizationMember{
+					OrganizationID: organization.ID,
+					UserID:         userID,
+					Role:           "ROLE_ADMIN",
+				})
+			},
+		)
+		is.NoErr(err)
+
+		organizations, err := organizationRepository.FindOrganizationsByUserID(context.Background(), userID)
+		is.NoErr(err)
+		is.Equal(2, len(organizations))
+	})
+
+	t.Run("FindNotExistingOrganization", func(t *testing.T) {
+		_, err := organizationRepository.FindOrganizationByID(context.Background(), uuid.New())
+		is.True(errors.Is(err, ErrOrganizationNotFound))
+	})
 }
 
 type InMemOrganizationRepository struct {
 	organizations []*Organization
+	members       []*OrganizationMember
 }
 
 var _ OrganizationRepository = (*InMemOrganizationRepository)(nil)
@@ -74,3 +138,47 @@ func (r *InMemOrganizationRepository) InsertOrganization(ctx context.Context, or
 	r.organizations = append(r.organizations, organization)
 	return organization, nil
 }
+
+func (r *InMemOrganizationRepository) FindOrganizationByID(ctx context.Context, organizationID uuid.UUID) (*Organization, error) {
+	for _, o := range r.organizations {
+		if o.ID == organizationID {
+			return o, nil
+		}
+	}
+	return nil, ErrOrganizationNotFound
+}
+
+func (r *InMemOrganizationRepository) UpdateOrganizationLockedUntil(ctx context.Context, organizationID uuid.UUID, lockedUntil *time.Time) error {
+	for _, o := range r.organizations {
+		if o.ID == organizationID {
+			o.LockedUntil = lockedUntil
+			return nil
+		}
+	}
+	return ErrOrganizationNotFound
+}
+
+func (r *InMemOrganizationRepository) FindOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]*Organization, error) {
+	var organizations []*Organization
+	for _, m := range r.members {
+		if m.UserID != userID {
+			continue
+		}
+		for _, o := range r.organizations {
+			if o.ID == m.OrganizationID {
+				organizations = append(organizations, o)
+			}
+		}
+	}
+	return organizations, nil
+}
+
+func (r *InMemOrganizationRepository) InsertOrganizationMember(ctx context.Context, member *OrganizationMember) error {
+	for _, m := range r.members {
+		if m.UserID == member.UserID && m.OrganizationID == member.OrganizationID {
+			return nil
+		}
+	}
+	r.members = append(r.members, member)
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrPeriodLocked = errors.New("period locked")
+
+// ReadOrganization reads the organization of the principal
+func (a *app) ReadOrganization(ctx context.Context, principal *Principal) (*Organization, error) {
+	return a.OrganizationRepository.FindOrganizationByID(ctx, principal.OrganizationID)
+}
+
+// ReadOrganizations reads the organizations the principal is a member of
+func (a *app) ReadOrganizations(ctx context.Context, principal *Principal) ([]*Organization, error) {
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.OrganizationRepository.FindOrganizationsByUserID(ctx, user.ID)
+}
+
+// SwitchOrganization switches the principal into another organization it is a member of,
+// where it has the roles of the user in that organization
+func (a *app) SwitchOrganization(ctx context.Context, principal *Principal, organizationID uuid.UUID) (*Principal, error) {
+	user, err := a.UserRepository.FindUserByUsername(ctx, principal.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations, err := a.OrganizationRepository.FindOrganizationsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := false
+	for _, organization := range organizations {
+		isMember = isMember || organization.ID == organizationID
+	}
+	if !isMember {
+		return nil, ErrOrganizationNotFound
+	}
+
+	roles, err := a.UserRepository.FindRolesByUserID(ctx, organizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	principalSwitched := mapUserToPrincipal(user, roles)
+	principalSwitched.OrganizationID = organizationID
+	return principalSwitched, nil
+}
+
+// UpdateLockedUntil closes the period of the principal's organization up to and including
+// the given day, without a day all periods are open again
+func (a *app) UpdateLockedUntil(ctx context.Context, principal *Principal, lockedUntil *time.Time) (*Organization, error) {
+	err := a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.OrganizationRepository.UpdateOrganizationLockedUntil(ctx, principal.OrganizationID, lockedUntil)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.ReadOrganization(ctx, principal)
+}
+
+// readLockingOrganization reads the organization whose closed period locks the activities
+// of the principal, admins override the lock so there is none for them. Closed periods
+// are settings of the whole organization, so managers of projects don't override them.
+func (a *app) readLockingOrganization(ctx context.Context, principal *Principal) (*Organization, error) {
+	if principal.HasRole("ROLE_ADMIN") {
+		return nil, nil
+	}
+
+	return a.ReadOrganization(ctx, principal)
+}
+
+// checkPeriodLocked checks whether an activity starting at the given time lies within
+// the closed period of the principal's organization
+func (a *app) checkPeriodLocked(ctx context.Context, principal *Principal, start time.Time) error {
+	organization, err := a.readLockingOrganization(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	if organization != nil && organization.IsLockedAt(start, principal.Location()) {
+		return ErrPeriodLocked
+	}
+
+	return nil
+}
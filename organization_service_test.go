@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestOrganizationIsLockedAt(t *testing.T) {
+	is := is.New(t)
+
+	lockedUntil := time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC)
+	organization := &Organization{LockedUntil: &lockedUntil}
+
+	is.True(organization.IsLockedAt(time.Date(2021, 11, 30, 23, 59, 0, 0, time.UTC), time.UTC))
+	is.True(!organization.IsLockedAt(time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.UTC))
+	is.True(!(&Organization{}).IsLockedAt(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), time.UTC))
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	is.True(!organization.IsLockedAt(time.Date(2021, 11, 30, 23, 30, 0, 0, time.UTC), berlin))
+}
+
+func TestClosedPeriodLocksActivities(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	organizationRepository := NewInMemOrganizationRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     NewInMemActivityRepository(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: organizationRepository,
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+	user := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}
+
+	lockedUntil := time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC)
+	organization, err := a.UpdateLockedUntil(context.Background(), admin, &lockedUntil)
+	is.NoErr(err)
+	is.Equal(lockedUntil, *organization.LockedUntil)
+
+	activityInPeriod := &Activity{
+		Start: time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	_, errUser := a.CreateActivity(context.Background(), user, activityInPeriod)
+	_, errAdmin := a.CreateActivity(context.Background(), admin, activityInPeriod)
+
+	_, err = a.UpdateLockedUntil(context.Background(), admin, nil)
+	is.NoErr(err)
+	_, errUserReopened := a.CreateActivity(context.Background(), user, activityInPeriod)
+
+	// Assert
+	is.True(errors.Is(errUser, ErrPeriodLocked))
+	is.NoErr(errAdmin)
+	is.NoErr(errUserReopened)
+}
+
+func TestSwitchOrganization(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	userID := uuid.MustParse("00000000-0000-0000-1111-000000000001")
+	otherOrganization := &Organization{
+		ID:    uuid.New(),
+		Title: "Other Organization",
+	}
+	foreignOrganization := &Organization{
+		ID:    uuid.New(),
+		Title: "Foreign Organization",
+	}
+
+	organizationRepository := NewInMemOrganizationRepository()
+	organizationRepository.organizations = append(organizationRepository.organizations, otherOrganization, foreignOrganization)
+	organizationRepository.members = []*OrganizationMember{
+		{OrganizationID: organizationIDSample, UserID: userID, Role: "ROLE_ADMIN"},
+		{OrganizationID: otherOrganization.ID, UserID: userID, Role: "ROLE_USER"},
+	}
+
+	userRepository := NewInMemUserRepository()
+	userRepository.roles[userID] = []string{"ROLE_USER"}
+
+	a := &app{
+		UserRepository:         userRepository,
+		OrganizationRepository: organizationRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	organizations, errRead := a.ReadOrganizations(context.Background(), principal)
+	principalSwitched, errSwitch := a.SwitchOrganization(context.Background(), principal, otherOrganization.ID)
+	_, errForeign := a.SwitchOrganization(context.Background(), principal, foreignOrganization.ID)
+
+	// Assert
+	is.NoErr(errRead)
+	is.Equal(2, len(organizations))
+
+	is.NoErr(errSwitch)
+	is.Equal(otherOrganization.ID, principalSwitched.OrganizationID)
+	is.Equal("admin@baralga.com", principalSwitched.Username)
+	is.Equal([]string{"ROLE_USER"}, principalSwitched.Roles)
+
+	is.True(errors.Is(errForeign, ErrOrganizationNotFound))
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type periodLockFormModel struct {
+	CSRFToken   string
+	LockedUntil string
+	Action      string
+}
+
+type organizationSwitchFormModel struct {
+	CSRFToken      string
+	OrganizationID string
+}
+
+// HandleOrganizationSwitcher renders the switcher of the navbar between the organizations of the principal
+func (a *app) HandleOrganizationSwitcher() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		organizations, err := a.ReadOrganizations(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		util.RenderHTML(w, OrganizationSwitcher(principal, csrf.Token(r), organizations))
+	}
+}
+
+// HandleOrganizationSwitchForm switches the principal into the selected organization
+// and reissues the cookie for it
+func (a *app) HandleOrganizationSwitchForm(tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+	isProduction := a.isProduction()
+	expiryDuration := a.Config.ExpiryDuration()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, "Please select an organization.", http.StatusBadRequest)
+			return
+		}
+
+		var formModel organizationSwitchFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			http.Error(w, "Please select an organization.", http.StatusBadRequest)
+			return
+		}
+
+		organizationID, err := uuid.Parse(formModel.OrganizationID)
+		if err != nil {
+			http.Error(w, "Please select an organization.", http.StatusBadRequest)
+			return
+		}
+
+		principalSwitched, err := a.SwitchOrganization(r.Context(), principal, organizationID)
+		if errors.Is(err, ErrOrganizationNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		cookie := a.CreateCookie(tokenAuth, expiryDuration, principalSwitched)
+		http.SetCookie(w, &cookie)
+
+		if hx.IsHXRequest(r) {
+			w.Header().Set("HX-Redirect", "/")
+			return
+		}
+
+		http.Redirect(w, r, "/", http.StatusFound)
+	}
+}
+
+func (a *app) HandlePeriodLockPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		organization, err := a.ReadOrganization(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Closed Periods",
+		}
+
+		formModel := periodLockFormModel{
+			CSRFToken: csrf.Token(r),
+		}
+		if organization.LockedUntil != nil {
+			formModel.LockedUntil = util.FormatDate(*organization.LockedUntil)
+		}
+
+		util.RenderHTML(w, PeriodLockPage(pageContext, formModel, &settingsParams{}))
+	}
+}
+
+func (a *app) HandlePeriodLockForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			util.RenderHTML(w, PeriodLockForm(periodLockFormModel{CSRFToken: csrf.Token(r)}, &settingsParams{}))
+			return
+		}
+
+		var formModel periodLockFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			util.RenderHTML(w, PeriodLockForm(periodLockFormModel{CSRFToken: csrf.Token(r)}, &settingsParams{}))
+			return
+		}
+		formModel.CSRFToken = csrf.Token(r)
+
+		var lockedUntil *time.Time
+		if formModel.Action != "reopen" {
+			lockedUntil, err = util.ParseDate(formModel.LockedUntil)
+			if err != nil {
+				util.RenderHTML(w, PeriodLockForm(formModel, &settingsParams{
+					errorMessage: "Please enter the last day of the closed period.",
+				}))
+				return
+			}
+		}
+
+		organization, err := a.UpdateLockedUntil(r.Context(), principal, lockedUntil)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		params := &settingsParams{
+			infoMessage: "All periods are open.",
+		}
+		formModel.LockedUntil = ""
+		if organization.LockedUntil != nil {
+			formModel.LockedUntil = util.FormatDate(*organization.LockedUntil)
+			params.infoMessage = "Activities up to " + util.FormatDateDE(*organization.LockedUntil) + " are locked."
+		}
+
+		util.RenderHTML(w, PeriodLockForm(formModel, params))
+	}
+}
+
+func PeriodLockPage(pageContext *pageContext, formModel periodLockFormModel, params *settingsParams) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Closed Periods")),
+						P(
+							Class("text-muted"),
+							g.Text("Activities up to and including the given day are locked. Only admins can still change them."),
+						),
+					),
+					PeriodLockForm(formModel, params),
+				),
+			),
+		},
+	)
+}
+
+func PeriodLockForm(formModel periodLockFormModel, params *settingsParams) g.Node {
+	var reopenButton g.Node
+	if formModel.LockedUntil != "" {
+		reopenButton = Button(
+			Type("submit"),
+			Name("Action"),
+			Value("reopen"),
+			Class("btn btn-outline-secondary me-2"),
+			g.Attr("formnovalidate", "formnovalidate"),
+			I(Class("bi-unlock me-2")),
+			g.Text("Reopen All"),
+		)
+	}
+
+	return FormEl(
+		ID("period_lock_form"),
+		hx.Post("/settings/periods"),
+		hx.Swap("outerHTML"),
+		g.If(
+			params.errorMessage != "",
+			Div(
+				Class("alert alert-warning text-center"),
+				Role("alert"),
+				Span(g.Text(params.errorMessage)),
+			),
+		),
+		g.If(
+			params.infoMessage != "",
+			Div(
+				Class("alert alert-success text-center"),
+				Role("alert"),
+				Span(g.Text(params.infoMessage)),
+			),
+		),
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("locked_until"),
+				Type("date"),
+				Name("LockedUntil"),
+				Class("form-control"),
+				g.Attr("required", "required"),
+				Value(formModel.LockedUntil),
+			),
+			Label(
+				g.Attr("for", "locked_until"),
+				g.Text("Closed until"),
+			),
+		),
+		Div(
+			Class("text-end"),
+			reopenButton,
+			Button(
+				Type("submit"),
+				Name("Action"),
+				Value("close"),
+				Class("btn btn-primary"),
+				I(Class("bi-lock me-2")),
+				g.Text("Close Period"),
+			),
+		),
+	)
+}
+
+// OrganizationSwitcher renders the dropdown of the navbar to switch between organizations,
+// which is only shown to members of several organizations
+func OrganizationSwitcher(principal *Principal, csrfToken string, organizations []*Organization) g.Node {
+	if len(organizations) < 2 {
+		return g.Text("")
+	}
+
+	currentTitle := ""
+	for _, organization := range organizations {
+		if organization.ID == principal.OrganizationID {
+			currentTitle = organization.Title
+		}
+	}
+
+	return Li(
+		ID("baralga__organization_switcher"),
+		Class("nav-item dropdown col-6 col-md-auto"),
+		A(
+			Class("nav-link dropdown-toggle"),
+			Href("#"),
+			Role("button"),
+			g.Attr("data-bs-toggle", "dropdown"),
+			TitleAttr("Switch Organization"),
+			I(Class("bi-building me-2")),
+			g.Text(currentTitle),
+		),
+		Ul(
+			Class("dropdown-menu dropdown-menu-end"),
+			g.Group(g.Map(len(organizations), func(i int) g.Node {
+				organization := organizations[i]
+
+				itemClass := "dropdown-item"
+				if organization.ID == principal.OrganizationID {
+					itemClass = "dropdown-item active"
+				}
+
+				return Li(
+					FormEl(
+						Action("/organizations/switch"),
+						Method("POST"),
+						Input(
+							Type("hidden"),
+							Name("CSRFToken"),
+							Value(csrfToken),
+						),
+						Input(
+							Type("hidden"),
+							Name("OrganizationID"),
+							Value(organization.ID.String()),
+						),
+						Button(
+							Type("submit"),
+							Class(itemClass),
+							g.Text(organization.Title),
+						),
+					),
+				)
+			})),
+		),
+	)
+}
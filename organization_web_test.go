@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandlePeriodLockPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                 &config{},
+		OrganizationRepository: NewInMemOrganizationRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/settings/periods", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandlePeriodLockPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Closed Periods # Baralga"))
+}
+
+func TestHandlePeriodLockPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                 &config{},
+		OrganizationRepository: NewInMemOrganizationRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/settings/periods", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandlePeriodLockPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandlePeriodLockForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	organizationRepository := NewInMemOrganizationRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		OrganizationRepository: organizationRepository,
+	}
+
+	data := url.Values{}
+	data["LockedUntil"] = []string{"2021-11-30"}
+	data["Action"] = []string{"close"}
+
+	r, _ := http.NewRequest("POST", "/settings/periods", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandlePeriodLockForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(organizationRepository.organizations[0].LockedUntil != nil)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Activities up to 30.11.2021 are locked."))
+}
+
+func TestHandleOrganizationSwitcher(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userID := uuid.MustParse("00000000-0000-0000-1111-000000000001")
+	otherOrganization := &Organization{
+		ID:    uuid.New(),
+		Title: "Other Organization",
+	}
+	organizationRepository := NewInMemOrganizationRepository()
+	organizationRepository.organizations = append(organizationRepository.organizations, otherOrganization)
+	organizationRepository.members = []*OrganizationMember{
+		{OrganizationID: organizationIDSample, UserID: userID, Role: "ROLE_ADMIN"},
+		{OrganizationID: otherOrganization.ID, UserID: userID, Role: "ROLE_USER"},
+	}
+
+	a := &app{
+		Config:                 &config{},
+		UserRepository:         NewInMemUserRepository(),
+		OrganizationRepository: organizationRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/organizations/switcher", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleOrganizationSwitcher()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Test Organization"))
+	is.True(strings.Contains(htmlBody, "Other Organization"))
+	is.True(strings.Contains(htmlBody, otherOrganization.ID.String()))
+}
+
+func TestHandleOrganizationSwitcherWithSingleOrganization(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                 &config{},
+		UserRepository:         NewInMemUserRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/organizations/switcher", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleOrganizationSwitcher()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal("", httpRec.Body.String())
+}
+
+func TestHandleOrganizationSwitchForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userID := uuid.MustParse("00000000-0000-0000-1111-000000000001")
+	otherOrganization := &Organization{
+		ID:    uuid.New(),
+		Title: "Other Organization",
+	}
+	organizationRepository := NewInMemOrganizationRepository()
+	organizationRepository.organizations = append(organizationRepository.organizations, otherOrganization)
+	organizationRepository.members = []*OrganizationMember{
+		{OrganizationID: organizationIDSample, UserID: userID, Role: "ROLE_ADMIN"},
+		{OrganizationID: otherOrganization.ID, UserID: userID, Role: "ROLE_USER"},
+	}
+
+	a := &app{
+		Config:                 &config{},
+		UserRepository:         NewInMemUserRepository(),
+		OrganizationRepository: organizationRepository,
+	}
+
+	data := url.Values{}
+	data["OrganizationID"] = []string{otherOrganization.ID.String()}
+
+	r, _ := http.NewRequest("POST", "/organizations/switch", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	a.HandleOrganizationSwitchForm(tokenAuth)(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusFound)
+	is.Equal("/", httpRec.Header().Get("Location"))
+
+	cookies := httpRec.Result().Cookies()
+	is.Equal(1, len(cookies))
+	is.Equal("jwt", cookies[0].Name)
+
+	token, err := tokenAuth.Decode(cookies[0].Value)
+	is.NoErr(err)
+	organizationID, _ := token.Get("organizationId")
+	is.Equal(otherOrganization.ID.String(), organizationID)
+}
+
+func TestHandleOrganizationSwitchFormToForeignOrganization(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                 &config{},
+		UserRepository:         NewInMemUserRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+	}
+
+	data := url.Values{}
+	data["OrganizationID"] = []string{uuid.New().String()}
+
+	r, _ := http.NewRequest("POST", "/organizations/switch", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleOrganizationSwitchForm(jwtauth.New("HS256", []byte("secret"), nil))(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+	is.Equal(0, len(httpRec.Result().Cookies()))
+}
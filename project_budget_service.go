@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReadBudgetConsumptions reads the consumed budgets of the projects with a budget in
+// their current budget period, using the aggregation of the project report
+func (a *app) ReadBudgetConsumptions(ctx context.Context, principal *Principal, projects []*Project) (map[uuid.UUID]*ProjectBudgetConsumption, error) {
+	now := time.Now().In(principal.Location())
+	consumptions := make(map[uuid.UUID]*ProjectBudgetConsumption)
+	reportsByPeriod := make(map[string]map[uuid.UUID]*ActivityProjectReportItem)
+
+	for _, project := range projects {
+		if !project.HasBudget() {
+			continue
+		}
+
+		period := project.BudgetPeriodOrDefault()
+		start, end := project.BudgetPeriodAt(now)
+
+		if _, ok := reportsByPeriod[period]; !ok {
+			reportItems, err := a.ActivityRepository.ProjectReport(ctx, &ActivitiesFilter{
+				Start:          start,
+				End:            end,
+				Timezone:       principal.Location().String(),
+				OrganizationID: principal.OrganizationID,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			reportItemsByProject := make(map[uuid.UUID]*ActivityProjectReportItem)
+			for _, reportItem := range reportItems {
+				reportItemsByProject[reportItem.ProjectID] = reportItem
+			}
+			reportsByPeriod[period] = reportItemsByProject
+		}
+
+		consumption := &ProjectBudgetConsumption{
+			Project:     project,
+			PeriodStart: start,
+			PeriodEnd:   end,
+		}
+		if reportItem, ok := reportsByPeriod[period][project.ID]; ok {
+			consumption.DurationInMinutesTotal = reportItem.DurationInMinutesTotal
+			consumption.Revenue = reportItem.Revenue
+		}
+		consumptions[project.ID] = consumption
+	}
+
+	return consumptions, nil
+}
+
+// ReadBudgetConsumption reads the consumed budget of the project in the current budget period
+func (a *app) ReadBudgetConsumption(ctx context.Context, principal *Principal, project *Project) (*ProjectBudgetConsumption, error) {
+	consumptions, err := a.ReadBudgetConsumptions(ctx, principal, []*Project{project})
+	if err != nil {
+		return nil, err
+	}
+	return consumptions[project.ID], nil
+}
+
+// checkBudgetAlerts alerts the admins of the organization by email once
+// the consumed budget of the project crosses one of the alert thresholds
+func (a *app) checkBudgetAlerts(ctx context.Context, principal *Principal, projectID uuid.UUID) {
+	err := a.alertBudgetConsumption(ctx, principal, projectID)
+	if err != nil {
+		log.Printf("could not check budget of project %v: %v", projectID, err)
+	}
+}
+
+func (a *app) alertBudgetConsumption(ctx context.Context, principal *Principal, projectID uuid.UUID) error {
+	project, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !project.HasBudget() {
+		return nil
+	}
+
+	consumption, err := a.ReadBudgetConsumption(ctx, principal, project)
+	if err != nil {
+		return err
+	}
+
+	percentage := consumption.Percentage()
+	for _, threshold := range budgetAlertThresholds {
+		if percentage < threshold {
+			continue
+		}
+
+		// lower thresholds are recorded as well, so they don't trigger an alert later on
+		var alerted bool
+		err = a.RepositoryTxer.InTx(
+			ctx,
+			func(ctx context.Context) error {
+				for _, t := range budgetAlertThresholds {
+					if t > threshold {
+						continue
+					}
+
+					inserted, err := a.ProjectRepository.InsertProjectBudgetAlert(ctx, &ProjectBudgetAlert{
+						ProjectID:      project.ID,
+						PeriodStart:    consumption.PeriodStart,
+						Threshold:      t,
+						OrganizationID: principal.OrganizationID,
+						CreatedAt:      time.Now(),
+					})
+					if err != nil {
+						return err
+					}
+					if t == threshold {
+						alerted = inserted
+					}
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		if alerted {
+			return a.sendBudgetAlert(ctx, principal.OrganizationID, consumption, threshold)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+func (a *app) sendBudgetAlert(ctx context.Context, organizationID uuid.UUID, consumption *ProjectBudgetConsumption, threshold int) error {
+	subject := fmt.Sprintf("Budget of project %v reached %v%%", consumption.Project.Title, threshold)
+	body := fmt.Sprintf(
+		`The project %v consumed %v%% of its budget (%v). See the projects at %v/projects.`,
+		consumption.Project.Title,
+		consumption.Percentage(),
+		consumption.ConsumedFormatted(),
+		a.Config.Webroot,
+	)
+
+	return a.sendMailToAdmins(ctx, organizationID, subject, body)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadBudgetConsumptions(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].BudgetType = BudgetTypeHours
+	projectRepository.projects[0].Budget = 4
+
+	a := &app{
+		ProjectRepository:  projectRepository,
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	consumptions, err := a.ReadBudgetConsumptions(context.Background(), principal, projectRepository.projects)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(len(consumptions), 1)
+	is.Equal(consumptions[projectIDSample].Percentage(), 25)
+}
+
+func TestCreateActivityAlertsBudgetConsumption(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].BudgetType = BudgetTypeHours
+	projectRepository.projects[0].Budget = 1
+
+	mailResource := NewInMemMailResource()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     NewInMemActivityRepository(),
+		UserRepository:         NewInMemUserRepository(),
+		MailResource:           mailResource,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.CreateActivity(context.Background(), principal, &Activity{ProjectID: projectIDSample})
+	is.NoErr(err)
+
+	_, err = a.CreateActivity(context.Background(), principal, &Activity{ProjectID: projectIDSample})
+	is.NoErr(err)
+
+	// Assert
+	is.Equal(len(mailResource.mails), 1)
+	is.True(strings.Contains(mailResource.mails[0], "reached 100%"))
+	is.Equal(len(projectRepository.budgetAlerts), 2)
+}
+
+func TestCreateActivityWithoutBudget(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	mailResource := NewInMemMailResource()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		ActivityRepository:     NewInMemActivityRepository(),
+		MailResource:           mailResource,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.CreateActivity(context.Background(), principal, &Activity{ProjectID: projectIDSample})
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(len(mailResource.mails), 0)
+}
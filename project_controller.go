@@ -3,7 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/baralga/hal"
 	"github.com/baralga/paged"
@@ -16,17 +20,61 @@ import (
 )
 
 type projectModel struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title" validate:"required,min=3,max=100"`
-	Description string     `json:"description" validate:"max=500"`
-	Active      bool       `json:"active"`
-	Links       *hal.Links `json:"_links"`
+	ID          string       `json:"id"`
+	Title       string       `json:"title" validate:"required,min=3,max=100"`
+	Description string       `json:"description" validate:"max=500"`
+	Active      *bool        `json:"active"`
+	HourlyRate  float64      `json:"hourlyRate" validate:"min=0"`
+	Currency    string       `json:"currency" validate:"omitempty,len=3,alpha"`
+	ClientID    string       `json:"clientId,omitempty" validate:"omitempty,uuid"`
+	ParentID    string       `json:"parentId,omitempty" validate:"omitempty,uuid"`
+	Budget      *budgetModel `json:"budget,omitempty"`
+	Links       *hal.Links   `json:"_links"`
+}
+
+type budgetModel struct {
+	Type           string   `json:"type" validate:"required,oneof=hours money"`
+	Period         string   `json:"period" validate:"omitempty,oneof=total monthly"`
+	Amount         float64  `json:"amount" validate:"gt=0"`
+	Consumed       float64  `json:"consumed"`
+	Percentage     int      `json:"percentage"`
+	RemainingHours *float64 `json:"remainingHours,omitempty"`
 }
 
 type EmbeddedProjects struct {
 	ProjectModels []*projectModel `json:"projects"`
 }
 
+type projectUserRateModel struct {
+	Username   string     `json:"username"`
+	HourlyRate float64    `json:"hourlyRate" validate:"min=0"`
+	Links      *hal.Links `json:"_links"`
+}
+
+type EmbeddedProjectUserRates struct {
+	ProjectUserRateModels []*projectUserRateModel `json:"rates"`
+}
+
+type projectUserRatesModel struct {
+	*EmbeddedProjectUserRates `json:"_embedded"`
+	Links                     *hal.Links `json:"_links"`
+}
+
+type projectMemberModel struct {
+	Username string     `json:"username"`
+	Role     string     `json:"role" validate:"omitempty,oneof=member manager"`
+	Links    *hal.Links `json:"_links"`
+}
+
+type EmbeddedProjectMembers struct {
+	ProjectMemberModels []*projectMemberModel `json:"members"`
+}
+
+type projectMembersModel struct {
+	*EmbeddedProjectMembers `json:"_embedded"`
+	Links                   *hal.Links `json:"_links"`
+}
+
 type projectsModel struct {
 	*EmbeddedProjects `json:"_embedded"`
 	*paged.Page       `json:"page"`
@@ -40,7 +88,25 @@ func (a *app) HandleGetProjects() http.HandlerFunc {
 		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 		pageParams := paged.PageParamsOf(r)
 
-		projectsPaged, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		filter, err := projectFilterFromQueryParams(r.URL.Query())
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("project filter not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		projectsPaged, err := a.ReadProjects(r.Context(), principal, filter, pageParams)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		consumptions, err := a.ReadBudgetConsumptions(r.Context(), principal, projectsPaged.Projects)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
@@ -48,7 +114,8 @@ func (a *app) HandleGetProjects() http.HandlerFunc {
 
 		var projectModels []*projectModel
 		for _, project := range projectsPaged.Projects {
-			projectModel := mapToProjectModel(principal, project)
+			projectModel := mapToProjectModel(permissions, project)
+			mapConsumptionToProjectModel(projectModel, consumptions[project.ID])
 			projectModels = append(projectModels, projectModel)
 		}
 
@@ -60,7 +127,7 @@ func (a *app) HandleGetProjects() http.HandlerFunc {
 		}
 
 		selfLink := hal.NewSelfLink(r.RequestURI)
-		if principal.HasRole("ROLE_ADMIN") {
+		if permissions.IsAdmin() {
 			projectsModel.Links = hal.NewLinks(
 				selfLink,
 				hal.NewLink("create", "/api/projects"),
@@ -98,7 +165,20 @@ func (a *app) HandleGetProject() http.HandlerFunc {
 			return
 		}
 
-		projectModel := mapToProjectModel(principal, project)
+		consumption, err := a.ReadBudgetConsumption(r.Context(), principal, project)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		projectModel := mapToProjectModel(permissions, project)
+		mapConsumptionToProjectModel(projectModel, consumption)
 
 		util.RenderJSON(w, projectModel)
 	}
@@ -118,7 +198,13 @@ func (a *app) HandleCreateProject() http.HandlerFunc {
 			return
 		}
 
-		if !principal.HasRole("ROLE_ADMIN") {
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
@@ -136,12 +222,16 @@ func (a *app) HandleCreateProject() http.HandlerFunc {
 		}
 
 		project, err := a.CreateProject(r.Context(), principal, projectToCreate)
+		if errors.Is(err, ErrProjectHierarchyInvalid) {
+			http.Error(w, problem.New(problem.Title("project hierarchy not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		projectModelCreated := mapToProjectModel(principal, project)
+		projectModelCreated := mapToProjectModel(permissions, project)
 
 		w.WriteHeader(http.StatusCreated)
 		util.RenderJSON(w, projectModelCreated)
@@ -169,7 +259,13 @@ func (a *app) HandleUpdateProject() http.HandlerFunc {
 			return
 		}
 
-		if !principal.HasRole("ROLE_ADMIN") {
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
@@ -188,22 +284,40 @@ func (a *app) HandleUpdateProject() http.HandlerFunc {
 
 		project.ID = projectID
 
-		projectUpdate, err := a.UpdateProject(r.Context(), principal.OrganizationID, project)
+		// projects are archived or unarchived only if active is given explicitly
+		if projectModel.Active == nil {
+			existingProject, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+			if errors.Is(err, ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				util.RenderProblemJSON(w, isProduction, err)
+				return
+			}
+			project.Active = existingProject.Active
+		}
+
+		projectUpdate, err := a.UpdateProject(r.Context(), principal, project)
 		if errors.Is(err, ErrProjectNotFound) {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, ErrProjectHierarchyInvalid) {
+			http.Error(w, problem.New(problem.Title("project hierarchy not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		projectModelUpdate := mapToProjectModel(principal, projectUpdate)
+		projectModelUpdate := mapToProjectModel(permissions, projectUpdate)
 		util.RenderJSON(w, projectModelUpdate)
 	}
 }
 
-// HandleDeleteProject deletes a project
+// HandleDeleteProject deletes a project, its activities are reassigned to the project given by query param reassignTo
 func (a *app) HandleDeleteProject() http.HandlerFunc {
 	isProduction := a.isProduction()
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -216,16 +330,43 @@ func (a *app) HandleDeleteProject() http.HandlerFunc {
 
 		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 
-		if !principal.HasRole("ROLE_ADMIN") {
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
 
-		err = a.DeleteProjectByID(r.Context(), principal, projectID)
+		targetProjectID := uuid.Nil
+		if r.URL.Query().Get("reassignTo") != "" {
+			targetProjectID, err = uuid.Parse(r.URL.Query().Get("reassignTo"))
+			if err != nil {
+				http.Error(w, problem.New(problem.Title("project to reassign activities to not valid")).JSONString(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		err = a.DeleteProjectByID(r.Context(), principal, projectID, targetProjectID)
 		if errors.Is(err, ErrProjectNotFound) {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, ErrProjectInUse) {
+			http.Error(w, problem.New(problem.Title("project has activities, reassign them to another project")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrProjectReassignmentInvalid) {
+			http.Error(w, problem.New(problem.Title("project to reassign activities to not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrProjectActivitiesLocked) {
+			http.Error(w, problem.New(problem.Title("project has locked activities")).JSONString(), http.StatusConflict)
+			return
+		}
 		if err != nil {
 			util.RenderProblemJSON(w, isProduction, err)
 			return
@@ -235,6 +376,171 @@ func (a *app) HandleDeleteProject() http.HandlerFunc {
 	}
 }
 
+// HandleGetProjectUserRates reads the hourly rates of users for a project
+func (a *app) HandleGetProjectUserRates() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		rates, err := a.ReadProjectUserRates(r.Context(), principal, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		rateModels := make([]*projectUserRateModel, len(rates))
+		for i, rate := range rates {
+			rateModels[i] = mapToProjectUserRateModel(rate)
+		}
+
+		ratesModel := &projectUserRatesModel{
+			EmbeddedProjectUserRates: &EmbeddedProjectUserRates{
+				ProjectUserRateModels: rateModels,
+			},
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+				hal.NewLink("project", fmt.Sprintf("/api/projects/%s", projectID)),
+			),
+		}
+
+		util.RenderJSON(w, ratesModel)
+	}
+}
+
+// HandleUpdateProjectUserRate sets the hourly rate of a user for a project
+func (a *app) HandleUpdateProjectUserRate() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		username := chi.URLParam(r, "username")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		var rateModel projectUserRateModel
+		err = json.NewDecoder(r.Body).Decode(&rateModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = validator.Struct(rateModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("rate not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		rate, err := a.UpdateProjectUserRate(r.Context(), principal, &ProjectUserRate{
+			ProjectID:  projectID,
+			Username:   username,
+			HourlyRate: rateModel.HourlyRate,
+		})
+		if errors.Is(err, ErrProjectNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrHourlyRateInvalid) {
+			http.Error(w, problem.New(problem.Title("rate not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		util.RenderJSON(w, mapToProjectUserRateModel(rate))
+	}
+}
+
+// HandleDeleteProjectUserRate removes the hourly rate of a user for a project
+func (a *app) HandleDeleteProjectUserRate() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		username := chi.URLParam(r, "username")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = a.DeleteProjectUserRate(r.Context(), principal, projectID, username)
+		if errors.Is(err, ErrProjectUserRateNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+	}
+}
+
+func projectFilterFromQueryParams(params url.Values) (*ProjectFilter, error) {
+	filter := &ProjectFilter{}
+
+	if params.Get("archived") != "" {
+		archived, err := strconv.ParseBool(params.Get("archived"))
+		if err != nil {
+			return nil, err
+		}
+		filter.Archived = archived
+	}
+
+	return filter, nil
+}
+
 func mapToProject(projectModel *projectModel) (*Project, error) {
 	var projectID uuid.UUID
 
@@ -246,28 +552,70 @@ func mapToProject(projectModel *projectModel) (*Project, error) {
 		projectID = pID
 	}
 
-	return &Project{
+	project := &Project{
 		ID:          projectID,
 		Title:       projectModel.Title,
 		Description: projectModel.Description,
-		Active:      projectModel.Active,
-	}, nil
+		Active:      projectModel.Active == nil || *projectModel.Active,
+		HourlyRate:  projectModel.HourlyRate,
+		Currency:    strings.ToUpper(projectModel.Currency),
+	}
+
+	if projectModel.ClientID != "" {
+		clientID, err := uuid.Parse(projectModel.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		project.ClientID = clientID
+	}
+
+	if projectModel.ParentID != "" {
+		parentID, err := uuid.Parse(projectModel.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		project.ParentID = parentID
+	}
+
+	if projectModel.Budget != nil {
+		project.BudgetType = projectModel.Budget.Type
+		project.BudgetPeriod = projectModel.Budget.Period
+		project.Budget = projectModel.Budget.Amount
+	}
+
+	return project, nil
 }
 
-func mapToProjectModel(principal *Principal, project *Project) *projectModel {
+func mapToProjectModel(permissions *Permissions, project *Project) *projectModel {
 	projectModel := &projectModel{
 		ID:          project.ID.String(),
 		Title:       project.Title,
 		Description: project.Description,
-		Active:      project.Active,
+		Active:      &project.Active,
+		HourlyRate:  project.HourlyRate,
+		Currency:    project.CurrencyOrDefault(),
+	}
+	if project.ClientID != uuid.Nil {
+		projectModel.ClientID = project.ClientID.String()
+	}
+	if project.IsSubProject() {
+		projectModel.ParentID = project.ParentID.String()
+	}
+	if project.HasBudget() {
+		projectModel.Budget = &budgetModel{
+			Type:   project.BudgetType,
+			Period: project.BudgetPeriodOrDefault(),
+			Amount: project.Budget,
+		}
 	}
 	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/projects/%s", projectModel.ID))
-	if principal.HasRole("ROLE_ADMIN") {
+	if permissions.IsAdmin() {
 		projectModel.Links = hal.NewLinks(
 			selfLink,
 			hal.NewLink("create", selfLink.Href()),
 			hal.NewLink("delete", selfLink.Href()),
 			hal.NewLink("edit", selfLink.Href()),
+			hal.NewLink("rates", fmt.Sprintf("%s/rates", selfLink.Href())),
 		)
 	} else {
 		projectModel.Links = hal.NewLinks(
@@ -276,3 +624,198 @@ func mapToProjectModel(principal *Principal, project *Project) *projectModel {
 	}
 	return projectModel
 }
+
+// HandleGetProjectMembers reads the users assigned to a project
+func (a *app) HandleGetProjectMembers() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, err = a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		members, err := a.ReadProjectMembers(r.Context(), principal, []uuid.UUID{projectID})
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		memberModels := make([]*projectMemberModel, len(members))
+		for i, member := range members {
+			memberModels[i] = mapToProjectMemberModel(member)
+		}
+
+		membersModel := &projectMembersModel{
+			EmbeddedProjectMembers: &EmbeddedProjectMembers{
+				ProjectMemberModels: memberModels,
+			},
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+				hal.NewLink("project", fmt.Sprintf("/api/projects/%s", projectID)),
+			),
+		}
+
+		util.RenderJSON(w, membersModel)
+	}
+}
+
+// HandleUpdateProjectMember assigns a user to a project with an optional role
+func (a *app) HandleUpdateProjectMember() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		username := chi.URLParam(r, "username")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		var memberModel projectMemberModel
+		err = json.NewDecoder(r.Body).Decode(&memberModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = validator.Struct(memberModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("member not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		member, err := a.UpdateProjectMember(r.Context(), principal, &ProjectMember{
+			ProjectID: projectID,
+			Username:  username,
+			Role:      memberModel.Role,
+		})
+		if errors.Is(err, ErrProjectNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrProjectMemberInvalid) {
+			http.Error(w, problem.New(problem.Title("member not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		util.RenderJSON(w, mapToProjectMemberModel(member))
+	}
+}
+
+// HandleDeleteProjectMember removes a user from a project
+func (a *app) HandleDeleteProjectMember() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		username := chi.URLParam(r, "username")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = a.DeleteProjectMember(r.Context(), principal, projectID, username)
+		if errors.Is(err, ErrProjectMemberNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+	}
+}
+
+func mapToProjectMemberModel(member *ProjectMember) *projectMemberModel {
+	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/projects/%s/members/%s", member.ProjectID, url.PathEscape(member.Username)))
+	return &projectMemberModel{
+		Username: member.Username,
+		Role:     member.Role,
+		Links: hal.NewLinks(
+			selfLink,
+			hal.NewLink("edit", selfLink.Href()),
+			hal.NewLink("delete", selfLink.Href()),
+		),
+	}
+}
+
+func mapToProjectUserRateModel(rate *ProjectUserRate) *projectUserRateModel {
+	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/projects/%s/rates/%s", rate.ProjectID, url.PathEscape(rate.Username)))
+	return &projectUserRateModel{
+		Username:   rate.Username,
+		HourlyRate: rate.HourlyRate,
+		Links: hal.NewLinks(
+			selfLink,
+			hal.NewLink("edit", selfLink.Href()),
+			hal.NewLink("delete", selfLink.Href()),
+		),
+	}
+}
+
+func mapConsumptionToProjectModel(projectModel *projectModel, consumption *ProjectBudgetConsumption) {
+	if consumption == nil || projectModel.Budget == nil {
+		return
+	}
+
+	projectModel.Budget.Consumed = math.Round(consumption.Consumed()*100) / 100
+	projectModel.Budget.Percentage = consumption.Percentage()
+	if remainingHours, ok := consumption.RemainingHours(); ok {
+		projectModel.Budget.RemainingHours = &remainingHours
+	}
+}
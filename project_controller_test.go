@@ -25,7 +25,7 @@ func TestMapToProjectModelWithoutClaims(t *testing.T) {
 		Description: "My Description",
 	}
 
-	projectModel := mapToProjectModel(principal, project)
+	projectModel := mapToProjectModel(NewPermissions(principal, nil), project)
 
 	is.Equal(project.ID.String(), projectModel.ID)
 	is.Equal(project.Title, projectModel.Title)
@@ -46,22 +46,23 @@ func TestMapToProjectModelWithAdminClaim(t *testing.T) {
 		Description: "My Description",
 	}
 
-	projectModel := mapToProjectModel(principal, project)
+	projectModel := mapToProjectModel(NewPermissions(principal, nil), project)
 
 	is.Equal(project.ID.String(), projectModel.ID)
 	is.Equal(project.Title, projectModel.Title)
 	is.Equal(project.Description, projectModel.Description)
-	is.Equal(4, projectModel.Links.Size())
+	is.Equal(5, projectModel.Links.Size())
 }
 
 func TestMapToProject(t *testing.T) {
 	is := is.New(t)
 
+	active := true
 	projectModel := &projectModel{
 		ID:          "00000000-0000-0000-1111-000000000001",
 		Title:       "Title",
 		Description: "Description",
-		Active:      true,
+		Active:      &active,
 	}
 
 	project, err := mapToProject(projectModel)
@@ -70,17 +71,18 @@ func TestMapToProject(t *testing.T) {
 	is.Equal(projectModel.ID, project.ID.String())
 	is.Equal(projectModel.Title, project.Title)
 	is.Equal(projectModel.Description, project.Description)
-	is.Equal(projectModel.Active, project.Active)
+	is.Equal(*projectModel.Active, project.Active)
 }
 
 func TestMapToProjectWithInvalidId(t *testing.T) {
 	is := is.New(t)
 
+	active := true
 	projectModel := &projectModel{
 		ID:          "not-a-uuid",
 		Title:       "Title",
 		Description: "Description",
-		Active:      true,
+		Active:      &active,
 	}
 
 	_, err := mapToProject(projectModel)
@@ -109,6 +111,90 @@ func TestHandleGetProjects(t *testing.T) {
 	is.Equal(1, len(projectsModel.EmbeddedProjects.ProjectModels))
 }
 
+func TestHandleGetArchivedProjects(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, &Project{
+		ID:             uuid.New(),
+		Title:          "My archived Project",
+		OrganizationID: organizationIDSample,
+	})
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: projectRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/api/projects?archived=true", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleGetProjects()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	projectsModel := &projectsModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(projectsModel)
+	is.NoErr(err)
+	is.Equal(1, len(projectsModel.EmbeddedProjects.ProjectModels))
+	is.Equal("My archived Project", projectsModel.EmbeddedProjects.ProjectModels[0].Title)
+}
+
+func TestHandleGetProjectsWithInvalidArchivedFilter(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/projects?archived=maybe", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleGetProjects()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
+
+func TestHandleUpdateProjectToUnarchive(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = false
+	auditLogRepository := NewInMemAuditLogRepository()
+
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		ProjectRepository:  projectRepository,
+		AuditLogRepository: auditLogRepository,
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	body := `
+	{
+		"title": "My Project",
+		"active": true
+	}
+	`
+
+	r, _ := http.NewRequest("PATCH", fmt.Sprintf("/api/projects/%v", projectIDSample), strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(projectRepository.projects[0].Active)
+	is.Equal(AuditActionUnarchive, auditLogRepository.entries[0].Action)
+}
+
 func TestHandleGetProjectWithInvalidId(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -179,9 +265,10 @@ func TestHandleUpdateProject(t *testing.T) {
 	httpRec := httptest.NewRecorder()
 
 	a := &app{
-		Config:            &config{},
-		RepositoryTxer:    NewInMemRepositoryTxer(),
-		ProjectRepository: NewInMemProjectRepository(),
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		ProjectRepository:  NewInMemProjectRepository(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
 	}
 
 	body := `
@@ -359,9 +446,11 @@ func TestHandleCreateProject(t *testing.T) {
 
 	repo := NewInMemProjectRepository()
 	a := &app{
-		Config:            &config{},
-		ProjectRepository: repo,
-		RepositoryTxer:    NewInMemRepositoryTxer(),
+		Config:             &config{},
+		ProjectRepository:  repo,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
 	}
 
 	countBefore := len(repo.projects)
@@ -382,6 +471,36 @@ func TestHandleCreateProject(t *testing.T) {
 	is.Equal(countBefore+1, len(repo.projects))
 }
 
+func TestHandleCreateProjectWithNotExistingParent(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		ClientRepository:  NewInMemClientRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	countBefore := len(repo.projects)
+	body := fmt.Sprintf(`
+	{
+		"title": "My new Work Package",
+		"parentId": "%v"
+	}
+	`, uuid.New())
+
+	r, _ := http.NewRequest("POST", "/api/projects", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleCreateProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+	is.Equal(countBefore, len(repo.projects))
+}
+
 func TestHandleInvalidCreateProject(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -459,9 +578,14 @@ func TestHandleDeleteProjectAsAdmin(t *testing.T) {
 
 	repo := NewInMemProjectRepository()
 	a := &app{
-		Config:            &config{},
-		ProjectRepository: repo,
-		RepositoryTxer:    NewInMemRepositoryTxer(),
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     &InMemActivityRepository{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
 	}
 
 	r, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/projects/%v", projectIDSample), nil)
@@ -479,14 +603,127 @@ func TestHandleDeleteProjectAsAdmin(t *testing.T) {
 	is.Equal(0, len(repo.projects))
 }
 
+func TestHandleDeleteProjectWithActivitiesAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     activityRepository,
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	r, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/projects/%v", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+	is.Equal(1, len(repo.projects))
+	is.Equal(projectIDSample, activityRepository.activities[0].ProjectID)
+}
+
+func TestHandleDeleteProjectWithReassignmentAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	targetProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Target Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	repo.projects = append(repo.projects, targetProject)
+
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     activityRepository,
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	r, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/projects/%v?reassignTo=%v", projectIDSample, targetProject.ID), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.projects))
+	is.Equal(targetProject.ID, activityRepository.activities[0].ProjectID)
+}
+
+func TestHandleDeleteProjectWithReassignmentToItself(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/projects/%v?reassignTo=%v", projectIDSample, projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+	is.Equal(1, len(repo.projects))
+}
+
 func TestHandleDeleteProjectAsUser(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
 
 	repo := NewInMemProjectRepository()
 	a := &app{
-		Config:            &config{},
-		ProjectRepository: repo,
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		RepositoryTxer:         NewInMemRepositoryTxer(),
 	}
 
 	r, _ := http.NewRequest("DELETE", "/api/projects/00000000-0000-0000-1111-000000000001", nil)
@@ -508,8 +745,13 @@ func TestHandleDeleteProjectIdNotValid(t *testing.T) {
 	httpRec := httptest.NewRecorder()
 
 	a := &app{
-		Config:            &config{},
-		ProjectRepository: NewInMemProjectRepository(),
+		Config:                 &config{},
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		RepositoryTxer:         NewInMemRepositoryTxer(),
 	}
 
 	r, _ := http.NewRequest("DELETE", "/api/projects/not-a-uuid", nil)
@@ -524,3 +766,229 @@ func TestHandleDeleteProjectIdNotValid(t *testing.T) {
 	a.HandleDeleteProject()(httpRec, r)
 	is.Equal(httpRec.Result().StatusCode, http.StatusNotAcceptable)
 }
+
+func TestHandleUpdateProjectUserRate(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("PUT", fmt.Sprintf("/api/projects/%v/rates/user1", projectIDSample), strings.NewReader(`{"hourlyRate": 95.5}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "user1")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateProjectUserRate()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.userRates))
+	is.Equal(95.5, repo.userRates[0].HourlyRate)
+}
+
+func TestHandleUpdateProjectUserRateWithNegativeRate(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("PUT", fmt.Sprintf("/api/projects/%v/rates/user1", projectIDSample), strings.NewReader(`{"hourlyRate": -1}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "admin",
+		Roles:    []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "user1")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateProjectUserRate()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
+
+func TestHandleGetProjectUserRatesAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", fmt.Sprintf("/api/projects/%v/rates", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleGetProjectUserRates()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleDeleteNonExistingProjectUserRate(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/projects/%v/rates/user1", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "admin",
+		Roles:    []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "user1")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectUserRate()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+}
+
+func TestHandleGetProjectWithBudget(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	repo.projects[0].BudgetType = BudgetTypeHours
+	repo.projects[0].Budget = 10
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  repo,
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", fmt.Sprintf("/api/projects/%v", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleGetProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	projectModel := &projectModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(projectModel)
+	is.NoErr(err)
+	is.Equal(10, projectModel.Budget.Percentage)
+	is.Equal(9.0, *projectModel.Budget.RemainingHours)
+}
+
+func TestHandleUpdateProjectMember(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		UserRepository:    NewInMemUserRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("PUT", fmt.Sprintf("/api/projects/%v/members/admin@baralga.com", projectIDSample), strings.NewReader(`{"role": "manager"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "admin@baralga.com")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateProjectMember()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.members))
+	is.Equal(ProjectRoleManager, repo.members[0].Role)
+}
+
+func TestHandleUpdateProjectMemberWithUnknownUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+		UserRepository:    NewInMemUserRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("PUT", fmt.Sprintf("/api/projects/%v/members/unknown", projectIDSample), strings.NewReader(`{}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "unknown")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateProjectMember()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
+
+func TestHandleGetProjectMembers(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	repo.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+	}
+
+	r, _ := http.NewRequest("GET", fmt.Sprintf("/api/projects/%v/members", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleGetProjectMembers()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	membersModel := &projectMembersModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(membersModel)
+	is.NoErr(err)
+	is.Equal(1, len(membersModel.EmbeddedProjectMembers.ProjectMemberModels))
+	is.Equal("user1", membersModel.EmbeddedProjectMembers.ProjectMemberModels[0].Username)
+}
@@ -1,11 +1,274 @@
 package main
 
-import "github.com/google/uuid"
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCurrency is the currency of projects without explicit currency
+const DefaultCurrency = "EUR"
+
+// Budget types of projects
+const (
+	BudgetTypeHours string = "hours"
+	BudgetTypeMoney string = "money"
+)
+
+// Budget periods of projects
+const (
+	BudgetPeriodTotal   string = "total"
+	BudgetPeriodMonthly string = "monthly"
+)
+
+// Roles of the members of a project
+const (
+	ProjectRoleMember  string = "member"
+	ProjectRoleManager string = "manager"
+)
+
+// budgetAlertThresholds are the consumed percentages of a budget which trigger an alert, highest first
+var budgetAlertThresholds = []int{100, 80}
 
 type Project struct {
 	ID             uuid.UUID
 	Title          string
 	Description    string
 	Active         bool
+	HourlyRate     float64
+	Currency       string
+	BudgetType     string
+	Budget         float64
+	BudgetPeriod   string
+	ClientID       uuid.UUID // uuid.Nil if the project has no client
+	ParentID       uuid.UUID // uuid.Nil if the project is no sub-project
+	ClientTitle    string    // only filled when reading projects and never stored
+	ParentTitle    string    // only filled when reading projects and never stored
+	OrganizationID uuid.UUID
+}
+
+// Client is the customer projects are done for
+type Client struct {
+	ID             uuid.UUID
+	Title          string
+	Description    string
 	OrganizationID uuid.UUID
 }
+
+// ProjectBudgetConsumption is the consumed budget of a project within a budget period
+type ProjectBudgetConsumption struct {
+	Project                *Project
+	PeriodStart            time.Time
+	PeriodEnd              time.Time
+	DurationInMinutesTotal int
+	Revenue                float64
+}
+
+// ProjectBudgetAlert records that admins were alerted about a consumed budget
+type ProjectBudgetAlert struct {
+	ProjectID      uuid.UUID
+	PeriodStart    time.Time
+	Threshold      int
+	OrganizationID uuid.UUID
+	CreatedAt      time.Time
+}
+
+// ProjectUserRate overrides the hourly rate of a project for a single user
+type ProjectUserRate struct {
+	ProjectID      uuid.UUID
+	Username       string
+	HourlyRate     float64
+	OrganizationID uuid.UUID
+}
+
+// ProjectMember assigns a user to a project, projects without members are open to all users of the organization
+type ProjectMember struct {
+	ProjectID      uuid.UUID
+	Username       string
+	Role           string
+	OrganizationID uuid.UUID
+}
+
+// ProjectUsage is the number and duration of the activities tracked on a project
+type ProjectUsage struct {
+	ActivityCount          int
+	RunningActivityCount   int
+	DurationInMinutesTotal int
+}
+
+// CurrencyOrDefault returns the currency of the project or the default currency
+func (p *Project) CurrencyOrDefault() string {
+	if p.Currency == "" {
+		return DefaultCurrency
+	}
+	return p.Currency
+}
+
+// IsSubProject checks if the project is a sub-project (e.g. a work package) of a parent project
+func (p *Project) IsSubProject() bool {
+	return p.ParentID != uuid.Nil
+}
+
+// HierarchicalTitle is the title of the project prefixed with the title of its parent (e.g. Website › Design)
+func (p *Project) HierarchicalTitle() string {
+	if !p.IsSubProject() || p.ParentTitle == "" {
+		return p.Title
+	}
+	return p.ParentTitle + " › " + p.Title
+}
+
+// OrderProjectsByHierarchy orders the projects by client and title with
+// each sub-project following its parent project
+func OrderProjectsByHierarchy(projects []*Project) []*Project {
+	ordered := make([]*Project, len(projects))
+	copy(ordered, projects)
+
+	rootTitle := func(p *Project) string {
+		if p.IsSubProject() && p.ParentTitle != "" {
+			return strings.ToLower(p.ParentTitle)
+		}
+		return strings.ToLower(p.Title)
+	}
+	rootID := func(p *Project) uuid.UUID {
+		if p.IsSubProject() {
+			return p.ParentID
+		}
+		return p.ID
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := ordered[i], ordered[j]
+		if ci, cj := strings.ToLower(pi.ClientTitle), strings.ToLower(pj.ClientTitle); ci != cj {
+			return ci < cj
+		}
+		if ri, rj := rootTitle(pi), rootTitle(pj); ri != rj {
+			return ri < rj
+		}
+		if ri, rj := rootID(pi), rootID(pj); ri != rj {
+			return ri.String() < rj.String()
+		}
+		if pi.IsSubProject() != pj.IsSubProject() {
+			return !pi.IsSubProject()
+		}
+		return strings.ToLower(pi.Title) < strings.ToLower(pj.Title)
+	})
+
+	return ordered
+}
+
+// IsValidBudgetType checks if the budget type is known, no budget type means the project has no budget
+func IsValidBudgetType(budgetType string) bool {
+	switch budgetType {
+	case "", BudgetTypeHours, BudgetTypeMoney:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidBudgetPeriod checks if the budget period is known
+func IsValidBudgetPeriod(budgetPeriod string) bool {
+	switch budgetPeriod {
+	case "", BudgetPeriodTotal, BudgetPeriodMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidProjectRole checks if the role of a project member is known
+func IsValidProjectRole(role string) bool {
+	switch role {
+	case ProjectRoleMember, ProjectRoleManager:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAssignedToProject checks if the user is assigned to the project with the given members,
+// all users are assigned to a project without members
+func IsAssignedToProject(members []*ProjectMember, username string) bool {
+	if len(members) == 0 {
+		return true
+	}
+	for _, member := range members {
+		if member.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBudget checks if a budget is set for the project
+func (p *Project) HasBudget() bool {
+	return p.BudgetType != "" && p.Budget > 0
+}
+
+// BudgetPeriodOrDefault returns the budget period of the project or the total period
+func (p *Project) BudgetPeriodOrDefault() string {
+	if p.BudgetPeriod == "" {
+		return BudgetPeriodTotal
+	}
+	return p.BudgetPeriod
+}
+
+// BudgetPeriodAt returns start and end of the budget period containing the given time
+func (p *Project) BudgetPeriodAt(t time.Time) (time.Time, time.Time) {
+	if p.BudgetPeriodOrDefault() == BudgetPeriodMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	return time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Consumed is the consumed budget in hours or money depending on the budget type
+func (c *ProjectBudgetConsumption) Consumed() float64 {
+	if c.Project.BudgetType == BudgetTypeMoney {
+		return c.Revenue
+	}
+	return float64(c.DurationInMinutesTotal) / 60.0
+}
+
+// Percentage is the consumed budget in percent (e.g. 80)
+func (c *ProjectBudgetConsumption) Percentage() int {
+	if !c.Project.HasBudget() {
+		return 0
+	}
+	return int(math.Floor(c.Consumed() / c.Project.Budget * 100))
+}
+
+// RemainingHours is the number of hours left in the budget, which is
+// only known for money budgets if the project has an hourly rate
+func (c *ProjectBudgetConsumption) RemainingHours() (float64, bool) {
+	remaining := c.Project.Budget - c.Consumed()
+	if c.Project.BudgetType == BudgetTypeMoney {
+		if c.Project.HourlyRate <= 0 {
+			return 0, false
+		}
+		remaining = remaining / c.Project.HourlyRate
+	}
+	return math.Round(remaining*100) / 100, true
+}
+
+// ConsumedFormatted is the consumed budget as formatted string (e.g. 12:30 h of 40:00 h)
+func (c *ProjectBudgetConsumption) ConsumedFormatted() string {
+	if c.Project.BudgetType == BudgetTypeMoney {
+		return FormatAmount(c.Revenue, c.Project.CurrencyOrDefault()) + " of " + FormatAmount(c.Project.Budget, c.Project.CurrencyOrDefault())
+	}
+	return FormatMinutesAsDuration(float64(c.DurationInMinutesTotal)) + " of " + FormatMinutesAsDuration(c.Project.Budget*60)
+}
+
+// IsUsed checks if activities are tracked on the project, which must be
+// reassigned to another project before the project can be deleted
+func (u *ProjectUsage) IsUsed() bool {
+	return u.ActivityCount > 0 || u.RunningActivityCount > 0
+}
+
+// DurationFormatted is the duration of all activities as formatted string (e.g. 12:30 h)
+func (u *ProjectUsage) DurationFormatted() string {
+	return FormatMinutesAsDuration(float64(u.DurationInMinutesTotal))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestProjectBudgetConsumption(t *testing.T) {
+	is := is.New(t)
+
+	t.Run("hours budget", func(t *testing.T) {
+		consumption := &ProjectBudgetConsumption{
+			Project: &Project{
+				BudgetType: BudgetTypeHours,
+				Budget:     10,
+			},
+			DurationInMinutesTotal: 8 * 60,
+		}
+
+		remainingHours, ok := consumption.RemainingHours()
+		is.Equal(80, consumption.Percentage())
+		is.True(ok)
+		is.Equal(2.0, remainingHours)
+	})
+
+	t.Run("money budget with hourly rate", func(t *testing.T) {
+		consumption := &ProjectBudgetConsumption{
+			Project: &Project{
+				BudgetType: BudgetTypeMoney,
+				Budget:     1000,
+				HourlyRate: 100,
+			},
+			Revenue: 1100,
+		}
+
+		remainingHours, ok := consumption.RemainingHours()
+		is.Equal(110, consumption.Percentage())
+		is.True(ok)
+		is.Equal(-1.0, remainingHours)
+	})
+
+	t.Run("money budget without hourly rate", func(t *testing.T) {
+		consumption := &ProjectBudgetConsumption{
+			Project: &Project{
+				BudgetType: BudgetTypeMoney,
+				Budget:     1000,
+			},
+		}
+
+		_, ok := consumption.RemainingHours()
+		is.True(!ok)
+	})
+}
+
+func TestProjectBudgetPeriodAt(t *testing.T) {
+	is := is.New(t)
+
+	project := &Project{
+		BudgetPeriod: BudgetPeriodMonthly,
+	}
+
+	start, end := project.BudgetPeriodAt(time.Date(2021, 11, 12, 11, 0, 0, 0, time.UTC))
+
+	is.Equal(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), start)
+	is.Equal(time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestProjectHierarchicalTitle(t *testing.T) {
+	is := is.New(t)
+
+	project := &Project{Title: "Website"}
+	is.Equal("Website", project.HierarchicalTitle())
+
+	subProject := &Project{Title: "Design", ParentID: uuid.New(), ParentTitle: "Website"}
+	is.Equal("Website › Design", subProject.HierarchicalTitle())
+}
+
+func TestOrderProjectsByHierarchy(t *testing.T) {
+	is := is.New(t)
+
+	website := &Project{ID: uuid.New(), Title: "Website", ClientTitle: "ACME"}
+	design := &Project{ID: uuid.New(), Title: "Design", ParentID: website.ID, ParentTitle: "Website", ClientTitle: "ACME"}
+	app := &Project{ID: uuid.New(), Title: "App", ClientTitle: "ACME"}
+	internal := &Project{ID: uuid.New(), Title: "Internal"}
+
+	ordered := OrderProjectsByHierarchy([]*Project{design, website, internal, app})
+
+	is.Equal(4, len(ordered))
+	is.Equal(internal.ID, ordered[0].ID)
+	is.Equal(app.ID, ordered[1].ID)
+	is.Equal(website.ID, ordered[2].ID)
+	is.Equal(design.ID, ordered[3].ID)
+}
+
+func TestIsAssignedToProject(t *testing.T) {
+	is := is.New(t)
+
+	is.True(IsAssignedToProject(nil, "user1"))
+
+	members := []*ProjectMember{
+		{Username: "user1", Role: ProjectRoleMember},
+		{Username: "user2", Role: ProjectRoleManager},
+	}
+	is.True(IsAssignedToProject(members, "user1"))
+	is.True(IsAssignedToProject(members, "user2"))
+	is.True(!IsAssignedToProject(members, "user3"))
+
+	is.True(IsValidProjectRole(ProjectRoleManager))
+	is.True(!IsValidProjectRole("owner"))
+}
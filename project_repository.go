@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/baralga/paged"
 	"github.com/google/uuid"
@@ -12,6 +13,16 @@ import (
 )
 
 var ErrProjectNotFound = errors.New("project not found")
+var ErrProjectUserRateNotFound = errors.New("project user rate not found")
+var ErrProjectMemberNotFound = errors.New("project member not found")
+
+// ProjectFilter filters projects, by default only active projects are found
+type ProjectFilter struct {
+	Archived bool
+
+	// Username restricts the projects to those the user is assigned to
+	Username string
+}
 
 type ProjectsPaged struct {
 	Projects []*Project
@@ -19,13 +30,24 @@ type ProjectsPaged struct {
 }
 
 type ProjectRepository interface {
-	FindProjects(ctx context.Context, organizationID uuid.UUID, pageParams *paged.PageParams) (*ProjectsPaged, error)
+	FindProjects(ctx context.Context, organizationID uuid.UUID, filter *ProjectFilter, pageParams *paged.PageParams) (*ProjectsPaged, error)
 	FindProjectsByIDs(ctx context.Context, organizationID uuid.UUID, projectIDs []uuid.UUID) ([]*Project, error)
+	FindProjectsByTitles(ctx context.Context, organizationID uuid.UUID, titles []string) ([]*Project, error)
 	FindProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) (*Project, error)
+	HasSubProjects(ctx context.Context, organizationID, projectID uuid.UUID) (bool, error)
 	InsertProject(ctx context.Context, project *Project) (*Project, error)
 	UpdateProject(ctx context.Context, organizationID uuid.UUID, project *Project) (*Project, error)
 	ArchiveProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error
+	UnarchiveProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error
 	DeleteProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error
+	FindProjectUserRates(ctx context.Context, organizationID, projectID uuid.UUID) ([]*ProjectUserRate, error)
+	UpsertProjectUserRate(ctx context.Context, rate *ProjectUserRate) (*ProjectUserRate, error)
+	DeleteProjectUserRate(ctx context.Context, organizationID, projectID uuid.UUID, username string) error
+	InsertProjectBudgetAlert(ctx context.Context, alert *ProjectBudgetAlert) (bool, error)
+	FindProjectMembers(ctx context.Context, organizationID uuid.UUID, projectIDs []uuid.UUID) ([]*ProjectMember, error)
+	UpsertProjectMember(ctx context.Context, member *ProjectMember) (*ProjectMember, error)
+	DeleteProjectMember(ctx context.Context, organizationID, projectID uuid.UUID, username string) error
+	FindManagedProjectIDs(ctx context.Context, organizationID uuid.UUID, username string) ([]uuid.UUID, error)
 }
 
 // DbProjectRepository is a SQL database repository for projects
@@ -42,15 +64,20 @@ func NewDbProjectRepository(connPool *pgxpool.Pool) *DbProjectRepository {
 	}
 }
 
-func (r *DbProjectRepository) FindProjects(ctx context.Context, organizationID uuid.UUID, pageParams *paged.PageParams) (*ProjectsPaged, error) {
+// projectMemberCondition restricts projects p to those the user $3 is assigned to, unless $3 is empty
+const projectMemberCondition = `($3 = ''
+		   OR NOT EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = p.project_id)
+		   OR EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = p.project_id AND m.username = $3))`
+
+func (r *DbProjectRepository) FindProjects(ctx context.Context, organizationID uuid.UUID, filter *ProjectFilter, pageParams *paged.PageParams) (*ProjectsPaged, error) {
 	rows, err := r.connPool.Query(
 		ctx,
-		`SELECT project_id as id, title, description, active 
-		 FROM projects 
-		 WHERE org_id = $1 AND active = true
-		 ORDER BY title ASC 
-		 LIMIT $2 OFFSET $3`,
-		organizationID, pageParams.Size, pageParams.Offset(),
+		`SELECT `+projectColumns+` 
+		 FROM `+projectTables+` 
+		 WHERE p.org_id = $1 AND p.active = $2 AND `+projectMemberCondition+`
+		 ORDER BY p.title ASC 
+		 LIMIT $4 OFFSET $5`,
+		organizationID, !filter.Archived, filter.Username, pageParams.Size, pageParams.Offset(),
 	)
 	if err != nil {
 		return nil, err
@@ -59,33 +86,19 @@ func (r *DbProjectRepository) FindProjects(ctx context.Context, organizationID u
 
 	var projects []*Project
 	for rows.Next() {
-		var (
-			id          string
-			title       string
-			description sql.NullString
-			active      bool
-		)
-
-		err = rows.Scan(&id, &title, &description, &active)
+		project, err := scanProject(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		project := &Project{
-			ID:          uuid.MustParse(id),
-			Title:       title,
-			Description: description.String,
-			Active:      active,
-		}
 		projects = append(projects, project)
 	}
 
 	row := r.connPool.QueryRow(
 		ctx,
 		`SELECT count(*) as total 
-		 FROM projects 
-		 WHERE org_id = $1 AND active = true`,
-		organizationID,
+		 FROM projects p 
+		 WHERE p.org_id = $1 AND p.active = $2 AND `+projectMemberCondition,
+		organizationID, !filter.Archived, filter.Username,
 	)
 	var total int
 	err = row.Scan(&total)
@@ -104,10 +117,10 @@ func (r *DbProjectRepository) FindProjects(ctx context.Context, organizationID u
 func (r *DbProjectRepository) FindProjectsByIDs(ctx context.Context, organizationID uuid.UUID, projectIDs []uuid.UUID) ([]*Project, error) {
 	rows, err := r.connPool.Query(
 		ctx,
-		`SELECT project_id as id, title, description, active 
-		 FROM projects 
-		 WHERE org_id = $1 AND project_id = any($2) 
-		 ORDER by title ASC`,
+		`SELECT `+projectColumns+` 
+		 FROM `+projectTables+` 
+		 WHERE p.org_id = $1 AND p.project_id = any($2) 
+		 ORDER by p.title ASC`,
 		organizationID, projectIDs,
 	)
 	if err != nil {
@@ -117,23 +130,41 @@ func (r *DbProjectRepository) FindProjectsByIDs(ctx context.Context, organizatio
 
 	var projects []*Project
 	for rows.Next() {
-		var (
-			id          string
-			title       string
-			description sql.NullString
-			active      bool
-		)
-
-		err = rows.Scan(&id, &title, &description, &active)
+		project, err := scanProject(rows)
 		if err != nil {
 			return nil, err
 		}
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// FindProjectsByTitles finds the projects with one of the titles ignoring case, including archived projects
+func (r *DbProjectRepository) FindProjectsByTitles(ctx context.Context, organizationID uuid.UUID, titles []string) ([]*Project, error) {
+	lowerTitles := make([]string, len(titles))
+	for i, title := range titles {
+		lowerTitles[i] = strings.ToLower(title)
+	}
+
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT `+projectColumns+` 
+		 FROM `+projectTables+` 
+		 WHERE p.org_id = $1 AND lower(p.title) = any($2) 
+		 ORDER by p.active DESC, p.title ASC`,
+		organizationID, lowerTitles,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		project := &Project{
-			ID:          uuid.MustParse(id),
-			Title:       title,
-			Description: description.String,
-			Active:      active,
+	var projects []*Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
 		}
 		projects = append(projects, project)
 	}
@@ -143,19 +174,12 @@ func (r *DbProjectRepository) FindProjectsByIDs(ctx context.Context, organizatio
 
 func (r *DbProjectRepository) FindProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) (*Project, error) {
 	row := r.connPool.QueryRow(ctx,
-		`SELECT project_id as id, title, description, active  
-         FROM projects 
-	     WHERE project_id = $1 AND org_id = $2`,
+		`SELECT `+projectColumns+`  
+         FROM `+projectTables+` 
+	     WHERE p.project_id = $1 AND p.org_id = $2`,
 		projectID, organizationID)
 
-	var (
-		id          string
-		title       string
-		description sql.NullString
-		active      bool
-	)
-
-	err := row.Scan(&id, &title, &description, &active)
+	project, err := scanProject(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrProjectNotFound
@@ -164,14 +188,24 @@ func (r *DbProjectRepository) FindProjectByID(ctx context.Context, organizationI
 		return nil, err
 	}
 
-	project := &Project{
-		ID:          uuid.MustParse(id),
-		Title:       title,
-		Description: description.String,
-		Active:      active,
+	return project, nil
+}
+
+// HasSubProjects checks if the project is the parent of other projects, including archived projects
+func (r *DbProjectRepository) HasSubProjects(ctx context.Context, organizationID, projectID uuid.UUID) (bool, error) {
+	row := r.connPool.QueryRow(ctx,
+		`SELECT count(*) 
+         FROM projects 
+	     WHERE parent_id = $1 AND org_id = $2`,
+		projectID, organizationID)
+
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return false, err
 	}
 
-	return project, nil
+	return count > 0, nil
 }
 
 func (r *DbProjectRepository) InsertProject(ctx context.Context, project *Project) (*Project, error) {
@@ -180,14 +214,21 @@ func (r *DbProjectRepository) InsertProject(ctx context.Context, project *Projec
 	_, err := tx.Exec(
 		ctx,
 		`INSERT INTO projects 
-		   (project_id, title, active, description, org_id) 
+		   (project_id, title, active, description, org_id, hourly_rate, currency, budget_type, budget, budget_period, client_id, parent_id) 
 		 VALUES 
-		   ($1, $2, $3, $4, $5)`,
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
 		project.ID,
 		project.Title,
 		project.Active,
 		project.Description,
 		project.OrganizationID,
+		project.HourlyRate,
+		project.CurrencyOrDefault(),
+		sql.NullString{String: project.BudgetType, Valid: project.BudgetType != ""},
+		project.Budget,
+		project.BudgetPeriodOrDefault(),
+		nullableUUID(project.ClientID),
+		nullableUUID(project.ParentID),
 	)
 	if err != nil {
 		return nil, err
@@ -201,11 +242,14 @@ func (r *DbProjectRepository) UpdateProject(ctx context.Context, organizationID
 
 	row := tx.QueryRow(ctx,
 		`UPDATE projects 
-		 SET title = $3, description = $4, active = $5 
+		 SET title = $3, description = $4, active = $5, hourly_rate = $6, currency = $7, 
+		   budget_type = $8, budget = $9, budget_period = $10, client_id = $11, parent_id = $12 
 		 WHERE project_id = $1 AND org_id = $2
 		 RETURNING project_id`,
 		project.ID, organizationID,
-		project.Title, project.Description, project.Active,
+		project.Title, project.Description, project.Active, project.HourlyRate, project.CurrencyOrDefault(),
+		sql.NullString{String: project.BudgetType, Valid: project.BudgetType != ""}, project.Budget, project.BudgetPeriodOrDefault(),
+		nullableUUID(project.ClientID), nullableUUID(project.ParentID),
 	)
 
 	var id string
@@ -224,16 +268,6 @@ func (r *DbProjectRepository) UpdateProject(ctx context.Context, organizationID
 func (r *DbProjectRepository) DeleteProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error {
 	tx := ctx.Value(contextKeyTx).(pgx.Tx)
 
-	_, err := tx.Exec(
-		ctx,
-		`DELETE FROM activities
-		 WHERE project_id = $1 AND org_id = $2`,
-		projectID, organizationID,
-	)
-	if err != nil {
-		return err
-	}
-
 	row := tx.QueryRow(ctx,
 		`DELETE 
          FROM projects 
@@ -242,7 +276,7 @@ func (r *DbProjectRepository) DeleteProjectByID(ctx context.Context, organizatio
 		projectID, organizationID)
 
 	var id string
-	err = row.Scan(&id)
+	err := row.Scan(&id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrProjectNotFound
@@ -258,6 +292,29 @@ func (r *DbProjectRepository) DeleteProjectByID(ctx context.Context, organizatio
 	return nil
 }
 
+func (r *DbProjectRepository) UnarchiveProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`UPDATE projects 
+		 SET active = true 
+		 WHERE project_id = $1 AND org_id = $2
+		 RETURNING project_id`,
+		projectID, organizationID)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrProjectNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 func (r *DbProjectRepository) ArchiveProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error {
 	tx := ctx.Value(contextKeyTx).(pgx.Tx)
 
@@ -284,3 +341,281 @@ func (r *DbProjectRepository) ArchiveProjectByID(ctx context.Context, organizati
 
 	return nil
 }
+
+func (r *DbProjectRepository) FindProjectUserRates(ctx context.Context, organizationID, projectID uuid.UUID) ([]*ProjectUserRate, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT username, hourly_rate 
+		 FROM project_user_rates 
+		 WHERE org_id = $1 AND project_id = $2 
+		 ORDER BY username ASC`,
+		organizationID, projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []*ProjectUserRate
+	for rows.Next() {
+		var (
+			username   string
+			hourlyRate float64
+		)
+
+		err = rows.Scan(&username, &hourlyRate)
+		if err != nil {
+			return nil, err
+		}
+
+		rate := &ProjectUserRate{
+			ProjectID:      projectID,
+			Username:       username,
+			HourlyRate:     hourlyRate,
+			OrganizationID: organizationID,
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func (r *DbProjectRepository) UpsertProjectUserRate(ctx context.Context, rate *ProjectUserRate) (*ProjectUserRate, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO project_user_rates 
+		   (project_id, username, hourly_rate, org_id) 
+		 VALUES 
+		   ($1, $2, $3, $4)
+		 ON CONFLICT (project_id, username) 
+		 DO UPDATE SET hourly_rate = EXCLUDED.hourly_rate`,
+		rate.ProjectID,
+		rate.Username,
+		rate.HourlyRate,
+		rate.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (r *DbProjectRepository) DeleteProjectUserRate(ctx context.Context, organizationID, projectID uuid.UUID, username string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`DELETE 
+         FROM project_user_rates 
+	     WHERE project_id = $1 AND org_id = $2 AND username = $3
+		 RETURNING project_id`,
+		projectID, organizationID, username)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrProjectUserRateNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// InsertProjectBudgetAlert records the alert unless it was already recorded before,
+// the result tells whether the alert is new
+func (r *DbProjectRepository) InsertProjectBudgetAlert(ctx context.Context, alert *ProjectBudgetAlert) (bool, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	result, err := tx.Exec(
+		ctx,
+		`INSERT INTO project_budget_alerts 
+		   (project_id, period_start, threshold, org_id, created_at) 
+		 VALUES 
+		   ($1, $2, $3, $4, $5)
+		 ON CONFLICT (project_id, period_start, threshold) DO NOTHING`,
+		alert.ProjectID,
+		alert.PeriodStart,
+		alert.Threshold,
+		alert.OrganizationID,
+		alert.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+const projectColumns = `p.project_id as id, p.title, p.description, p.active, p.hourly_rate, p.currency, p.budget_type, p.budget, p.budget_period, 
+  p.parent_id, parent.title as parent_title,
+  c.client_id, c.title as client_title, p.org_id`
+
+// projectTables joins the parent and the client of projects, sub-projects always belong to the client of their parent
+const projectTables = `projects p 
+  LEFT JOIN projects parent ON parent.project_id = p.parent_id 
+  LEFT JOIN clients c ON c.client_id = (CASE WHEN p.parent_id IS NULL THEN p.client_id ELSE parent.client_id END)`
+
+func scanProject(row pgx.Row) (*Project, error) {
+	var (
+		id           string
+		title        string
+		description  sql.NullString
+		active       bool
+		hourlyRate   float64
+		currency     string
+		budgetType   sql.NullString
+		budget       float64
+		budgetPeriod string
+		parentID     sql.NullString
+		parentTitle  sql.NullString
+		clientID     sql.NullString
+		clientTitle  sql.NullString
+		orgID        string
+	)
+
+	err := row.Scan(&id, &title, &description, &active, &hourlyRate, &currency, &budgetType, &budget, &budgetPeriod,
+		&parentID, &parentTitle, &clientID, &clientTitle, &orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{
+		ID:             uuid.MustParse(id),
+		Title:          title,
+		Description:    description.String,
+		Active:         active,
+		HourlyRate:     hourlyRate,
+		Currency:       currency,
+		BudgetType:     budgetType.String,
+		Budget:         budget,
+		BudgetPeriod:   budgetPeriod,
+		ParentTitle:    parentTitle.String,
+		ClientTitle:    clientTitle.String,
+		OrganizationID: uuid.MustParse(orgID),
+	}
+	if parentID.Valid {
+		project.ParentID = uuid.MustParse(parentID.String)
+	}
+	if clientID.Valid {
+		project.ClientID = uuid.MustParse(clientID.String)
+	}
+
+	return project, nil
+}
+
+// nullableUUID maps uuid.Nil to NULL
+func nullableUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+func (r *DbProjectRepository) FindProjectMembers(ctx context.Context, organizationID uuid.UUID, projectIDs []uuid.UUID) ([]*ProjectMember, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT project_id, username, role 
+		 FROM project_members 
+		 WHERE org_id = $1 AND project_id = any($2) 
+		 ORDER BY username ASC`,
+		organizationID, projectIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*ProjectMember
+	for rows.Next() {
+		member := &ProjectMember{
+			OrganizationID: organizationID,
+		}
+
+		err = rows.Scan(&member.ProjectID, &member.Username, &member.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *DbProjectRepository) FindManagedProjectIDs(ctx context.Context, organizationID uuid.UUID, username string) ([]uuid.UUID, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT project_id 
+		 FROM project_members 
+		 WHERE org_id = $1 AND username = $2 AND role = $3`,
+		organizationID, username, ProjectRoleManager,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projectIDs []uuid.UUID
+	for rows.Next() {
+		var projectID uuid.UUID
+		err = rows.Scan(&projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	return projectIDs, nil
+}
+
+func (r *DbProjectRepository) UpsertProjectMember(ctx context.Context, member *ProjectMember) (*ProjectMember, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO project_members 
+		   (project_id, username, role, org_id) 
+		 VALUES 
+		   ($1, $2, $3, $4)
+		 ON CONFLICT (project_id, username) 
+		 DO UPDATE SET role = EXCLUDED.role`,
+		member.ProjectID,
+		member.Username,
+		member.Role,
+		member.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (r *DbProjectRepository) DeleteProjectMember(ctx context.Context, organizationID, projectID uuid.UUID, username string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`DELETE 
+         FROM project_members 
+	     WHERE project_id = $1 AND org_id = $2 AND username = $3
+		 RETURNING project_id`,
+		projectID, organizationID, username)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrProjectMemberNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
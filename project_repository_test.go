@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/baralga/paged"
 	"github.com/google/uuid"
@@ -32,12 +34,14 @@ func TestProjectRepository(t *testing.T) {
 	}()
 
 	projectRepository := NewDbProjectRepository(connPool)
+	clientRepository := NewDbClientRepository(connPool)
 	repositoryTxer := NewDbRepositoryTxer(connPool)
 
 	t.Run("FindProjects", func(t *testing.T) {
 		projectsPage, err := projectRepository.FindProjects(
 			context.Background(),
 			organizationIDSample,
+			&ProjectFilter{},
 			&paged.PageParams{
 				Page: 0,
 				Size: 50,
@@ -61,6 +65,18 @@ func TestProjectRepository(t *testing.T) {
 		is.Equal(len(projects), 1)
 	})
 
+	t.Run("FindProjectsByTitles", func(t *testing.T) {
+		projects, err := projectRepository.FindProjectsByTitles(
+			context.Background(),
+			organizationIDSample,
+			[]string{"MY PROJECT", "Not existing"},
+		)
+
+		is.NoErr(err)
+		is.Equal(len(projects), 1)
+		is.Equal(projects[0].ID, projectIDSample)
+	})
+
 	t.Run("InsertAndUpdateProject", func(t *testing.T) {
 		project := &Project{
 			ID:             uuid.New(),
@@ -100,6 +116,60 @@ func TestProjectRepository(t *testing.T) {
 		is.Equal("My updated Description", projectUpdate.Description)
 	})
 
+	t.Run("InsertSubProjectOfClient", func(t *testing.T) {
+		client := &Client{
+			ID:             uuid.New(),
+			Title:          "My Client",
+			OrganizationID: organizationIDSample,
+		}
+		parent := &Project{
+			ID:             uuid.New(),
+			Title:          "My Parent",
+			Active:         true,
+			ClientID:       client.ID,
+			OrganizationID: organizationIDSample,
+		}
+		subProject := &Project{
+			ID:             uuid.New(),
+			Title:          "My Work Package",
+			Active:         true,
+			ParentID:       parent.ID,
+			OrganizationID: organizationIDSample,
+		}
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := clientRepository.InsertClient(ctx, client)
+				if err != nil {
+					return err
+				}
+				_, err = projectRepository.InsertProject(ctx, parent)
+				if err != nil {
+					return err
+				}
+				_, err = projectRepository.InsertProject(ctx, subProject)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		subProjectFound, err := projectRepository.FindProjectByID(context.Background(), organizationIDSample, subProject.ID)
+		is.NoErr(err)
+		is.Equal(parent.ID, subProjectFound.ParentID)
+		is.Equal("My Parent", subProjectFound.ParentTitle)
+		is.Equal(client.ID, subProjectFound.ClientID)
+		is.Equal("My Client", subProjectFound.ClientTitle)
+
+		hasSubProjects, err := projectRepository.HasSubProjects(context.Background(), organizationIDSample, parent.ID)
+		is.NoErr(err)
+		is.True(hasSubProjects)
+
+		hasSubProjects, err = projectRepository.HasSubProjects(context.Background(), organizationIDSample, subProject.ID)
+		is.NoErr(err)
+		is.True(!hasSubProjects)
+	})
+
 	t.Run("ArchiveProject", func(t *testing.T) {
 		// Arrange
 		project := &Project{
@@ -137,6 +207,210 @@ func TestProjectRepository(t *testing.T) {
 		// Assert
 		is.NoErr(err)
 	})
+
+	t.Run("UnarchiveProject", func(t *testing.T) {
+		// Arrange
+		project := &Project{
+			ID:             uuid.New(),
+			Title:          "My archived Title",
+			OrganizationID: organizationIDSample,
+			Active:         false,
+		}
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.InsertProject(ctx, project)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		archivedProjects, err := projectRepository.FindProjects(context.Background(), organizationIDSample, &ProjectFilter{Archived: true}, &paged.PageParams{Page: 0, Size: 50})
+		is.NoErr(err)
+		archivedCount := len(archivedProjects.Projects)
+		is.True(archivedCount > 0)
+		for _, p := range archivedProjects.Projects {
+			is.True(!p.Active)
+		}
+
+		// Act
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.UnarchiveProjectByID(ctx, organizationIDSample, project.ID)
+			},
+		)
+
+		// Assert
+		is.NoErr(err)
+
+		projectFound, err := projectRepository.FindProjectByID(context.Background(), organizationIDSample, project.ID)
+		is.NoErr(err)
+		is.True(projectFound.Active)
+
+		archivedProjects, err = projectRepository.FindProjects(context.Background(), organizationIDSample, &ProjectFilter{Archived: true}, &paged.PageParams{Page: 0, Size: 50})
+		is.NoErr(err)
+		is.Equal(archivedCount-1, len(archivedProjects.Projects))
+	})
+
+	t.Run("UnarchiveNotExistingProject", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.UnarchiveProjectByID(ctx, organizationIDSample, uuid.New())
+			},
+		)
+		is.True(errors.Is(err, ErrProjectNotFound))
+	})
+
+	t.Run("UpsertAndFindAndDeleteProjectMember", func(t *testing.T) {
+		member := &ProjectMember{
+			ProjectID:      projectIDSample,
+			Username:       "user1",
+			Role:           ProjectRoleMember,
+			OrganizationID: organizationIDSample,
+		}
+
+		projects, err := projectRepository.FindProjects(context.Background(), organizationIDSample, &ProjectFilter{Username: "user2"}, &paged.PageParams{Size: 50})
+		is.NoErr(err)
+		projectCount := len(projects.Projects)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.UpsertProjectMember(ctx, member)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		member.Role = ProjectRoleManager
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.UpsertProjectMember(ctx, member)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		members, err := projectRepository.FindProjectMembers(context.Background(), organizationIDSample, []uuid.UUID{projectIDSample})
+		is.NoErr(err)
+		is.Equal(1, len(members))
+		is.Equal(ProjectRoleManager, members[0].Role)
+
+		managedProjectIDs, err := projectRepository.FindManagedProjectIDs(context.Background(), organizationIDSample, "user1")
+		is.NoErr(err)
+		is.Equal([]uuid.UUID{projectIDSample}, managedProjectIDs)
+
+		projects, err = projectRepository.FindProjects(context.Background(), organizationIDSample, &ProjectFilter{Username: "user1"}, &paged.PageParams{Size: 50})
+		is.NoErr(err)
+		is.Equal(projectCount, len(projects.Projects))
+
+		projects, err = projectRepository.FindProjects(context.Background(), organizationIDSample, &ProjectFilter{Username: "user2"}, &paged.PageParams{Size: 50})
+		is.NoErr(err)
+		is.Equal(projectCount-1, len(projects.Projects))
+		is.Equal(projectCount-1, projects.Page.TotalElements)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.DeleteProjectMember(ctx, organizationIDSample, projectIDSample, member.Username)
+			},
+		)
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.DeleteProjectMember(ctx, organizationIDSample, projectIDSample, member.Username)
+			},
+		)
+		is.True(errors.Is(err, ErrProjectMemberNotFound))
+	})
+
+	t.Run("UpsertAndFindAndDeleteProjectUserRate", func(t *testing.T) {
+		rate := &ProjectUserRate{
+			ProjectID:      projectIDSample,
+			Username:       "user1@baralga.com",
+			HourlyRate:     80,
+			OrganizationID: organizationIDSample,
+		}
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.UpsertProjectUserRate(ctx, rate)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		rate.HourlyRate = 90
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.UpsertProjectUserRate(ctx, rate)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		rates, err := projectRepository.FindProjectUserRates(context.Background(), organizationIDSample, projectIDSample)
+		is.NoErr(err)
+		is.Equal(len(rates), 1)
+		is.Equal(rates[0].HourlyRate, 90.0)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.DeleteProjectUserRate(ctx, organizationIDSample, projectIDSample, rate.Username)
+			},
+		)
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return projectRepository.DeleteProjectUserRate(ctx, organizationIDSample, projectIDSample, rate.Username)
+			},
+		)
+		is.True(errors.Is(err, ErrProjectUserRateNotFound))
+	})
+
+	t.Run("InsertProjectBudgetAlert", func(t *testing.T) {
+		alert := &ProjectBudgetAlert{
+			ProjectID:      projectIDSample,
+			PeriodStart:    time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+			Threshold:      80,
+			OrganizationID: organizationIDSample,
+			CreatedAt:      time.Now(),
+		}
+
+		var inserted bool
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				i, err := projectRepository.InsertProjectBudgetAlert(ctx, alert)
+				inserted = i
+				return err
+			},
+		)
+		is.NoErr(err)
+		is.True(inserted)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				i, err := projectRepository.InsertProjectBudgetAlert(ctx, alert)
+				inserted = i
+				return err
+			},
+		)
+		is.NoErr(err)
+		is.True(!inserted)
+	})
 }
 
 func TestProjectRepositoryDeleteProject(t *testing.T) {
@@ -161,6 +435,7 @@ func TestProjectRepositoryDeleteProject(t *testing.T) {
 	}()
 
 	projectRepository := NewDbProjectRepository(connPool)
+	activityRepository := NewDbActivityRepository(connPool)
 	repositoryTxer := NewDbRepositoryTxer(connPool)
 
 	t.Run("FindProjectByID", func(t *testing.T) {
@@ -198,6 +473,69 @@ func TestProjectRepositoryDeleteProject(t *testing.T) {
 		is.True(errors.Is(err, ErrProjectNotFound))
 	})
 
+	t.Run("ReassignActivitiesOfProject", func(t *testing.T) {
+		targetProject := &Project{
+			ID:             uuid.New(),
+			Title:          "Target Project",
+			Active:         true,
+			OrganizationID: organizationIDSample,
+		}
+		activity := &Activity{
+			ID:             uuid.New(),
+			Start:          time.Date(2022, 2, 10, 15, 0, 0, 0, time.UTC),
+			End:            time.Date(2022, 2, 10, 16, 30, 0, 0, time.UTC),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Username:       "user1",
+		}
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := projectRepository.InsertProject(ctx, targetProject)
+				if err != nil {
+					return err
+				}
+				_, err = activityRepository.InsertActivity(ctx, activity)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				usage, err := activityRepository.FindProjectUsage(ctx, organizationIDSample, projectIDSample)
+				is.NoErr(err)
+				is.Equal(1, usage.ActivityCount)
+				is.Equal(90, usage.DurationInMinutesTotal)
+
+				activities, err := activityRepository.FindProjectActivities(ctx, organizationIDSample, projectIDSample)
+				is.NoErr(err)
+				is.Equal(1, len(activities))
+				is.Equal(activity.ID, activities[0].ID)
+
+				return activityRepository.ReassignProjectActivities(ctx, organizationIDSample, projectIDSample, targetProject.ID)
+			},
+		)
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				usage, err := activityRepository.FindProjectUsage(ctx, organizationIDSample, projectIDSample)
+				is.NoErr(err)
+				is.True(!usage.IsUsed())
+				return nil
+			},
+		)
+		is.NoErr(err)
+
+		activityFound, err := activityRepository.FindActivityByID(context.Background(), activity.ID, organizationIDSample)
+		is.NoErr(err)
+		is.Equal(targetProject.ID, activityFound.ProjectID)
+	})
+
 	t.Run("DeleteExistingProject", func(t *testing.T) {
 		err = repositoryTxer.InTx(
 			context.Background(),
@@ -215,7 +553,10 @@ func TestProjectRepositoryDeleteProject(t *testing.T) {
 }
 
 type InMemProjectRepository struct {
-	projects []*Project
+	projects     []*Project
+	userRates    []*ProjectUserRate
+	budgetAlerts []*ProjectBudgetAlert
+	members      []*ProjectMember
 }
 
 var _ ProjectRepository = (*InMemProjectRepository)(nil)
@@ -226,6 +567,7 @@ func NewInMemProjectRepository() *InMemProjectRepository {
 			{
 				ID:             projectIDSample,
 				Title:          "My Project",
+				Active:         true,
 				OrganizationID: organizationIDSample,
 			},
 		},
@@ -237,10 +579,24 @@ func (r *InMemProjectRepository) InsertProject(ctx context.Context, project *Pro
 	return project, nil
 }
 
-func (r *InMemProjectRepository) FindProjects(ctx context.Context, organizationID uuid.UUID, pageParams *paged.PageParams) (*ProjectsPaged, error) {
+func (r *InMemProjectRepository) FindProjects(ctx context.Context, organizationID uuid.UUID, filter *ProjectFilter, pageParams *paged.PageParams) (*ProjectsPaged, error) {
+	var projects []*Project
+	for _, p := range r.projects {
+		if p.Active == filter.Archived {
+			continue
+		}
+		if filter.Username != "" {
+			members, _ := r.FindProjectMembers(ctx, organizationID, []uuid.UUID{p.ID})
+			if !IsAssignedToProject(members, filter.Username) {
+				continue
+			}
+		}
+		projects = append(projects, p)
+	}
+
 	projectsPaged := &ProjectsPaged{
-		Projects: r.projects,
-		Page:     pageParams.PageOfTotal(len(r.projects)),
+		Projects: projects,
+		Page:     pageParams.PageOfTotal(len(projects)),
 	}
 	return projectsPaged, nil
 }
@@ -260,6 +616,21 @@ func (r *InMemProjectRepository) FindProjectsByIDs(ctx context.Context, organiza
 	return projects, nil
 }
 
+func (r *InMemProjectRepository) FindProjectsByTitles(ctx context.Context, organizationID uuid.UUID, titles []string) ([]*Project, error) {
+	var projects []*Project
+
+	for _, p := range r.projects {
+		for _, title := range titles {
+			if strings.EqualFold(p.Title, title) {
+				projects = append(projects, p)
+				break
+			}
+		}
+	}
+
+	return projects, nil
+}
+
 func (r *InMemProjectRepository) UpdateProject(ctx context.Context, organizationID uuid.UUID, project *Project) (*Project, error) {
 	for i, p := range r.projects {
 		if p.ID == project.ID {
@@ -290,6 +661,16 @@ func (r *InMemProjectRepository) ArchiveProjectByID(ctx context.Context, organiz
 	return ErrProjectNotFound
 }
 
+func (r *InMemProjectRepository) UnarchiveProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) error {
+	for i, a := range r.projects {
+		if a.ID == projectID {
+			r.projects[i].Active = true
+			return nil
+		}
+	}
+	return ErrProjectNotFound
+}
+
 func (r *InMemProjectRepository) FindProjectByID(ctx context.Context, organizationID, projectID uuid.UUID) (*Project, error) {
 	for _, a := range r.projects {
 		if a.ID == projectID {
@@ -298,3 +679,97 @@ func (r *InMemProjectRepository) FindProjectByID(ctx context.Context, organizati
 	}
 	return nil, ErrProjectNotFound
 }
+
+func (r *InMemProjectRepository) HasSubProjects(ctx context.Context, organizationID, projectID uuid.UUID) (bool, error) {
+	for _, p := range r.projects {
+		if p.ParentID == projectID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *InMemProjectRepository) FindProjectUserRates(ctx context.Context, organizationID, projectID uuid.UUID) ([]*ProjectUserRate, error) {
+	var rates []*ProjectUserRate
+	for _, rate := range r.userRates {
+		if rate.ProjectID == projectID {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+func (r *InMemProjectRepository) UpsertProjectUserRate(ctx context.Context, rate *ProjectUserRate) (*ProjectUserRate, error) {
+	for i, ur := range r.userRates {
+		if ur.ProjectID == rate.ProjectID && ur.Username == rate.Username {
+			r.userRates[i] = rate
+			return rate, nil
+		}
+	}
+	r.userRates = append(r.userRates, rate)
+	return rate, nil
+}
+
+func (r *InMemProjectRepository) DeleteProjectUserRate(ctx context.Context, organizationID, projectID uuid.UUID, username string) error {
+	for i, rate := range r.userRates {
+		if rate.ProjectID == projectID && rate.Username == username {
+			r.userRates = append(r.userRates[:i], r.userRates[i+1:]...)
+			return nil
+		}
+	}
+	return ErrProjectUserRateNotFound
+}
+
+func (r *InMemProjectRepository) InsertProjectBudgetAlert(ctx context.Context, alert *ProjectBudgetAlert) (bool, error) {
+	for _, a := range r.budgetAlerts {
+		if a.ProjectID == alert.ProjectID && a.PeriodStart.Equal(alert.PeriodStart) && a.Threshold == alert.Threshold {
+			return false, nil
+		}
+	}
+	r.budgetAlerts = append(r.budgetAlerts, alert)
+	return true, nil
+}
+
+func (r *InMemProjectRepository) FindProjectMembers(ctx context.Context, organizationID uuid.UUID, projectIDs []uuid.UUID) ([]*ProjectMember, error) {
+	var members []*ProjectMember
+	for _, member := range r.members {
+		for _, projectID := range projectIDs {
+			if member.ProjectID == projectID {
+				members = append(members, member)
+				break
+			}
+		}
+	}
+	return members, nil
+}
+
+func (r *InMemProjectRepository) FindManagedProjectIDs(ctx context.Context, organizationID uuid.UUID, username string) ([]uuid.UUID, error) {
+	var projectIDs []uuid.UUID
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID && member.Username == username && member.Role == ProjectRoleManager {
+			projectIDs = append(projectIDs, member.ProjectID)
+		}
+	}
+	return projectIDs, nil
+}
+
+func (r *InMemProjectRepository) UpsertProjectMember(ctx context.Context, member *ProjectMember) (*ProjectMember, error) {
+	for i, m := range r.members {
+		if m.ProjectID == member.ProjectID && m.Username == member.Username {
+			r.members[i] = member
+			return member, nil
+		}
+	}
+	r.members = append(r.members, member)
+	return member, nil
+}
+
+func (r *InMemProjectRepository) DeleteProjectMember(ctx context.Context, organizationID, projectID uuid.UUID, username string) error {
+	for i, m := range r.members {
+		if m.ProjectID == projectID && m.Username == username {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return ErrProjectMemberNotFound
+}
@@ -2,24 +2,54 @@ package main
 
 import (
 	"context"
+	"time"
 
+	"github.com/baralga/paged"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
+var ErrHourlyRateInvalid = errors.New("hourly rate invalid")
+var ErrProjectHierarchyInvalid = errors.New("project hierarchy invalid")
+var ErrProjectInUse = errors.New("project has activities")
+var ErrProjectReassignmentInvalid = errors.New("project reassignment invalid")
+var ErrProjectActivitiesLocked = errors.New("project has locked activities")
+var ErrProjectNotAssigned = errors.New("not assigned to project")
+var ErrProjectMemberInvalid = errors.New("project member invalid")
+
+// ReadProjects reads the projects of the organization, users only read the projects they are assigned to,
+// which includes the projects they manage
+func (a *app) ReadProjects(ctx context.Context, principal *Principal, filter *ProjectFilter, pageParams *paged.PageParams) (*ProjectsPaged, error) {
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if !permissions.IsAdmin() {
+		filter.Username = principal.Username
+	}
+	return a.ProjectRepository.FindProjects(ctx, principal.OrganizationID, filter, pageParams)
+}
+
 func (a *app) CreateProject(ctx context.Context, principal *Principal, project *Project) (*Project, error) {
 	project.ID = uuid.New()
 	project.OrganizationID = principal.OrganizationID
 
+	err := a.checkProjectHierarchy(ctx, principal, project)
+	if err != nil {
+		return nil, err
+	}
+
 	var projectCreated *Project
-	err := a.RepositoryTxer.InTx(
-		context.Background(),
+	err = a.RepositoryTxer.InTx(
+		ctx,
 		func(ctx context.Context) error {
-			a, err := a.ProjectRepository.InsertProject(ctx, project)
+			p, err := a.ProjectRepository.InsertProject(ctx, project)
 			if err != nil {
 				return err
 			}
-			projectCreated = a
-			return nil
+			projectCreated = p
+			return a.recordChange(ctx, principal, AuditActionCreate, AuditEntityProject, p.ID, nil, p)
 		},
 	)
 	if err != nil {
@@ -28,17 +58,28 @@ func (a *app) CreateProject(ctx context.Context, principal *Principal, project *
 	return projectCreated, nil
 }
 
-func (a *app) UpdateProject(ctx context.Context, organizationID uuid.UUID, project *Project) (*Project, error) {
+func (a *app) UpdateProject(ctx context.Context, principal *Principal, project *Project) (*Project, error) {
+	existingProject, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	projectBefore := *existingProject
+
+	err = a.checkProjectHierarchy(ctx, principal, project)
+	if err != nil {
+		return nil, err
+	}
+
 	var projectUpdated *Project
-	err := a.RepositoryTxer.InTx(
-		context.Background(),
+	err = a.RepositoryTxer.InTx(
+		ctx,
 		func(ctx context.Context) error {
-			p, err := a.ProjectRepository.UpdateProject(ctx, organizationID, project)
+			p, err := a.ProjectRepository.UpdateProject(ctx, principal.OrganizationID, project)
 			if err != nil {
 				return err
 			}
 			projectUpdated = p
-			return nil
+			return a.recordChange(ctx, principal, projectUpdateAction(&projectBefore, p), AuditEntityProject, p.ID, &projectBefore, p)
 		},
 	)
 	if err != nil {
@@ -47,28 +88,404 @@ func (a *app) UpdateProject(ctx context.Context, organizationID uuid.UUID, proje
 	return projectUpdated, nil
 }
 
-func (a *app) ArchiveProject(ctx context.Context, organizationID, projectID uuid.UUID) error {
+func (a *app) ArchiveProject(ctx context.Context, principal *Principal, projectID uuid.UUID) error {
+	existingProject, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return err
+	}
+	projectBefore := *existingProject
+	projectArchived := *existingProject
+	projectArchived.Active = false
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			err := a.ProjectRepository.ArchiveProjectByID(ctx, principal.OrganizationID, projectID)
+			if err != nil {
+				return err
+			}
+			return a.recordChange(ctx, principal, AuditActionArchive, AuditEntityProject, projectID, &projectBefore, &projectArchived)
+		},
+	)
+}
+
+func (a *app) UnarchiveProject(ctx context.Context, principal *Principal, projectID uuid.UUID) error {
+	existingProject, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return err
+	}
+	projectBefore := *existingProject
+	projectUnarchived := *existingProject
+	projectUnarchived.Active = true
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			err := a.ProjectRepository.UnarchiveProjectByID(ctx, principal.OrganizationID, projectID)
+			if err != nil {
+				return err
+			}
+			return a.recordChange(ctx, principal, AuditActionUnarchive, AuditEntityProject, projectID, &projectBefore, &projectUnarchived)
+		},
+	)
+}
+
+// DeleteProjectByID deletes the project after reassigning its activities to the target project,
+// so no tracked time is lost and a project with activities can't be deleted without a target
+func (a *app) DeleteProjectByID(ctx context.Context, principal *Principal, projectID, targetProjectID uuid.UUID) error {
+	existingProject, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return err
+	}
+
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			usage, err := a.ActivityRepository.FindProjectUsage(ctx, principal.OrganizationID, projectID)
+			if err != nil {
+				return err
+			}
+
+			if usage.IsUsed() {
+				err := a.reassignProjectActivities(ctx, principal, projectID, targetProjectID)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = a.ProjectRepository.DeleteProjectByID(ctx, principal.OrganizationID, projectID)
+			if err != nil {
+				return err
+			}
+			return a.recordChange(ctx, principal, AuditActionDelete, AuditEntityProject, projectID, existingProject, nil)
+		},
+	)
+}
+
+// reassignProjectActivities moves the activities of the project to the target project and records
+// the change of every moved activity, it fails if any of the activities is locked
+// and must be called within the transaction of the project's deletion
+func (a *app) reassignProjectActivities(ctx context.Context, principal *Principal, projectID, targetProjectID uuid.UUID) error {
+	if targetProjectID == uuid.Nil {
+		return ErrProjectInUse
+	}
+	if targetProjectID == projectID {
+		return ErrProjectReassignmentInvalid
+	}
+
+	_, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, targetProjectID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return ErrProjectReassignmentInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	activities, err := a.ActivityRepository.FindProjectActivities(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return err
+	}
+
+	err = a.checkActivitiesUnlocked(ctx, principal, activities)
+	if err != nil {
+		return err
+	}
+
+	err = a.ActivityRepository.ReassignProjectActivities(ctx, principal.OrganizationID, projectID, targetProjectID)
+	if err != nil {
+		return err
+	}
+
+	for _, activity := range activities {
+		activityMoved := *activity
+		activityMoved.ProjectID = targetProjectID
+
+		err := a.recordChange(ctx, principal, AuditActionUpdate, AuditEntityActivity, activity.ID, activity, &activityMoved)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkActivitiesUnlocked checks that none of the activities lies within the closed period
+// of the organization or is part of an approved timesheet, unlike the checks of single
+// activities closed periods apply to admins as well
+func (a *app) checkActivitiesUnlocked(ctx context.Context, principal *Principal, activities []*Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	organization, err := a.ReadOrganization(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	type userWeek struct {
+		username   string
+		year, week int
+	}
+	approvedWeeks := make(map[userWeek]bool)
+	for _, activity := range activities {
+		if organization.IsLockedAt(activity.Start, principal.Location()) {
+			return ErrProjectActivitiesLocked
+		}
+
+		year, week := activity.Start.In(principal.Location()).ISOWeek()
+		key := userWeek{activity.Username, year, week}
+		approved, ok := approvedWeeks[key]
+		if !ok {
+			timesheet, err := a.TimesheetRepository.FindTimesheetByWeek(ctx, principal.OrganizationID, activity.Username, year, week)
+			if err != nil && !errors.Is(err, ErrTimesheetNotFound) {
+				return err
+			}
+			approved = timesheet != nil && timesheet.IsApproved()
+			approvedWeeks[key] = approved
+		}
+		if approved {
+			return ErrProjectActivitiesLocked
+		}
+	}
+
+	return nil
+}
+
+// ReadProjectUsage reads the number and duration of the activities tracked on the project
+func (a *app) ReadProjectUsage(ctx context.Context, principal *Principal, projectID uuid.UUID) (*ProjectUsage, error) {
+	var usage *ProjectUsage
 	err := a.RepositoryTxer.InTx(
-		context.Background(),
+		ctx,
 		func(ctx context.Context) error {
-			err := a.ProjectRepository.ArchiveProjectByID(ctx, organizationID, projectID)
+			u, err := a.ActivityRepository.FindProjectUsage(ctx, principal.OrganizationID, projectID)
 			if err != nil {
 				return err
 			}
+			usage = u
 			return nil
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// ReadProjectTotals reads the time spent on and the revenue of the projects of the organization over all time
+func (a *app) ReadProjectTotals(ctx context.Context, principal *Principal) (map[uuid.UUID]*ActivityProjectReportItem, error) {
+	reportItems, err := a.ActivityRepository.ProjectReport(ctx, &ActivitiesFilter{
+		Start:          time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:            time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC),
+		Timezone:       principal.Location().String(),
+		OrganizationID: principal.OrganizationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uuid.UUID]*ActivityProjectReportItem)
+	for _, reportItem := range reportItems {
+		totals[reportItem.ProjectID] = reportItem
+	}
+
+	return totals, nil
+}
+
+// projectUpdateAction is the audited action of a project update, which archives
+// or unarchives the project if its active flag changes
+func projectUpdateAction(before, after *Project) string {
+	switch {
+	case before.Active && !after.Active:
+		return AuditActionArchive
+	case !before.Active && after.Active:
+		return AuditActionUnarchive
+	default:
+		return AuditActionUpdate
+	}
+}
+
+// checkProjectHierarchy checks that client and parent of the project exist. Projects are
+// organized in two levels only, so the parent must not be a sub-project itself and a project
+// with sub-projects can't become a sub-project. Sub-projects always belong to the client of their parent.
+func (a *app) checkProjectHierarchy(ctx context.Context, principal *Principal, project *Project) error {
+	if project.ParentID != uuid.Nil {
+		if project.ParentID == project.ID {
+			return ErrProjectHierarchyInvalid
+		}
+
+		parent, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, project.ParentID)
+		if errors.Is(err, ErrProjectNotFound) {
+			return ErrProjectHierarchyInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if parent.IsSubProject() {
+			return ErrProjectHierarchyInvalid
+		}
+
+		hasSubProjects, err := a.ProjectRepository.HasSubProjects(ctx, principal.OrganizationID, project.ID)
+		if err != nil {
+			return err
+		}
+		if hasSubProjects {
+			return ErrProjectHierarchyInvalid
+		}
+
+		project.ClientID = parent.ClientID
+		project.ClientTitle = parent.ClientTitle
+		project.ParentTitle = parent.Title
+		return nil
+	}
+
+	project.ParentTitle = ""
+	if project.ClientID == uuid.Nil {
+		project.ClientTitle = ""
+		return nil
+	}
+
+	client, err := a.ClientRepository.FindClientByID(ctx, principal.OrganizationID, project.ClientID)
+	if errors.Is(err, ErrClientNotFound) {
+		return ErrProjectHierarchyInvalid
+	}
 	if err != nil {
 		return err
 	}
+	project.ClientTitle = client.Title
+
 	return nil
 }
 
-func (a *app) DeleteProjectByID(ctx context.Context, principal *Principal, projectID uuid.UUID) error {
+// ReadProjectUserRates reads the hourly rates of users which override the rate of the project
+func (a *app) ReadProjectUserRates(ctx context.Context, principal *Principal, projectID uuid.UUID) ([]*ProjectUserRate, error) {
+	_, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.ProjectRepository.FindProjectUserRates(ctx, principal.OrganizationID, projectID)
+}
+
+// UpdateProjectUserRate sets the hourly rate of a user for the project
+func (a *app) UpdateProjectUserRate(ctx context.Context, principal *Principal, rate *ProjectUserRate) (*ProjectUserRate, error) {
+	if rate.HourlyRate < 0 {
+		return nil, ErrHourlyRateInvalid
+	}
+
+	_, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, rate.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rate.OrganizationID = principal.OrganizationID
+
+	var rateUpdated *ProjectUserRate
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			r, err := a.ProjectRepository.UpsertProjectUserRate(ctx, rate)
+			if err != nil {
+				return err
+			}
+			rateUpdated = r
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rateUpdated, nil
+}
+
+// DeleteProjectUserRate removes the hourly rate of a user, so the rate of the project applies again
+func (a *app) DeleteProjectUserRate(ctx context.Context, principal *Principal, projectID uuid.UUID, username string) error {
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.ProjectRepository.DeleteProjectUserRate(ctx, principal.OrganizationID, projectID, username)
+		},
+	)
+}
+
+// checkProjectAssigned checks if the principal is assigned to the project, admins are assigned to all projects
+func (a *app) checkProjectAssigned(ctx context.Context, principal *Principal, projectID uuid.UUID) error {
+	permissions, err := a.ReadPermissions(ctx, principal)
+	if err != nil {
+		return err
+	}
+
+	if permissions.ManagesProject(projectID) {
+		return nil
+	}
+
+	members, err := a.ProjectRepository.FindProjectMembers(ctx, principal.OrganizationID, []uuid.UUID{projectID})
+	if err != nil {
+		return err
+	}
+
+	if !IsAssignedToProject(members, principal.Username) {
+		return ErrProjectNotAssigned
+	}
+
+	return nil
+}
+
+// ReadProjectMembers reads the users assigned to the projects
+func (a *app) ReadProjectMembers(ctx context.Context, principal *Principal, projectIDs []uuid.UUID) ([]*ProjectMember, error) {
+	return a.ProjectRepository.FindProjectMembers(ctx, principal.OrganizationID, projectIDs)
+}
+
+// UpdateProjectMember assigns a user of the organization to the project, users without a role become members
+func (a *app) UpdateProjectMember(ctx context.Context, principal *Principal, member *ProjectMember) (*ProjectMember, error) {
+	if member.Role == "" {
+		member.Role = ProjectRoleMember
+	}
+	if !IsValidProjectRole(member.Role) {
+		return nil, ErrProjectMemberInvalid
+	}
+
+	_, err := a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, member.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.UserRepository.FindUserByUsername(ctx, member.Username)
+	if err == nil {
+		_, err = a.UserRepository.FindUserByID(ctx, principal.OrganizationID, user.ID)
+	}
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrProjectMemberInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	member.OrganizationID = principal.OrganizationID
+
+	var memberUpdated *ProjectMember
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			m, err := a.ProjectRepository.UpsertProjectMember(ctx, member)
+			if err != nil {
+				return err
+			}
+			memberUpdated = m
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return memberUpdated, nil
+}
+
+// DeleteProjectMember removes a user from the project, a project without members is open to all users again
+func (a *app) DeleteProjectMember(ctx context.Context, principal *Principal, projectID uuid.UUID, username string) error {
 	return a.RepositoryTxer.InTx(
 		ctx,
 		func(ctx context.Context) error {
-			return a.ProjectRepository.DeleteProjectByID(ctx, principal.OrganizationID, projectID)
+			return a.ProjectRepository.DeleteProjectMember(ctx, principal.OrganizationID, projectID, username)
 		},
 	)
 }
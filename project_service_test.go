@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/baralga/paged"
+	"github.com/google/uuid"
 	"github.com/matryer/is"
+	"github.com/pkg/errors"
 )
 
 func TestArchiveProject(t *testing.T) {
@@ -12,15 +16,445 @@ func TestArchiveProject(t *testing.T) {
 	is := is.New(t)
 
 	projectRepository := NewInMemProjectRepository()
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		ProjectRepository:  projectRepository,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: auditLogRepository,
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	err := a.ArchiveProject(context.Background(), principal, projectIDSample)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(projectRepository.projects[0].Active, false)
+	is.Equal(1, len(auditLogRepository.entries))
+	is.Equal(AuditActionArchive, auditLogRepository.entries[0].Action)
+	is.Equal(projectIDSample, auditLogRepository.entries[0].EntityID)
+}
+
+func TestUnarchiveProject(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = false
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		ProjectRepository:  projectRepository,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: auditLogRepository,
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	err := a.UnarchiveProject(context.Background(), principal, projectIDSample)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(projectRepository.projects[0].Active, true)
+	is.Equal(1, len(auditLogRepository.entries))
+	is.Equal(AuditActionUnarchive, auditLogRepository.entries[0].Action)
+}
+
+func TestUpdateProjectRecordsArchive(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		ProjectRepository:  projectRepository,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: auditLogRepository,
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.UpdateProject(context.Background(), principal, &Project{ID: projectIDSample, Title: "My Project", Active: false})
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(1, len(auditLogRepository.entries))
+	is.Equal(AuditActionArchive, auditLogRepository.entries[0].Action)
+}
+
+func TestCreateSubProjectOfClient(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	clientRepository := NewInMemClientRepository()
+	a := &app{
+		ProjectRepository:  projectRepository,
+		ClientRepository:   clientRepository,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	client, err := a.CreateClient(context.Background(), principal, &Client{Title: "ACME"})
+	is.NoErr(err)
+
+	parent, err := a.CreateProject(context.Background(), principal, &Project{Title: "Website", Active: true, ClientID: client.ID})
+	is.NoErr(err)
+	is.Equal("ACME", parent.ClientTitle)
+
+	// Act
+	subProject, err := a.CreateProject(context.Background(), principal, &Project{Title: "Design", Active: true, ParentID: parent.ID})
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(client.ID, subProject.ClientID)
+	is.Equal("Website › Design", subProject.HierarchicalTitle())
+}
+
+func TestCreateProjectWithInvalidHierarchy(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	a := &app{
+		ProjectRepository:  projectRepository,
+		ClientRepository:   NewInMemClientRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	subProject, err := a.CreateProject(context.Background(), principal, &Project{Title: "Design", Active: true, ParentID: projectIDSample})
+	is.NoErr(err)
+
+	// Act & Assert
+	_, err = a.CreateProject(context.Background(), principal, &Project{Title: "Mockups", Active: true, ParentID: subProject.ID})
+	is.True(errors.Is(err, ErrProjectHierarchyInvalid))
+
+	_, err = a.CreateProject(context.Background(), principal, &Project{Title: "Mockups", Active: true, ParentID: uuid.New()})
+	is.True(errors.Is(err, ErrProjectHierarchyInvalid))
+
+	_, err = a.CreateProject(context.Background(), principal, &Project{Title: "Mockups", Active: true, ClientID: uuid.New()})
+	is.True(errors.Is(err, ErrProjectHierarchyInvalid))
+
+	otherProject, err := a.CreateProject(context.Background(), principal, &Project{Title: "Other", Active: true})
+	is.NoErr(err)
+
+	parent, err := projectRepository.FindProjectByID(context.Background(), organizationIDSample, projectIDSample)
+	is.NoErr(err)
+	parentWithParent := *parent
+	parentWithParent.ParentID = otherProject.ID
+	_, err = a.UpdateProject(context.Background(), principal, &parentWithParent)
+	is.True(errors.Is(err, ErrProjectHierarchyInvalid))
+}
+
+func TestReadProjectsOfAssignedUser(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
 	a := &app{
 		ProjectRepository: projectRepository,
+	}
+
+	pageParams := &paged.PageParams{Size: 50}
+	admin := &Principal{Username: "admin", OrganizationID: organizationIDSample, Roles: []string{"ROLE_ADMIN"}}
+	member := &Principal{Username: "user1", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+	other := &Principal{Username: "user2", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+
+	// Act
+	projectsOfAdmin, errAdmin := a.ReadProjects(context.Background(), admin, &ProjectFilter{}, pageParams)
+	projectsOfMember, errMember := a.ReadProjects(context.Background(), member, &ProjectFilter{}, pageParams)
+	projectsOfOther, errOther := a.ReadProjects(context.Background(), other, &ProjectFilter{}, pageParams)
+
+	// Assert
+	is.NoErr(errAdmin)
+	is.NoErr(errMember)
+	is.NoErr(errOther)
+	is.Equal(1, len(projectsOfAdmin.Projects))
+	is.Equal(1, len(projectsOfMember.Projects))
+	is.Equal(0, len(projectsOfOther.Projects))
+}
+
+func TestCreateActivityOnUnassignedProject(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+		{ProjectID: projectIDSample, Username: "manager1", Role: ProjectRoleManager, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     NewInMemActivityRepository(),
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	admin := &Principal{Username: "admin", OrganizationID: organizationIDSample, Roles: []string{"ROLE_ADMIN"}}
+	member := &Principal{Username: "user1", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+	manager := &Principal{Username: "manager1", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+	other := &Principal{Username: "user2", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+
+	newActivity := func() *Activity {
+		return &Activity{
+			Start:     time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC),
+			End:       time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC),
+			ProjectID: projectIDSample,
+		}
+	}
+
+	// Act
+	_, errAdmin := a.CreateActivity(context.Background(), admin, newActivity())
+	_, errMember := a.CreateActivity(context.Background(), member, newActivity())
+	_, errManager := a.CreateActivity(context.Background(), manager, newActivity())
+	_, errOther := a.CreateActivity(context.Background(), other, newActivity())
+
+	// Assert
+	is.NoErr(errAdmin)
+	is.NoErr(errMember)
+	is.NoErr(errManager)
+	is.True(errors.Is(errOther, ErrProjectNotAssigned))
+}
+
+func TestUpdateActivityOntoUnassignedProject(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	otherProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Other Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, otherProject)
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: otherProject.ID, Username: "user2", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     activityRepository,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	user := &Principal{Username: "user1", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+
+	activity := *activityRepository.activities[0]
+	activity.ProjectID = otherProject.ID
+
+	// Act
+	_, err := a.UpdateActivity(context.Background(), user, &activity)
+
+	// Assert
+	is.True(errors.Is(err, ErrProjectNotAssigned))
+	is.Equal(projectIDSample, activityRepository.activities[0].ProjectID)
+}
+
+func TestUpdateProjectMember(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	a := &app{
 		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ProjectRepository: projectRepository,
+		UserRepository:    NewInMemUserRepository(),
 	}
 
+	admin := &Principal{Username: "admin", OrganizationID: organizationIDSample, Roles: []string{"ROLE_ADMIN"}}
+
 	// Act
-	err := a.ArchiveProject(context.Background(), organizationIDSample, projectIDSample)
+	member, err := a.UpdateProjectMember(context.Background(), admin, &ProjectMember{
+		ProjectID: projectIDSample,
+		Username:  "admin@baralga.com",
+	})
+	_, errUnknownUser := a.UpdateProjectMember(context.Background(), admin, &ProjectMember{
+		ProjectID: projectIDSample,
+		Username:  "unknown@baralga.com",
+	})
+	_, errUnknownRole := a.UpdateProjectMember(context.Background(), admin, &ProjectMember{
+		ProjectID: projectIDSample,
+		Username:  "admin@baralga.com",
+		Role:      "owner",
+	})
 
 	// Assert
 	is.NoErr(err)
-	is.Equal(projectRepository.projects[0].Active, false)
+	is.Equal(ProjectRoleMember, member.Role)
+	is.Equal(1, len(projectRepository.members))
+	is.True(errors.Is(errUnknownUser, ErrProjectMemberInvalid))
+	is.True(errors.Is(errUnknownRole, ErrProjectMemberInvalid))
+}
+
+func TestUpdateActivityAsProjectManager(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	otherProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Other Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, otherProject)
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "manager", Role: ProjectRoleManager, OrganizationID: organizationIDSample},
+	}
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     activityRepository,
+		ProjectRepository:      projectRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	manager := &Principal{Username: "manager", OrganizationID: organizationIDSample, Roles: []string{"ROLE_USER"}}
+
+	activityUpdate := *activityRepository.activities[0]
+	activityUpdate.Description = "Changed by manager"
+
+	activityMove := *activityRepository.activities[0]
+	activityMove.ProjectID = otherProject.ID
+
+	// Act
+	_, errUpdate := a.UpdateActivity(context.Background(), manager, &activityUpdate)
+	_, errMove := a.UpdateActivity(context.Background(), manager, &activityMove)
+
+	// Assert
+	is.NoErr(errUpdate)
+	is.Equal("Changed by manager", activityRepository.activities[0].Description)
+	is.True(errors.Is(errMove, ErrProjectNotAssigned))
+	is.Equal(projectIDSample, activityRepository.activities[0].ProjectID)
+}
+
+func TestDeleteProjectWithReassignment(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	targetProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Target Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, targetProject)
+	activityRepository := NewInMemActivityRepository()
+	auditLogRepository := NewInMemAuditLogRepository()
+	a := &app{
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     auditLogRepository,
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	err := a.DeleteProjectByID(context.Background(), admin, projectIDSample, targetProject.ID)
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(targetProject.ID, activityRepository.activities[0].ProjectID)
+	is.Equal(2, len(auditLogRepository.entries))
+	is.Equal(AuditEntityActivity, auditLogRepository.entries[0].EntityType)
+	is.Equal(AuditActionUpdate, auditLogRepository.entries[0].Action)
+	is.Equal(activityRepository.activities[0].ID, auditLogRepository.entries[0].EntityID)
+	is.Equal(AuditEntityProject, auditLogRepository.entries[1].EntityType)
+	is.Equal(AuditActionDelete, auditLogRepository.entries[1].Action)
+}
+
+func TestDeleteProjectWithActivitiesInClosedPeriod(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	targetProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Target Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, targetProject)
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities[0].Start = time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	activityRepository.activities[0].End = time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+	a := &app{
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      projectRepository,
+		ActivityRepository:     activityRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	lockedUntil := time.Date(2021, 11, 30, 0, 0, 0, 0, time.UTC)
+	_, err := a.UpdateLockedUntil(context.Background(), admin, &lockedUntil)
+	is.NoErr(err)
+
+	// Act
+	err = a.DeleteProjectByID(context.Background(), admin, projectIDSample, targetProject.ID)
+
+	// Assert
+	is.True(errors.Is(err, ErrProjectActivitiesLocked))
+	is.Equal(projectIDSample, activityRepository.activities[0].ProjectID)
 }
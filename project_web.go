@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	hx "github.com/baralga/htmx"
 	"github.com/baralga/paged"
@@ -24,6 +26,51 @@ type projectFormModel struct {
 	Title     string ` validate:"required,min=3,max=50"`
 }
 
+type projectRateFormModel struct {
+	CSRFToken  string
+	HourlyRate float64 `validate:"min=0"`
+	Currency   string  `validate:"required,len=3,alpha"`
+}
+
+type projectHierarchyFormModel struct {
+	CSRFToken string
+	ClientID  string `validate:"omitempty,uuid"`
+	ParentID  string `validate:"omitempty,uuid"`
+}
+
+type projectDeleteFormModel struct {
+	CSRFToken  string
+	ReassignTo string `validate:"omitempty,uuid"`
+}
+
+type clientFormModel struct {
+	CSRFToken string
+	Title     string `validate:"required,min=2,max=100"`
+}
+
+type projectMemberFormModel struct {
+	CSRFToken string
+	Username  string `validate:"required,max=50"`
+	Role      string `validate:"omitempty,oneof=member manager"`
+}
+
+// projectCardOptions are the clients, parent projects and users a project can be assigned to
+// together with the members of the projects
+type projectCardOptions struct {
+	permissions *Permissions
+	clients     []*Client
+	projects    []*Project
+	users       []*User
+	members     []*ProjectMember
+}
+
+type projectBudgetFormModel struct {
+	CSRFToken    string
+	BudgetType   string  `validate:"omitempty,oneof=hours money"`
+	Budget       float64 `validate:"min=0"`
+	BudgetPeriod string  `validate:"required,oneof=total monthly"`
+}
+
 func (a *app) HandleProjectsPage() http.HandlerFunc {
 	isProduction := a.isProduction()
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +81,19 @@ func (a *app) HandleProjectsPage() http.HandlerFunc {
 			Size: 50,
 		}
 
-		projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		consumptions, err := a.ReadBudgetConsumptions(r.Context(), principal, projects.Projects)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		options, err := a.readProjectCardOptions(r, principal, projects.Projects)
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, err)
 			return
@@ -50,7 +109,7 @@ func (a *app) HandleProjectsPage() http.HandlerFunc {
 			formModel := projectFormModel{}
 			formModel.CSRFToken = csrf.Token(r)
 
-			util.RenderHTML(w, ProjectsPage(pageContext, formModel, projects))
+			util.RenderHTML(w, ProjectsPage(pageContext, formModel, projects, options, consumptions))
 			return
 		}
 
@@ -59,7 +118,53 @@ func (a *app) HandleProjectsPage() http.HandlerFunc {
 		formModel := projectFormModel{}
 		formModel.CSRFToken = csrf.Token(r)
 
-		util.RenderHTML(w, ProjectsView(principal, formModel, projects))
+		util.RenderHTML(w, ProjectsView(formModel, projects, options, consumptions))
+	}
+}
+
+// HandleArchivedProjectsPage lists the archived projects with their totals
+func (a *app) HandleArchivedProjectsPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		pageParams := &paged.PageParams{
+			Page: 0,
+			Size: 50,
+		}
+
+		projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{Archived: true}, pageParams)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		totals, err := a.ReadProjectTotals(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !hx.IsHXRequest(r) {
+			pageContext := &pageContext{
+				principal:   principal,
+				currentPath: r.URL.Path,
+				title:       "Archived Projects",
+			}
+
+			util.RenderHTML(w, ArchivedProjectsPage(pageContext, permissions, csrf.Token(r), projects, totals))
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__main_content_modal-show")
+
+		util.RenderHTML(w, ArchivedProjectsView(permissions, csrf.Token(r), projects, totals))
 	}
 }
 
@@ -69,12 +174,18 @@ func (a *app) HandleProjectForm() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 
-		if !principal.HasRole("ROLE_ADMIN") {
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
 			http.Error(w, "No permission.", http.StatusForbidden)
 			return
 		}
 
-		err := r.ParseForm()
+		err = r.ParseForm()
 		if err != nil {
 			_ = a.renderProjectsView(
 				w,
@@ -146,12 +257,18 @@ func (a *app) HandleArchiveProject() http.HandlerFunc {
 			return
 		}
 
-		if !principal.HasRole("ROLE_ADMIN") {
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
 
-		err = a.ArchiveProject(r.Context(), principal.OrganizationID, projectID)
+		err = a.ArchiveProject(r.Context(), principal, projectID)
 		if errors.Is(err, ErrProjectNotFound) {
 			w.WriteHeader(http.StatusNotFound)
 			return
@@ -165,110 +282,1551 @@ func (a *app) HandleArchiveProject() http.HandlerFunc {
 	}
 }
 
-func (a *app) renderProjectsView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel projectFormModel) error {
-	pageParams := &paged.PageParams{
-		Page: 0,
-		Size: 50,
-	}
+// HandleUnarchiveProject reactivates an archived project
+func (a *app) HandleUnarchiveProject() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 
-	projects, err := a.ProjectRepository.FindProjects(r.Context(), principal.OrganizationID, pageParams)
-	if err != nil {
-		return err
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = a.UnarchiveProject(r.Context(), principal, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
 	}
+}
 
-	formModel.CSRFToken = csrf.Token(r)
+// HandleDeleteProjectPage asks to confirm the deletion of a project showing the activities affected
+func (a *app) HandleDeleteProjectPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
 
-	util.RenderHTML(w, ProjectsView(principal, formModel, projects))
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
 
-	return nil
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderProjectDeleteCard(w, r, principal, isProduction, project, "")
+	}
 }
 
-func ProjectsPage(pageContext *pageContext, formModel projectFormModel, projects *ProjectsPaged) g.Node {
-	return Page(
-		pageContext.title,
-		pageContext.currentPath,
-		[]g.Node{
-			Navbar(pageContext),
-			Section(
-				Class("full-center"),
-				Div(
-					Class("container"),
-					Div(
-						Class("mt-4 mb-4"),
-					),
-					ProjectsView(pageContext.principal, formModel, projects),
-				),
-			),
-		},
-	)
+// HandleDeleteProjectForm deletes a project after reassigning its activities to the chosen project
+func (a *app) HandleDeleteProjectForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			a.renderProjectDeleteCard(w, r, principal, isProduction, project, "")
+			return
+		}
+
+		var formModel projectDeleteFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			a.renderProjectDeleteCard(w, r, principal, isProduction, project, "Please choose a valid project to reassign the activities to.")
+			return
+		}
+
+		targetProjectID := uuid.Nil
+		if formModel.ReassignTo != "" {
+			targetProjectID = uuid.MustParse(formModel.ReassignTo)
+		}
+
+		err = a.DeleteProjectByID(r.Context(), principal, projectID, targetProjectID)
+		if errors.Is(err, ErrProjectInUse) || errors.Is(err, ErrProjectReassignmentInvalid) {
+			a.renderProjectDeleteCard(w, r, principal, isProduction, project, "Please choose the project to reassign the activities to.")
+			return
+		}
+		if errors.Is(err, ErrProjectActivitiesLocked) {
+			a.renderProjectDeleteCard(w, r, principal, isProduction, project, "Some activities of the project are in a closed period or an approved timesheet, so they can't be reassigned.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "{ \"baralga__activities-changed\": true, \"baralga__projects-changed\": true } ")
+	}
 }
 
-func ProjectsView(principal *Principal, formModel projectFormModel, projects *ProjectsPaged) g.Node {
-	return Div(
-		ID("baralga__main_content_modal_content"),
-		Class("modal-content"),
-		Div(
-			Class("modal-header"),
-			H2(
-				Class("modal-title"),
-				g.Text("Projects"),
-			),
-			Button(
-				Type("type"),
-				Class("btn-close"),
-				g.Attr("data-bs-dismiss", "modal"),
-			),
-		),
-		Div(
-			Class("modal-body"),
-			g.If(
-				principal.HasRole("ROLE_ADMIN"),
-				ProjectForm(formModel, ""),
-			),
-			g.Group(
-				g.Map(len(projects.Projects), func(i int) g.Node {
-					project := projects.Projects[i]
-					return Div(
-						Class("card mt-2"),
+// HandleProjectMemberForm assigns a user to a project
+func (a *app) HandleProjectMemberForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
 
-						hx.Target("this"),
-						hx.Swap("outerHTML"),
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
 
-						Div(
-							Class("card-body"),
-							H5(
-								Class("card-title mt-2"),
-								Div(
-									Class("d-flex justify-content-between mb-2"),
-									Span(
-										Class("flex-grow-1"),
-										g.Text(project.Title),
-									),
-									g.If(
-										principal.HasRole("ROLE_ADMIN"),
-										A(
-											hx.Confirm(fmt.Sprintf("Do you really want to delete project %v?", project.Title)),
-											hx.Delete(fmt.Sprintf("/api/projects/%v", project.ID)),
-											Class("btn btn-outline-secondary btn-sm ms-1"),
-											I(Class("bi-trash2")),
-										),
-									),
-									g.If(
-										principal.HasRole("ROLE_ADMIN"),
-										A(
-											hx.Confirm(fmt.Sprintf("Do you really want to archive project %v?", project.Title)),
-											hx.Get(fmt.Sprintf("/projects/%v/archive", project.ID)),
-											Class("btn btn-outline-secondary btn-sm ms-1"),
-											I(Class("bi-archive")),
-										),
-									),
-								),
-							),
-						),
-					)
-				}),
-			),
-		),
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "")
+			return
+		}
+
+		var formModel projectMemberFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Please choose a user and role.")
+			return
+		}
+
+		_, err = a.UpdateProjectMember(r.Context(), principal, &ProjectMember{
+			ProjectID: projectID,
+			Username:  formModel.Username,
+			Role:      formModel.Role,
+		})
+		if errors.Is(err, ErrProjectMemberInvalid) {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Please choose a user and role.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
+		a.renderProjectCard(w, r, principal, isProduction, project, "")
+	}
+}
+
+// HandleDeleteProjectMemberForm removes a user from a project
+func (a *app) HandleDeleteProjectMemberForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		username := chi.URLParam(r, "username")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = a.DeleteProjectMember(r.Context(), principal, projectID, username)
+		if err != nil && !errors.Is(err, ErrProjectMemberNotFound) {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
+		a.renderProjectCard(w, r, principal, isProduction, project, "")
+	}
+}
+
+// HandleProjectRateForm updates the hourly rate and currency of a project
+func (a *app) HandleProjectRateForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "")
+			return
+		}
+
+		var formModel projectRateFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Please enter a valid hourly rate and currency.")
+			return
+		}
+
+		project.HourlyRate = formModel.HourlyRate
+		project.Currency = strings.ToUpper(formModel.Currency)
+
+		project, err = a.UpdateProject(r.Context(), principal, project)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderProjectCard(w, r, principal, isProduction, project, "")
+	}
+}
+
+// HandleProjectBudgetForm updates the budget of a project
+func (a *app) HandleProjectBudgetForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "")
+			return
+		}
+
+		var formModel projectBudgetFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Please enter a valid budget.")
+			return
+		}
+
+		project.BudgetType = formModel.BudgetType
+		project.Budget = formModel.Budget
+		project.BudgetPeriod = formModel.BudgetPeriod
+
+		project, err = a.UpdateProject(r.Context(), principal, project)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderProjectCard(w, r, principal, isProduction, project, "")
+	}
+}
+
+// HandleProjectHierarchyForm updates the client and the parent of a project
+func (a *app) HandleProjectHierarchyForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectIDParam := chi.URLParam(r, "project-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		projectID, err := uuid.Parse(projectIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		project, err := a.ProjectRepository.FindProjectByID(r.Context(), principal.OrganizationID, projectID)
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "")
+			return
+		}
+
+		var formModel projectHierarchyFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Please choose a valid client and parent project.")
+			return
+		}
+
+		projectToUpdate := *project
+		projectToUpdate.ClientID = uuid.Nil
+		projectToUpdate.ParentID = uuid.Nil
+		if formModel.ClientID != "" {
+			projectToUpdate.ClientID = uuid.MustParse(formModel.ClientID)
+		}
+		if formModel.ParentID != "" {
+			projectToUpdate.ParentID = uuid.MustParse(formModel.ParentID)
+		}
+
+		projectUpdated, err := a.UpdateProject(r.Context(), principal, &projectToUpdate)
+		if errors.Is(err, ErrProjectHierarchyInvalid) {
+			a.renderProjectCard(w, r, principal, isProduction, project, "Sub-projects can't have sub-projects themselves.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
+		a.renderProjectCard(w, r, principal, isProduction, projectUpdated, "")
+	}
+}
+
+// HandleClientForm creates a client
+func (a *app) HandleClientForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		var formModel clientFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err == nil {
+			err = validator.Struct(formModel)
+		}
+		if err != nil {
+			err = a.renderProjectsView(w, r, principal, isProduction, projectFormModel{})
+			if err != nil {
+				util.RenderProblemHTML(w, isProduction, err)
+			}
+			return
+		}
+
+		_, err = a.CreateClient(r.Context(), principal, &Client{Title: formModel.Title})
+		if err != nil && !errors.Is(err, ErrClientInvalid) {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = a.renderProjectsView(w, r, principal, isProduction, projectFormModel{})
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+	}
+}
+
+// HandleDeleteClientForm deletes a client, its projects are kept without client
+func (a *app) HandleDeleteClientForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientIDParam := chi.URLParam(r, "client-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		permissions, err := a.ReadPermissions(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		if !permissions.IsAdmin() {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		clientID, err := uuid.Parse(clientIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = a.DeleteClient(r.Context(), principal, clientID)
+		if errors.Is(err, ErrClientNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		w.Header().Set("HX-Trigger", "baralga__projects-changed")
+		err = a.renderProjectsView(w, r, principal, isProduction, projectFormModel{})
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+	}
+}
+
+// readProjectCardOptions reads the options of the cards of the projects, only admins
+// assign users to projects so the users and members are read for admins only
+func (a *app) readProjectCardOptions(r *http.Request, principal *Principal, projects []*Project) (*projectCardOptions, error) {
+	clients, err := a.ReadClients(r.Context(), principal)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := a.ReadPermissions(r.Context(), principal)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &projectCardOptions{
+		permissions: permissions,
+		clients:     clients,
+		projects:    projects,
+	}
+
+	if !permissions.IsAdmin() {
+		return options, nil
+	}
+
+	users, err := a.UserRepository.FindUsers(r.Context(), principal.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	options.users = users
+
+	projectIDs := make([]uuid.UUID, len(projects))
+	for i, project := range projects {
+		projectIDs[i] = project.ID
+	}
+
+	members, err := a.ReadProjectMembers(r.Context(), principal, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	options.members = members
+
+	return options, nil
+}
+
+func (a *app) renderProjectCard(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, project *Project, errorMessage string) {
+	consumption, err := a.ReadBudgetConsumption(r.Context(), principal, project)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	pageParams := &paged.PageParams{
+		Page: 0,
+		Size: 50,
+	}
+
+	projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	options, err := a.readProjectCardOptions(r, principal, projects.Projects)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	util.RenderHTML(w, ProjectCard(csrf.Token(r), project, consumption, options, errorMessage))
+}
+
+func (a *app) renderProjectDeleteCard(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, project *Project, errorMessage string) {
+	usage, err := a.ReadProjectUsage(r.Context(), principal, project.ID)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	pageParams := &paged.PageParams{
+		Page: 0,
+		Size: 50,
+	}
+
+	projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	var targetProjects []*Project
+	for _, p := range projects.Projects {
+		if p.ID != project.ID {
+			targetProjects = append(targetProjects, p)
+		}
+	}
+
+	util.RenderHTML(w, ProjectDeleteCard(csrf.Token(r), project, usage, targetProjects, errorMessage))
+}
+
+func (a *app) renderProjectsView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel projectFormModel) error {
+	pageParams := &paged.PageParams{
+		Page: 0,
+		Size: 50,
+	}
+
+	projects, err := a.ReadProjects(r.Context(), principal, &ProjectFilter{}, pageParams)
+	if err != nil {
+		return err
+	}
+
+	consumptions, err := a.ReadBudgetConsumptions(r.Context(), principal, projects.Projects)
+	if err != nil {
+		return err
+	}
+
+	options, err := a.readProjectCardOptions(r, principal, projects.Projects)
+	if err != nil {
+		return err
+	}
+
+	formModel.CSRFToken = csrf.Token(r)
+
+	util.RenderHTML(w, ProjectsView(formModel, projects, options, consumptions))
+
+	return nil
+}
+
+func ProjectsPage(pageContext *pageContext, formModel projectFormModel, projects *ProjectsPaged, options *projectCardOptions, consumptions map[uuid.UUID]*ProjectBudgetConsumption) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+					),
+					ProjectsView(formModel, projects, options, consumptions),
+				),
+			),
+		},
+	)
+}
+
+func ProjectsView(formModel projectFormModel, projects *ProjectsPaged, options *projectCardOptions, consumptions map[uuid.UUID]*ProjectBudgetConsumption) g.Node {
+	orderedProjects := OrderProjectsByHierarchy(projects.Projects)
+
+	return Div(
+		ID("baralga__main_content_modal_content"),
+		Class("modal-content"),
+		Div(
+			Class("modal-header"),
+			H2(
+				Class("modal-title"),
+				g.Text("Projects"),
+			),
+			Button(
+				Type("type"),
+				Class("btn-close"),
+				g.Attr("data-bs-dismiss", "modal"),
+			),
+		),
+		Div(
+			Class("modal-body"),
+			ProjectsNav(false),
+			g.If(
+				options.permissions.IsAdmin(),
+				ProjectForm(formModel, ""),
+			),
+			g.If(
+				options.permissions.IsAdmin(),
+				ClientsView(formModel.CSRFToken, options.clients),
+			),
+			g.Group(
+				g.Map(len(orderedProjects), func(i int) g.Node {
+					project := orderedProjects[i]
+					return ProjectCard(formModel.CSRFToken, project, consumptions[project.ID], options, "")
+				}),
+			),
+		),
+	)
+}
+
+func ArchivedProjectsPage(pageContext *pageContext, permissions *Permissions, csrfToken string, projects *ProjectsPaged, totals map[uuid.UUID]*ActivityProjectReportItem) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+					),
+					ArchivedProjectsView(permissions, csrfToken, projects, totals),
+				),
+			),
+		},
+	)
+}
+
+func ArchivedProjectsView(permissions *Permissions, csrfToken string, projects *ProjectsPaged, totals map[uuid.UUID]*ActivityProjectReportItem) g.Node {
+	var emptyView g.Node
+	if len(projects.Projects) == 0 {
+		emptyView = Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			g.Text("No archived projects."),
+		)
+	}
+
+	return Div(
+		ID("baralga__main_content_modal_content"),
+		Class("modal-content"),
+		Div(
+			Class("modal-header"),
+			H2(
+				Class("modal-title"),
+				g.Text("Archived Projects"),
+			),
+			Button(
+				Type("type"),
+				Class("btn-close"),
+				g.Attr("data-bs-dismiss", "modal"),
+			),
+		),
+		Div(
+			Class("modal-body"),
+			ProjectsNav(true),
+			emptyView,
+			g.Group(
+				g.Map(len(projects.Projects), func(i int) g.Node {
+					project := projects.Projects[i]
+					return ArchivedProjectCard(permissions, csrfToken, project, totals[project.ID])
+				}),
+			),
+		),
+	)
+}
+
+// ProjectsNav switches between the active and the archived projects
+func ProjectsNav(archived bool) g.Node {
+	navLink := func(title, path string, active bool) g.Node {
+		linkClass := "nav-link"
+		if active {
+			linkClass = "nav-link active"
+		}
+		return Li(
+			Class("nav-item"),
+			A(
+				Class(linkClass),
+				Href(path),
+				hx.Get(path),
+				hx.Target("#baralga__main_content_modal_content"),
+				hx.Swap("outerHTML"),
+				g.Text(title),
+			),
+		)
+	}
+
+	return Ul(
+		Class("nav nav-pills mb-3"),
+		navLink("Active", "/projects", !archived),
+		navLink("Archived", "/projects/archived", archived),
+	)
+}
+
+func ArchivedProjectCard(permissions *Permissions, csrfToken string, project *Project, total *ActivityProjectReportItem) g.Node {
+	totalText := "No activities tracked."
+	if total != nil {
+		totalText = fmt.Sprintf(
+			"%v total, %v billable, %v revenue",
+			total.DurationFormatted(),
+			total.BillableDurationFormatted(),
+			total.RevenueFormatted(),
+		)
+	}
+
+	return Div(
+		Class("card mt-2"),
+
+		hx.Target("this"),
+		hx.Swap("outerHTML"),
+
+		Div(
+			Class("card-body"),
+			H5(
+				Class("card-title mt-2"),
+				Div(
+					Class("d-flex justify-content-between mb-2"),
+					Span(
+						Class("flex-grow-1"),
+						g.Text(project.HierarchicalTitle()),
+					),
+					g.If(
+						permissions.IsAdmin(),
+						A(
+							hx.Get(fmt.Sprintf("/projects/%v/delete", project.ID)),
+							Class("btn btn-outline-secondary btn-sm ms-1"),
+							TitleAttr("Delete Project"),
+							I(Class("bi-trash2")),
+						),
+					),
+					g.If(
+						permissions.IsAdmin(),
+						FormEl(
+							Class("d-inline"),
+							hx.Post(fmt.Sprintf("/projects/%v/unarchive", project.ID)),
+							hx.Confirm(fmt.Sprintf("Do you really want to unarchive project %v?", project.Title)),
+
+							Input(
+								Type("hidden"),
+								Name("CSRFToken"),
+								Value(csrfToken),
+							),
+							Button(
+								Type("submit"),
+								Class("btn btn-outline-secondary btn-sm ms-1"),
+								TitleAttr("Unarchive Project"),
+								I(Class("bi-arrow-counterclockwise")),
+							),
+						),
+					),
+				),
+			),
+			P(
+				Class("card-text text-muted small"),
+				g.Text(totalText),
+			),
+		),
+	)
+}
+
+func ProjectCard(csrfToken string, project *Project, consumption *ProjectBudgetConsumption, options *projectCardOptions, errorMessage string) g.Node {
+	var budgetView g.Node
+	if consumption != nil {
+		budgetView = ProjectBudgetView(consumption)
+	}
+
+	var hierarchyView g.Node
+	if project.ClientTitle != "" || project.IsSubProject() {
+		var path []string
+		if project.ClientTitle != "" {
+			path = append(path, project.ClientTitle)
+		}
+		if project.IsSubProject() {
+			path = append(path, project.ParentTitle)
+		}
+		hierarchyView = H6(
+			Class("card-subtitle mb-2 text-muted"),
+			g.Text(strings.Join(path, " › ")),
+		)
+	}
+
+	cardClass := "card mt-2"
+	if project.IsSubProject() {
+		cardClass = "card mt-2 ms-4"
+	}
+
+	return Div(
+		Class(cardClass),
+
+		hx.Target("this"),
+		hx.Swap("outerHTML"),
+
+		Div(
+			Class("card-body"),
+			H5(
+				Class("card-title mt-2"),
+				Div(
+					Class("d-flex justify-content-between mb-2"),
+					Span(
+						Class("flex-grow-1"),
+						g.Text(project.Title),
+					),
+					g.If(
+						options.permissions.IsAdmin(),
+						A(
+							hx.Get(fmt.Sprintf("/projects/%v/delete", project.ID)),
+							Class("btn btn-outline-secondary btn-sm ms-1"),
+							TitleAttr("Delete Project"),
+							I(Class("bi-trash2")),
+						),
+					),
+					g.If(
+						options.permissions.IsAdmin(),
+						FormEl(
+							Class("d-inline"),
+							hx.Post(fmt.Sprintf("/projects/%v/archive", project.ID)),
+							hx.Confirm(fmt.Sprintf("Do you really want to archive project %v?", project.Title)),
+
+							Input(
+								Type("hidden"),
+								Name("CSRFToken"),
+								Value(csrfToken),
+							),
+							Button(
+								Type("submit"),
+								Class("btn btn-outline-secondary btn-sm ms-1"),
+								TitleAttr("Archive Project"),
+								I(Class("bi-archive")),
+							),
+						),
+					),
+				),
+			),
+			hierarchyView,
+			budgetView,
+			g.If(
+				options.permissions.IsAdmin(),
+				ProjectRateForm(csrfToken, project, errorMessage),
+			),
+			g.If(
+				options.permissions.IsAdmin(),
+				ProjectHierarchyForm(csrfToken, project, options),
+			),
+			g.If(
+				options.permissions.IsAdmin(),
+				ProjectMembersForm(csrfToken, project, options),
+			),
+			g.If(
+				options.permissions.IsAdmin(),
+				ProjectBudgetForm(csrfToken, project),
+			),
+		),
+	)
+}
+
+// ProjectDeleteCard confirms the deletion of a project, the activities tracked
+// on the project are reassigned to the chosen project before
+func ProjectDeleteCard(csrfToken string, project *Project, usage *ProjectUsage, targetProjects []*Project, errorMessage string) g.Node {
+	cancelPath := "/projects"
+	if !project.Active {
+		cancelPath = "/projects/archived"
+	}
+
+	usageText := "No activities are tracked on this project."
+	var reassignView g.Node
+	if usage.IsUsed() {
+		usageText = fmt.Sprintf("%v with %v tracked on this project.", activitiesCountText(usage.ActivityCount), usage.DurationFormatted())
+		if usage.RunningActivityCount > 0 {
+			usageText = fmt.Sprintf("%v Also %v running.", usageText, activitiesCountText(usage.RunningActivityCount))
+		}
+		usageText += " They are reassigned to the chosen project, so no tracked time is lost."
+		if len(targetProjects) == 0 {
+			usageText += " Create another project to reassign them to first."
+		}
+
+		reassignView = Div(
+			Class("input-group input-group-sm mb-2"),
+			Span(
+				Class("input-group-text"),
+				g.Text("Reassign to"),
+			),
+			Select(
+				Name("ReassignTo"),
+				Class("form-select"),
+				g.Attr("required", "required"),
+				Option(
+					Value(""),
+					g.Text("Choose a project"),
+				),
+				ProjectOptions(targetProjects, ""),
+			),
+		)
+	}
+
+	return Div(
+		Class("card mt-2 border-danger"),
+
+		hx.Target("this"),
+		hx.Swap("outerHTML"),
+
+		Div(
+			Class("card-body"),
+			H5(
+				Class("card-title mt-2"),
+				g.Text(fmt.Sprintf("Delete project %v?", project.HierarchicalTitle())),
+			),
+			g.If(
+				errorMessage != "",
+				Div(
+					Class("alert alert-danger text-center"),
+					Role("alert"),
+					Span(g.Text(errorMessage)),
+				),
+			),
+			P(
+				Class("card-text"),
+				g.Text(usageText),
+			),
+			FormEl(
+				hx.Post(fmt.Sprintf("/projects/%v/delete", project.ID)),
+				hx.Target("closest .card"),
+				hx.Swap("outerHTML"),
+
+				Input(
+					Type("hidden"),
+					Name("CSRFToken"),
+					Value(csrfToken),
+				),
+				reassignView,
+				Div(
+					Class("text-end"),
+					A(
+						hx.Get(cancelPath),
+						hx.Target("#baralga__main_content_modal_content"),
+						hx.Swap("outerHTML"),
+						Class("btn btn-outline-secondary btn-sm me-2"),
+						g.Text("Cancel"),
+					),
+					Button(
+						Type("submit"),
+						Class("btn btn-danger btn-sm"),
+						g.If(usage.IsUsed() && len(targetProjects) == 0, Disabled()),
+						I(Class("bi-trash2 me-2")),
+						g.Text("Delete Project"),
+					),
+				),
+			),
+		),
+	)
+}
+
+func activitiesCountText(count int) string {
+	if count == 1 {
+		return "1 activity"
+	}
+	return fmt.Sprintf("%v activities", count)
+}
+
+func ProjectBudgetView(consumption *ProjectBudgetConsumption) g.Node {
+	percentage := consumption.Percentage()
+
+	progressClass := "bg-success"
+	if percentage >= 100 {
+		progressClass = "bg-danger"
+	} else if percentage >= 80 {
+		progressClass = "bg-warning"
+	}
+
+	width := percentage
+	if width > 100 {
+		width = 100
+	}
+
+	period := "Total budget"
+	if consumption.Project.BudgetPeriodOrDefault() == BudgetPeriodMonthly {
+		period = "Monthly budget"
+	}
+
+	return Div(
+		Class("mb-2"),
+		Div(
+			Class("d-flex justify-content-between small text-muted"),
+			Span(g.Text(period)),
+			Span(g.Text(fmt.Sprintf("%v (%v%%)", consumption.ConsumedFormatted(), percentage))),
+		),
+		Div(
+			Class("progress"),
+			Div(
+				Class("progress-bar "+progressClass),
+				Role("progressbar"),
+				g.Attr("style", fmt.Sprintf("width: %v%%", width)),
+				g.Attr("aria-valuenow", fmt.Sprintf("%v", percentage)),
+				g.Attr("aria-valuemin", "0"),
+				g.Attr("aria-valuemax", "100"),
+			),
+		),
+	)
+}
+
+func ProjectBudgetForm(csrfToken string, project *Project) g.Node {
+	return FormEl(
+		Class("mt-2"),
+		hx.Post(fmt.Sprintf("/projects/%v/budget", project.ID)),
+		hx.Target("closest .card"),
+		hx.Swap("outerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(csrfToken),
+		),
+		Div(
+			Class("input-group input-group-sm"),
+			Span(
+				Class("input-group-text"),
+				g.Text("Budget"),
+			),
+			Select(
+				Name("BudgetType"),
+				Class("form-select"),
+				Option(
+					Value(""),
+					g.Text("None"),
+					g.If(project.BudgetType == "", Selected()),
+				),
+				Option(
+					Value(BudgetTypeHours),
+					g.Text("Hours"),
+					g.If(project.BudgetType == BudgetTypeHours, Selected()),
+				),
+				Option(
+					Value(BudgetTypeMoney),
+					g.Text(project.CurrencyOrDefault()),
+					g.If(project.BudgetType == BudgetTypeMoney, Selected()),
+				),
+			),
+			Input(
+				Type("number"),
+				Name("Budget"),
+				g.Attr("min", "0"),
+				g.Attr("step", "0.01"),
+				Value(fmt.Sprintf("%.2f", project.Budget)),
+				Class("form-control"),
+			),
+			Select(
+				Name("BudgetPeriod"),
+				Class("form-select"),
+				Option(
+					Value(BudgetPeriodTotal),
+					g.Text("Total"),
+					g.If(project.BudgetPeriodOrDefault() == BudgetPeriodTotal, Selected()),
+				),
+				Option(
+					Value(BudgetPeriodMonthly),
+					g.Text("Monthly"),
+					g.If(project.BudgetPeriodOrDefault() == BudgetPeriodMonthly, Selected()),
+				),
+			),
+			Button(
+				Type("submit"),
+				Class("btn btn-outline-primary"),
+				TitleAttr("Save Budget"),
+				I(Class("bi-save")),
+			),
+		),
+	)
+}
+
+func ProjectRateForm(csrfToken string, project *Project, errorMessage string) g.Node {
+	return FormEl(
+		Class("mt-2"),
+		hx.Post(fmt.Sprintf("/projects/%v/rate", project.ID)),
+		hx.Target("closest .card"),
+		hx.Swap("outerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(csrfToken),
+		),
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-danger text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+		Div(
+			Class("input-group input-group-sm"),
+			Span(
+				Class("input-group-text"),
+				g.Text("Hourly Rate"),
+			),
+			Input(
+				Type("number"),
+				Name("HourlyRate"),
+				g.Attr("min", "0"),
+				g.Attr("step", "0.01"),
+				Value(fmt.Sprintf("%.2f", project.HourlyRate)),
+				Class("form-control"),
+			),
+			Input(
+				Type("text"),
+				Name("Currency"),
+				MinLength("3"),
+				MaxLength("3"),
+				Value(project.CurrencyOrDefault()),
+				g.Attr("required", "required"),
+				Class("form-control"),
+				g.Attr("style", "max-width: 5em"),
+			),
+			Button(
+				Type("submit"),
+				Class("btn btn-outline-primary"),
+				TitleAttr("Save Hourly Rate"),
+				I(Class("bi-save")),
+			),
+		),
+	)
+}
+
+func ProjectHierarchyForm(csrfToken string, project *Project, options *projectCardOptions) g.Node {
+	var parents []*Project
+	for _, p := range options.projects {
+		if p.ID != project.ID && !p.IsSubProject() {
+			parents = append(parents, p)
+		}
+	}
+
+	return FormEl(
+		Class("mt-2"),
+		hx.Post(fmt.Sprintf("/projects/%v/hierarchy", project.ID)),
+		hx.Target("closest .card"),
+		hx.Swap("outerHTML"),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(csrfToken),
+		),
+		Div(
+			Class("input-group input-group-sm"),
+			Span(
+				Class("input-group-text"),
+				g.Text("Client"),
+			),
+			Select(
+				Name("ClientID"),
+				Class("form-select"),
+				TitleAttr("Sub-projects belong to the client of their parent"),
+				g.If(project.IsSubProject(), Disabled()),
+				Option(
+					Value(""),
+					g.Text("None"),
+					g.If(project.ClientID == uuid.Nil, Selected()),
+				),
+				g.Group(g.Map(len(options.clients), func(i int) g.Node {
+					client := options.clients[i]
+					return Option(
+						Value(client.ID.String()),
+						g.Text(client.Title),
+						g.If(project.ClientID == client.ID, Selected()),
+					)
+				})),
+			),
+			Span(
+				Class("input-group-text"),
+				g.Text("Parent"),
+			),
+			Select(
+				Name("ParentID"),
+				Class("form-select"),
+				Option(
+					Value(""),
+					g.Text("None"),
+					g.If(!project.IsSubProject(), Selected()),
+				),
+				g.Group(g.Map(len(parents), func(i int) g.Node {
+					parent := parents[i]
+					return Option(
+						Value(parent.ID.String()),
+						g.Text(parent.Title),
+						g.If(project.ParentID == parent.ID, Selected()),
+					)
+				})),
+			),
+			Button(
+				Type("submit"),
+				Class("btn btn-outline-primary"),
+				TitleAttr("Save Client and Parent"),
+				I(Class("bi-save")),
+			),
+		),
+	)
+}
+
+// ProjectOptions are the options of a project picker, grouped by client with each sub-project following its parent
+func ProjectOptions(projects []*Project, selectedProjectID string) g.Node {
+	option := func(project *Project) g.Node {
+		return Option(
+			Value(project.ID.String()),
+			g.Text(project.HierarchicalTitle()),
+			g.If(selectedProjectID == project.ID.String(), Selected()),
+		)
+	}
+
+	var nodes []g.Node
+	var clientGroup []g.Node
+	clientTitle := ""
+	flush := func() {
+		if len(clientGroup) == 0 {
+			return
+		}
+		if clientTitle == "" {
+			nodes = append(nodes, clientGroup...)
+		} else {
+			nodes = append(nodes, OptGroup(append([]g.Node{g.Attr("label", clientTitle)}, clientGroup...)...))
+		}
+		clientGroup = nil
+	}
+
+	for _, project := range OrderProjectsByHierarchy(projects) {
+		if project.ClientTitle != clientTitle {
+			flush()
+			clientTitle = project.ClientTitle
+		}
+		clientGroup = append(clientGroup, option(project))
+	}
+	flush()
+
+	return g.Group(nodes)
+}
+
+// ProjectMembersForm assigns users to the project, a project without members is open to all users
+func ProjectMembersForm(csrfToken string, project *Project, options *projectCardOptions) g.Node {
+	var members []*ProjectMember
+	for _, member := range options.members {
+		if member.ProjectID == project.ID {
+			members = append(members, member)
+		}
+	}
+
+	var openView g.Node
+	if len(members) == 0 {
+		openView = Span(
+			Class("small text-muted"),
+			g.Text("Open to all users, assign users to restrict the project to them."),
+		)
+	}
+
+	return Div(
+		Class("mt-2"),
+		Div(
+			Class("mb-1"),
+			openView,
+			g.Group(g.Map(len(members), func(i int) g.Node {
+				member := members[i]
+				icon := "bi-person me-1"
+				title := member.Username
+				if member.Role == ProjectRoleManager {
+					icon = "bi-person-badge me-1"
+					title = member.Username + " (Manager)"
+				}
+				return FormEl(
+					Class("d-inline-block me-1 mb-1"),
+					hx.Post(fmt.Sprintf("/projects/%v/members/%v/delete", project.ID, url.PathEscape(member.Username))),
+					hx.Target("closest .card"),
+					hx.Swap("outerHTML"),
+
+					Input(
+						Type("hidden"),
+						Name("CSRFToken"),
+						Value(csrfToken),
+					),
+					Span(
+						Class("badge bg-secondary"),
+						I(Class(icon)),
+						g.Text(title),
+						Button(
+							Type("submit"),
+							Class("btn btn-link btn-sm p-0 ms-1 text-white"),
+							TitleAttr("Remove Member"),
+							I(Class("bi-x")),
+						),
+					),
+				)
+			})),
+		),
+		FormEl(
+			hx.Post(fmt.Sprintf("/projects/%v/members", project.ID)),
+			hx.Target("closest .card"),
+			hx.Swap("outerHTML"),
+
+			Input(
+				Type("hidden"),
+				Name("CSRFToken"),
+				Value(csrfToken),
+			),
+			Div(
+				Class("input-group input-group-sm"),
+				Span(
+					Class("input-group-text"),
+					g.Text("Member"),
+				),
+				Select(
+					Name("Username"),
+					Class("form-select"),
+					g.Attr("required", "required"),
+					g.Group(g.Map(len(options.users), func(i int) g.Node {
+						user := options.users[i]
+						return Option(
+							Value(user.Username),
+							g.Text(user.Name),
+						)
+					})),
+				),
+				Select(
+					Name("Role"),
+					Class("form-select"),
+					Option(
+						Value(ProjectRoleMember),
+						g.Text("Member"),
+					),
+					Option(
+						Value(ProjectRoleManager),
+						g.Text("Manager"),
+					),
+				),
+				Button(
+					Type("submit"),
+					Class("btn btn-outline-primary"),
+					TitleAttr("Assign User"),
+					I(Class("bi-person-plus")),
+				),
+			),
+		),
+	)
+}
+
+func ClientsView(csrfToken string, clients []*Client) g.Node {
+	return Div(
+		Class("mb-4"),
+		FormEl(
+			ID("client_form"),
+			Class("mb-2"),
+			hx.Post("/clients/new"),
+			hx.Target("#baralga__main_content_modal_content"),
+			hx.Swap("outerHTML"),
+
+			Input(
+				Type("hidden"),
+				Name("CSRFToken"),
+				Value(csrfToken),
+			),
+
+			Div(
+				Class("input-group"),
+				Input(
+					ID("ClientTitle"),
+					Type("text"),
+					Name("Title"),
+					MinLength("2"),
+					MaxLength("100"),
+					g.Attr("required", "required"),
+					Class("form-control"),
+					g.Attr("placeholder", "My new Client"),
+				),
+				Button(
+					Class("btn btn-outline-primary"),
+					g.Attr("for", "ClientTitle"),
+					TitleAttr("Add Client"),
+					I(Class("bi-plus")),
+				),
+			),
+		),
+		g.Group(g.Map(len(clients), func(i int) g.Node {
+			client := clients[i]
+			return FormEl(
+				Class("d-inline-block me-1 mb-1"),
+				hx.Post(fmt.Sprintf("/clients/%v/delete", client.ID)),
+				hx.Target("#baralga__main_content_modal_content"),
+				hx.Swap("outerHTML"),
+				hx.Confirm(fmt.Sprintf("Do you really want to delete client %v? Its projects are kept without client.", client.Title)),
+
+				Input(
+					Type("hidden"),
+					Name("CSRFToken"),
+					Value(csrfToken),
+				),
+				Span(
+					Class("badge bg-secondary"),
+					I(Class("bi-building me-1")),
+					g.Text(client.Title),
+					Button(
+						Type("submit"),
+						Class("btn btn-link btn-sm p-0 ms-1 text-white"),
+						TitleAttr("Delete Client"),
+						I(Class("bi-x")),
+					),
+				),
+			)
+		})),
 	)
 }
 
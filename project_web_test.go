@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
 
@@ -20,6 +21,7 @@ func TestHandleProjectsPageAsUser(t *testing.T) {
 	a := &app{
 		Config:            &config{},
 		ProjectRepository: NewInMemProjectRepository(),
+		ClientRepository:  NewInMemClientRepository(),
 	}
 
 	r, _ := http.NewRequest("GET", "/projects", nil)
@@ -30,7 +32,7 @@ func TestHandleProjectsPageAsUser(t *testing.T) {
 
 	htmlBody := httpRec.Body.String()
 	is.True(!strings.Contains(htmlBody, "<form"))
-	is.True(!strings.Contains(htmlBody, "hx-delete"))
+	is.True(!strings.Contains(htmlBody, fmt.Sprintf("/projects/%v/delete", projectIDSample)))
 }
 
 func TestHandleProjectsPageAsAdmin(t *testing.T) {
@@ -40,6 +42,8 @@ func TestHandleProjectsPageAsAdmin(t *testing.T) {
 	a := &app{
 		Config:            &config{},
 		ProjectRepository: NewInMemProjectRepository(),
+		ClientRepository:  NewInMemClientRepository(),
+		UserRepository:    NewInMemUserRepository(),
 	}
 
 	r, _ := http.NewRequest("GET", "/projects", nil)
@@ -53,7 +57,7 @@ func TestHandleProjectsPageAsAdmin(t *testing.T) {
 
 	htmlBody := httpRec.Body.String()
 	is.True(strings.Contains(htmlBody, "<form"))
-	is.True(strings.Contains(htmlBody, "hx-delete"))
+	is.True(strings.Contains(htmlBody, fmt.Sprintf("/projects/%v/delete", projectIDSample)))
 }
 
 func TestHandleCreateProjectWithNotValidProject(t *testing.T) {
@@ -64,6 +68,8 @@ func TestHandleCreateProjectWithNotValidProject(t *testing.T) {
 	a := &app{
 		Config:            &config{},
 		ProjectRepository: repo,
+		ClientRepository:  NewInMemClientRepository(),
+		UserRepository:    NewInMemUserRepository(),
 	}
 
 	countBefore := len(repo.projects)
@@ -92,9 +98,13 @@ func TestHandleCreateProjectWithValidProject(t *testing.T) {
 
 	repo := NewInMemProjectRepository()
 	a := &app{
-		Config:            &config{},
-		RepositoryTxer:    NewInMemRepositoryTxer(),
-		ProjectRepository: repo,
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		ProjectRepository:  repo,
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
+		ClientRepository:   NewInMemClientRepository(),
+		UserRepository:     NewInMemUserRepository(),
 	}
 
 	countBefore := len(repo.projects)
@@ -150,6 +160,8 @@ func TestHandleCreateProjectWithInvalidProject(t *testing.T) {
 	a := &app{
 		Config:            &config{},
 		ProjectRepository: repo,
+		ClientRepository:  NewInMemClientRepository(),
+		UserRepository:    NewInMemUserRepository(),
 	}
 
 	data := url.Values{}
@@ -175,9 +187,11 @@ func TestHandleArchiveProjectAsAdmin(t *testing.T) {
 
 	repo := NewInMemProjectRepository()
 	a := &app{
-		Config:            &config{},
-		ProjectRepository: repo,
-		RepositoryTxer:    NewInMemRepositoryTxer(),
+		Config:             &config{},
+		ProjectRepository:  repo,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
 	}
 
 	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/archive", projectIDSample), nil)
@@ -215,3 +229,659 @@ func TestHandleArchiveProjectAsUser(t *testing.T) {
 	a.HandleArchiveProject()(httpRec, r)
 	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
 }
+
+func TestHandleProjectRateFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  repo,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		ClientRepository:   NewInMemClientRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["HourlyRate"] = []string{"85.50"}
+	data["Currency"] = []string{"usd"}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/rate", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectRateForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(85.5, repo.projects[0].HourlyRate)
+	is.Equal("USD", repo.projects[0].Currency)
+}
+
+func TestHandleProjectRateFormWithInvalidCurrency(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ClientRepository:  NewInMemClientRepository(),
+		UserRepository:    NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["HourlyRate"] = []string{"85.50"}
+	data["Currency"] = []string{"EURO"}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/rate", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectRateForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "valid hourly rate"))
+	is.Equal(0.0, repo.projects[0].HourlyRate)
+}
+
+func TestHandleProjectRateFormAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/rate", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	a.HandleProjectRateForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleProjectBudgetFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  repo,
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		ClientRepository:   NewInMemClientRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["BudgetType"] = []string{"hours"}
+	data["Budget"] = []string{"40"}
+	data["BudgetPeriod"] = []string{"monthly"}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/budget", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectBudgetForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(BudgetTypeHours, repo.projects[0].BudgetType)
+	is.Equal(40.0, repo.projects[0].Budget)
+	is.Equal(BudgetPeriodMonthly, repo.projects[0].BudgetPeriod)
+	is.True(strings.Contains(httpRec.Body.String(), "Monthly budget"))
+}
+
+func TestHandleProjectBudgetFormWithInvalidPeriod(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ClientRepository:  NewInMemClientRepository(),
+		UserRepository:    NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["BudgetType"] = []string{"hours"}
+	data["Budget"] = []string{"40"}
+	data["BudgetPeriod"] = []string{"weekly"}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/budget", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectBudgetForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "valid budget"))
+	is.Equal("", repo.projects[0].BudgetType)
+}
+
+func TestHandleArchivedProjectsPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	archivedProjectID := uuid.New()
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects = append(projectRepository.projects, &Project{
+		ID:             archivedProjectID,
+		Title:          "My archived Project",
+		OrganizationID: organizationIDSample,
+	})
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  projectRepository,
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/projects/archived", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleArchivedProjectsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "My archived Project"))
+	is.True(!strings.Contains(htmlBody, "My Project<"))
+	is.True(strings.Contains(htmlBody, fmt.Sprintf("hx-post=\"/projects/%v/unarchive\"", archivedProjectID)))
+}
+
+func TestHandleUnarchiveProjectAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.projects[0].Active = false
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  projectRepository,
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/unarchive", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUnarchiveProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(projectRepository.projects[0].Active)
+}
+
+func TestHandleUnarchiveProjectAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/unarchive", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUnarchiveProject()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleDeleteProjectPageWithActivities(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%v/delete", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Delete project My Project?"))
+	is.True(strings.Contains(htmlBody, "1 activity with"))
+	is.True(strings.Contains(htmlBody, "Create another project"))
+}
+
+func TestHandleDeleteProjectFormWithReassignment(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	targetProject := &Project{
+		ID:             uuid.New(),
+		Title:          "Target Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	repo.projects = append(repo.projects, targetProject)
+
+	activityRepository := NewInMemActivityRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     activityRepository,
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	data := url.Values{}
+	data["ReassignTo"] = []string{targetProject.ID.String()}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/delete", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.projects))
+	is.Equal(targetProject.ID, activityRepository.activities[0].ProjectID)
+}
+
+func TestHandleDeleteProjectFormWithoutReassignment(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      repo,
+		ActivityRepository:     NewInMemActivityRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/delete", projectIDSample), strings.NewReader(url.Values{}.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.projects))
+	is.True(strings.Contains(httpRec.Body.String(), "Please choose the project to reassign the activities to."))
+}
+
+func TestHandleDeleteProjectFormAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                 &config{},
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/delete", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleProjectHierarchyFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	subProject := &Project{
+		ID:             uuid.New(),
+		Title:          "My Work Package",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	repo.projects = append(repo.projects, subProject)
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  repo,
+		ClientRepository:   NewInMemClientRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  NewInMemWebhookRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["ParentID"] = []string{projectIDSample.String()}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/hierarchy", subProject.ID), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", subProject.ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectHierarchyForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(projectIDSample, repo.projects[1].ParentID)
+	is.True(strings.Contains(httpRec.Body.String(), "My Project"))
+}
+
+func TestHandleProjectHierarchyFormWithSubProjectAsParent(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	subProject := &Project{
+		ID:             uuid.New(),
+		Title:          "My Work Package",
+		Active:         true,
+		ParentID:       projectIDSample,
+		OrganizationID: organizationIDSample,
+	}
+	otherProject := &Project{
+		ID:             uuid.New(),
+		Title:          "My other Project",
+		Active:         true,
+		OrganizationID: organizationIDSample,
+	}
+	repo.projects = append(repo.projects, subProject, otherProject)
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  repo,
+		ClientRepository:   NewInMemClientRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["ParentID"] = []string{subProject.ID.String()}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/hierarchy", otherProject.ID), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", otherProject.ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectHierarchyForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "Sub-projects can&#39;t have sub-projects"))
+	is.Equal(uuid.Nil, otherProject.ParentID)
+}
+
+func TestHandleProjectHierarchyFormAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/hierarchy", projectIDSample), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	a.HandleProjectHierarchyForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleClientFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	clientRepository := NewInMemClientRepository()
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ClientRepository:   clientRepository,
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	data := url.Values{}
+	data["Title"] = []string{"ACME Inc."}
+
+	r, _ := http.NewRequest("POST", "/clients/new", strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleClientForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(clientRepository.clients))
+	is.True(strings.Contains(httpRec.Body.String(), "ACME Inc."))
+}
+
+func TestHandleDeleteClientFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	clientRepository := NewInMemClientRepository()
+	client := &Client{
+		ID:             uuid.New(),
+		Title:          "ACME Inc.",
+		OrganizationID: organizationIDSample,
+	}
+	clientRepository.clients = append(clientRepository.clients, client)
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ClientRepository:   clientRepository,
+		ActivityRepository: NewInMemActivityRepository(),
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/clients/%v/delete", client.ID), nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("client-id", client.ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteClientForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(clientRepository.clients))
+}
+
+func TestHandleProjectMemberFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		UserRepository:    NewInMemUserRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ClientRepository:  NewInMemClientRepository(),
+	}
+
+	data := url.Values{}
+	data["Username"] = []string{"admin@baralga.com"}
+	data["Role"] = []string{ProjectRoleManager}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/members", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectMemberForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(repo.members))
+	is.Equal(ProjectRoleManager, repo.members[0].Role)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "admin@baralga.com"))
+}
+
+func TestHandleProjectMemberFormAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+	}
+
+	data := url.Values{}
+	data["Username"] = []string{"user1"}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/members", projectIDSample), strings.NewReader(data.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleProjectMemberForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+	is.Equal(0, len(repo.members))
+}
+
+func TestHandleDeleteProjectMemberFormAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	repo := NewInMemProjectRepository()
+	repo.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:            &config{},
+		ProjectRepository: repo,
+		UserRepository:    NewInMemUserRepository(),
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		ClientRepository:  NewInMemClientRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%v/members/user1/delete", projectIDSample), strings.NewReader(url.Values{}.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("project-id", projectIDSample.String())
+	rctx.URLParams.Add("username", "user1")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleDeleteProjectMemberForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(repo.members))
+}
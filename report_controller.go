@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/util"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type userReportItemModel struct {
+	Username string         `json:"username"`
+	Name     string         `json:"name"`
+	Duration *durationModel `json:"duration"`
+}
+
+type projectUserReportItemModel struct {
+	ProjectID    string         `json:"projectId"`
+	ProjectTitle string         `json:"projectTitle"`
+	Username     string         `json:"username"`
+	Duration     *durationModel `json:"duration"`
+	Links        *hal.Links     `json:"_links"`
+}
+
+type userReportModel struct {
+	Users        []*userReportItemModel        `json:"users"`
+	ProjectUsers []*projectUserReportItemModel `json:"projectUsers"`
+	Links        *hal.Links                    `json:"_links"`
+}
+
+// HandleGetUserReport reads the time spent per user and per project and user
+func (a *app) HandleGetUserReport() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		filter, err := filterFromQueryParams(r.URL.Query(), principal.Location())
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("invalid filter")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		userReports, matrix, err := a.UserReports(r.Context(), principal, filter)
+		if errors.Is(err, ErrNoPermission) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		userReportModel := &userReportModel{
+			Users:        make([]*userReportItemModel, len(userReports)),
+			ProjectUsers: []*projectUserReportItemModel{},
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+			),
+		}
+
+		for i, userReport := range userReports {
+			userReportModel.Users[i] = &userReportItemModel{
+				Username: userReport.Username,
+				Name:     userReport.Name,
+				Duration: mapToDurationModel(userReport.DurationInMinutesTotal),
+			}
+		}
+
+		for _, row := range matrix.Projects {
+			for _, user := range matrix.Users {
+				durationInMinutes := row.DurationInMinutes(user.Username)
+				if durationInMinutes == 0 {
+					continue
+				}
+
+				userReportModel.ProjectUsers = append(userReportModel.ProjectUsers, &projectUserReportItemModel{
+					ProjectID:    row.ProjectID.String(),
+					ProjectTitle: row.ProjectTitle,
+					Username:     user.Username,
+					Duration:     mapToDurationModel(durationInMinutes),
+					Links: hal.NewLinks(
+						hal.NewLink("project", fmt.Sprintf("/api/projects/%s", row.ProjectID)),
+					),
+				})
+			}
+		}
+
+		util.RenderJSON(w, userReportModel)
+	}
+}
+
+func mapToDurationModel(durationInMinutes int) *durationModel {
+	return &durationModel{
+		Hours:     durationInMinutes / 60,
+		Minutes:   durationInMinutes % 60,
+		Decimal:   float64(durationInMinutes) / 60.0,
+		Formatted: FormatMinutesAsDuration(float64(durationInMinutes)),
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleGetUserReport(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	start := time.Now()
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities = []*Activity{
+		{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Username:       "user1",
+			Start:          start,
+			End:            start.Add(90 * time.Minute),
+		},
+		{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Username:       "user2",
+			Start:          start,
+			End:            start.Add(30 * time.Minute),
+		},
+	}
+
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: activityRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/api/reports/users?t=year", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleGetUserReport()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	userReportModel := &userReportModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(userReportModel)
+	is.NoErr(err)
+	is.Equal(2, len(userReportModel.Users))
+	is.Equal("user1", userReportModel.Users[0].Username)
+	is.Equal(90, userReportModel.Users[0].Duration.Hours*60+userReportModel.Users[0].Duration.Minutes)
+	is.Equal(2, len(userReportModel.ProjectUsers))
+	is.Equal(projectIDSample.String(), userReportModel.ProjectUsers[1].ProjectID)
+	is.Equal("0:30 h", userReportModel.ProjectUsers[1].Duration.Formatted)
+}
+
+func TestHandleGetUserReportAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: NewInMemActivityRepository(),
+		ProjectRepository:  NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/reports/users?t=year", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	a.HandleGetUserReport()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleGetUserReportAsProjectManager(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleManager, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: NewInMemActivityRepository(),
+		ProjectRepository:  projectRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/api/reports/users?t=year", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandleGetUserReport()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+}
+
+func TestHandleGetUserReportWithInvalidFilter(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/reports/users?t=year&v=XXXX", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleGetUserReport()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
@@ -28,7 +28,7 @@ func (a *app) HandleReportPage() http.HandlerFunc {
 		}
 
 		queryParams := r.URL.Query()
-		filter, err := filterFromQueryParams(queryParams)
+		filter, err := filterFromQueryParams(queryParams, principal.Location())
 		if err != nil {
 			util.RenderProblemHTML(w, isProduction, errors.New("invalid query params"))
 			return
@@ -53,11 +53,20 @@ func (a *app) HandleReportPage() http.HandlerFunc {
 
 func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *ActivityFilter) (g.Node, error) {
 	previousFilter := filter.Previous()
-	homeFilter := filter.Home()
+	homeFilter := filter.Home(pageContext.principal.Location())
 	nextFilter := filter.Next()
 
-	var reportGeneralView, reportTimeView, reportProjectView g.Node
-	var err error
+	permissions, err := a.ReadPermissions(pageContext.ctx, pageContext.principal)
+	if err != nil {
+		return nil, err
+	}
+
+	canReadReportsOfOthers := permissions.CanReadReportsOfOthers()
+	if view.main == "users" && !canReadReportsOfOthers {
+		view = &reportView{main: "general"}
+	}
+
+	var reportGeneralView, reportTimeView, reportProjectView, reportTagView, reportUserView, reportGapView g.Node
 	if view.main == "general" {
 		reportGeneralView, err = a.reportGeneralView(pageContext, filter, view)
 		if err != nil {
@@ -76,6 +85,75 @@ func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *Act
 			return nil, err
 		}
 	}
+	if view.main == "tags" {
+		reportTagView, err = a.reportTagView(pageContext, view, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if view.main == "users" {
+		reportUserView, err = a.reportUserView(pageContext, view, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if view.main == "gaps" {
+		reportGapView, err = a.reportGapView(pageContext, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var usersNavLink g.Node
+	if canReadReportsOfOthers {
+		usersNavLink = A(
+			g.If(view.main == "users",
+				Class("nav-link active"),
+			),
+			g.If(view.main != "users",
+				g.Group([]g.Node{
+					Class("btn nav-link"),
+					hx.Get(reportHrefForView(filter, "users", "")),
+					hx.PushURLTrue(),
+					hx.Target("#baralga__report_content"),
+					hx.Swap("outerHTML"),
+				}),
+			),
+			I(Class("bi-people me-2")),
+			g.Text("Users"),
+			Class("nav-link"),
+		)
+	}
+
+	criteriaView, err := a.reportCriteriaView(pageContext, view, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagFilterView g.Node
+	if filter.Tag() != "" {
+		tagFilterView = Div(
+			Class("row mb-2"),
+			Div(
+				Class("col d-flex justify-content-center"),
+				Span(
+					Class("badge rounded-pill bg-secondary"),
+					I(Class("bi-tag me-1")),
+					g.Text(filter.Tag()),
+					A(
+						hx.Get(reportHref(filter.WithTag(""), view)),
+						hx.PushURLTrue(),
+						hx.Target("#baralga__report_content"),
+						hx.Swap("outerHTML"),
+
+						TitleAttr("Remove tag filter"),
+						Class("btn text-white p-0 ms-1"),
+						I(Class("bi-x")),
+					),
+				),
+			),
+		)
+	}
 
 	return Div(
 		ID("baralga__report_content"),
@@ -91,7 +169,7 @@ func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *Act
 			Div(
 				Class("col-md-4 col-12 mt-2"),
 				Select(
-					hx.Get(fmt.Sprintf("/reports?c=%v", view.asParam())),
+					hx.Get(fmt.Sprintf("/reports?c=%v%v", view.asParam(), criteriaParams(filter))),
 					hx.PushURLTrue(),
 					hx.Target("#baralga__report_content"),
 					hx.Swap("outerHTML"),
@@ -182,13 +260,23 @@ func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *Act
 			),
 			Div(
 				Class("col-1 text-end mt-2"),
-				A(
-					Href(
-						fmt.Sprintf("/api/activities?contentType=application/vnd.ms-excel&t=%v&v=%v", filter.Timespan, filter.String()),
+				Div(
+					Class("btn-group"),
+					Role("group"),
+					A(
+						Href(
+							exportHref(filter),
+						),
+						Class("btn btn-outline-primary"),
+						I(Class("bi-file-excel")),
+						TitleAttr("Export Activities"),
+					),
+					A(
+						Href("/activities/import"),
+						Class("btn btn-outline-primary"),
+						I(Class("bi-upload")),
+						TitleAttr("Import Activities"),
 					),
-					Class("btn btn-outline-primary"),
-					I(Class("bi-file-excel")),
-					TitleAttr("Export Activities"),
 				),
 			),
 		),
@@ -249,9 +337,46 @@ func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *Act
 						g.Text("Project"),
 						Class("nav-link"),
 					),
+					A(
+						g.If(view.main == "tags",
+							Class("nav-link active"),
+						),
+						g.If(view.main != "tags",
+							g.Group([]g.Node{
+								Class("btn nav-link"),
+								hx.Get(reportHrefForView(filter, "tags", "")),
+								hx.PushURLTrue(),
+								hx.Target("#baralga__report_content"),
+								hx.Swap("outerHTML"),
+							}),
+						),
+						I(Class("bi-tags me-2")),
+						g.Text("Tags"),
+						Class("nav-link"),
+					),
+					A(
+						g.If(view.main == "gaps",
+							Class("nav-link active"),
+						),
+						g.If(view.main != "gaps",
+							g.Group([]g.Node{
+								Class("btn nav-link"),
+								hx.Get(reportHrefForView(filter, "gaps", "")),
+								hx.PushURLTrue(),
+								hx.Target("#baralga__report_content"),
+								hx.Swap("outerHTML"),
+							}),
+						),
+						I(Class("bi-hourglass-split me-2")),
+						g.Text("Gaps"),
+						Class("nav-link"),
+					),
+					usersNavLink,
 				),
 			),
 		),
+		criteriaView,
+		tagFilterView,
 		g.If(view.main == "general",
 			reportGeneralView,
 		),
@@ -261,6 +386,140 @@ func (a *app) ReportView(pageContext *pageContext, view *reportView, filter *Act
 		g.If(view.main == "project",
 			reportProjectView,
 		),
+		g.If(view.main == "tags",
+			reportTagView,
+		),
+		g.If(view.main == "users",
+			reportUserView,
+		),
+		g.If(view.main == "gaps",
+			reportGapView,
+		),
+	), nil
+}
+
+func (a *app) reportCriteriaView(pageContext *pageContext, view *reportView, filter *ActivityFilter) (g.Node, error) {
+	pageParams := &paged.PageParams{
+		Page: 0,
+		Size: 50,
+	}
+
+	projects, err := a.ReadProjects(pageContext.ctx, pageContext.principal, &ProjectFilter{}, pageParams)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := a.ReadPermissions(pageContext.ctx, pageContext.principal)
+	if err != nil {
+		return nil, err
+	}
+
+	var usersSelect g.Node
+	if permissions.CanReadReportsOfOthers() {
+		users, err := a.UserRepository.FindUsers(pageContext.ctx, pageContext.principal.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		usernamesFiltered := make(map[string]bool)
+		for _, username := range filter.usernames {
+			usernamesFiltered[username] = true
+		}
+
+		usersSelect = Div(
+			Class("col-md-3 col-12 mt-2"),
+			Select(
+				Name("user"),
+				Class("form-select"),
+				TitleAttr("User"),
+				Option(
+					Value(""),
+					g.Text("All users"),
+				),
+				g.Group(g.Map(len(users), func(i int) g.Node {
+					return Option(
+						Value(users[i].Username),
+						g.Text(users[i].Name),
+						g.If(usernamesFiltered[users[i].Username], Selected()),
+					)
+				})),
+			),
+		)
+	}
+
+	projectIDsFiltered := make(map[uuid.UUID]bool)
+	for _, projectID := range filter.projectIDs {
+		projectIDsFiltered[projectID] = true
+	}
+
+	var sortParam string
+	if filter.sortBy != "" && filter.sortOrder != "" {
+		sortParam = fmt.Sprintf("%v:%v", filter.sortBy, filter.sortOrder)
+	}
+
+	return FormEl(
+		hx.Get("/reports"),
+		hx.PushURLTrue(),
+		hx.Target("#baralga__report_content"),
+		hx.Swap("outerHTML"),
+
+		Class("row mb-2"),
+		Input(Type("hidden"), Name("t"), Value(filter.Timespan)),
+		Input(Type("hidden"), Name("v"), Value(filter.String())),
+		Input(Type("hidden"), Name("c"), Value(view.asParam())),
+		g.If(sortParam != "", Input(Type("hidden"), Name("sort"), Value(sortParam))),
+		g.If(filter.tag != "", Input(Type("hidden"), Name("tag"), Value(filter.tag))),
+		Div(
+			Class("col-md-3 col-12 mt-2"),
+			Select(
+				Name("project"),
+				Class("form-select"),
+				TitleAttr("Project"),
+				Option(
+					Value(""),
+					g.Text("All projects"),
+				),
+				g.Group(g.Map(len(projects.Projects), func(i int) g.Node {
+					return Option(
+						Value(projects.Projects[i].ID.String()),
+						g.Text(projects.Projects[i].Title),
+						g.If(projectIDsFiltered[projects.Projects[i].ID], Selected()),
+					)
+				})),
+			),
+		),
+		usersSelect,
+		Div(
+			Class("col-md-4 col-12 mt-2"),
+			Input(
+				Type("search"),
+				Name("description"),
+				Class("form-control"),
+				g.Attr("placeholder", "Search descriptions ..."),
+				Value(filter.description),
+			),
+		),
+		Div(
+			Class("col-md-2 col-12 mt-2"),
+			Button(
+				Type("submit"),
+				Class("btn btn-outline-primary"),
+				TitleAttr("Filter activities"),
+				I(Class("bi-funnel")),
+			),
+			g.If(filter.HasCriteria(),
+				A(
+					hx.Get(reportHref(filter.WithoutCriteria(), view)),
+					hx.PushURLTrue(),
+					hx.Target("#baralga__report_content"),
+					hx.Swap("outerHTML"),
+
+					TitleAttr("Remove filters"),
+					Class("btn btn-outline-secondary ms-1"),
+					I(Class("bi-x")),
+				),
+			),
+		),
 	), nil
 }
 
@@ -391,6 +650,24 @@ func (a *app) reportTimeView(pageContext *pageContext, view *reportView, filter
 				),
 			),
 		),
+		Div(
+			Class("form-check form-switch mt-2"),
+			Input(
+				ID("SplitAtMidnight"),
+				Type("checkbox"),
+				Class("form-check-input"),
+				hx.Get(reportHref(filter.WithSplitAtMidnight(!filter.SplitAtMidnight()), view)),
+				hx.PushURLTrue(),
+				hx.Target("#baralga__report_content"),
+				hx.Swap("outerHTML"),
+				g.If(filter.SplitAtMidnight(), g.Attr("checked", "checked")),
+			),
+			Label(
+				Class("form-check-label"),
+				g.Attr("for", "SplitAtMidnight"),
+				g.Text("Split activities at midnight"),
+			),
+		),
 		Div(
 			Class("tab-content"),
 			reportView,
@@ -412,6 +689,11 @@ func (a *app) reportProjectView(pageContext *pageContext, view *reportView, filt
 		), nil
 	}
 
+	rollUps, err := a.ProjectReportRollUps(pageContext.ctx, pageContext.principal, projectReports)
+	if err != nil {
+		return nil, err
+	}
+
 	return g.Group([]g.Node{
 		Div(
 			Class("table-responsive"),
@@ -425,6 +707,14 @@ func (a *app) reportProjectView(pageContext *pageContext, view *reportView, filt
 							Class("text-end"),
 							g.Text("Duration"),
 						),
+						Th(
+							Class("text-end"),
+							g.Text("Billable"),
+						),
+						Th(
+							Class("text-end"),
+							g.Text("Revenue"),
+						),
 					),
 				),
 				TBody(
@@ -439,6 +729,271 @@ func (a *app) reportProjectView(pageContext *pageContext, view *reportView, filt
 								Class("text-end"),
 								g.Text(activity.DurationFormatted()),
 							),
+							Td(
+								Class("text-end"),
+								g.Text(activity.BillableDurationFormatted()),
+							),
+							Td(
+								Class("text-end"),
+								g.Text(activity.RevenueFormatted()),
+							),
+						)
+					}),
+					),
+				),
+			),
+		),
+		projectRollUpView("client-report", "Client", rollUps.Clients),
+		projectRollUpView("parent-report", "Parent Project", rollUps.Parents),
+	}), nil
+}
+
+func projectRollUpView(id, title string, rollUpItems []*ActivityProjectRollUpItem) g.Node {
+	if len(rollUpItems) == 0 {
+		return nil
+	}
+
+	return Div(
+		Class("table-responsive mt-4"),
+		Table(
+			ID(id),
+			Class("table table-borderless table-striped"),
+			THead(
+				Tr(
+					Th(g.Text(title)),
+					Th(
+						Class("text-end"),
+						g.Text("Duration"),
+					),
+					Th(
+						Class("text-end"),
+						g.Text("Billable"),
+					),
+					Th(
+						Class("text-end"),
+						g.Text("Revenue"),
+					),
+				),
+			),
+			TBody(
+				g.Group(g.Map(len(rollUpItems), func(i int) g.Node {
+					rollUpItem := rollUpItems[i]
+					return Tr(
+						Td(g.Text(rollUpItem.Title)),
+						Td(
+							Class("text-end"),
+							g.Text(rollUpItem.DurationFormatted()),
+						),
+						Td(
+							Class("text-end"),
+							g.Text(rollUpItem.BillableDurationFormatted()),
+						),
+						Td(
+							Class("text-end"),
+							g.Text(rollUpItem.RevenueFormatted()),
+						),
+					)
+				})),
+			),
+		),
+	)
+}
+
+func (a *app) reportTagView(pageContext *pageContext, view *reportView, filter *ActivityFilter) (g.Node, error) {
+	tagReports, err := a.TagReports(pageContext.ctx, pageContext.principal, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tagReports) == 0 {
+		return Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			g.Text(fmt.Sprintf("No tagged activities found in %v.", filter.String())),
+		), nil
+	}
+
+	return g.Group([]g.Node{
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("tag-report"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(g.Text("Tag")),
+						Th(
+							Class("text-end"),
+							g.Text("Duration"),
+						),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(tagReports), func(i int) g.Node {
+						reportItem := tagReports[i]
+						return Tr(
+							Td(
+								A(
+									hx.Get(reportHref(filter.WithTag(reportItem.Tag), &reportView{main: "general"})),
+									hx.PushURLTrue(),
+									hx.Target("#baralga__report_content"),
+									hx.Swap("outerHTML"),
+
+									Class("btn btn-link p-0"),
+									g.Text(reportItem.Tag),
+								),
+							),
+							Td(
+								Class("text-end"),
+								g.Text(reportItem.DurationFormatted()),
+							),
+						)
+					}),
+					),
+				),
+			),
+		),
+	}), nil
+}
+
+func (a *app) reportGapView(pageContext *pageContext, filter *ActivityFilter) (g.Node, error) {
+	gaps, err := a.ReadActivityGaps(pageContext.ctx, pageContext.principal, filter, DefaultWorkingHours)
+	if err != nil {
+		return nil, err
+	}
+
+	workingHours := DefaultWorkingHours.String()
+
+	if len(gaps) == 0 {
+		return Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			g.Text(fmt.Sprintf("No gaps in your activities within the working hours %v found in %v.", workingHours, filter.String())),
+		), nil
+	}
+
+	return g.Group([]g.Node{
+		P(
+			Class("text-muted"),
+			g.Textf("Time without activities within the working hours %v from monday to friday.", workingHours),
+		),
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("gap-report"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(g.Text("Day")),
+						Th(g.Text("Time")),
+						Th(
+							Class("text-end"),
+							g.Text("Duration"),
+						),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(gaps), func(i int) g.Node {
+						gap := gaps[i]
+						return Tr(
+							Td(g.Text(util.FormatDateDE(gap.Start))),
+							Td(g.Textf("%v - %v", util.FormatTime(gap.Start), util.FormatTime(gap.End))),
+							Td(
+								Class("text-end"),
+								g.Text(gap.DurationFormatted()),
+							),
+						)
+					}),
+					),
+				),
+			),
+		),
+	}), nil
+}
+
+func (a *app) reportUserView(pageContext *pageContext, view *reportView, filter *ActivityFilter) (g.Node, error) {
+	userReports, matrix, err := a.UserReports(pageContext.ctx, pageContext.principal, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userReports) == 0 {
+		return Div(
+			Class("alert alert-info"),
+			Role("alert"),
+			g.Text(fmt.Sprintf("No activities found in %v.", filter.String())),
+		), nil
+	}
+
+	return g.Group([]g.Node{
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("user-report"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(g.Text("User")),
+						Th(
+							Class("text-end"),
+							g.Text("Duration"),
+						),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(userReports), func(i int) g.Node {
+						reportItem := userReports[i]
+						return Tr(
+							Td(g.Text(reportItem.Name)),
+							Td(
+								Class("text-end"),
+								g.Text(reportItem.DurationFormatted()),
+							),
+						)
+					}),
+					),
+				),
+			),
+		),
+		H5(
+			Class("mt-4"),
+			g.Text("Projects by User"),
+		),
+		Div(
+			Class("table-responsive"),
+			Table(
+				ID("project-user-report"),
+				Class("table table-borderless table-striped"),
+				THead(
+					Tr(
+						Th(g.Text("Project")),
+						g.Group(g.Map(len(matrix.Users), func(i int) g.Node {
+							return Th(
+								Class("text-end"),
+								g.Text(matrix.Users[i].Name),
+							)
+						})),
+						Th(
+							Class("text-end"),
+							g.Text("Total"),
+						),
+					),
+				),
+				TBody(
+					g.Group(g.Map(len(matrix.Projects), func(i int) g.Node {
+						row := matrix.Projects[i]
+						return Tr(
+							Td(g.Text(row.ProjectTitle)),
+							g.Group(g.Map(len(matrix.Users), func(j int) g.Node {
+								return Td(
+									Class("text-end"),
+									g.Text(row.DurationFormatted(matrix.Users[j].Username)),
+								)
+							})),
+							Td(
+								Class("text-end fw-bold"),
+								g.Text(row.TotalFormatted()),
+							),
 						)
 					}),
 					),
@@ -710,6 +1265,7 @@ func (a *app) reportGeneralView(pageContext *pageContext, filter *ActivityFilter
 						),
 						Th(g.Text("Start")),
 						Th(g.Text("End")),
+						Th(g.Text("Tags")),
 						Th(
 							Class("text-end"),
 							g.Text("Duration"),
@@ -727,7 +1283,8 @@ func (a *app) reportGeneralView(pageContext *pageContext, filter *ActivityFilter
 							Td(g.Text(projectsById[activity.ProjectID].Title)),
 							Td(g.Text(util.FormatDateDE(activity.Start))),
 							Td(g.Text(util.FormatTime(activity.Start))),
-							Td(g.Text(util.FormatTime(activity.End))),
+							Td(g.Text(activity.EndFormatted())),
+							Td(tagBadges(filter, view, activity.Tags)),
 							Td(
 								Class("text-end"),
 								g.Text(activity.DurationFormatted()),
@@ -852,6 +1409,21 @@ func (a *app) reportGeneralView(pageContext *pageContext, filter *ActivityFilter
 	}), nil
 }
 
+func tagBadges(filter *ActivityFilter, view *reportView, tags []string) g.Node {
+	return g.Group(g.Map(len(tags), func(i int) g.Node {
+		return A(
+			hx.Get(reportHref(filter.WithTag(tags[i]), view)),
+			hx.PushURLTrue(),
+			hx.Target("#baralga__report_content"),
+			hx.Swap("outerHTML"),
+
+			TitleAttr(fmt.Sprintf("Show activities tagged with %v", tags[i])),
+			Class("badge rounded-pill bg-light text-dark text-decoration-none me-1"),
+			g.Text(tags[i]),
+		)
+	}))
+}
+
 type reportView struct {
 	main string
 	sub  string
@@ -879,9 +1451,46 @@ func reportHref(filter *ActivityFilter, view *reportView) string {
 		reportHref += fmt.Sprintf("&sort=%v", fmt.Sprintf("%v:%v", filter.sortBy, filter.sortOrder))
 	}
 
+	if filter.splitAtMidnight {
+		reportHref += "&split=midnight"
+	}
+
+	reportHref += criteriaParams(filter)
+
 	return reportHref
 }
 
+func exportHref(filter *ActivityFilter) string {
+	exportHref := fmt.Sprintf("/api/activities?contentType=application/vnd.ms-excel&t=%v&v=%v", filter.Timespan, filter.String())
+
+	exportHref += criteriaParams(filter)
+
+	return exportHref
+}
+
+// criteriaParams returns the query params of the criteria of the filter
+func criteriaParams(filter *ActivityFilter) string {
+	params := url.Values{}
+
+	if filter.tag != "" {
+		params.Set("tag", filter.tag)
+	}
+	for _, projectID := range filter.projectIDs {
+		params.Add("project", projectID.String())
+	}
+	for _, username := range filter.usernames {
+		params.Add("user", username)
+	}
+	if filter.description != "" {
+		params.Set("description", filter.description)
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+	return "&" + params.Encode()
+}
+
 func (a *app) ReportPage(pageContext *pageContext, reportView g.Node) g.Node {
 	return Page(
 		pageContext.title,
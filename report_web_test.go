@@ -7,7 +7,9 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/matryer/is"
 )
 
@@ -53,6 +55,85 @@ func TestHandleReportPageWithTimeByDay(t *testing.T) {
 	is.True(strings.Contains(htmlBody, "id=\"time-report-by-day\""))
 }
 
+func TestHandleReportPageWithTimeSplitAtMidnight(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=time:d&split=midnight", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"SplitAtMidnight\""))
+	is.True(strings.Contains(htmlBody, "split=midnight"))
+}
+
+func TestHandleReportPageWithTimeSplitAtMidnightTotals(t *testing.T) {
+	is := is.New(t)
+
+	start, _ := time.Parse(time.RFC3339, "2022-02-10T22:00:00Z")
+	end, _ := time.Parse(time.RFC3339, "2022-02-12T02:30:00Z")
+
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities = []*Activity{
+		{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Username:       "night",
+			Start:          start,
+			End:            end,
+		},
+	}
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: activityRepository,
+	}
+
+	reportByDay := func(path string) string {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", path, nil)
+		r.Header.Add("HX-Request", "true")
+		r.Header.Add("HX-Target", "baralga__report_content")
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+			Username:       "night",
+			OrganizationID: organizationIDSample,
+			Roles:          []string{"ROLE_USER"},
+		}))
+
+		a.HandleReportPage()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+		return httpRec.Body.String()
+	}
+
+	htmlBody := reportByDay("/reports?c=time:d&t=month&v=2022-02")
+	is.True(strings.Contains(htmlBody, "10.02.2022 Thursday"))
+	is.True(strings.Contains(htmlBody, FormatMinutesAsDuration(1710)))
+	is.True(!strings.Contains(htmlBody, "11.02.2022 Friday"))
+
+	htmlBody = reportByDay("/reports?c=time:d&t=month&v=2022-02&split=midnight")
+	is.True(strings.Contains(htmlBody, "10.02.2022 Thursday"))
+	is.True(strings.Contains(htmlBody, FormatMinutesAsDuration(120)))
+	is.True(strings.Contains(htmlBody, "11.02.2022 Friday"))
+	is.True(strings.Contains(htmlBody, FormatMinutesAsDuration(1440)))
+	is.True(strings.Contains(htmlBody, "12.02.2022 Saturday"))
+	is.True(strings.Contains(htmlBody, FormatMinutesAsDuration(150)))
+	is.True(!strings.Contains(htmlBody, FormatMinutesAsDuration(1710)))
+}
+
 func TestHandleReportPageWithTimeByWeek(t *testing.T) {
 	is := is.New(t)
 	httpRec := httptest.NewRecorder()
@@ -141,6 +222,157 @@ func TestHandleReportPageWithProject(t *testing.T) {
 	is.True(strings.Contains(htmlBody, "id=\"project-report\""))
 }
 
+func TestHandleReportPageWithTags(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities[0].Tags = []string{"meeting"}
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: activityRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=tags&t=year", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"tag-report\""))
+	is.True(strings.Contains(htmlBody, "meeting"))
+}
+
+func TestHandleReportPageWithTagFilter(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	activityRepository := NewInMemActivityRepository()
+	activityRepository.activities[0].Tags = []string{"meeting"}
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: activityRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=general&t=year&tag=support", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "No activities found"))
+	is.True(strings.Contains(htmlBody, "Remove tag filter"))
+}
+
+func TestHandleReportPageWithCriteriaAsAdmin(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=project&t=year&user=admin%40baralga.com&description=invoice", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "All users"))
+	is.True(strings.Contains(htmlBody, "value=\"invoice\""))
+	is.True(strings.Contains(htmlBody, "Remove filters"))
+	is.True(strings.Contains(htmlBody, "description=invoice"))
+}
+
+func TestHandleReportPageWithUsers(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=users&t=year", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"user-report\""))
+	is.True(strings.Contains(htmlBody, "id=\"project-user-report\""))
+}
+
+func TestHandleReportPageWithGaps(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=gaps&t=day&v=2021-11-12", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "id=\"gap-report\""))
+	is.True(strings.Contains(htmlBody, "09:00 - 17:00"))
+}
+
+func TestHandleReportPageWithUsersAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  NewInMemProjectRepository(),
+		ActivityRepository: NewInMemActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/reports?c=users&t=year", nil)
+	r.Header.Add("HX-Request", "true")
+	r.Header.Add("HX-Target", "baralga__report_content")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleReportPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(!strings.Contains(htmlBody, "id=\"user-report\""))
+}
+
 func TestReportViewFromQueryParams(t *testing.T) {
 	is := is.New(t)
 
@@ -253,3 +485,37 @@ func TestReportViewFromQueryParams(t *testing.T) {
 		is.Equal(view.sub, "d")
 	})
 }
+
+func TestHandleReportPageWithCriteriaOfUnassignedProject(t *testing.T) {
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	a := &app{
+		Config:             &config{},
+		ProjectRepository:  projectRepository,
+		ActivityRepository: NewInMemActivityRepository(),
+		UserRepository:     NewInMemUserRepository(),
+	}
+
+	reportCriteriaOf := func(username string) string {
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/reports?c=time&t=year&description=invoice", nil)
+		r.Header.Add("HX-Request", "true")
+		r.Header.Add("HX-Target", "baralga__report_content")
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+			Username:       username,
+			OrganizationID: organizationIDSample,
+			Roles:          []string{"ROLE_USER"},
+		}))
+
+		a.HandleReportPage()(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+		return httpRec.Body.String()
+	}
+
+	is.True(strings.Contains(reportCriteriaOf("user1"), projectIDSample.String()))
+	is.True(!strings.Contains(reportCriteriaOf("user2"), projectIDSample.String()))
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var ErrRunningActivityNotFound = errors.New("running activity not found")
+
+type RunningActivityRepository interface {
+	FindRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) (*RunningActivity, error)
+	InsertRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error)
+	UpdateRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error)
+	DeleteRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) error
+}
+
+// DbRunningActivityRepository is a SQL database repository for running activities
+type DbRunningActivityRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ RunningActivityRepository = (*DbRunningActivityRepository)(nil)
+
+// NewDbRunningActivityRepository creates a new SQL database repository for running activities
+func NewDbRunningActivityRepository(connPool *pgxpool.Pool) *DbRunningActivityRepository {
+	return &DbRunningActivityRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbRunningActivityRepository) FindRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) (*RunningActivity, error) {
+	row := r.connPool.QueryRow(ctx,
+		`SELECT running_activity_id as id, description, start_time, project_id
+         FROM running_activities
+	     WHERE org_id = $1 AND username = $2`,
+		organizationID, username)
+
+	var (
+		id          string
+		description pgtype.Varchar
+		startTime   time.Time
+		projectID   string
+	)
+
+	err := row.Scan(&id, &description, &startTime, &projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRunningActivityNotFound
+		}
+
+		return nil, err
+	}
+
+	runningActivity := &RunningActivity{
+		ID:             uuid.MustParse(id),
+		Description:    description.String,
+		Start:          startTime,
+		Username:       username,
+		OrganizationID: organizationID,
+		ProjectID:      uuid.MustParse(projectID),
+	}
+
+	return runningActivity, nil
+}
+
+func (r *DbRunningActivityRepository) InsertRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO running_activities
+		   (running_activity_id, start_time, description, project_id, org_id, username)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6)`,
+		runningActivity.ID,
+		runningActivity.Start,
+		runningActivity.Description,
+		runningActivity.ProjectID,
+		runningActivity.OrganizationID,
+		runningActivity.Username,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return runningActivity, nil
+}
+
+func (r *DbRunningActivityRepository) UpdateRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`UPDATE running_activities
+		 SET description = $4, project_id = $5
+		 WHERE running_activity_id = $1 AND org_id = $2 AND username = $3
+		 RETURNING running_activity_id`,
+		runningActivity.ID, runningActivity.OrganizationID, runningActivity.Username,
+		runningActivity.Description, runningActivity.ProjectID,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRunningActivityNotFound
+		}
+
+		return nil, err
+	}
+
+	return runningActivity, nil
+}
+
+func (r *DbRunningActivityRepository) DeleteRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`DELETE
+         FROM running_activities
+	     WHERE org_id = $1 AND username = $2
+		 RETURNING running_activity_id`,
+		organizationID, username)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRunningActivityNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
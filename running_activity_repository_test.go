@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestRunningActivityRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	runningActivityRepository := NewDbRunningActivityRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("FindNotExistingRunningActivity", func(t *testing.T) {
+		_, err := runningActivityRepository.FindRunningActivityByUsername(
+			context.Background(),
+			organizationIDSample,
+			"-not running-",
+		)
+
+		is.True(errors.Is(err, ErrRunningActivityNotFound))
+	})
+
+	t.Run("InsertAndFindAndUpdateAndDeleteRunningActivity", func(t *testing.T) {
+		start, _ := time.Parse(time.RFC3339, "2021-11-12T11:00:00.000Z")
+
+		runningActivity := &RunningActivity{
+			ID:             uuid.New(),
+			ProjectID:      projectIDSample,
+			OrganizationID: organizationIDSample,
+			Username:       "user1@baralga.com",
+			Start:          start,
+			Description:    "My Desc",
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := runningActivityRepository.InsertRunningActivity(
+					ctx,
+					runningActivity,
+				)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		runningActivityFound, err := runningActivityRepository.FindRunningActivityByUsername(
+			context.Background(),
+			organizationIDSample,
+			runningActivity.Username,
+		)
+		is.NoErr(err)
+		is.Equal(runningActivity.ID, runningActivityFound.ID)
+		is.Equal(runningActivity.Description, runningActivityFound.Description)
+
+		runningActivity.Description = "My updated Desc"
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := runningActivityRepository.UpdateRunningActivity(
+					ctx,
+					runningActivity,
+				)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		runningActivityFound, err = runningActivityRepository.FindRunningActivityByUsername(
+			context.Background(),
+			organizationIDSample,
+			runningActivity.Username,
+		)
+		is.NoErr(err)
+		is.Equal("My updated Desc", runningActivityFound.Description)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return runningActivityRepository.DeleteRunningActivityByUsername(
+					ctx,
+					organizationIDSample,
+					runningActivity.Username,
+				)
+			},
+		)
+		is.NoErr(err)
+
+		_, err = runningActivityRepository.FindRunningActivityByUsername(
+			context.Background(),
+			organizationIDSample,
+			runningActivity.Username,
+		)
+		is.True(errors.Is(err, ErrRunningActivityNotFound))
+	})
+}
+
+type InMemRunningActivityRepository struct {
+	runningActivities []*RunningActivity
+}
+
+var _ RunningActivityRepository = (*InMemRunningActivityRepository)(nil)
+
+func NewInMemRunningActivityRepository() *InMemRunningActivityRepository {
+	return &InMemRunningActivityRepository{
+		runningActivities: []*RunningActivity{},
+	}
+}
+
+func (r *InMemRunningActivityRepository) FindRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) (*RunningActivity, error) {
+	for _, a := range r.runningActivities {
+		if a.Username == username {
+			return a, nil
+		}
+	}
+	return nil, ErrRunningActivityNotFound
+}
+
+func (r *InMemRunningActivityRepository) InsertRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error) {
+	r.runningActivities = append(r.runningActivities, runningActivity)
+	return runningActivity, nil
+}
+
+func (r *InMemRunningActivityRepository) UpdateRunningActivity(ctx context.Context, runningActivity *RunningActivity) (*RunningActivity, error) {
+	for i, a := range r.runningActivities {
+		if a.ID == runningActivity.ID {
+			r.runningActivities[i] = runningActivity
+			return runningActivity, nil
+		}
+	}
+	return nil, ErrRunningActivityNotFound
+}
+
+func (r *InMemRunningActivityRepository) DeleteRunningActivityByUsername(ctx context.Context, organizationID uuid.UUID, username string) error {
+	for i, a := range r.runningActivities {
+		if a.Username == username {
+			r.runningActivities = append(r.runningActivities[:i], r.runningActivities[i+1:]...)
+			return nil
+		}
+	}
+	return ErrRunningActivityNotFound
+}
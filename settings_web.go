@@ -0,0 +1,238 @@
+package main
+
+import (
+	"net/http"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type settingsFormModel struct {
+	CSRFToken string
+	Timezone  string
+}
+
+type settingsParams struct {
+	errorMessage string
+	infoMessage  string
+}
+
+var commonTimezones = []string{
+	"UTC",
+	"Europe/London",
+	"Europe/Berlin",
+	"Europe/Paris",
+	"Europe/Madrid",
+	"Europe/Warsaw",
+	"Europe/Helsinki",
+	"Europe/Moscow",
+	"America/New_York",
+	"America/Chicago",
+	"America/Denver",
+	"America/Los_Angeles",
+	"America/Sao_Paulo",
+	"Asia/Dubai",
+	"Asia/Kolkata",
+	"Asia/Singapore",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Australia/Sydney",
+	"Pacific/Auckland",
+}
+
+func (a *app) HandleSettingsPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Settings",
+		}
+
+		formModel := settingsFormModel{
+			CSRFToken: csrf.Token(r),
+			Timezone:  principal.Location().String(),
+		}
+
+		util.RenderHTML(w, SettingsPage(pageContext, formModel, &settingsParams{}))
+	}
+}
+
+func (a *app) HandleSettingsForm(tokenAuth *jwtauth.JWTAuth) http.HandlerFunc {
+	isProduction := a.isProduction()
+	expiryDuration := a.Config.ExpiryDuration()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Settings",
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			formModel := settingsFormModel{}
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, SettingsPage(pageContext, formModel, &settingsParams{}))
+			return
+		}
+
+		var formModel settingsFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			formModel.CSRFToken = csrf.Token(r)
+			util.RenderHTML(w, SettingsPage(pageContext, formModel, &settingsParams{}))
+			return
+		}
+
+		principalUpdated, err := a.UpdateTimezone(r.Context(), principal, formModel.Timezone)
+		if errors.Is(err, ErrTimezoneInvalid) {
+			formModel.CSRFToken = csrf.Token(r)
+			settingsParams := &settingsParams{
+				errorMessage: "Unknown time zone. Please use a name like Europe/Berlin.",
+			}
+			util.RenderHTML(w, SettingsPage(pageContext, formModel, settingsParams))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		// issue a new cookie so the time zone is used from the next request on
+		cookie := a.CreateCookie(tokenAuth, expiryDuration, principalUpdated)
+		http.SetCookie(w, &cookie)
+
+		pageContext.principal = principalUpdated
+		formModel.CSRFToken = csrf.Token(r)
+		formModel.Timezone = principalUpdated.Timezone
+		settingsParams := &settingsParams{
+			infoMessage: "Settings saved.",
+		}
+		util.RenderHTML(w, SettingsPage(pageContext, formModel, settingsParams))
+	}
+}
+
+func SettingsPage(pageContext *pageContext, formModel settingsFormModel, settingsParams *settingsParams) g.Node {
+	var adminLinks g.Node
+	if pageContext.principal.HasRole("ROLE_ADMIN") {
+		adminLinks = Div(
+			Div(
+				Class("mt-2"),
+				A(
+					Href("/settings/periods"),
+					I(Class("bi-lock me-2")),
+					g.Text("Manage Closed Periods"),
+				),
+			),
+			Div(
+				Class("mt-2"),
+				A(
+					Href("/webhooks"),
+					I(Class("bi-broadcast me-2")),
+					g.Text("Manage Webhooks"),
+				),
+			),
+		)
+	}
+
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Settings")),
+					),
+					SettingsForm(formModel, settingsParams),
+					Div(
+						Class("mt-4"),
+						A(
+							Href("/settings/tokens"),
+							I(Class("bi-key me-2")),
+							g.Text("Manage API Tokens"),
+						),
+					),
+					adminLinks,
+				),
+			),
+		},
+	)
+}
+
+func SettingsForm(formModel settingsFormModel, settingsParams *settingsParams) g.Node {
+	return FormEl(
+		ID("settings_form"),
+		Action("/settings"),
+		Method("POST"),
+		hx.Boost(),
+		g.If(
+			settingsParams.errorMessage != "",
+			Div(
+				Class("alert alert-warning text-center"),
+				Role("alert"),
+				Span(g.Text(settingsParams.errorMessage)),
+			),
+		),
+		g.If(
+			settingsParams.infoMessage != "",
+			Div(
+				Class("alert alert-success text-center"),
+				Role("alert"),
+				Span(g.Text(settingsParams.infoMessage)),
+			),
+		),
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+		Div(
+			Class("form-floating mb-3"),
+			Input(
+				ID("timezone"),
+				Type("text"),
+				Name("Timezone"),
+				Class("form-control"),
+				g.Attr("list", "timezones"),
+				g.Attr("placeholder", "Europe/Berlin"),
+				g.Attr("required", "required"),
+				Value(formModel.Timezone),
+			),
+			Label(
+				g.Attr("for", "timezone"),
+				g.Text("Time Zone"),
+			),
+			DataList(
+				ID("timezones"),
+				g.Group(
+					g.Map(len(commonTimezones), func(i int) g.Node {
+						return Option(Value(commonTimezones[i]))
+					}),
+				),
+			),
+		),
+		Div(
+			Class("text-end"),
+			Button(
+				Type("submit"),
+				Class("btn btn-primary"),
+				I(Class("bi-save me-2")),
+				g.Text("Save"),
+			),
+		),
+	)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/matryer/is"
+)
+
+func TestHandleSettingsPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config: &config{},
+	}
+
+	r, _ := http.NewRequest("GET", "/settings", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Timezone: "Europe/Berlin"}))
+
+	a.HandleSettingsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Settings # Baralga"))
+	is.True(strings.Contains(htmlBody, "Europe/Berlin"))
+}
+
+func TestHandleSettingsFormWithValidTimezone(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	data := url.Values{}
+	data["Timezone"] = []string{"America/New_York"}
+
+	r, _ := http.NewRequest("POST", "/settings", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleSettingsForm(tokenAuth)(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(httpRec.Result().Cookies()))
+	is.Equal("America/New_York", userRepository.users[0].Timezone)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Settings saved."))
+}
+
+func TestHandleSettingsFormWithInvalidTimezone(t *testing.T) {
+	is := is.New(t)
+
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	for _, timezone := range []string{"Mars/Olympus_Mons", "Local"} {
+		httpRec := httptest.NewRecorder()
+		data := url.Values{}
+		data["Timezone"] = []string{timezone}
+
+		r, _ := http.NewRequest("POST", "/settings", strings.NewReader(data.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+			Username:       "admin@baralga.com",
+			OrganizationID: organizationIDSample,
+		}))
+
+		a.HandleSettingsForm(tokenAuth)(httpRec, r)
+		is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+		is.Equal(0, len(httpRec.Result().Cookies()))
+		is.Equal("", userRepository.users[0].Timezone)
+
+		htmlBody := httpRec.Body.String()
+		is.True(strings.Contains(htmlBody, "Unknown time zone"))
+	}
+}
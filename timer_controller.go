@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type timerModel struct {
+	ID          string         `json:"id"`
+	Start       string         `json:"start"`
+	Description string         `json:"description" validate:"max=500"`
+	Duration    *durationModel `json:"duration"`
+	Links       *hal.Links     `json:"_links"`
+}
+
+// HandleGetTimer reads the running timer
+func (a *app) HandleGetTimer() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		runningActivity, err := a.ReadRunningActivity(r.Context(), principal)
+		if errors.Is(err, ErrRunningActivityNotFound) {
+			http.Error(w, problem.New(problem.Title("timer not running")).JSONString(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		timerModel := mapToTimerModel(runningActivity, time.Now().In(principal.Location()))
+		util.RenderJSON(w, timerModel)
+	}
+}
+
+// HandleStartTimer starts the timer
+func (a *app) HandleStartTimer() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		var timerModel timerModel
+		err := json.NewDecoder(r.Body).Decode(&timerModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		err = validator.Struct(timerModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("timer not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		runningActivityToStart, err := mapToRunningActivity(&timerModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		runningActivity, err := a.StartTimer(r.Context(), principal, runningActivityToStart)
+		if errors.Is(err, ErrTimerAlreadyRunning) {
+			http.Error(w, problem.New(problem.Title("timer already running")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrProjectNotFound) {
+			http.Error(w, problem.New(problem.Title("project not found")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrProjectNotAssigned) {
+			http.Error(w, problem.New(problem.Title("not assigned to project")).JSONString(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		timerModelStarted := mapToTimerModel(runningActivity, time.Now().In(principal.Location()))
+
+		w.WriteHeader(http.StatusCreated)
+		util.RenderJSON(w, timerModelStarted)
+	}
+}
+
+// HandleUpdateTimer updates the description of the running timer
+func (a *app) HandleUpdateTimer() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		var timerModel timerModel
+		err := json.NewDecoder(r.Body).Decode(&timerModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		err = validator.Struct(timerModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("timer not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		runningActivity, err := a.UpdateTimer(r.Context(), principal, timerModel.Description)
+		if errors.Is(err, ErrRunningActivityNotFound) {
+			http.Error(w, problem.New(problem.Title("timer not running")).JSONString(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		timerModelUpdated := mapToTimerModel(runningActivity, time.Now().In(principal.Location()))
+		util.RenderJSON(w, timerModelUpdated)
+	}
+}
+
+// HandleStopTimer stops the running timer and creates the tracked activity
+func (a *app) HandleStopTimer() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		activity, err := a.StopTimer(r.Context(), principal)
+		if errors.Is(err, ErrRunningActivityNotFound) {
+			http.Error(w, problem.New(problem.Title("timer not running")).JSONString(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrTimerNotStoppable) {
+			http.Error(w, problem.New(problem.Title(fmt.Sprintf("%v, discard the timer with DELETE /api/timer", err.Error()))).JSONString(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		activityModel := mapToActivityModel(activity.In(principal.Location()))
+
+		w.Header().Set("HX-Trigger", "baralga__activities-changed")
+		w.WriteHeader(http.StatusCreated)
+		util.RenderJSON(w, activityModel)
+	}
+}
+
+// HandleDiscardTimer stops the running timer without creating an activity
+func (a *app) HandleDiscardTimer() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		err := a.DiscardTimer(r.Context(), principal)
+		if errors.Is(err, ErrRunningActivityNotFound) {
+			http.Error(w, problem.New(problem.Title("timer not running")).JSONString(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func mapToRunningActivity(timerModel *timerModel) (*RunningActivity, error) {
+	if timerModel.Links == nil {
+		return nil, errors.New("missing project link")
+	}
+
+	projectHref := timerModel.Links.HrefOf("project")
+	projectID, err := uuid.Parse(projectHref[strings.LastIndex(projectHref, "/")+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunningActivity{
+		ProjectID:   projectID,
+		Description: timerModel.Description,
+	}, nil
+}
+
+func mapToTimerModel(runningActivity *RunningActivity, now time.Time) *timerModel {
+	activity := runningActivity.StopAt(now)
+	return &timerModel{
+		ID:          runningActivity.ID.String(),
+		Description: runningActivity.Description,
+		Start:       util.FormatDateTime(runningActivity.Start.In(now.Location())),
+		Links: hal.NewLinks(
+			hal.NewSelfLink("/api/timer"),
+			hal.NewLink("stop", "/api/timer/stop"),
+			hal.NewLink("discard", "/api/timer"),
+			hal.NewLink("edit", "/api/timer"),
+			hal.NewLink("project", fmt.Sprintf("/api/projects/%s", runningActivity.ProjectID)),
+		),
+		Duration: &durationModel{
+			Hours:     activity.DurationHours(),
+			Minutes:   activity.DurationMinutes(),
+			Decimal:   activity.DurationDecimal(),
+			Formatted: activity.DurationFormatted(),
+		},
+	}
+}
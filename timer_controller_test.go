@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleGetTimerNotRunning(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                    &config{},
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/timer", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleGetTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+}
+
+func TestHandleGetTimer(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:             uuid.New(),
+		Start:          time.Now().Add(-90 * time.Minute),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       "user1",
+	})
+
+	a := &app{
+		Config:                    &config{},
+		RunningActivityRepository: runningActivityRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/api/timer", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1"}))
+
+	a.HandleGetTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	timerModel := &timerModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(timerModel)
+	is.NoErr(err)
+	is.Equal(1, timerModel.Duration.Hours)
+	is.True(strings.Contains(timerModel.Links.HrefOf("project"), projectIDSample.String()))
+}
+
+func TestHandleStartAndStopTimer(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+		OrganizationRepository:    NewInMemOrganizationRepository(),
+		TimesheetRepository:       NewInMemTimesheetRepository(),
+	}
+
+	countBefore := len(activityRepository.activities)
+	body := `
+	{
+		"description":"My Timer",
+		"_links":{
+		   "project":{
+			  "href":"http://localhost:8080/api/projects/f4b1087c-8fbb-4c8d-bbb7-ab4d46da16ea"
+		   }
+		}
+	 }
+	`
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/api/timer/start", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleStartTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+	is.Equal(1, len(runningActivityRepository.runningActivities))
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "/api/timer/start", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleStartTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "/api/timer/stop", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleStopTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusCreated)
+	is.Equal(0, len(runningActivityRepository.runningActivities))
+	is.Equal(countBefore+1, len(activityRepository.activities))
+}
+
+func TestHandleDiscardTimer(t *testing.T) {
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:             uuid.New(),
+		Start:          time.Now().Add(-1 * time.Hour).Truncate(time.Minute),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       "user1",
+	})
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+	}
+
+	countBefore := len(activityRepository.activities)
+
+	httpRec := httptest.NewRecorder()
+	r, _ := http.NewRequest("DELETE", "/api/timer", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleDiscardTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNoContent)
+	is.Equal(0, len(runningActivityRepository.runningActivities))
+	is.Equal(countBefore, len(activityRepository.activities))
+
+	httpRec = httptest.NewRecorder()
+	r, _ = http.NewRequest("DELETE", "/api/timer", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1", OrganizationID: organizationIDSample}))
+
+	a.HandleDiscardTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNotFound)
+}
+
+func TestHandleStartTimerWithoutProject(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	r, _ := http.NewRequest("POST", "/api/timer/start", strings.NewReader(`{"description":"My Timer"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1"}))
+
+	a.HandleStartTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
+
+func TestHandleUpdateTimer(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:             uuid.New(),
+		Start:          time.Now(),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       "user1",
+	})
+
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		RunningActivityRepository: runningActivityRepository,
+	}
+
+	r, _ := http.NewRequest("PATCH", "/api/timer", strings.NewReader(`{"description":"My Timer"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{Username: "user1"}))
+
+	a.HandleUpdateTimer()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal("My Timer", runningActivityRepository.runningActivities[0].Description)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrTimerAlreadyRunning = errors.New("timer already running")
+
+// ErrTimerNotStoppable is returned if the tracked time can't be booked as activity, e.g. since its
+// period was closed or its week approved meanwhile, so the timer can only be discarded
+var ErrTimerNotStoppable = errors.New("timer can not be stopped")
+
+// ReadRunningActivity reads the running activity of the principal
+func (a *app) ReadRunningActivity(ctx context.Context, principal *Principal) (*RunningActivity, error) {
+	return a.RunningActivityRepository.FindRunningActivityByUsername(ctx, principal.OrganizationID, principal.Username)
+}
+
+// StartTimer starts tracking time for the principal
+func (a *app) StartTimer(ctx context.Context, principal *Principal, runningActivity *RunningActivity) (*RunningActivity, error) {
+	_, err := a.ReadRunningActivity(ctx, principal)
+	if err == nil {
+		return nil, ErrTimerAlreadyRunning
+	}
+	if !errors.Is(err, ErrRunningActivityNotFound) {
+		return nil, err
+	}
+
+	_, err = a.ProjectRepository.FindProjectByID(ctx, principal.OrganizationID, runningActivity.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.checkProjectAssigned(ctx, principal, runningActivity.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	runningActivity.ID = uuid.New()
+	runningActivity.Start = time.Now().Truncate(time.Minute)
+	runningActivity.OrganizationID = principal.OrganizationID
+	runningActivity.Username = principal.Username
+
+	var runningActivityStarted *RunningActivity
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			ra, err := a.RunningActivityRepository.InsertRunningActivity(ctx, runningActivity)
+			if err != nil {
+				return err
+			}
+			runningActivityStarted = ra
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return runningActivityStarted, nil
+}
+
+// UpdateTimer updates the description of the running activity of the principal
+func (a *app) UpdateTimer(ctx context.Context, principal *Principal, description string) (*RunningActivity, error) {
+	runningActivity, err := a.ReadRunningActivity(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	if runningActivity.Description == description {
+		return runningActivity, nil
+	}
+	runningActivity.Description = description
+
+	var runningActivityUpdated *RunningActivity
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			ra, err := a.RunningActivityRepository.UpdateRunningActivity(ctx, runningActivity)
+			if err != nil {
+				return err
+			}
+			runningActivityUpdated = ra
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return runningActivityUpdated, nil
+}
+
+// StopTimer stops the running activity of the principal and creates an activity of the tracked time
+func (a *app) StopTimer(ctx context.Context, principal *Principal) (*Activity, error) {
+	runningActivity, err := a.ReadRunningActivity(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := runningActivity.StopAt(time.Now().Truncate(time.Minute))
+
+	err = a.checkActivityCreatable(ctx, principal, activity)
+	if errors.Is(err, ErrPeriodLocked) || errors.Is(err, ErrActivityLocked) || errors.Is(err, ErrProjectNotAssigned) || errors.Is(err, ErrActivityOverlaps) {
+		return nil, errors.Wrap(ErrTimerNotStoppable, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	activity.ID = uuid.New()
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			activityCreated, err := a.ActivityRepository.InsertActivity(ctx, activity)
+			if err != nil {
+				return err
+			}
+			return a.recordChange(ctx, principal, AuditActionCreate, AuditEntityActivity, activityCreated.ID, nil, activityCreated)
+		},
+		func(ctx context.Context) error {
+			return a.RunningActivityRepository.DeleteRunningActivityByUsername(ctx, principal.OrganizationID, principal.Username)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.checkBudgetAlerts(ctx, principal, activity.ProjectID)
+
+	return activity, nil
+}
+
+// DiscardTimer stops the running activity of the principal without creating an activity
+func (a *app) DiscardTimer(ctx context.Context, principal *Principal) error {
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.RunningActivityRepository.DeleteRunningActivityByUsername(ctx, principal.OrganizationID, principal.Username)
+		},
+	)
+}
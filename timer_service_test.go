@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestStartAndStopTimer(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	a := &app{
+		Config:                    &config{},
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+		OrganizationRepository:    NewInMemOrganizationRepository(),
+		TimesheetRepository:       NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+	activityCount := len(activityRepository.activities)
+
+	// Act
+	runningActivity, err := a.StartTimer(context.Background(), principal, &RunningActivity{
+		ProjectID:   projectIDSample,
+		Description: "My Timer",
+	})
+	is.NoErr(err)
+
+	_, err = a.UpdateTimer(context.Background(), principal, "My Updated Timer")
+	is.NoErr(err)
+
+	activity, err := a.StopTimer(context.Background(), principal)
+
+	// Assert
+	is.NoErr(err)
+	is.True(runningActivity.ID != uuid.Nil)
+	is.Equal(activity.Username, principal.Username)
+	is.Equal(activity.ProjectID, projectIDSample)
+	is.Equal(activity.Description, "My Updated Timer")
+	is.Equal(len(activityRepository.activities), activityCount+1)
+	is.Equal(len(runningActivityRepository.runningActivities), 0)
+}
+
+func TestStartTimerAlreadyRunning(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	_, err := a.StartTimer(context.Background(), principal, &RunningActivity{ProjectID: projectIDSample})
+	is.NoErr(err)
+
+	// Act
+	_, err = a.StartTimer(context.Background(), principal, &RunningActivity{ProjectID: projectIDSample})
+
+	// Assert
+	is.True(errors.Is(err, ErrTimerAlreadyRunning))
+}
+
+func TestStartTimerWithUnknownProject(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.StartTimer(context.Background(), principal, &RunningActivity{ProjectID: uuid.New()})
+
+	// Assert
+	is.True(errors.Is(err, ErrProjectNotFound))
+}
+
+func TestStopTimerNotRunning(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ActivityRepository:        NewInMemActivityRepository(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.StopTimer(context.Background(), principal)
+
+	// Assert
+	is.True(errors.Is(err, ErrRunningActivityNotFound))
+}
+
+func TestStopTimerInClosedPeriod(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         NewInMemProjectRepository(),
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		OrganizationRepository:    NewInMemOrganizationRepository(),
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+	}
+
+	admin := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}
+
+	runningActivityRepository.runningActivities = append(runningActivityRepository.runningActivities, &RunningActivity{
+		ID:             uuid.New(),
+		Start:          time.Now().Add(-2 * time.Hour).Truncate(time.Minute),
+		ProjectID:      projectIDSample,
+		OrganizationID: organizationIDSample,
+		Username:       principal.Username,
+	})
+	activityCount := len(activityRepository.activities)
+
+	lockedUntil := time.Now().Add(24 * time.Hour)
+	_, err := a.UpdateLockedUntil(context.Background(), admin, &lockedUntil)
+	is.NoErr(err)
+
+	// Act
+	_, err = a.StopTimer(context.Background(), principal)
+
+	// Assert
+	is.True(errors.Is(err, ErrTimerNotStoppable))
+	is.True(strings.Contains(err.Error(), ErrPeriodLocked.Error()))
+	is.Equal(len(activityRepository.activities), activityCount)
+	is.Equal(len(runningActivityRepository.runningActivities), 1)
+
+	err = a.DiscardTimer(context.Background(), principal)
+	is.NoErr(err)
+	is.Equal(len(activityRepository.activities), activityCount)
+	is.Equal(len(runningActivityRepository.runningActivities), 0)
+}
+
+func TestStopTimerOnUnassignedProject(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	projectRepository := NewInMemProjectRepository()
+	activityRepository := NewInMemActivityRepository()
+	runningActivityRepository := NewInMemRunningActivityRepository()
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		ProjectRepository:         projectRepository,
+		ActivityRepository:        activityRepository,
+		RunningActivityRepository: runningActivityRepository,
+		OrganizationRepository:    NewInMemOrganizationRepository(),
+		AuditLogRepository:        NewInMemAuditLogRepository(),
+		WebhookRepository:         NewInMemWebhookRepository(),
+		TimesheetRepository:       NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}
+
+	_, err := a.StartTimer(context.Background(), principal, &RunningActivity{ProjectID: projectIDSample})
+	is.NoErr(err)
+
+	projectRepository.members = []*ProjectMember{
+		{ProjectID: projectIDSample, Username: "user2", Role: ProjectRoleMember, OrganizationID: organizationIDSample},
+	}
+	activityCount := len(activityRepository.activities)
+
+	// Act
+	_, err = a.StopTimer(context.Background(), principal)
+
+	// Assert
+	is.True(errors.Is(err, ErrTimerNotStoppable))
+	is.Equal(len(activityRepository.activities), activityCount)
+
+	err = a.DiscardTimer(context.Background(), principal)
+	is.NoErr(err)
+
+	projectRepository.members = append(projectRepository.members, &ProjectMember{
+		ProjectID: projectIDSample, Username: "user1", Role: ProjectRoleMember, OrganizationID: organizationIDSample,
+	})
+	_, err = a.StartTimer(context.Background(), principal, &RunningActivity{ProjectID: projectIDSample})
+	is.NoErr(err)
+}
+
+func TestDiscardTimerNotRunning(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer:            NewInMemRepositoryTxer(),
+		RunningActivityRepository: NewInMemRunningActivityRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	err := a.DiscardTimer(context.Background(), principal)
+
+	// Assert
+	is.True(errors.Is(err, ErrRunningActivityNotFound))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/snabb/isoweek"
+)
+
+// Status of timesheets
+const (
+	TimesheetStatusDraft     string = "draft"
+	TimesheetStatusSubmitted string = "submitted"
+	TimesheetStatusApproved  string = "approved"
+	TimesheetStatusRejected  string = "rejected"
+)
+
+var ErrTimesheetStatusInvalid = errors.New("timesheet status invalid")
+
+// Timesheet is the approval of the activities of a user in an ISO week
+type Timesheet struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Username       string
+	Year           int
+	Week           int
+	Status         string
+	Comment        string
+	SubmittedAt    *time.Time
+	ReviewedBy     string
+	ReviewedAt     *time.Time
+}
+
+// NewTimesheet creates a draft timesheet of the user for the ISO week
+func NewTimesheet(organizationID uuid.UUID, username string, year, week int) *Timesheet {
+	return &Timesheet{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		Username:       username,
+		Year:           year,
+		Week:           week,
+		Status:         TimesheetStatusDraft,
+	}
+}
+
+// Start returns the start of the timesheet's week in the location
+func (t *Timesheet) Start(loc *time.Location) time.Time {
+	return isoweek.StartTime(t.Year, t.Week, loc)
+}
+
+// End returns the end of the timesheet's week in the location
+func (t *Timesheet) End(loc *time.Location) time.Time {
+	return t.Start(loc).AddDate(0, 0, 7)
+}
+
+// WeekValue is the week of the timesheet as used in urls (e.g. 2021-5)
+func (t *Timesheet) WeekValue() string {
+	return fmt.Sprintf("%v-%v", t.Year, t.Week)
+}
+
+// WeekFormatted is the week of the timesheet as formatted string (e.g. Week 5/2021)
+func (t *Timesheet) WeekFormatted() string {
+	return fmt.Sprintf("Week %v/%v", t.Week, t.Year)
+}
+
+// IsApproved checks whether the timesheet is approved, which locks its activities
+func (t *Timesheet) IsApproved() bool {
+	return t.Status == TimesheetStatusApproved
+}
+
+// CanSubmit checks whether the user may submit the timesheet for approval
+func (t *Timesheet) CanSubmit() bool {
+	return t.Status == TimesheetStatusDraft || t.Status == TimesheetStatusRejected
+}
+
+// CanApprove checks whether the timesheet awaits approval
+func (t *Timesheet) CanApprove() bool {
+	return t.Status == TimesheetStatusSubmitted
+}
+
+// CanReject checks whether the timesheet may be rejected, approved timesheets
+// are rejected to reopen them for changes
+func (t *Timesheet) CanReject() bool {
+	return t.Status == TimesheetStatusSubmitted || t.Status == TimesheetStatusApproved
+}
+
+// Submit submits the timesheet for approval
+func (t *Timesheet) Submit(comment string, now time.Time) error {
+	if !t.CanSubmit() {
+		return ErrTimesheetStatusInvalid
+	}
+
+	t.Status = TimesheetStatusSubmitted
+	t.Comment = comment
+	t.SubmittedAt = &now
+	t.ReviewedBy = ""
+	t.ReviewedAt = nil
+	return nil
+}
+
+// Approve approves the submitted timesheet
+func (t *Timesheet) Approve(reviewer, comment string, now time.Time) error {
+	if !t.CanApprove() {
+		return ErrTimesheetStatusInvalid
+	}
+
+	t.review(TimesheetStatusApproved, reviewer, comment, now)
+	return nil
+}
+
+// Reject rejects the timesheet, so the user may change its activities and submit it again
+func (t *Timesheet) Reject(reviewer, comment string, now time.Time) error {
+	if !t.CanReject() {
+		return ErrTimesheetStatusInvalid
+	}
+
+	t.review(TimesheetStatusRejected, reviewer, comment, now)
+	return nil
+}
+
+func (t *Timesheet) review(status, reviewer, comment string, now time.Time) {
+	t.Status = status
+	t.ReviewedBy = reviewer
+	t.ReviewedAt = &now
+	if comment != "" {
+		t.Comment = comment
+	}
+}
+
+// ParseTimesheetWeek parses the ISO week of a timesheet (e.g. 2021-5)
+func ParseTimesheetWeek(value string) (int, int, error) {
+	valueParts := strings.Split(value, "-")
+	if len(valueParts) != 2 {
+		return 0, 0, errors.New("invalid week")
+	}
+
+	year, err := strconv.Atoi(valueParts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	week, err := strconv.Atoi(valueParts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if week < 1 || week > 53 {
+		return 0, 0, errors.New("invalid week")
+	}
+
+	return year, week, nil
+}
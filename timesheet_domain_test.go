@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTimesheetApprovalWorkflow(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Now()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	is.Equal(TimesheetStatusDraft, timesheet.Status)
+	is.True(timesheet.CanSubmit())
+	is.True(!timesheet.CanApprove())
+
+	err := timesheet.Approve("admin", "", now)
+	is.True(errors.Is(err, ErrTimesheetStatusInvalid))
+
+	err = timesheet.Submit("All done", now)
+	is.NoErr(err)
+	is.Equal(TimesheetStatusSubmitted, timesheet.Status)
+	is.True(!timesheet.CanSubmit())
+
+	err = timesheet.Reject("admin", "Friday is missing", now)
+	is.NoErr(err)
+	is.Equal(TimesheetStatusRejected, timesheet.Status)
+	is.Equal("Friday is missing", timesheet.Comment)
+
+	err = timesheet.Submit("Added friday", now)
+	is.NoErr(err)
+	is.True(timesheet.ReviewedAt == nil)
+
+	err = timesheet.Approve("admin", "", now)
+	is.NoErr(err)
+	is.True(timesheet.IsApproved())
+	is.Equal("admin", timesheet.ReviewedBy)
+	is.Equal("Added friday", timesheet.Comment)
+
+	err = timesheet.Submit("", now)
+	is.True(errors.Is(err, ErrTimesheetStatusInvalid))
+
+	err = timesheet.Reject("admin", "Reopened", now)
+	is.NoErr(err)
+	is.True(!timesheet.IsApproved())
+}
+
+func TestTimesheetWeek(t *testing.T) {
+	is := is.New(t)
+
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 1)
+
+	is.Equal("2021-1", timesheet.WeekValue())
+	is.Equal("Week 1/2021", timesheet.WeekFormatted())
+	is.Equal(time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), timesheet.Start(time.UTC))
+	is.Equal(time.Date(2021, 1, 11, 0, 0, 0, 0, time.UTC), timesheet.End(time.UTC))
+}
+
+func TestParseTimesheetWeek(t *testing.T) {
+	is := is.New(t)
+
+	year, week, err := ParseTimesheetWeek("2021-45")
+	is.NoErr(err)
+	is.Equal(2021, year)
+	is.Equal(45, week)
+
+	_, _, err = ParseTimesheetWeek("2021-54")
+	is.True(err != nil)
+
+	_, _, err = ParseTimesheetWeek("2021")
+	is.True(err != nil)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var ErrTimesheetNotFound = errors.New("timesheet not found")
+
+type TimesheetRepository interface {
+	FindTimesheetByID(ctx context.Context, organizationID, timesheetID uuid.UUID) (*Timesheet, error)
+	FindTimesheetByWeek(ctx context.Context, organizationID uuid.UUID, username string, year, week int) (*Timesheet, error)
+	FindTimesheetsByStatus(ctx context.Context, organizationID uuid.UUID, status string) ([]*Timesheet, error)
+	InsertTimesheet(ctx context.Context, timesheet *Timesheet) (*Timesheet, error)
+	UpdateTimesheet(ctx context.Context, organizationID uuid.UUID, timesheet *Timesheet) (*Timesheet, error)
+}
+
+// DbTimesheetRepository is a SQL database repository for timesheets
+type DbTimesheetRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ TimesheetRepository = (*DbTimesheetRepository)(nil)
+
+// NewDbTimesheetRepository creates a new SQL database repository for timesheets
+func NewDbTimesheetRepository(connPool *pgxpool.Pool) *DbTimesheetRepository {
+	return &DbTimesheetRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbTimesheetRepository) FindTimesheetByID(ctx context.Context, organizationID, timesheetID uuid.UUID) (*Timesheet, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT timesheet_id, username, year, week, status, comment, submitted_at, reviewed_by, reviewed_at, org_id
+		 FROM timesheets
+		 WHERE org_id = $1 AND timesheet_id = $2`,
+		organizationID, timesheetID,
+	)
+
+	timesheet, err := scanTimesheet(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+
+		return nil, err
+	}
+
+	return timesheet, nil
+}
+
+func (r *DbTimesheetRepository) FindTimesheetByWeek(ctx context.Context, organizationID uuid.UUID, username string, year, week int) (*Timesheet, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT timesheet_id, username, year, week, status, comment, submitted_at, reviewed_by, reviewed_at, org_id
+		 FROM timesheets
+		 WHERE org_id = $1 AND username = $2 AND year = $3 AND week = $4`,
+		organizationID, username, year, week,
+	)
+
+	timesheet, err := scanTimesheet(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+
+		return nil, err
+	}
+
+	return timesheet, nil
+}
+
+func (r *DbTimesheetRepository) FindTimesheetsByStatus(ctx context.Context, organizationID uuid.UUID, status string) ([]*Timesheet, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT timesheet_id, username, year, week, status, comment, submitted_at, reviewed_by, reviewed_at, org_id
+		 FROM timesheets
+		 WHERE org_id = $1 AND status = $2
+		 ORDER BY year, week, username`,
+		organizationID, status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var timesheets []*Timesheet
+	for rows.Next() {
+		timesheet, err := scanTimesheet(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		timesheets = append(timesheets, timesheet)
+	}
+
+	return timesheets, nil
+}
+
+func (r *DbTimesheetRepository) InsertTimesheet(ctx context.Context, timesheet *Timesheet) (*Timesheet, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO timesheets
+		   (timesheet_id, username, year, week, status, comment, submitted_at, reviewed_by, reviewed_at, org_id)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		timesheet.ID,
+		timesheet.Username,
+		timesheet.Year,
+		timesheet.Week,
+		timesheet.Status,
+		timesheet.Comment,
+		timesheet.SubmittedAt,
+		timesheet.ReviewedBy,
+		timesheet.ReviewedAt,
+		timesheet.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return timesheet, nil
+}
+
+func (r *DbTimesheetRepository) UpdateTimesheet(ctx context.Context, organizationID uuid.UUID, timesheet *Timesheet) (*Timesheet, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`UPDATE timesheets
+		 SET status = $3, comment = $4, submitted_at = $5, reviewed_by = $6, reviewed_at = $7
+		 WHERE timesheet_id = $1 AND org_id = $2
+		 RETURNING timesheet_id`,
+		timesheet.ID,
+		organizationID,
+		timesheet.Status,
+		timesheet.Comment,
+		timesheet.SubmittedAt,
+		timesheet.ReviewedBy,
+		timesheet.ReviewedAt,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+
+		return nil, err
+	}
+
+	return timesheet, nil
+}
+
+func scanTimesheet(row pgx.Row) (*Timesheet, error) {
+	timesheet := &Timesheet{}
+
+	var comment, reviewedBy *string
+	err := row.Scan(
+		&timesheet.ID,
+		&timesheet.Username,
+		&timesheet.Year,
+		&timesheet.Week,
+		&timesheet.Status,
+		&comment,
+		&timesheet.SubmittedAt,
+		&reviewedBy,
+		&timesheet.ReviewedAt,
+		&timesheet.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment != nil {
+		timesheet.Comment = *comment
+	}
+	if reviewedBy != nil {
+		timesheet.ReviewedBy = *reviewedBy
+	}
+
+	return timesheet, nil
+}
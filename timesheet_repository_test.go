@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestTimesheetRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	timesheetRepository := NewDbTimesheetRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("FindNotExistingTimesheet", func(t *testing.T) {
+		_, err := timesheetRepository.FindTimesheetByWeek(
+			context.Background(),
+			organizationIDSample,
+			"user1",
+			2021,
+			45,
+		)
+
+		is.True(errors.Is(err, ErrTimesheetNotFound))
+	})
+
+	t.Run("InsertAndFindAndUpdateTimesheet", func(t *testing.T) {
+		timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 46)
+		err := timesheet.Submit("", time.Now())
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := timesheetRepository.InsertTimesheet(ctx, timesheet)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		timesheetFound, err := timesheetRepository.FindTimesheetByWeek(context.Background(), organizationIDSample, "user1", 2021, 46)
+		is.NoErr(err)
+		is.Equal(timesheet.ID, timesheetFound.ID)
+		is.Equal(TimesheetStatusSubmitted, timesheetFound.Status)
+		is.True(timesheetFound.SubmittedAt != nil)
+		is.True(timesheetFound.ReviewedAt == nil)
+
+		timesheets, err := timesheetRepository.FindTimesheetsByStatus(context.Background(), organizationIDSample, TimesheetStatusSubmitted)
+		is.NoErr(err)
+		is.Equal(1, len(timesheets))
+
+		err = timesheet.Approve("admin", "Looks good", time.Now())
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := timesheetRepository.UpdateTimesheet(ctx, organizationIDSample, timesheet)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		timesheetFound, err = timesheetRepository.FindTimesheetByID(context.Background(), organizationIDSample, timesheet.ID)
+		is.NoErr(err)
+		is.Equal(TimesheetStatusApproved, timesheetFound.Status)
+		is.Equal("admin", timesheetFound.ReviewedBy)
+		is.Equal("Looks good", timesheetFound.Comment)
+
+		timesheets, err = timesheetRepository.FindTimesheetsByStatus(context.Background(), organizationIDSample, TimesheetStatusSubmitted)
+		is.NoErr(err)
+		is.Equal(0, len(timesheets))
+	})
+
+	t.Run("UpdateNotExistingTimesheet", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := timesheetRepository.UpdateTimesheet(ctx, organizationIDSample, NewTimesheet(organizationIDSample, "user1", 2021, 47))
+				return err
+			},
+		)
+		is.True(errors.Is(err, ErrTimesheetNotFound))
+	})
+}
+
+type InMemTimesheetRepository struct {
+	timesheets []*Timesheet
+}
+
+var _ TimesheetRepository = (*InMemTimesheetRepository)(nil)
+
+func NewInMemTimesheetRepository() *InMemTimesheetRepository {
+	return &InMemTimesheetRepository{
+		timesheets: []*Timesheet{},
+	}
+}
+
+func (r *InMemTimesheetRepository) FindTimesheetByID(ctx context.Context, organizationID, timesheetID uuid.UUID) (*Timesheet, error) {
+	for _, t := range r.timesheets {
+		if t.ID == timesheetID && t.OrganizationID == organizationID {
+			return t, nil
+		}
+	}
+	return nil, ErrTimesheetNotFound
+}
+
+func (r *InMemTimesheetRepository) FindTimesheetByWeek(ctx context.Context, organizationID uuid.UUID, username string, year, week int) (*Timesheet, error) {
+	for _, t := range r.timesheets {
+		if t.OrganizationID == organizationID && t.Username == username && t.Year == year && t.Week == week {
+			return t, nil
+		}
+	}
+	return nil, ErrTimesheetNotFound
+}
+
+func (r *InMemTimesheetRepository) FindTimesheetsByStatus(ctx context.Context, organizationID uuid.UUID, status string) ([]*Timesheet, error) {
+	var timesheets []*Timesheet
+	for _, t := range r.timesheets {
+		if t.OrganizationID == organizationID && t.Status == status {
+			timesheets = append(timesheets, t)
+		}
+	}
+	return timesheets, nil
+}
+
+func (r *InMemTimesheetRepository) InsertTimesheet(ctx context.Context, timesheet *Timesheet) (*Timesheet, error) {
+	r.timesheets = append(r.timesheets, timesheet)
+	return timesheet, nil
+}
+
+func (r *InMemTimesheetRepository) UpdateTimesheet(ctx context.Context, organizationID uuid.UUID, timesheet *Timesheet) (*Timesheet, error) {
+	for i, t := range r.timesheets {
+		if t.ID == timesheet.ID && t.OrganizationID == organizationID {
+			r.timesheets[i] = timesheet
+			return timesheet, nil
+		}
+	}
+	return nil, ErrTimesheetNotFound
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrActivityLocked = errors.New("activity locked")
+
+// ReadTimesheet reads the timesheet of the principal for the ISO week,
+// weeks without a timesheet yet are drafts
+func (a *app) ReadTimesheet(ctx context.Context, principal *Principal, year, week int) (*Timesheet, error) {
+	timesheet, err := a.TimesheetRepository.FindTimesheetByWeek(ctx, principal.OrganizationID, principal.Username, year, week)
+	if errors.Is(err, ErrTimesheetNotFound) {
+		return NewTimesheet(principal.OrganizationID, principal.Username, year, week), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return timesheet, nil
+}
+
+// ReadSubmittedTimesheets reads the timesheets of the organization awaiting approval
+func (a *app) ReadSubmittedTimesheets(ctx context.Context, principal *Principal) ([]*Timesheet, error) {
+	return a.TimesheetRepository.FindTimesheetsByStatus(ctx, principal.OrganizationID, TimesheetStatusSubmitted)
+}
+
+// SubmitTimesheet submits the timesheet of the principal for the ISO week for approval
+// and notifies the admins of the organization
+func (a *app) SubmitTimesheet(ctx context.Context, principal *Principal, year, week int, comment string) (*Timesheet, error) {
+	timesheet, err := a.ReadTimesheet(ctx, principal, year, week)
+	if err != nil {
+		return nil, err
+	}
+
+	isNew := timesheet.Status == TimesheetStatusDraft
+
+	err = timesheet.Submit(comment, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			if isNew {
+				_, err := a.TimesheetRepository.InsertTimesheet(ctx, timesheet)
+				return err
+			}
+			_, err := a.TimesheetRepository.UpdateTimesheet(ctx, principal.OrganizationID, timesheet)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.notifyTimesheetSubmitted(ctx, principal, timesheet)
+
+	return timesheet, nil
+}
+
+// ApproveTimesheet approves a submitted timesheet, which locks the activities of its week
+func (a *app) ApproveTimesheet(ctx context.Context, principal *Principal, timesheetID uuid.UUID, comment string) (*Timesheet, error) {
+	return a.reviewTimesheet(ctx, principal, timesheetID, func(timesheet *Timesheet) error {
+		return timesheet.Approve(principal.Username, comment, time.Now())
+	})
+}
+
+// RejectTimesheet rejects a submitted or approved timesheet, so the user can change
+// the activities of its week and submit it again
+func (a *app) RejectTimesheet(ctx context.Context, principal *Principal, timesheetID uuid.UUID, comment string) (*Timesheet, error) {
+	return a.reviewTimesheet(ctx, principal, timesheetID, func(timesheet *Timesheet) error {
+		return timesheet.Reject(principal.Username, comment, time.Now())
+	})
+}
+
+func (a *app) reviewTimesheet(ctx context.Context, principal *Principal, timesheetID uuid.UUID, review func(timesheet *Timesheet) error) (*Timesheet, error) {
+	timesheet, err := a.TimesheetRepository.FindTimesheetByID(ctx, principal.OrganizationID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = review(timesheet)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			_, err := a.TimesheetRepository.UpdateTimesheet(ctx, principal.OrganizationID, timesheet)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.notifyTimesheetReviewed(ctx, timesheet)
+
+	return timesheet, nil
+}
+
+// checkActivityLocked checks whether the activity of the user starting at the given
+// time lies within a closed period or is part of an approved timesheet
+func (a *app) checkActivityLocked(ctx context.Context, principal *Principal, username string, start time.Time) error {
+	err := a.checkPeriodLocked(ctx, principal, start)
+	if err != nil {
+		return err
+	}
+
+	year, week := start.In(principal.Location()).ISOWeek()
+
+	approved, err := a.isTimesheetApproved(ctx, principal, username, year, week)
+	if err != nil {
+		return err
+	}
+
+	if approved {
+		return ErrActivityLocked
+	}
+
+	return nil
+}
+
+// isTimesheetApproved checks whether the timesheet of the user for the ISO week is approved
+func (a *app) isTimesheetApproved(ctx context.Context, principal *Principal, username string, year, week int) (bool, error) {
+	timesheet, err := a.TimesheetRepository.FindTimesheetByWeek(ctx, principal.OrganizationID, username, year, week)
+	if errors.Is(err, ErrTimesheetNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return timesheet.IsApproved(), nil
+}
+
+// notifyTimesheetSubmitted notifies the admins of the organization by email about the submitted timesheet
+func (a *app) notifyTimesheetSubmitted(ctx context.Context, principal *Principal, timesheet *Timesheet) {
+	subject := fmt.Sprintf("Timesheet %v of %v submitted", timesheet.WeekFormatted(), principal.Name)
+	body := fmt.Sprintf(
+		`%v submitted the timesheet of %v for approval. Review it at %v/timesheets/approvals.`,
+		principal.Name,
+		timesheet.WeekFormatted(),
+		a.Config.Webroot,
+	)
+
+	err := a.sendMailToAdmins(ctx, principal.OrganizationID, subject, body)
+	if err != nil {
+		log.Printf("could not notify admins about timesheet %v: %v", timesheet.ID, err)
+	}
+}
+
+// notifyTimesheetReviewed notifies the user of the timesheet by email about the review
+func (a *app) notifyTimesheetReviewed(ctx context.Context, timesheet *Timesheet) {
+	user, err := a.UserRepository.FindUserByUsername(ctx, timesheet.Username)
+	if err != nil {
+		log.Printf("could not notify user about timesheet %v: %v", timesheet.ID, err)
+		return
+	}
+	if user.EMail == "" {
+		return
+	}
+
+	subject := fmt.Sprintf("Timesheet %v %v", timesheet.WeekFormatted(), timesheet.Status)
+	body := fmt.Sprintf(
+		`Your timesheet of %v was %v. See it at %v/timesheets?v=%v.`,
+		timesheet.WeekFormatted(),
+		timesheet.Status,
+		a.Config.Webroot,
+		timesheet.WeekValue(),
+	)
+	if timesheet.Comment != "" {
+		body = fmt.Sprintf("%v\n\nComment: %v", body, timesheet.Comment)
+	}
+
+	err = a.MailResource.SendMail(user.EMail, subject, body)
+	if err != nil {
+		log.Printf("could not notify user about timesheet %v: %v", timesheet.ID, err)
+	}
+}
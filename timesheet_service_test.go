@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSubmitAndApproveTimesheet(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	mailResource := NewInMemMailResource()
+	timesheetRepository := NewInMemTimesheetRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		UserRepository:      NewInMemUserRepository(),
+		TimesheetRepository: timesheetRepository,
+		MailResource:        mailResource,
+	}
+
+	principal := &Principal{
+		Name:           "Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	timesheet, err := a.SubmitTimesheet(context.Background(), principal, 2021, 45, "All done")
+	is.NoErr(err)
+
+	submittedTimesheets, err := a.ReadSubmittedTimesheets(context.Background(), principal)
+	is.NoErr(err)
+
+	timesheetApproved, err := a.ApproveTimesheet(context.Background(), principal, timesheet.ID, "")
+	is.NoErr(err)
+
+	_, errSubmitAgain := a.SubmitTimesheet(context.Background(), principal, 2021, 45, "")
+
+	// Assert
+	is.Equal(1, len(timesheetRepository.timesheets))
+	is.Equal(1, len(submittedTimesheets))
+	is.Equal(TimesheetStatusApproved, timesheetApproved.Status)
+	is.Equal(principal.Username, timesheetApproved.ReviewedBy)
+	is.True(errors.Is(errSubmitAgain, ErrTimesheetStatusInvalid))
+
+	// notification of the admin about the submit and the user about the approval
+	is.Equal(2, len(mailResource.mails))
+}
+
+func TestReadTimesheetWithoutSubmit(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	timesheet, err := a.ReadTimesheet(context.Background(), &Principal{Username: "user1"}, 2021, 45)
+
+	is.NoErr(err)
+	is.Equal(TimesheetStatusDraft, timesheet.Status)
+	is.Equal("user1", timesheet.Username)
+}
+
+func TestApprovedTimesheetLocksActivities(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	activity := activityRepository.activities[0]
+	activity.Start = time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	activity.End = time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusApproved
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		ActivityRepository:     activityRepository,
+		TimesheetRepository:    timesheetRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	activityUpdate := *activity
+	activityUpdate.Description = "Changed"
+
+	// Act
+	_, errUpdate := a.UpdateActivity(context.Background(), principal, &activityUpdate)
+	errDelete := a.DeleteActivityByID(context.Background(), principal, activity.ID)
+
+	timesheet.Status = TimesheetStatusRejected
+	_, errUpdateRejected := a.UpdateActivity(context.Background(), principal, &activityUpdate)
+
+	// Assert
+	is.True(errors.Is(errUpdate, ErrActivityLocked))
+	is.True(errors.Is(errDelete, ErrActivityLocked))
+	is.NoErr(errUpdateRejected)
+	is.Equal(1, len(activityRepository.activities))
+}
+
+func TestMoveActivityIntoApprovedTimesheet(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+	activity := activityRepository.activities[0]
+	activity.Start = time.Date(2021, 11, 17, 9, 0, 0, 0, time.UTC)
+	activity.End = time.Date(2021, 11, 17, 10, 0, 0, 0, time.UTC)
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusApproved
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	a := &app{
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		ActivityRepository:  activityRepository,
+		TimesheetRepository: timesheetRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	activityUpdate := *activity
+	activityUpdate.Start = time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	activityUpdate.End = time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC)
+
+	// Act
+	_, err := a.UpdateActivity(context.Background(), principal, &activityUpdate)
+
+	// Assert
+	is.True(errors.Is(err, ErrActivityLocked))
+}
+
+func TestCreateActivityInApprovedTimesheet(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	activityRepository := NewInMemActivityRepository()
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusApproved
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	a := &app{
+		Config:                 &config{},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		ActivityRepository:     activityRepository,
+		TimesheetRepository:    timesheetRepository,
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      NewInMemWebhookRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}
+
+	activity := &Activity{
+		ProjectID: projectIDSample,
+		Start:     time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC),
+		End:       time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC),
+	}
+
+	// Act
+	_, err := a.CreateActivity(context.Background(), principal, activity)
+
+	// Assert
+	is.True(errors.Is(err, ErrActivityLocked))
+	is.Equal(1, len(activityRepository.activities))
+}
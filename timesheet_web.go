@@ -0,0 +1,566 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type timesheetFormModel struct {
+	CSRFToken string
+	Week      string `validate:"required,max=8"`
+	Comment   string `validate:"max=500"`
+}
+
+type timesheetReviewFormModel struct {
+	CSRFToken string
+	Action    string `validate:"required,oneof=approve reject"`
+	Comment   string `validate:"max=500"`
+}
+
+type timesheetReviewParams struct {
+	errorMessage string
+	infoMessage  string
+}
+
+var timesheetStatusClasses = map[string]string{
+	TimesheetStatusDraft:     "bg-secondary",
+	TimesheetStatusSubmitted: "bg-info",
+	TimesheetStatusApproved:  "bg-success",
+	TimesheetStatusRejected:  "bg-danger",
+}
+
+func (a *app) HandleTimesheetPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		year, week := time.Now().In(principal.Location()).ISOWeek()
+		if r.URL.Query().Get("v") != "" {
+			var err error
+			year, week, err = ParseTimesheetWeek(r.URL.Query().Get("v"))
+			if err != nil {
+				http.Error(w, "Invalid week.", http.StatusBadRequest)
+				return
+			}
+		}
+
+		timesheet, err := a.ReadTimesheet(r.Context(), principal, year, week)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		reportItems, err := a.timesheetReportItems(r, principal, timesheet)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		formModel := timesheetFormModel{
+			CSRFToken: csrf.Token(r),
+			Week:      timesheet.WeekValue(),
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Timesheet",
+		}
+
+		util.RenderHTML(w, TimesheetPage(pageContext, formModel, timesheet, reportItems))
+	}
+}
+
+func (a *app) HandleTimesheetSubmitForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, "Invalid timesheet.", http.StatusBadRequest)
+			return
+		}
+
+		var formModel timesheetFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			http.Error(w, "Invalid timesheet.", http.StatusBadRequest)
+			return
+		}
+
+		year, week, err := ParseTimesheetWeek(formModel.Week)
+		if err != nil {
+			http.Error(w, "Invalid week.", http.StatusBadRequest)
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			a.renderTimesheetView(w, r, principal, isProduction, formModel, year, week, "The comment must not be longer than 500 characters.")
+			return
+		}
+
+		_, err = a.SubmitTimesheet(r.Context(), principal, year, week, formModel.Comment)
+		if errors.Is(err, ErrTimesheetStatusInvalid) {
+			a.renderTimesheetView(w, r, principal, isProduction, formModel, year, week, "The timesheet has already been submitted.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderTimesheetView(w, r, principal, isProduction, timesheetFormModel{Week: formModel.Week}, year, week, "")
+	}
+}
+
+func (a *app) HandleTimesheetApprovalsPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		timesheets, err := a.ReadSubmittedTimesheets(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Approvals",
+		}
+
+		util.RenderHTML(w, TimesheetApprovalsPage(pageContext, csrf.Token(r), timesheets))
+	}
+}
+
+func (a *app) HandleTimesheetReviewForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		timesheetID, err := uuid.Parse(chi.URLParam(r, "timesheet-id"))
+		if err != nil {
+			http.Error(w, "Invalid timesheet.", http.StatusBadRequest)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			http.Error(w, "Invalid timesheet review.", http.StatusBadRequest)
+			return
+		}
+
+		var formModel timesheetReviewFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			http.Error(w, "Invalid timesheet review.", http.StatusBadRequest)
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			http.Error(w, "Invalid timesheet review.", http.StatusBadRequest)
+			return
+		}
+
+		var timesheet *Timesheet
+		if formModel.Action == "approve" {
+			timesheet, err = a.ApproveTimesheet(r.Context(), principal, timesheetID, formModel.Comment)
+		} else {
+			timesheet, err = a.RejectTimesheet(r.Context(), principal, timesheetID, formModel.Comment)
+		}
+		if errors.Is(err, ErrTimesheetNotFound) {
+			http.Error(w, "Timesheet not found.", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrTimesheetStatusInvalid) {
+			timesheet, err = a.TimesheetRepository.FindTimesheetByID(r.Context(), principal.OrganizationID, timesheetID)
+			if err != nil {
+				util.RenderProblemHTML(w, isProduction, err)
+				return
+			}
+
+			util.RenderHTML(w, TimesheetReviewCard(csrf.Token(r), timesheet, &timesheetReviewParams{
+				errorMessage: "The timesheet has already been reviewed.",
+			}))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		util.RenderHTML(w, TimesheetReviewCard(csrf.Token(r), timesheet, &timesheetReviewParams{
+			infoMessage: fmt.Sprintf("Timesheet %v.", timesheet.Status),
+		}))
+	}
+}
+
+func (a *app) renderTimesheetView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel timesheetFormModel, year, week int, errorMessage string) {
+	timesheet, err := a.ReadTimesheet(r.Context(), principal, year, week)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	reportItems, err := a.timesheetReportItems(r, principal, timesheet)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	formModel.CSRFToken = csrf.Token(r)
+
+	util.RenderHTML(w, TimesheetView(formModel, timesheet, reportItems, errorMessage))
+}
+
+// timesheetReportItems reads the time per day of the principal in the week of the timesheet
+func (a *app) timesheetReportItems(r *http.Request, principal *Principal, timesheet *Timesheet) ([]*ActivityTimeReportItem, error) {
+	filter := &ActivityFilter{
+		Timespan: TimespanWeek,
+		start:    timesheet.Start(principal.Location()),
+	}
+	filter.usernames = []string{principal.Username}
+
+	return a.TimeReports(r.Context(), principal, filter, "day")
+}
+
+func timesheetReportHref(timesheet *Timesheet) string {
+	params := url.Values{}
+	params.Set("c", "time:d")
+	params.Set("t", TimespanWeek)
+	params.Set("v", timesheet.WeekValue())
+	params.Set("user", timesheet.Username)
+	return fmt.Sprintf("/reports?%v", params.Encode())
+}
+
+func TimesheetPage(pageContext *pageContext, formModel timesheetFormModel, timesheet *Timesheet, reportItems []*ActivityTimeReportItem) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Timesheet")),
+						P(
+							Class("text-muted"),
+							g.Text("Submit your timesheet at the end of the week for approval. "),
+							g.Text("The activities of approved weeks can't be changed anymore."),
+						),
+					),
+					Div(
+						ID("baralga__timesheet"),
+						TimesheetView(formModel, timesheet, reportItems, ""),
+					),
+				),
+			),
+		},
+	)
+}
+
+func TimesheetView(formModel timesheetFormModel, timesheet *Timesheet, reportItems []*ActivityTimeReportItem, errorMessage string) g.Node {
+	previousWeek := timesheet.Start(time.UTC).AddDate(0, 0, -7)
+	nextWeek := timesheet.Start(time.UTC).AddDate(0, 0, 7)
+
+	durationTotal := 0
+	for _, reportItem := range reportItems {
+		durationTotal += reportItem.DurationInMinutesTotal
+	}
+
+	var reviewed g.Node
+	if timesheet.ReviewedAt != nil {
+		reviewed = Small(
+			Class("text-muted ms-2"),
+			g.Textf("by %v on %v", timesheet.ReviewedBy, util.FormatDateDE(*timesheet.ReviewedAt)),
+		)
+	}
+
+	var comment g.Node
+	if timesheet.Comment != "" {
+		comment = P(
+			Class("fst-italic"),
+			g.Text(timesheet.Comment),
+		)
+	}
+
+	var submitForm g.Node
+	if timesheet.CanSubmit() {
+		submitForm = FormEl(
+			ID("timesheet_form"),
+			hx.Post("/timesheets/submit"),
+			hx.Target("#baralga__timesheet"),
+			hx.Swap("innerHTML"),
+
+			Input(
+				Type("hidden"),
+				Name("CSRFToken"),
+				Value(formModel.CSRFToken),
+			),
+			Input(
+				Type("hidden"),
+				Name("Week"),
+				Value(formModel.Week),
+			),
+			Div(
+				Class("input-group mb-3"),
+				Input(
+					ID("TimesheetComment"),
+					Type("text"),
+					Name("Comment"),
+					MaxLength("500"),
+					Value(formModel.Comment),
+					Class("form-control"),
+					g.Attr("placeholder", "Comment"),
+				),
+				Button(
+					Type("submit"),
+					Class("btn btn-primary"),
+					TitleAttr("Submit Timesheet"),
+					I(Class("bi-send me-2")),
+					g.Text("Submit"),
+				),
+			),
+		)
+	}
+
+	return Div(
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-warning"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+		Div(
+			Class("d-flex justify-content-between align-items-center mb-3"),
+			A(
+				Href(fmt.Sprintf("/timesheets?v=%v", timesheetWeekValue(previousWeek))),
+				Class("btn btn-outline-secondary btn-sm"),
+				TitleAttr("Previous Week"),
+				I(Class("bi-arrow-left")),
+			),
+			H4(
+				Class("mb-0"),
+				g.Text(timesheet.WeekFormatted()),
+				Span(
+					ID("timesheet-status"),
+					Class(fmt.Sprintf("badge %v ms-2", timesheetStatusClasses[timesheet.Status])),
+					g.Text(timesheet.Status),
+				),
+				reviewed,
+			),
+			A(
+				Href(fmt.Sprintf("/timesheets?v=%v", timesheetWeekValue(nextWeek))),
+				Class("btn btn-outline-secondary btn-sm"),
+				TitleAttr("Next Week"),
+				I(Class("bi-arrow-right")),
+			),
+		),
+		comment,
+		Table(
+			ID("timesheet-days"),
+			Class("table table-borderless table-striped"),
+			TBody(
+				g.Group(g.Map(len(reportItems), func(i int) g.Node {
+					reportItem := reportItems[i]
+					return Tr(
+						Td(g.Text(util.FormatDateDE(reportItem.AsTime()))),
+						Td(
+							Class("text-end"),
+							g.Text(reportItem.DurationFormatted()),
+						),
+					)
+				})),
+				Tr(
+					Th(g.Text("Total")),
+					Th(
+						Class("text-end"),
+						g.Text(FormatMinutesAsDuration(float64(durationTotal))),
+					),
+				),
+			),
+		),
+		submitForm,
+	)
+}
+
+func timesheetWeekValue(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%v-%v", year, week)
+}
+
+func TimesheetApprovalsPage(pageContext *pageContext, csrfToken string, timesheets []*Timesheet) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Approvals")),
+						P(
+							Class("text-muted"),
+							g.Text("Timesheets awaiting your approval. Approved weeks are locked, reject them to reopen them for changes."),
+						),
+					),
+					g.If(
+						len(timesheets) == 0,
+						P(g.Text("No timesheets awaiting approval.")),
+					),
+					g.Group(
+						g.Map(len(timesheets), func(i int) g.Node {
+							return TimesheetReviewCard(csrfToken, timesheets[i], &timesheetReviewParams{})
+						}),
+					),
+				),
+			),
+		},
+	)
+}
+
+func TimesheetReviewCard(csrfToken string, timesheet *Timesheet, params *timesheetReviewParams) g.Node {
+	var submitted g.Node
+	if timesheet.SubmittedAt != nil {
+		submitted = Small(
+			Class("text-muted ms-2"),
+			g.Textf("submitted on %v", util.FormatDateDE(*timesheet.SubmittedAt)),
+		)
+	}
+
+	var comment g.Node
+	if timesheet.Comment != "" {
+		comment = P(
+			Class("fst-italic"),
+			g.Text(timesheet.Comment),
+		)
+	}
+
+	var reviewForm g.Node
+	if timesheet.CanReject() {
+		reviewForm = FormEl(
+			hx.Post(fmt.Sprintf("/timesheets/%v/review", timesheet.ID)),
+			hx.Target("closest .card"),
+			hx.Swap("outerHTML"),
+
+			Input(
+				Type("hidden"),
+				Name("CSRFToken"),
+				Value(csrfToken),
+			),
+			Div(
+				Class("input-group"),
+				Input(
+					Type("text"),
+					Name("Comment"),
+					MaxLength("500"),
+					Class("form-control"),
+					g.Attr("placeholder", "Comment"),
+				),
+				g.If(
+					timesheet.CanApprove(),
+					Button(
+						Type("submit"),
+						Name("Action"),
+						Value("approve"),
+						Class("btn btn-outline-success"),
+						TitleAttr("Approve Timesheet"),
+						I(Class("bi-check-lg me-2")),
+						g.Text("Approve"),
+					),
+				),
+				Button(
+					Type("submit"),
+					Name("Action"),
+					Value("reject"),
+					Class("btn btn-outline-danger"),
+					TitleAttr("Reject Timesheet"),
+					I(Class("bi-x-lg me-2")),
+					g.Text("Reject"),
+				),
+			),
+		)
+	}
+
+	return Div(
+		Class("card mt-2"),
+		Div(
+			Class("card-body"),
+			g.If(
+				params.errorMessage != "",
+				Div(
+					Class("alert alert-warning"),
+					Role("alert"),
+					Span(g.Text(params.errorMessage)),
+				),
+			),
+			g.If(
+				params.infoMessage != "",
+				Div(
+					Class("alert alert-success"),
+					Role("alert"),
+					Span(g.Text(params.infoMessage)),
+				),
+			),
+			H5(
+				Class("card-title"),
+				g.Text(timesheet.Username),
+				Small(
+					Class("text-muted ms-2"),
+					g.Text(timesheet.WeekFormatted()),
+				),
+				Span(
+					Class(fmt.Sprintf("badge %v ms-2", timesheetStatusClasses[timesheet.Status])),
+					g.Text(timesheet.Status),
+				),
+				submitted,
+			),
+			comment,
+			P(
+				A(
+					Href(timesheetReportHref(timesheet)),
+					I(Class("bi-bar-chart me-2")),
+					g.Text("Show activities"),
+				),
+			),
+			reviewForm,
+		),
+	)
+}
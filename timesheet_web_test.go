@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/matryer/is"
+)
+
+func TestHandleTimesheetPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:              &config{},
+		ActivityRepository:  NewInMemActivityRepository(),
+		TimesheetRepository: NewInMemTimesheetRepository(),
+		ProjectRepository:   NewInMemProjectRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/timesheets?v=2021-45", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleTimesheetPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Week 45/2021"))
+	is.True(strings.Contains(htmlBody, "id=\"timesheet_form\""))
+	is.True(strings.Contains(htmlBody, "/timesheets?v=2021-44"))
+}
+
+func TestHandleTimesheetPageWithInvalidWeek(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:              &config{},
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/timesheets?v=2021-xx", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{}))
+
+	a.HandleTimesheetPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
+
+func TestHandleTimesheetSubmitForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		UserRepository:      NewInMemUserRepository(),
+		ActivityRepository:  NewInMemActivityRepository(),
+		TimesheetRepository: timesheetRepository,
+		MailResource:        NewInMemMailResource(),
+		ProjectRepository:   NewInMemProjectRepository(),
+	}
+
+	data := url.Values{}
+	data["Week"] = []string{"2021-45"}
+	data["Comment"] = []string{"All done"}
+
+	r, _ := http.NewRequest("POST", "/timesheets/submit", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "user1",
+		OrganizationID: organizationIDSample,
+	}))
+
+	a.HandleTimesheetSubmitForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(timesheetRepository.timesheets))
+	is.Equal(TimesheetStatusSubmitted, timesheetRepository.timesheets[0].Status)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "submitted"))
+	is.True(!strings.Contains(htmlBody, "id=\"timesheet_form\""))
+}
+
+func TestHandleTimesheetApprovalsPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusSubmitted
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	a := &app{
+		Config:              &config{},
+		TimesheetRepository: timesheetRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/timesheets/approvals", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleTimesheetApprovalsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Approvals # Baralga"))
+	is.True(strings.Contains(htmlBody, "Week 45/2021"))
+	is.True(strings.Contains(htmlBody, "user=user1"))
+}
+
+func TestHandleTimesheetApprovalsPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:              &config{},
+		TimesheetRepository: NewInMemTimesheetRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/timesheets/approvals", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Roles: []string{"ROLE_USER"},
+	}))
+
+	a.HandleTimesheetApprovalsPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleTimesheetReviewForm(t *testing.T) {
+	is := is.New(t)
+
+	timesheetRepository := NewInMemTimesheetRepository()
+	timesheet := NewTimesheet(organizationIDSample, "user1", 2021, 45)
+	timesheet.Status = TimesheetStatusSubmitted
+	timesheetRepository.timesheets = append(timesheetRepository.timesheets, timesheet)
+
+	mailResource := NewInMemMailResource()
+	userRepository := NewInMemUserRepository()
+	userRepository.users[0].Username = "user1"
+
+	a := &app{
+		Config:              &config{},
+		RepositoryTxer:      NewInMemRepositoryTxer(),
+		UserRepository:      userRepository,
+		TimesheetRepository: timesheetRepository,
+		MailResource:        mailResource,
+	}
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	review := func(action string) *httptest.ResponseRecorder {
+		data := url.Values{}
+		data["Action"] = []string{action}
+		data["Comment"] = []string{"Friday is missing"}
+
+		httpRec := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/timesheets/"+timesheet.ID.String()+"/review", strings.NewReader(data.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("timesheet-id", timesheet.ID.String())
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, principal))
+
+		a.HandleTimesheetReviewForm()(httpRec, r)
+		return httpRec
+	}
+
+	httpRec := review("reject")
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(TimesheetStatusRejected, timesheet.Status)
+	is.Equal("Friday is missing", timesheet.Comment)
+	is.True(strings.Contains(httpRec.Body.String(), "Timesheet rejected."))
+	is.Equal(1, len(mailResource.mails))
+
+	httpRec = review("approve")
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.True(strings.Contains(httpRec.Body.String(), "The timesheet has already been reviewed."))
+}
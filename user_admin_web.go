@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type userAdminFormModel struct {
+	CSRFToken string
+	Role      string
+	Enabled   bool
+	Password  string `validate:"omitempty,min=8,max=100"`
+}
+
+type userAdminParams struct {
+	errorMessage string
+	infoMessage  string
+}
+
+var roleTitles = map[string]string{
+	"ROLE_USER":  "User",
+	"ROLE_ADMIN": "Admin",
+}
+
+func (a *app) HandleUsersPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		users, err := a.ReadUsers(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Users",
+		}
+
+		util.RenderHTML(w, UsersPage(pageContext, csrf.Token(r), users))
+	}
+}
+
+func (a *app) HandleUserAdminForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDParam := chi.URLParam(r, "user-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		user, err := a.ReadUser(r.Context(), principal, userID)
+		if errors.Is(err, ErrUserNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, &userAdminParams{}))
+			return
+		}
+
+		var formModel userAdminFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, &userAdminParams{}))
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			userAdminParams := &userAdminParams{
+				errorMessage: "The password needs at least 8 characters.",
+			}
+			util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, userAdminParams))
+			return
+		}
+
+		if user.Username != principal.Username {
+			if formModel.Role != "" && formModel.Role != strings.Join(user.Roles, ",") {
+				user, err = a.UpdateUserRoles(r.Context(), principal, userID, []string{formModel.Role})
+			}
+			if err == nil && formModel.Enabled != user.Enabled {
+				user, err = a.UpdateUserEnabled(r.Context(), principal, userID, formModel.Enabled)
+			}
+		}
+		if err == nil && formModel.Password != "" {
+			err = a.ResetUserPassword(r.Context(), principal, userID, formModel.Password)
+		}
+		if errors.Is(err, ErrRoleInvalid) {
+			userAdminParams := &userAdminParams{
+				errorMessage: "Please select a valid role.",
+			}
+			util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, userAdminParams))
+			return
+		}
+		if errors.Is(err, ErrGuestUserNotChangeable) {
+			userAdminParams := &userAdminParams{
+				errorMessage: "The user registered with another organization, only that organization can disable the user or reset the password.",
+			}
+			util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, userAdminParams))
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		userAdminParams := &userAdminParams{
+			infoMessage: "User saved.",
+		}
+		util.RenderHTML(w, UserAdminCard(principal, csrf.Token(r), user, userAdminParams))
+	}
+}
+
+func UsersPage(pageContext *pageContext, csrfToken string, users []*User) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Users")),
+					),
+					g.Group(
+						g.Map(len(users), func(i int) g.Node {
+							return UserAdminCard(pageContext.principal, csrfToken, users[i], &userAdminParams{})
+						}),
+					),
+				),
+			),
+		},
+	)
+}
+
+func UserAdminCard(principal *Principal, csrfToken string, user *User, userAdminParams *userAdminParams) g.Node {
+	isOwnUser := user.Username == principal.Username
+	return Div(
+		Class("card mt-2"),
+		Div(
+			Class("card-body"),
+			H5(
+				Class("card-title"),
+				g.Text(user.Name),
+				Small(
+					Class("text-muted ms-2"),
+					g.Text(user.Username),
+				),
+				g.If(
+					!user.Enabled,
+					Span(
+						Class("badge bg-secondary ms-2"),
+						g.Text("disabled"),
+					),
+				),
+			),
+			FormEl(
+				hx.Post(fmt.Sprintf("/users/%v", user.ID)),
+				hx.Target("closest .card"),
+				hx.Swap("outerHTML"),
+
+				g.If(
+					userAdminParams.errorMessage != "",
+					Div(
+						Class("alert alert-warning"),
+						Role("alert"),
+						Span(g.Text(userAdminParams.errorMessage)),
+					),
+				),
+				g.If(
+					userAdminParams.infoMessage != "",
+					Div(
+						Class("alert alert-success"),
+						Role("alert"),
+						Span(g.Text(userAdminParams.infoMessage)),
+					),
+				),
+				Input(
+					Type("hidden"),
+					Name("CSRFToken"),
+					Value(csrfToken),
+				),
+				Div(
+					Class("row g-2 align-items-center"),
+					Div(
+						Class("col-md-3"),
+						Select(
+							Name("Role"),
+							Class("form-select"),
+							TitleAttr("Role"),
+							g.If(isOwnUser, Disabled()),
+							g.Group(
+								g.Map(len(Roles), func(i int) g.Node {
+									role := Roles[i]
+									return Option(
+										Value(role),
+										g.If(len(user.Roles) > 0 && user.Roles[0] == role, Selected()),
+										g.Text(roleTitles[role]),
+									)
+								}),
+							),
+						),
+					),
+					Div(
+						Class("col-md-2"),
+						Div(
+							Class("form-check form-switch"),
+							Input(
+								ID(fmt.Sprintf("enabled_%v", user.ID)),
+								Type("checkbox"),
+								Name("Enabled"),
+								Value("true"),
+								Class("form-check-input"),
+								g.If(user.Enabled, g.Attr("checked", "checked")),
+								g.If(isOwnUser, Disabled()),
+							),
+							Label(
+								Class("form-check-label"),
+								g.Attr("for", fmt.Sprintf("enabled_%v", user.ID)),
+								g.Text("Enabled"),
+							),
+						),
+					),
+					Div(
+						Class("col-md-5"),
+						Input(
+							Type("password"),
+							Name("Password"),
+							MinLength("8"),
+							MaxLength("100"),
+							Class("form-control"),
+							g.Attr("placeholder", "New password"),
+							g.Attr("autocomplete", "new-password"),
+						),
+					),
+					Div(
+						Class("col-md-2 text-end"),
+						Button(
+							Type("submit"),
+							Class("btn btn-outline-primary"),
+							I(Class("bi-save me-2")),
+							g.Text("Save"),
+						),
+					),
+				),
+			),
+		),
+	)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleUsersPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Name:           "Ado Admin",
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleUsersPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Users # Baralga"))
+	is.True(strings.Contains(htmlBody, "admin@baralga.com"))
+}
+
+func TestHandleUsersPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/users", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1@baralga.com",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	a.HandleUsersPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleUserAdminForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	userID := uuid.New()
+	userRepository.users = append(userRepository.users, &User{
+		ID:             userID,
+		Name:           "Ulani User",
+		Username:       "user1@baralga.com",
+		OrganizationID: organizationIDSample,
+		Enabled:        true,
+	})
+	userRepository.roles[userID] = []string{"ROLE_USER"}
+
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     userRepository,
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	data := url.Values{}
+	data["Role"] = []string{"ROLE_ADMIN"}
+
+	r, _ := http.NewRequest("POST", "/users/"+userID.String(), strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user-id", userID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUserAdminForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal([]string{"ROLE_ADMIN"}, userRepository.roles[userID])
+	is.True(!userRepository.users[1].Enabled)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "User saved."))
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/baralga/hal"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"schneider.vip/problem"
+)
+
+type userModel struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Username string     `json:"username"`
+	EMail    string     `json:"email"`
+	Enabled  *bool      `json:"enabled"`
+	Roles    []string   `json:"roles"`
+	Links    *hal.Links `json:"_links"`
+}
+
+type EmbeddedUsers struct {
+	UserModels []*userModel `json:"users"`
+}
+
+type usersModel struct {
+	*EmbeddedUsers `json:"_embedded"`
+	Links          *hal.Links `json:"_links"`
+}
+
+type passwordModel struct {
+	Password string `json:"password" validate:"required,min=8,max=100"`
+}
+
+// HandleGetUsers reads the users of the organization
+func (a *app) HandleGetUsers() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		users, err := a.ReadUsers(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		userModels := make([]*userModel, len(users))
+		for i, user := range users {
+			userModels[i] = mapToUserModel(user)
+		}
+
+		usersModel := &usersModel{
+			EmbeddedUsers: &EmbeddedUsers{
+				UserModels: userModels,
+			},
+			Links: hal.NewLinks(
+				hal.NewSelfLink(r.RequestURI),
+			),
+		}
+
+		util.RenderJSON(w, usersModel)
+	}
+}
+
+// HandleGetUser reads a user of the organization
+func (a *app) HandleGetUser() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDParam := chi.URLParam(r, "user-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		user, err := a.ReadUser(r.Context(), principal, userID)
+		if errors.Is(err, ErrUserNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		util.RenderJSON(w, mapToUserModel(user))
+	}
+}
+
+// HandleUpdateUser updates roles and enabled state of a user
+func (a *app) HandleUpdateUser() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDParam := chi.URLParam(r, "user-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		var userModel userModel
+		err = json.NewDecoder(r.Body).Decode(&userModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		user, err := a.ReadUser(r.Context(), principal, userID)
+		if userModel.Roles != nil && err == nil {
+			user, err = a.UpdateUserRoles(r.Context(), principal, userID, userModel.Roles)
+		}
+		if userModel.Enabled != nil && err == nil {
+			user, err = a.UpdateUserEnabled(r.Context(), principal, userID, *userModel.Enabled)
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrRoleInvalid) {
+			http.Error(w, problem.New(problem.Title("role not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, ErrOwnUserNotChangeable) {
+			http.Error(w, problem.New(problem.Title("own user can not be changed")).JSONString(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrGuestUserNotChangeable) {
+			http.Error(w, problem.New(problem.Title("user of other organization can not be changed")).JSONString(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		util.RenderJSON(w, mapToUserModel(user))
+	}
+}
+
+// HandleResetUserPassword sets a new password for a user
+func (a *app) HandleResetUserPassword() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDParam := chi.URLParam(r, "user-id")
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusNotAcceptable)
+			return
+		}
+
+		var passwordModel passwordModel
+		err = json.NewDecoder(r.Body).Decode(&passwordModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Wrap(err)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		err = validator.Struct(passwordModel)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("password not valid")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		err = a.ResetUserPassword(r.Context(), principal, userID, passwordModel.Password)
+		if errors.Is(err, ErrUserNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrGuestUserNotChangeable) {
+			http.Error(w, problem.New(problem.Title("user of other organization can not be changed")).JSONString(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			util.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func mapToUserModel(user *User) *userModel {
+	enabled := user.Enabled
+	selfLink := hal.NewSelfLink(fmt.Sprintf("/api/users/%s", user.ID))
+	return &userModel{
+		ID:       user.ID.String(),
+		Name:     user.Name,
+		Username: user.Username,
+		EMail:    user.EMail,
+		Enabled:  &enabled,
+		Roles:    user.Roles,
+		Links: hal.NewLinks(
+			selfLink,
+			hal.NewLink("edit", selfLink.Href()),
+			hal.NewLink("password", fmt.Sprintf("%s/password", selfLink.Href())),
+		),
+	}
+}
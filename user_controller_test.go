@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHandleGetUsers(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/users", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleGetUsers()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	usersModel := &usersModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(usersModel)
+	is.NoErr(err)
+	is.Equal(1, len(usersModel.UserModels))
+	is.Equal("admin@baralga.com", usersModel.UserModels[0].Username)
+	is.Equal([]string{"ROLE_ADMIN"}, usersModel.UserModels[0].Roles)
+}
+
+func TestHandleGetUsersAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:         &config{},
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/api/users", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username: "user1@baralga.com",
+		Roles:    []string{"ROLE_USER"},
+	}))
+
+	a.HandleGetUsers()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleUpdateUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	userID := uuid.New()
+	userRepository.users = append(userRepository.users, &User{
+		ID:             userID,
+		Username:       "user1@baralga.com",
+		OrganizationID: organizationIDSample,
+		Enabled:        true,
+	})
+
+	a := &app{
+		Config:             &config{},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     userRepository,
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	r, _ := http.NewRequest("PATCH", "/api/users/"+userID.String(), strings.NewReader(`{"enabled": false, "roles": ["ROLE_USER"]}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user-id", userID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateUser()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	userModel := &userModel{}
+	err := json.NewDecoder(httpRec.Body).Decode(userModel)
+	is.NoErr(err)
+	is.True(!*userModel.Enabled)
+	is.Equal([]string{"ROLE_USER"}, userModel.Roles)
+}
+
+func TestHandleUpdateOwnUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	userID := userRepository.users[0].ID
+
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	r, _ := http.NewRequest("PATCH", "/api/users/"+userID.String(), strings.NewReader(`{"enabled": false}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user-id", userID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleUpdateUser()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusConflict)
+}
+
+func TestHandleResetUserPassword(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	userRepository := NewInMemUserRepository()
+	userID := userRepository.users[0].ID
+
+	a := &app{
+		Config:         &config{},
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	r, _ := http.NewRequest("POST", "/api/users/"+userID.String()+"/password", strings.NewReader(`{"password": "myNewPassword"}`))
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("user-id", userID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleResetUserPassword()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusNoContent)
+	is.NoErr(bcrypt.CompareHashAndPassword([]byte(userRepository.users[0].Password), []byte("myNewPassword")))
+}
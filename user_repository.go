@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/baralga/util"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -11,6 +13,7 @@ import (
 )
 
 var ErrUserNotFound = errors.New("user not found")
+var ErrInvitationNotFound = errors.New("invitation not found")
 
 type User struct {
 	ID             uuid.UUID
@@ -19,12 +22,60 @@ type User struct {
 	EMail          string
 	Password       string
 	Origin         string
+	Timezone       string
+	CalendarURL    string
+	Enabled        bool
+	Roles          []string
 	OrganizationID uuid.UUID
 }
 
+// HasRole checks if the user has the given role
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 type Organization struct {
 	ID    uuid.UUID
 	Title string
+
+	// LockedUntil is the last day of the closed period of the organization
+	LockedUntil *time.Time
+}
+
+// IsLockedAt checks whether the time lies within the closed period of the organization
+func (o *Organization) IsLockedAt(t time.Time, loc *time.Location) bool {
+	if o.LockedUntil == nil {
+		return false
+	}
+
+	lockEnd := util.WallClockIn(o.LockedUntil.AddDate(0, 0, 1), loc)
+	return t.Before(lockEnd)
+}
+
+// OrganizationMember is the membership of a user in an organization with its role there
+type OrganizationMember struct {
+	OrganizationID uuid.UUID
+	UserID         uuid.UUID
+	Role           string
+}
+
+// Invitation is a pending invitation of a user into an organization
+type Invitation struct {
+	ID             uuid.UUID
+	EMail          string
+	OrganizationID uuid.UUID
+	CreatedBy      string
+	CreatedAt      time.Time
+}
+
+// IsExpiredAt checks whether the invitation is expired at the given time
+func (i *Invitation) IsExpiredAt(t time.Time) bool {
+	return i.CreatedAt.Add(invitationExpiryDuration).Before(t)
 }
 
 type UserRepository interface {
@@ -32,7 +83,21 @@ type UserRepository interface {
 	FindUserIDByConfirmationID(ctx context.Context, confirmationID string) (uuid.UUID, error)
 	InsertUserWithConfirmationID(ctx context.Context, user *User, confirmationID uuid.UUID) (*User, error)
 	FindUserByUsername(ctx context.Context, username string) (*User, error)
+	FindUserByUsernameIncludingDisabled(ctx context.Context, username string) (*User, error)
 	FindRolesByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]string, error)
+	UpdateUserTimezone(ctx context.Context, organizationID uuid.UUID, username, timezone string) error
+	UpdateUserCalendarURL(ctx context.Context, organizationID uuid.UUID, username, calendarURL string) error
+	InsertUserWithRole(ctx context.Context, user *User, role string) (*User, error)
+	FindUsers(ctx context.Context, organizationID uuid.UUID) ([]*User, error)
+	FindUserByID(ctx context.Context, organizationID, userID uuid.UUID) (*User, error)
+	UpdateUserRoles(ctx context.Context, organizationID, userID uuid.UUID, roles []string) error
+	UpdateUserEnabled(ctx context.Context, organizationID, userID uuid.UUID, enabled bool) error
+	UpdateUserPassword(ctx context.Context, organizationID, userID uuid.UUID, password string) error
+	InsertInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error)
+	FindInvitationByID(ctx context.Context, invitationID uuid.UUID) (*Invitation, error)
+	FindInvitations(ctx context.Context, organizationID uuid.UUID) ([]*Invitation, error)
+	DeleteInvitationByID(ctx context.Context, organizationID, invitationID uuid.UUID) error
+	DeleteInvitationByEMail(ctx context.Context, organizationID uuid.UUID, email string) error
 }
 
 // DbUserRepository is a SQL database repository for users
@@ -71,12 +136,42 @@ func (r *DbUserRepository) InsertUserWithConfirmationID(ctx context.Context, use
 		enabled = 1
 	}
 
+	err := r.insertUser(ctx, tx, user, enabled, "ROLE_ADMIN")
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmationID == uuid.Nil {
+		return user, nil
+	}
+
+	_, err = r.insertConfirmation(ctx, tx, user, confirmationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// InsertUserWithRole inserts an enabled user with the given role
+func (r *DbUserRepository) InsertUserWithRole(ctx context.Context, user *User, role string) (*User, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	err := r.insertUser(ctx, tx, user, 1, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *DbUserRepository) insertUser(ctx context.Context, tx pgx.Tx, user *User, enabled int, role string) error {
 	_, err := tx.Exec(
 		ctx,
 		`INSERT INTO users 
-		   (user_id, username, email, name, password, enabled, org_id, origin) 
+		   (user_id, username, email, name, password, enabled, org_id, origin, timezone) 
 		 VALUES 
-		   ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
 		user.ID,
 		user.Username,
 		user.EMail,
@@ -85,34 +180,36 @@ func (r *DbUserRepository) InsertUserWithConfirmationID(ctx context.Context, use
 		enabled,
 		user.OrganizationID,
 		user.Origin,
+		timezoneOrDefault(user.Timezone),
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	_, err = tx.Exec(
 		ctx,
-		`INSERT INTO roles 
-		   (user_id, role, org_id) 
+		`INSERT INTO organization_members 
+		   (user_id, org_id) 
 		 VALUES 
-		   ($1, 'ROLE_ADMIN', $2)`,
+		   ($1, $2)`,
 		user.ID,
 		user.OrganizationID,
 	)
 	if err != nil {
-		return nil, err
-	}
-
-	if confirmationID == uuid.Nil {
-		return user, nil
-	}
-
-	_, err = r.insertConfirmation(ctx, tx, user, confirmationID)
-	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return user, nil
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO roles 
+		   (user_id, role, org_id) 
+		 VALUES 
+		   ($1, $2, $3)`,
+		user.ID,
+		role,
+		user.OrganizationID,
+	)
+	return err
 }
 
 func (r *DbUserRepository) FindUserIDByConfirmationID(ctx context.Context, confirmationID string) (uuid.UUID, error) {
@@ -169,7 +266,7 @@ func (r *DbUserRepository) ConfirmUser(ctx context.Context, userID uuid.UUID) er
 func (r *DbUserRepository) FindUserByUsername(ctx context.Context, username string) (*User, error) {
 	row := r.connPool.QueryRow(
 		ctx,
-		`SELECT user_id, name, password, org_id 
+		`SELECT user_id, name, password, org_id, timezone, calendar_url 
 		 FROM users 
 		 WHERE username = $1 AND enabled = 1`, username,
 	)
@@ -179,9 +276,11 @@ func (r *DbUserRepository) FindUserByUsername(ctx context.Context, username stri
 		name           string
 		password       string
 		organizationID string
+		timezone       *string
+		calendarURL    sql.NullString
 	)
 
-	err := row.Scan(&id, &name, &password, &organizationID)
+	err := row.Scan(&id, &name, &password, &organizationID, &timezone, &calendarURL)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -195,17 +294,245 @@ func (r *DbUserRepository) FindUserByUsername(ctx context.Context, username stri
 		Name:           name,
 		Username:       username,
 		Password:       password,
+		CalendarURL:    calendarURL.String,
 		OrganizationID: uuid.MustParse(organizationID),
 	}
+	if timezone != nil {
+		user.Timezone = *timezone
+	}
 	return user, nil
 }
 
+// FindUserByUsernameIncludingDisabled finds the user regardless of whether it's enabled,
+// unlike FindUserByUsername which only finds the users who may sign in
+func (r *DbUserRepository) FindUserByUsernameIncludingDisabled(ctx context.Context, username string) (*User, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT user_id, name, password, org_id, enabled 
+		 FROM users 
+		 WHERE username = $1`, username,
+	)
+
+	var (
+		id             string
+		name           string
+		password       string
+		organizationID string
+		enabled        int
+	)
+
+	err := row.Scan(&id, &name, &password, &organizationID, &enabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return &User{
+		ID:             uuid.MustParse(id),
+		Name:           name,
+		Username:       username,
+		Password:       password,
+		Enabled:        enabled == 1,
+		OrganizationID: uuid.MustParse(organizationID),
+	}, nil
+}
+
+func (r *DbUserRepository) FindUsers(ctx context.Context, organizationID uuid.UUID) ([]*User, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT u.user_id, u.name, u.username, u.email, u.enabled, m.org_id, u.timezone 
+		 FROM users u 
+		 INNER JOIN organization_members m ON m.user_id = u.user_id 
+		 WHERE m.org_id = $1 
+		 ORDER BY u.name ASC`, organizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *DbUserRepository) FindUserByID(ctx context.Context, organizationID, userID uuid.UUID) (*User, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT u.user_id, u.name, u.username, u.email, u.enabled, m.org_id, u.timezone 
+		 FROM users u 
+		 INNER JOIN organization_members m ON m.user_id = u.user_id 
+		 WHERE m.org_id = $1 AND u.user_id = $2`, organizationID, userID,
+	)
+
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func scanUser(row pgx.Row) (*User, error) {
+	var (
+		id             string
+		name           sql.NullString
+		username       string
+		email          sql.NullString
+		enabled        int
+		organizationID string
+		timezone       sql.NullString
+	)
+
+	err := row.Scan(&id, &name, &username, &email, &enabled, &organizationID, &timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:             uuid.MustParse(id),
+		Name:           name.String,
+		Username:       username,
+		EMail:          email.String,
+		Enabled:        enabled == 1,
+		Timezone:       timezone.String,
+		OrganizationID: uuid.MustParse(organizationID),
+	}, nil
+}
+
+func (r *DbUserRepository) UpdateUserRoles(ctx context.Context, organizationID, userID uuid.UUID, roles []string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`SELECT user_id 
+		 FROM organization_members 
+		 WHERE user_id = $1 AND org_id = $2`,
+		userID,
+		organizationID,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`DELETE FROM roles 
+		 WHERE user_id = $1 AND org_id = $2`,
+		userID,
+		organizationID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO roles 
+			   (user_id, role, org_id) 
+			 VALUES 
+			   ($1, $2, $3)`,
+			userID,
+			role,
+			organizationID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *DbUserRepository) UpdateUserEnabled(ctx context.Context, organizationID, userID uuid.UUID, enabled bool) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	enabledValue := 0
+	if enabled {
+		enabledValue = 1
+	}
+
+	row := tx.QueryRow(
+		ctx,
+		`UPDATE users
+		 SET enabled = $3 
+		 WHERE user_id = $1 AND org_id = $2
+		 RETURNING user_id`,
+		userID,
+		organizationID,
+		enabledValue,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *DbUserRepository) UpdateUserPassword(ctx context.Context, organizationID, userID uuid.UUID, password string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`UPDATE users
+		 SET password = $3 
+		 WHERE user_id = $1 AND org_id = $2
+		 RETURNING user_id`,
+		userID,
+		organizationID,
+		password,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 func (r *DbUserRepository) FindRolesByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]string, error) {
 	rows, err := r.connPool.Query(
 		ctx,
-		`SELECT role 
-		 FROM roles 
-		 WHERE user_id = $1 AND org_id = $2`, userID, organizationID,
+		`SELECT r.role 
+		 FROM roles r
+		 JOIN organization_members m ON m.user_id = r.user_id AND m.org_id = r.org_id
+		 WHERE r.user_id = $1 AND r.org_id = $2`, userID, organizationID,
 	)
 	if err != nil {
 		return nil, err
@@ -226,3 +553,149 @@ func (r *DbUserRepository) FindRolesByUserID(ctx context.Context, organizationID
 
 	return roles, nil
 }
+
+func (r *DbUserRepository) UpdateUserTimezone(ctx context.Context, organizationID uuid.UUID, username, timezone string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE users
+		 SET timezone = $3 
+		 WHERE username = $1 AND user_id IN (
+		   SELECT user_id FROM organization_members WHERE org_id = $2)`,
+		username,
+		organizationID,
+		timezone,
+	)
+	return err
+}
+
+func (r *DbUserRepository) UpdateUserCalendarURL(ctx context.Context, organizationID uuid.UUID, username, calendarURL string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE users
+		 SET calendar_url = $3 
+		 WHERE username = $1 AND user_id IN (
+		   SELECT user_id FROM organization_members WHERE org_id = $2)`,
+		username,
+		organizationID,
+		sql.NullString{String: calendarURL, Valid: calendarURL != ""},
+	)
+	return err
+}
+
+func timezoneOrDefault(timezone string) string {
+	if timezone == "" {
+		return "UTC"
+	}
+	return timezone
+}
+
+func (r *DbUserRepository) InsertInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO invitations 
+		   (invitation_id, email, org_id, created_by, created_at) 
+		 VALUES 
+		   ($1, $2, $3, $4, $5)`,
+		invitation.ID,
+		invitation.EMail,
+		invitation.OrganizationID,
+		invitation.CreatedBy,
+		invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (r *DbUserRepository) FindInvitationByID(ctx context.Context, invitationID uuid.UUID) (*Invitation, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT invitation_id, email, org_id, created_by, created_at 
+		 FROM invitations 
+		 WHERE invitation_id = $1`, invitationID,
+	)
+
+	invitation := &Invitation{}
+	err := row.Scan(&invitation.ID, &invitation.EMail, &invitation.OrganizationID, &invitation.CreatedBy, &invitation.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (r *DbUserRepository) FindInvitations(ctx context.Context, organizationID uuid.UUID) ([]*Invitation, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT invitation_id, email, org_id, created_by, created_at 
+		 FROM invitations 
+		 WHERE org_id = $1 
+		 ORDER BY created_at DESC`, organizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*Invitation
+	for rows.Next() {
+		invitation := &Invitation{}
+
+		err = rows.Scan(&invitation.ID, &invitation.EMail, &invitation.OrganizationID, &invitation.CreatedBy, &invitation.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+func (r *DbUserRepository) DeleteInvitationByID(ctx context.Context, organizationID, invitationID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(ctx,
+		`DELETE 
+         FROM invitations 
+	     WHERE invitation_id = $1 AND org_id = $2
+		 RETURNING invitation_id`,
+		invitationID, organizationID)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvitationNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *DbUserRepository) DeleteInvitationByEMail(ctx context.Context, organizationID uuid.UUID, email string) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`DELETE FROM invitations 
+		 WHERE org_id = $1 AND email = $2`,
+		organizationID,
+		email,
+	)
+	return err
+}
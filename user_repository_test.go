@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/matryer/is"
@@ -149,10 +150,258 @@ func TestUserRepository(t *testing.T) {
 		)
 		is.True(errors.Is(err, ErrUserNotFound))
 	})
+
+	t.Run("UpdateUserTimezone", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserTimezone(
+					ctx,
+					organizationIDSample,
+					"admin@baralga.com",
+					"Europe/Berlin",
+				)
+			},
+		)
+		is.NoErr(err)
+
+		adminUser, err := userRepository.FindUserByUsername(
+			context.Background(),
+			"admin@baralga.com",
+		)
+		is.NoErr(err)
+		is.Equal(adminUser.Timezone, "Europe/Berlin")
+	})
+
+	t.Run("UpdateUserCalendarURL", func(t *testing.T) {
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserCalendarURL(
+					ctx,
+					organizationIDSample,
+					"admin@baralga.com",
+					"https://calendar.example.com/admin.ics",
+				)
+			},
+		)
+		is.NoErr(err)
+
+		adminUser, err := userRepository.FindUserByUsername(
+			context.Background(),
+			"admin@baralga.com",
+		)
+		is.NoErr(err)
+		is.Equal(adminUser.CalendarURL, "https://calendar.example.com/admin.ics")
+	})
+
+	t.Run("InsertUserWithRole", func(t *testing.T) {
+		user := &User{
+			ID:             uuid.New(),
+			Name:           "Ivy Invited",
+			Username:       "ivy.invited@baralga.com",
+			EMail:          "ivy.invited@baralga.com",
+			OrganizationID: organizationIDSample,
+			Origin:         "baralga",
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := userRepository.InsertUserWithRole(ctx, user, "ROLE_USER")
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		roles, err := userRepository.FindRolesByUserID(
+			context.Background(),
+			organizationIDSample,
+			user.ID,
+		)
+		is.NoErr(err)
+		is.Equal(len(roles), 1)
+		is.Equal(roles[0], "ROLE_USER")
+	})
+
+	t.Run("InsertAndFindAndDeleteInvitation", func(t *testing.T) {
+		invitation := &Invitation{
+			ID:             uuid.New(),
+			EMail:          "ina.invitee@baralga.com",
+			OrganizationID: organizationIDSample,
+			CreatedBy:      "admin@baralga.com",
+			CreatedAt:      time.Now(),
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := userRepository.InsertInvitation(ctx, invitation)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		invitationFound, err := userRepository.FindInvitationByID(context.Background(), invitation.ID)
+		is.NoErr(err)
+		is.Equal(invitationFound.EMail, invitation.EMail)
+
+		invitations, err := userRepository.FindInvitations(context.Background(), organizationIDSample)
+		is.NoErr(err)
+		is.Equal(len(invitations), 1)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.DeleteInvitationByID(ctx, organizationIDSample, invitation.ID)
+			},
+		)
+		is.NoErr(err)
+
+		_, err = userRepository.FindInvitationByID(context.Background(), invitation.ID)
+		is.True(errors.Is(err, ErrInvitationNotFound))
+	})
+
+	t.Run("FindUsers", func(t *testing.T) {
+		users, err := userRepository.FindUsers(context.Background(), organizationIDSample)
+
+		is.NoErr(err)
+		is.True(len(users) >= 3)
+	})
+
+	t.Run("FindUserByID", func(t *testing.T) {
+		user, err := userRepository.FindUserByID(context.Background(), organizationIDSample, userIDAdminSample)
+
+		is.NoErr(err)
+		is.Equal(user.Username, "admin@baralga.com")
+		is.True(user.Enabled)
+
+		_, err = userRepository.FindUserByID(context.Background(), organizationIDSample, uuid.New())
+		is.True(errors.Is(err, ErrUserNotFound))
+	})
+
+	t.Run("UpdateUserRolesAndEnabledAndPassword", func(t *testing.T) {
+		user := &User{
+			ID:             uuid.New(),
+			Name:           "Udo Updated",
+			Username:       "udo.updated@baralga.com",
+			EMail:          "udo.updated@baralga.com",
+			OrganizationID: organizationIDSample,
+			Origin:         "baralga",
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := userRepository.InsertUserWithRole(ctx, user, "ROLE_USER")
+				return err
+			},
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserRoles(ctx, organizationIDSample, user.ID, []string{"ROLE_ADMIN"})
+			},
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserEnabled(ctx, organizationIDSample, user.ID, false)
+			},
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserPassword(ctx, organizationIDSample, user.ID, "-new-")
+			},
+		)
+		is.NoErr(err)
+
+		roles, err := userRepository.FindRolesByUserID(context.Background(), organizationIDSample, user.ID)
+		is.NoErr(err)
+		is.Equal(roles, []string{"ROLE_ADMIN"})
+
+		_, err = userRepository.FindUserByUsername(context.Background(), user.Username)
+		is.True(errors.Is(err, ErrUserNotFound))
+
+		userDisabled, err := userRepository.FindUserByUsernameIncludingDisabled(context.Background(), user.Username)
+		is.NoErr(err)
+		is.Equal(user.ID, userDisabled.ID)
+		is.True(!userDisabled.Enabled)
+	})
+
+	t.Run("UpdateUserOfOtherHomeOrganization", func(t *testing.T) {
+		organizationRepository := NewDbOrganizationRepository(connPool)
+		user := &User{
+			ID:             uuid.New(),
+			Name:           "Olga Other",
+			Username:       "olga.other@baralga.com",
+			EMail:          "olga.other@baralga.com",
+			OrganizationID: organizationIDSample,
+			Origin:         "baralga",
+		}
+		organization := &Organization{
+			ID:    uuid.New(),
+			Title: "My Joined Organization",
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := userRepository.InsertUserWithRole(ctx, user, "ROLE_USER")
+				if err != nil {
+					return err
+				}
+				_, err = organizationRepository.InsertOrganization(ctx, organization)
+				if err != nil {
+					return err
+				}
+				return organizationRepository.InsertOrganizationMember(ctx, &OrganizationMember{
+					OrganizationID: organization.ID,
+					UserID:         user.ID,
+					Role:           "ROLE_USER",
+				})
+			},
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserRoles(ctx, organization.ID, user.ID, []string{"ROLE_ADMIN"})
+			},
+		)
+		is.NoErr(err)
+
+		roles, err := userRepository.FindRolesByUserID(context.Background(), organization.ID, user.ID)
+		is.NoErr(err)
+		is.Equal(roles, []string{"ROLE_ADMIN"})
+
+		// enabled and password are shared by all organizations, so only the home organization changes them
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserEnabled(ctx, organization.ID, user.ID, false)
+			},
+		)
+		is.True(errors.Is(err, ErrUserNotFound))
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserPassword(ctx, organization.ID, user.ID, "-new-")
+			},
+		)
+		is.True(errors.Is(err, ErrUserNotFound))
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserEnabled(ctx, organizationIDSample, user.ID, false)
+			},
+		)
+		is.NoErr(err)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return userRepository.UpdateUserEnabled(ctx, uuid.New(), user.ID, true)
+			},
+		)
+		is.True(errors.Is(err, ErrUserNotFound))
+	})
 }
 
 type InMemUserRepository struct {
-	users []*User
+	users       []*User
+	roles       map[uuid.UUID][]string
+	invitations []*Invitation
 }
 
 var _ UserRepository = (*InMemUserRepository)(nil)
@@ -165,13 +414,25 @@ func NewInMemUserRepository() *InMemUserRepository {
 				Username:       "admin@baralga.com",
 				EMail:          "admin@baralga.com",
 				Password:       "$2a$10$NuzYobDOSTCx/EKBClGwGe0A9c8/yC7D4IP75hwz1jn.RCBfdEtb2",
+				Enabled:        true,
 				OrganizationID: organizationIDSample,
 			},
 		},
+		roles:       make(map[uuid.UUID][]string),
+		invitations: []*Invitation{},
 	}
 }
 
 func (r *InMemUserRepository) FindUserByUsername(ctx context.Context, username string) (*User, error) {
+	for _, a := range r.users {
+		if a.Username == username && a.Enabled {
+			return a, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *InMemUserRepository) FindUserByUsernameIncludingDisabled(ctx context.Context, username string) (*User, error) {
 	for _, a := range r.users {
 		if a.Username == username {
 			return a, nil
@@ -181,6 +442,9 @@ func (r *InMemUserRepository) FindUserByUsername(ctx context.Context, username s
 }
 
 func (r *InMemUserRepository) FindRolesByUserID(ctx context.Context, organizationID, userID uuid.UUID) ([]string, error) {
+	if roles, ok := r.roles[userID]; ok {
+		return roles, nil
+	}
 	return []string{"ROLE_ADMIN"}, nil
 }
 
@@ -188,6 +452,7 @@ func (r *InMemUserRepository) InsertUserWithConfirmationID(ctx context.Context,
 	if confirmationID == confirmationIDError {
 		return nil, errors.New("error for tests")
 	}
+	user.Enabled = confirmationID == uuid.Nil
 	r.users = append(r.users, user)
 	return user, nil
 }
@@ -200,5 +465,123 @@ func (r *InMemUserRepository) FindUserIDByConfirmationID(ctx context.Context, co
 }
 
 func (r *InMemUserRepository) ConfirmUser(ctx context.Context, userID uuid.UUID) error {
+	for _, u := range r.users {
+		if u.ID == userID {
+			u.Enabled = true
+		}
+	}
+	return nil
+}
+
+func (r *InMemUserRepository) UpdateUserTimezone(ctx context.Context, organizationID uuid.UUID, username, timezone string) error {
+	for _, u := range r.users {
+		if u.Username == username {
+			u.Timezone = timezone
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemUserRepository) UpdateUserCalendarURL(ctx context.Context, organizationID uuid.UUID, username, calendarURL string) error {
+	for _, u := range r.users {
+		if u.Username == username {
+			u.CalendarURL = calendarURL
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemUserRepository) InsertUserWithRole(ctx context.Context, user *User, role string) (*User, error) {
+	user.Enabled = true
+	r.users = append(r.users, user)
+	r.roles[user.ID] = []string{role}
+	return user, nil
+}
+
+func (r *InMemUserRepository) InsertInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error) {
+	r.invitations = append(r.invitations, invitation)
+	return invitation, nil
+}
+
+func (r *InMemUserRepository) FindInvitationByID(ctx context.Context, invitationID uuid.UUID) (*Invitation, error) {
+	for _, i := range r.invitations {
+		if i.ID == invitationID {
+			return i, nil
+		}
+	}
+	return nil, ErrInvitationNotFound
+}
+
+func (r *InMemUserRepository) FindInvitations(ctx context.Context, organizationID uuid.UUID) ([]*Invitation, error) {
+	var invitations []*Invitation
+	for _, i := range r.invitations {
+		if i.OrganizationID == organizationID {
+			invitations = append(invitations, i)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *InMemUserRepository) DeleteInvitationByID(ctx context.Context, organizationID, invitationID uuid.UUID) error {
+	for i, invitation := range r.invitations {
+		if invitation.ID == invitationID && invitation.OrganizationID == organizationID {
+			r.invitations = append(r.invitations[:i], r.invitations[i+1:]...)
+			return nil
+		}
+	}
+	return ErrInvitationNotFound
+}
+
+func (r *InMemUserRepository) DeleteInvitationByEMail(ctx context.Context, organizationID uuid.UUID, email string) error {
+	for i, invitation := range r.invitations {
+		if invitation.EMail == email && invitation.OrganizationID == organizationID {
+			r.invitations = append(r.invitations[:i], r.invitations[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *InMemUserRepository) FindUsers(ctx context.Context, organizationID uuid.UUID) ([]*User, error) {
+	var users []*User
+	for _, u := range r.users {
+		if u.OrganizationID == organizationID {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (r *InMemUserRepository) FindUserByID(ctx context.Context, organizationID, userID uuid.UUID) (*User, error) {
+	for _, u := range r.users {
+		if u.ID == userID && u.OrganizationID == organizationID {
+			return u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *InMemUserRepository) UpdateUserRoles(ctx context.Context, organizationID, userID uuid.UUID, roles []string) error {
+	r.roles[userID] = roles
+	return nil
+}
+
+func (r *InMemUserRepository) UpdateUserEnabled(ctx context.Context, organizationID, userID uuid.UUID, enabled bool) error {
+	user, err := r.FindUserByID(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	user.Enabled = enabled
+	return nil
+}
+
+func (r *InMemUserRepository) UpdateUserPassword(ctx context.Context, organizationID, userID uuid.UUID, password string) error {
+	user, err := r.FindUserByID(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	user.Password = password
 	return nil
 }
@@ -3,10 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
+var ErrTimezoneInvalid = errors.New("timezone invalid")
+var ErrRoleInvalid = errors.New("role invalid")
+var ErrOwnUserNotChangeable = errors.New("own user can not be changed")
+var ErrGuestUserNotChangeable = errors.New("user of other organization can not be changed")
+
+// Roles are the roles which can be assigned to users
+var Roles = []string{"ROLE_USER", "ROLE_ADMIN"}
+
 func (a *app) ConfirmUser(ctx context.Context, userID uuid.UUID) error {
 	return a.RepositoryTxer.InTx(
 		ctx,
@@ -78,3 +88,195 @@ func (a *app) SetUpNewUser(ctx context.Context, user *User, confirmationID uuid.
 		},
 	)
 }
+
+// UpdateTimezone updates the time zone of the principal
+func (a *app) UpdateTimezone(ctx context.Context, principal *Principal, timezone string) (*Principal, error) {
+	if !IsValidTimezone(timezone) {
+		return nil, ErrTimezoneInvalid
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrTimezoneInvalid
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.UserRepository.UpdateUserTimezone(ctx, principal.OrganizationID, principal.Username, loc.String())
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	principalUpdated := *principal
+	principalUpdated.Timezone = loc.String()
+	return &principalUpdated, nil
+}
+
+// ReadUsers reads all users of the principal's organization with their roles
+func (a *app) ReadUsers(ctx context.Context, principal *Principal) ([]*User, error) {
+	users, err := a.UserRepository.FindUsers(ctx, principal.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		roles, err := a.UserRepository.FindRolesByUserID(ctx, principal.OrganizationID, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		user.Roles = roles
+	}
+
+	return users, nil
+}
+
+// ReadUser reads a user of the principal's organization with the roles
+func (a *app) ReadUser(ctx context.Context, principal *Principal, userID uuid.UUID) (*User, error) {
+	user, err := a.UserRepository.FindUserByID(ctx, principal.OrganizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := a.UserRepository.FindRolesByUserID(ctx, principal.OrganizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+
+	return user, nil
+}
+
+// UpdateUserRoles replaces the roles of a user, admins can not change their own roles
+func (a *app) UpdateUserRoles(ctx context.Context, principal *Principal, userID uuid.UUID, roles []string) (*User, error) {
+	if len(roles) == 0 {
+		return nil, ErrRoleInvalid
+	}
+	for _, role := range roles {
+		if !IsValidRole(role) {
+			return nil, ErrRoleInvalid
+		}
+	}
+
+	user, err := a.ReadUser(ctx, principal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == principal.Username {
+		return nil, ErrOwnUserNotChangeable
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			err := a.UserRepository.UpdateUserRoles(ctx, principal.OrganizationID, userID, roles)
+			if err != nil {
+				return err
+			}
+
+			// only username and roles are logged, the user itself holds the password
+			before := map[string]interface{}{"Username": user.Username, "Roles": user.Roles}
+			after := map[string]interface{}{"Username": user.Username, "Roles": roles}
+			return a.recordChange(ctx, principal, AuditActionUpdate, AuditEntityUser, userID, before, after)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Roles = roles
+	return user, nil
+}
+
+// UpdateUserEnabled enables or disables a user, admins can not disable themselves and
+// only the organization the user registered with can disable the user
+func (a *app) UpdateUserEnabled(ctx context.Context, principal *Principal, userID uuid.UUID, enabled bool) (*User, error) {
+	user, err := a.ReadUser(ctx, principal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == principal.Username {
+		return nil, ErrOwnUserNotChangeable
+	}
+
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.UserRepository.UpdateUserEnabled(ctx, principal.OrganizationID, userID, enabled)
+		},
+	)
+	if errors.Is(err, ErrUserNotFound) {
+		// the user is a member but was registered with another organization
+		return nil, ErrGuestUserNotChangeable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user.Enabled = enabled
+	return user, nil
+}
+
+// ResetUserPassword sets a new password for a user, only the organization
+// the user registered with can reset it since the password is shared by all organizations
+func (a *app) ResetUserPassword(ctx context.Context, principal *Principal, userID uuid.UUID, password string) error {
+	_, err := a.ReadUser(ctx, principal, userID)
+	if err != nil {
+		return err
+	}
+
+	encryptedPassword := a.EncryptPassword(password)
+	err = a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.UserRepository.UpdateUserPassword(ctx, principal.OrganizationID, userID, encryptedPassword)
+		},
+	)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrGuestUserNotChangeable
+	}
+	return err
+}
+
+// IsValidRole checks whether the role can be assigned to users
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// sendMailToAdmins sends the mail to all admins of the organization with an email address
+func (a *app) sendMailToAdmins(ctx context.Context, organizationID uuid.UUID, subject, body string) error {
+	users, err := a.UserRepository.FindUsers(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.EMail == "" {
+			continue
+		}
+
+		roles, err := a.UserRepository.FindRolesByUserID(ctx, organizationID, user.ID)
+		if err != nil {
+			return err
+		}
+		user.Roles = roles
+
+		if !user.HasRole("ROLE_ADMIN") {
+			continue
+		}
+
+		err = a.MailResource.SendMail(user.EMail, subject, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
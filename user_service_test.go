@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -86,3 +87,85 @@ func TestSetUpNewUserWithUserRepositoryError(t *testing.T) {
 	is.True(err != nil)
 	is.Equal(len(mailResource.mails), mailCount)
 }
+
+func TestUpdateUserRolesAndEnabled(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	userRepository := NewInMemUserRepository()
+	user := &User{
+		ID:             uuid.New(),
+		Username:       "user1@baralga.com",
+		OrganizationID: organizationIDSample,
+		Enabled:        true,
+	}
+	userRepository.users = append(userRepository.users, user)
+
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		UserRepository:     userRepository,
+		AuditLogRepository: NewInMemAuditLogRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	// Act
+	_, err := a.UpdateUserRoles(context.Background(), principal, user.ID, []string{"ROLE_USER"})
+	is.NoErr(err)
+	_, err = a.UpdateUserEnabled(context.Background(), principal, user.ID, false)
+	is.NoErr(err)
+
+	// Assert
+	userUpdated, err := a.ReadUser(context.Background(), principal, user.ID)
+	is.NoErr(err)
+	is.Equal(userUpdated.Roles, []string{"ROLE_USER"})
+	is.True(!userUpdated.Enabled)
+}
+
+func TestUpdateUserRolesWithInvalidRole(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	a := &app{
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: NewInMemUserRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.UpdateUserRoles(context.Background(), principal, uuid.New(), []string{"ROLE_SUPERHERO"})
+
+	// Assert
+	is.True(errors.Is(err, ErrRoleInvalid))
+}
+
+func TestUpdateOwnUserEnabled(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	userRepository := NewInMemUserRepository()
+	a := &app{
+		RepositoryTxer: NewInMemRepositoryTxer(),
+		UserRepository: userRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin@baralga.com",
+		OrganizationID: organizationIDSample,
+	}
+
+	// Act
+	_, err := a.UpdateUserEnabled(context.Background(), principal, userRepository.users[0].ID, false)
+
+	// Assert
+	is.True(errors.Is(err, ErrOwnUserNotChangeable))
+	is.True(userRepository.users[0].Enabled)
+}
@@ -18,7 +18,12 @@ const (
 )
 
 func ParseDateTime(dateTime string) (*time.Time, error) {
-	t, err := time.Parse(dateTimeFormat, dateTime)
+	return ParseDateTimeIn(dateTime, time.UTC)
+}
+
+// ParseDateTimeIn parses the date time as wall clock time in the given location
+func ParseDateTimeIn(dateTime string, loc *time.Location) (*time.Time, error) {
+	t, err := time.ParseInLocation(dateTimeFormat, dateTime, loc)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse date time from '%s'", dateTime)
 	}
@@ -26,13 +31,23 @@ func ParseDateTime(dateTime string) (*time.Time, error) {
 }
 
 func ParseDateTimeForm(dateTime string) (*time.Time, error) {
-	t, err := time.Parse(dateTimeFormatForm, dateTime)
+	return ParseDateTimeFormIn(dateTime, time.UTC)
+}
+
+// ParseDateTimeFormIn parses the date time of a form as wall clock time in the given location
+func ParseDateTimeFormIn(dateTime string, loc *time.Location) (*time.Time, error) {
+	t, err := time.ParseInLocation(dateTimeFormatForm, dateTime, loc)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse date time from '%s'", dateTime)
 	}
 	return &t, nil
 }
 
+// WallClockIn keeps the wall clock time of t but moves it to the given location
+func WallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
 func FormatTime(dateTime time.Time) string {
 	return dateTime.Format(timeFormat)
 }
@@ -2,6 +2,7 @@ package util
 
 import (
 	"testing"
+	"time"
 
 	"github.com/matryer/is"
 )
@@ -48,6 +49,32 @@ func TestParseDateTimeForm(t *testing.T) {
 	})
 }
 
+func TestParseDateTimeFormIn(t *testing.T) {
+	is := is.New(t)
+
+	loc, err := time.LoadLocation("America/New_York")
+	is.NoErr(err)
+
+	dateTime, err := ParseDateTimeFormIn("21.11.2020 22:30", loc)
+	is.NoErr(err)
+	is.Equal(dateTime.Day(), 21)
+	is.Equal(dateTime.Hour(), 22)
+	is.Equal(dateTime.UTC().Day(), 22)
+	is.Equal(dateTime.UTC().Hour(), 3)
+}
+
+func TestWallClockIn(t *testing.T) {
+	is := is.New(t)
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	is.NoErr(err)
+
+	dateTime := WallClockIn(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), loc)
+	is.Equal(dateTime.Hour(), 0)
+	is.Equal(dateTime.Location(), loc)
+	is.Equal(dateTime.UTC().Hour(), 23)
+}
+
 func TestParseDate(t *testing.T) {
 	is := is.New(t)
 
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types webhooks subscribe to
+const (
+	WebhookEventActivityCreated   string = "activity.created"
+	WebhookEventActivityUpdated   string = "activity.updated"
+	WebhookEventActivityDeleted   string = "activity.deleted"
+	WebhookEventProjectCreated    string = "project.created"
+	WebhookEventProjectArchived   string = "project.archived"
+	WebhookEventProjectUnarchived string = "project.unarchived"
+)
+
+// WebhookEventTypes are all event types in the order they are offered
+var WebhookEventTypes = []string{
+	WebhookEventActivityCreated,
+	WebhookEventActivityUpdated,
+	WebhookEventActivityDeleted,
+	WebhookEventProjectCreated,
+	WebhookEventProjectArchived,
+	WebhookEventProjectUnarchived,
+}
+
+// Status of webhook deliveries
+const (
+	WebhookDeliveryStatusPending   string = "pending"
+	WebhookDeliveryStatusDelivered string = "delivered"
+	WebhookDeliveryStatusFailed    string = "failed"
+)
+
+// maxWebhookDeliveryAttempts is the number of attempts after which a delivery is given up
+const maxWebhookDeliveryAttempts = 6
+
+// Webhook pushes the events of an organization to an url
+type Webhook struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	URL            string
+	Secret         string
+	EventTypes     []string
+	CreatedAt      time.Time
+}
+
+// WebhookDelivery is the delivery of an event to a webhook, pending
+// deliveries are retried with backoff until they fail for good
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	WebhookID      uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	LastError      string
+	CreatedAt      time.Time
+}
+
+// webhookPayload is the body posted to webhooks
+type webhookPayload struct {
+	ID             string          `json:"id"`
+	Event          string          `json:"event"`
+	OrganizationID string          `json:"organizationId"`
+	Username       string          `json:"username"`
+	OccurredAt     string          `json:"occurredAt"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Subscribes checks whether the webhook subscribed to the event type
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.EventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign signs the payload with the secret of the webhook as hex encoded HMAC-SHA256,
+// receivers verify the signature of the header X-Baralga-Signature
+func (w *Webhook) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsValidWebhookEventType checks whether webhooks can subscribe to the event type
+func IsValidWebhookEventType(eventType string) bool {
+	for _, e := range WebhookEventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEventOf maps the change of an entity to the event type of webhooks,
+// changes without an event type are not pushed
+func WebhookEventOf(action, entityType string) (string, bool) {
+	switch {
+	case entityType == AuditEntityActivity && action == AuditActionCreate:
+		return WebhookEventActivityCreated, true
+	case entityType == AuditEntityActivity && action == AuditActionUpdate:
+		return WebhookEventActivityUpdated, true
+	case entityType == AuditEntityActivity && action == AuditActionDelete:
+		return WebhookEventActivityDeleted, true
+	case entityType == AuditEntityProject && action == AuditActionCreate:
+		return WebhookEventProjectCreated, true
+	case entityType == AuditEntityProject && action == AuditActionArchive:
+		return WebhookEventProjectArchived, true
+	case entityType == AuditEntityProject && action == AuditActionUnarchive:
+		return WebhookEventProjectUnarchived, true
+	}
+	return "", false
+}
+
+// IsValidWebhookURL checks that the url is an https url of a public host, so webhooks can't
+// be used to call endpoints within the server's network, insecure allows http and any host
+func IsValidWebhookURL(webhookURL string, insecure bool) bool {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	if insecure {
+		return u.Scheme == "http" || u.Scheme == "https"
+	}
+
+	if u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip == nil || IsPublicWebhookIP(ip)
+}
+
+// IsPublicWebhookIP checks whether webhooks may be delivered to the ip,
+// which is neither loopback, link-local, private nor unspecified
+func IsPublicWebhookIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified()
+}
+
+// Claim reserves the delivery until the given time, so it isn't attempted
+// again while it's delivered or in case the attempt is never recorded
+func (d *WebhookDelivery) Claim(until time.Time) {
+	d.NextAttemptAt = &until
+}
+
+// Delivered marks the delivery as delivered
+func (d *WebhookDelivery) Delivered(responseStatus int, now time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryStatusDelivered
+	d.ResponseStatus = responseStatus
+	d.LastError = ""
+	d.LastAttemptAt = &now
+	d.NextAttemptAt = nil
+}
+
+// Failed records a failed attempt and schedules the next attempt with exponential backoff,
+// after the maximum number of attempts the delivery fails for good
+func (d *WebhookDelivery) Failed(responseStatus int, lastError string, now time.Time) {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.LastError = lastError
+	d.LastAttemptAt = &now
+
+	if d.Attempts >= maxWebhookDeliveryAttempts {
+		d.Status = WebhookDeliveryStatusFailed
+		d.NextAttemptAt = nil
+		return
+	}
+
+	nextAttemptAt := now.Add(WebhookBackoff(d.Attempts))
+	d.NextAttemptAt = &nextAttemptAt
+}
+
+// WebhookBackoff is the delay after the given number of failed attempts,
+// it doubles with each attempt starting at one minute
+func WebhookBackoff(attempts int) time.Duration {
+	return time.Minute * time.Duration(1<<(attempts-1))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWebhookSign(t *testing.T) {
+	is := is.New(t)
+
+	webhook := &Webhook{Secret: "my-secret"}
+
+	is.Equal(
+		"sha256=d6ab26a5d386138ade38ff92eb57b3d750b6140a3eeac448724998238ff799b9",
+		webhook.Sign([]byte(`{"event":"activity.created"}`)),
+	)
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	is := is.New(t)
+
+	webhook := &Webhook{EventTypes: []string{WebhookEventActivityCreated}}
+
+	is.True(webhook.Subscribes(WebhookEventActivityCreated))
+	is.True(!webhook.Subscribes(WebhookEventProjectArchived))
+}
+
+func TestWebhookEventOf(t *testing.T) {
+	is := is.New(t)
+
+	eventType, ok := WebhookEventOf(AuditActionArchive, AuditEntityProject)
+	is.True(ok)
+	is.Equal(WebhookEventProjectArchived, eventType)
+
+	eventType, ok = WebhookEventOf(AuditActionUnarchive, AuditEntityProject)
+	is.True(ok)
+	is.Equal(WebhookEventProjectUnarchived, eventType)
+
+	_, ok = WebhookEventOf(AuditActionUpdate, AuditEntityUser)
+	is.True(!ok)
+}
+
+func TestWebhookDeliveryFailedWithBackoff(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC)
+	delivery := &WebhookDelivery{Status: WebhookDeliveryStatusPending}
+
+	delivery.Failed(500, "unexpected status", now)
+	is.Equal(WebhookDeliveryStatusPending, delivery.Status)
+	is.Equal(now.Add(time.Minute), *delivery.NextAttemptAt)
+
+	delivery.Failed(500, "unexpected status", now)
+	is.Equal(now.Add(2*time.Minute), *delivery.NextAttemptAt)
+
+	for delivery.Attempts < maxWebhookDeliveryAttempts {
+		delivery.Failed(0, "connection refused", now)
+	}
+	is.Equal(WebhookDeliveryStatusFailed, delivery.Status)
+	is.True(delivery.NextAttemptAt == nil)
+	is.Equal("connection refused", delivery.LastError)
+}
+
+func TestWebhookDeliveryDelivered(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Now()
+	delivery := &WebhookDelivery{Status: WebhookDeliveryStatusPending, NextAttemptAt: &now}
+
+	delivery.Delivered(204, now)
+
+	is.Equal(WebhookDeliveryStatusDelivered, delivery.Status)
+	is.Equal(1, delivery.Attempts)
+	is.Equal(204, delivery.ResponseStatus)
+	is.True(delivery.NextAttemptAt == nil)
+}
+
+func TestIsValidWebhookURL(t *testing.T) {
+	is := is.New(t)
+
+	is.True(IsValidWebhookURL("https://example.com/hooks", false))
+	is.True(IsValidWebhookURL("https://93.184.216.34/hooks", false))
+	is.True(!IsValidWebhookURL("http://example.com/hooks", false))
+	is.True(!IsValidWebhookURL("https://localhost/hooks", false))
+	is.True(!IsValidWebhookURL("https://172.16.0.1/hooks", false))
+	is.True(!IsValidWebhookURL("https://[fe80::1]/hooks", false))
+	is.True(!IsValidWebhookURL("https:///hooks", false))
+
+	is.True(IsValidWebhookURL("http://127.0.0.1:8080/hooks", true))
+	is.True(!IsValidWebhookURL("ftp://example.com", true))
+}
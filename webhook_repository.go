@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+type WebhookRepository interface {
+	FindWebhooks(ctx context.Context, organizationID uuid.UUID) ([]*Webhook, error)
+	FindWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) (*Webhook, error)
+	InsertWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error)
+	DeleteWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) error
+	FindWebhookDeliveries(ctx context.Context, organizationID, webhookID uuid.UUID, limit int) ([]*WebhookDelivery, error)
+	FindDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+	InsertWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error)
+}
+
+// DbWebhookRepository is a SQL database repository for webhooks and their deliveries
+type DbWebhookRepository struct {
+	connPool *pgxpool.Pool
+}
+
+var _ WebhookRepository = (*DbWebhookRepository)(nil)
+
+// NewDbWebhookRepository creates a new SQL database repository for webhooks
+func NewDbWebhookRepository(connPool *pgxpool.Pool) *DbWebhookRepository {
+	return &DbWebhookRepository{
+		connPool: connPool,
+	}
+}
+
+func (r *DbWebhookRepository) FindWebhooks(ctx context.Context, organizationID uuid.UUID) ([]*Webhook, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT webhook_id, url, secret, event_types, created_at, org_id
+		 FROM webhooks
+		 WHERE org_id = $1
+		 ORDER BY created_at`,
+		organizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (r *DbWebhookRepository) FindWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) (*Webhook, error) {
+	row := r.connPool.QueryRow(
+		ctx,
+		`SELECT webhook_id, url, secret, event_types, created_at, org_id
+		 FROM webhooks
+		 WHERE org_id = $1 AND webhook_id = $2`,
+		organizationID, webhookID,
+	)
+
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (r *DbWebhookRepository) InsertWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO webhooks
+		   (webhook_id, url, secret, event_types, created_at, org_id)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6)`,
+		webhook.ID,
+		webhook.URL,
+		webhook.Secret,
+		webhook.EventTypes,
+		webhook.CreatedAt,
+		webhook.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (r *DbWebhookRepository) DeleteWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) error {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	row := tx.QueryRow(
+		ctx,
+		`DELETE FROM webhooks
+		 WHERE webhook_id = $1 AND org_id = $2
+		 RETURNING webhook_id`,
+		webhookID, organizationID,
+	)
+
+	var id string
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *DbWebhookRepository) FindWebhookDeliveries(ctx context.Context, organizationID, webhookID uuid.UUID, limit int) ([]*WebhookDelivery, error) {
+	rows, err := r.connPool.Query(
+		ctx,
+		`SELECT delivery_id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+		        last_attempt_at, response_status, last_error, created_at, org_id
+		 FROM webhook_deliveries
+		 WHERE org_id = $1 AND webhook_id = $2
+		 ORDER BY created_at DESC
+		 LIMIT $3`,
+		organizationID, webhookID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+// FindDueWebhookDeliveries reads the pending deliveries due for an attempt of all organizations,
+// it must be called within a transaction as the deliveries are locked until the transaction ends
+func (r *DbWebhookRepository) FindDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT delivery_id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+		        last_attempt_at, response_status, last_error, created_at, org_id
+		 FROM webhook_deliveries
+		 WHERE status = $1 AND next_attempt_at <= $2
+		 ORDER BY next_attempt_at
+		 LIMIT $3
+		 FOR UPDATE SKIP LOCKED`,
+		WebhookDeliveryStatusPending, now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *DbWebhookRepository) InsertWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`INSERT INTO webhook_deliveries
+		   (delivery_id, webhook_id, event_type, payload, status, attempts, next_attempt_at, created_at, org_id)
+		 VALUES
+		   ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		delivery.ID,
+		delivery.WebhookID,
+		delivery.EventType,
+		[]byte(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func (r *DbWebhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error) {
+	tx := ctx.Value(contextKeyTx).(pgx.Tx)
+
+	_, err := tx.Exec(
+		ctx,
+		`UPDATE webhook_deliveries
+		 SET status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5, response_status = $6, last_error = $7
+		 WHERE delivery_id = $1`,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.ResponseStatus,
+		delivery.LastError,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func scanWebhook(row pgx.Row) (*Webhook, error) {
+	webhook := &Webhook{}
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.EventTypes,
+		&webhook.CreatedAt,
+		&webhook.OrganizationID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func scanWebhookDeliveries(rows pgx.Rows) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		delivery := &WebhookDelivery{}
+
+		var (
+			payload        []byte
+			responseStatus *int
+			lastError      *string
+		)
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastAttemptAt,
+			&responseStatus,
+			&lastError,
+			&delivery.CreatedAt,
+			&delivery.OrganizationID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		delivery.Payload = payload
+		if responseStatus != nil {
+			delivery.ResponseStatus = *responseStatus
+		}
+		if lastError != nil {
+			delivery.LastError = *lastError
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	// skip in short mode
+	if testing.Short() {
+		return
+	}
+
+	is := is.New(t)
+
+	// Setup database
+	ctx := context.Background()
+	dbContainer, connPool, err := setupDatabase(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		err := dbContainer.Terminate(ctx)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	webhookRepository := NewDbWebhookRepository(connPool)
+	repositoryTxer := NewDbRepositoryTxer(connPool)
+
+	t.Run("FindNotExistingWebhook", func(t *testing.T) {
+		_, err := webhookRepository.FindWebhookByID(context.Background(), organizationIDSample, uuid.New())
+
+		is.True(errors.Is(err, ErrWebhookNotFound))
+	})
+
+	t.Run("InsertAndFindAndDeleteWebhookWithDeliveries", func(t *testing.T) {
+		webhook := &Webhook{
+			ID:             uuid.New(),
+			OrganizationID: organizationIDSample,
+			URL:            "https://example.com/hooks",
+			Secret:         "secret",
+			EventTypes:     []string{WebhookEventActivityCreated, WebhookEventProjectArchived},
+			CreatedAt:      time.Now(),
+		}
+
+		now := time.Now()
+		delivery := &WebhookDelivery{
+			ID:             uuid.New(),
+			OrganizationID: organizationIDSample,
+			WebhookID:      webhook.ID,
+			EventType:      WebhookEventActivityCreated,
+			Payload:        json.RawMessage(`{"event":"activity.created"}`),
+			Status:         WebhookDeliveryStatusPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+		}
+
+		err := repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				_, err := webhookRepository.InsertWebhook(ctx, webhook)
+				if err != nil {
+					return err
+				}
+				_, err = webhookRepository.InsertWebhookDelivery(ctx, delivery)
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		webhooks, err := webhookRepository.FindWebhooks(context.Background(), organizationIDSample)
+		is.NoErr(err)
+		is.Equal(1, len(webhooks))
+		is.Equal(webhook.EventTypes, webhooks[0].EventTypes)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				deliveries, err := webhookRepository.FindDueWebhookDeliveries(ctx, time.Now().Add(time.Second), 10)
+				if err != nil {
+					return err
+				}
+				is.Equal(1, len(deliveries))
+
+				deliveries[0].Failed(500, "unexpected status", time.Now())
+				_, err = webhookRepository.UpdateWebhookDelivery(ctx, deliveries[0])
+				return err
+			},
+		)
+		is.NoErr(err)
+
+		deliveries, err := webhookRepository.FindWebhookDeliveries(context.Background(), organizationIDSample, webhook.ID, 10)
+		is.NoErr(err)
+		is.Equal(1, len(deliveries))
+		is.Equal(1, deliveries[0].Attempts)
+		is.Equal(500, deliveries[0].ResponseStatus)
+		is.Equal("unexpected status", deliveries[0].LastError)
+
+		err = repositoryTxer.InTx(
+			context.Background(),
+			func(ctx context.Context) error {
+				return webhookRepository.DeleteWebhookByID(ctx, organizationIDSample, webhook.ID)
+			},
+		)
+		is.NoErr(err)
+
+		deliveries, err = webhookRepository.FindWebhookDeliveries(context.Background(), organizationIDSample, webhook.ID, 10)
+		is.NoErr(err)
+		is.Equal(0, len(deliveries))
+	})
+}
+
+type InMemWebhookRepository struct {
+	webhooks   []*Webhook
+	deliveries []*WebhookDelivery
+}
+
+var _ WebhookRepository = (*InMemWebhookRepository)(nil)
+
+func NewInMemWebhookRepository() *InMemWebhookRepository {
+	return &InMemWebhookRepository{
+		webhooks:   []*Webhook{},
+		deliveries: []*WebhookDelivery{},
+	}
+}
+
+func (r *InMemWebhookRepository) FindWebhooks(ctx context.Context, organizationID uuid.UUID) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	for _, w := range r.webhooks {
+		if w.OrganizationID == organizationID {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *InMemWebhookRepository) FindWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) (*Webhook, error) {
+	for _, w := range r.webhooks {
+		if w.ID == webhookID && w.OrganizationID == organizationID {
+			return w, nil
+		}
+	}
+	return nil, ErrWebhookNotFound
+}
+
+func (r *InMemWebhookRepository) InsertWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error) {
+	r.webhooks = append(r.webhooks, webhook)
+	return webhook, nil
+}
+
+func (r *InMemWebhookRepository) DeleteWebhookByID(ctx context.Context, organizationID, webhookID uuid.UUID) error {
+	for i, w := range r.webhooks {
+		if w.ID == webhookID && w.OrganizationID == organizationID {
+			r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return ErrWebhookNotFound
+}
+
+func (r *InMemWebhookRepository) FindWebhookDeliveries(ctx context.Context, organizationID, webhookID uuid.UUID, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := r.deliveries[i]
+		if d.OrganizationID == organizationID && d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *InMemWebhookRepository) FindDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	for _, d := range r.deliveries {
+		if len(deliveries) == limit {
+			break
+		}
+		if d.Status == WebhookDeliveryStatusPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *InMemWebhookRepository) InsertWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error) {
+	r.deliveries = append(r.deliveries, delivery)
+	return delivery, nil
+}
+
+func (r *InMemWebhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) (*WebhookDelivery, error) {
+	for i, d := range r.deliveries {
+		if d.ID == delivery.ID {
+			r.deliveries[i] = delivery
+			return delivery, nil
+		}
+	}
+	return delivery, nil
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrWebhookInvalid = errors.New("webhook invalid")
+
+const (
+	webhookWorkerInterval       = 15 * time.Second
+	webhookDeliveryBatchSize    = 20
+	webhookDeliveryHistorySize  = 100
+	webhookDeliveryErrorMaxSize = 500
+	webhookDeliveryClaimTimeout = 5 * time.Minute
+)
+
+// webhookClient only connects to public addresses, so host names resolving
+// to addresses within the server's network can't be used to call internal endpoints
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: controlWebhookDial,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+var insecureWebhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+func controlWebhookDial(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicWebhookIP(ip) {
+		return errors.Errorf("address %v not allowed", host)
+	}
+
+	return nil
+}
+
+// ReadWebhooks reads the webhooks of the principal's organization
+func (a *app) ReadWebhooks(ctx context.Context, principal *Principal) ([]*Webhook, error) {
+	return a.WebhookRepository.FindWebhooks(ctx, principal.OrganizationID)
+}
+
+// CreateWebhook creates a webhook pushing the events of the given types to the url
+func (a *app) CreateWebhook(ctx context.Context, principal *Principal, webhookURL, secret string, eventTypes []string) (*Webhook, error) {
+	if !IsValidWebhookURL(webhookURL, a.Config.WebhookAllowInsecure) {
+		return nil, ErrWebhookInvalid
+	}
+	if secret == "" || len(eventTypes) == 0 {
+		return nil, ErrWebhookInvalid
+	}
+	for _, eventType := range eventTypes {
+		if !IsValidWebhookEventType(eventType) {
+			return nil, ErrWebhookInvalid
+		}
+	}
+
+	webhook := &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: principal.OrganizationID,
+		URL:            webhookURL,
+		Secret:         secret,
+		EventTypes:     eventTypes,
+		CreatedAt:      time.Now(),
+	}
+
+	err := a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			_, err := a.WebhookRepository.InsertWebhook(ctx, webhook)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook together with its deliveries
+func (a *app) DeleteWebhook(ctx context.Context, principal *Principal, webhookID uuid.UUID) error {
+	return a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			return a.WebhookRepository.DeleteWebhookByID(ctx, principal.OrganizationID, webhookID)
+		},
+	)
+}
+
+// ReadWebhookDeliveries reads the webhook with its latest deliveries
+func (a *app) ReadWebhookDeliveries(ctx context.Context, principal *Principal, webhookID uuid.UUID) (*Webhook, []*WebhookDelivery, error) {
+	webhook, err := a.WebhookRepository.FindWebhookByID(ctx, principal.OrganizationID, webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, err := a.WebhookRepository.FindWebhookDeliveries(ctx, principal.OrganizationID, webhookID, webhookDeliveryHistorySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return webhook, deliveries, nil
+}
+
+// enqueueWebhookEvent queues the delivery of the event to all webhooks of the principal's organization
+// subscribed to it, it must be called within the transaction of the change so only stored changes are pushed
+func (a *app) enqueueWebhookEvent(ctx context.Context, principal *Principal, eventType string, data interface{}) error {
+	webhooks, err := a.WebhookRepository.FindWebhooks(ctx, principal.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = marshalWebhookPayload(principal, eventType, data, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := a.WebhookRepository.InsertWebhookDelivery(ctx, &WebhookDelivery{
+			ID:             uuid.New(),
+			OrganizationID: principal.OrganizationID,
+			WebhookID:      webhook.ID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         WebhookDeliveryStatusPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func marshalWebhookPayload(principal *Principal, eventType string, data interface{}, now time.Time) ([]byte, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&webhookPayload{
+		ID:             uuid.New().String(),
+		Event:          eventType,
+		OrganizationID: principal.OrganizationID.String(),
+		Username:       principal.Username,
+		OccurredAt:     now.Format(time.RFC3339),
+		Data:           dataJSON,
+	})
+}
+
+// DeliverDueWebhooks attempts to deliver the pending deliveries which are due,
+// it returns the number of attempted deliveries
+func (a *app) DeliverDueWebhooks(ctx context.Context) (int, error) {
+	deliveries, err := a.claimDueWebhookDeliveries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for _, delivery := range deliveries {
+		webhook, err := a.WebhookRepository.FindWebhookByID(ctx, delivery.OrganizationID, delivery.WebhookID)
+		if errors.Is(err, ErrWebhookNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("could not read webhook %v: %v", delivery.WebhookID, err)
+			continue
+		}
+
+		a.deliverWebhook(ctx, webhook, delivery)
+		attempted++
+
+		err = a.RepositoryTxer.InTx(
+			ctx,
+			func(ctx context.Context) error {
+				_, err := a.WebhookRepository.UpdateWebhookDelivery(ctx, delivery)
+				return err
+			},
+		)
+		if err != nil {
+			log.Printf("could not record webhook delivery %v: %v", delivery.ID, err)
+		}
+	}
+
+	return attempted, nil
+}
+
+// claimDueWebhookDeliveries reserves a batch of due deliveries in a short transaction,
+// so they are delivered outside of it and no other worker attempts them meanwhile
+func (a *app) claimDueWebhookDeliveries(ctx context.Context) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	err := a.RepositoryTxer.InTx(
+		ctx,
+		func(ctx context.Context) error {
+			now := time.Now()
+			dueDeliveries, err := a.WebhookRepository.FindDueWebhookDeliveries(ctx, now, webhookDeliveryBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, delivery := range dueDeliveries {
+				delivery.Claim(now.Add(webhookDeliveryClaimTimeout))
+				_, err := a.WebhookRepository.UpdateWebhookDelivery(ctx, delivery)
+				if err != nil {
+					return err
+				}
+			}
+
+			deliveries = dueDeliveries
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// deliverWebhook posts the signed payload of the delivery to the webhook and records the outcome
+func (a *app) deliverWebhook(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		delivery.Failed(0, truncateWebhookError(err.Error()), time.Now())
+		return
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Baralga-Webhook")
+	request.Header.Set("X-Baralga-Event", delivery.EventType)
+	request.Header.Set("X-Baralga-Delivery", delivery.ID.String())
+	request.Header.Set("X-Baralga-Signature", webhook.Sign(delivery.Payload))
+
+	response, err := a.webhookHTTPClient().Do(request)
+	if err != nil {
+		delivery.Failed(0, truncateWebhookError(err.Error()), time.Now())
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		delivery.Failed(response.StatusCode, fmt.Sprintf("unexpected status %v", response.Status), time.Now())
+		return
+	}
+
+	delivery.Delivered(response.StatusCode, time.Now())
+}
+
+func (a *app) webhookHTTPClient() *http.Client {
+	if a.Config.WebhookAllowInsecure {
+		return insecureWebhookClient
+	}
+	return webhookClient
+}
+
+func truncateWebhookError(message string) string {
+	if len(message) > webhookDeliveryErrorMaxSize {
+		return message[:webhookDeliveryErrorMaxSize]
+	}
+	return message
+}
+
+// StartWebhookWorker delivers the due webhook deliveries in the background in the given interval
+func (a *app) StartWebhookWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := a.DeliverDueWebhooks(ctx)
+				if err != nil {
+					log.Printf("could not deliver webhooks: %v", err)
+				}
+			}
+		}
+	}()
+}
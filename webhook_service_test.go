@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestCreateActivityDeliversWebhook(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	type receivedRequest struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	received := make(chan receivedRequest, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{
+			event:     r.Header.Get("X-Baralga-Event"),
+			signature: r.Header.Get("X-Baralga-Signature"),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhookRepository := NewInMemWebhookRepository()
+	a := &app{
+		Config:                 &config{WebhookAllowInsecure: true},
+		RepositoryTxer:         NewInMemRepositoryTxer(),
+		ActivityRepository:     NewInMemActivityRepository(),
+		ProjectRepository:      NewInMemProjectRepository(),
+		OrganizationRepository: NewInMemOrganizationRepository(),
+		AuditLogRepository:     NewInMemAuditLogRepository(),
+		WebhookRepository:      webhookRepository,
+		TimesheetRepository:    NewInMemTimesheetRepository(),
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}
+
+	webhook, err := a.CreateWebhook(context.Background(), principal, receiver.URL, "my-webhook-secret", []string{WebhookEventActivityCreated})
+	is.NoErr(err)
+
+	// Act
+	activity, err := a.CreateActivity(context.Background(), principal, &Activity{
+		Start:       time.Date(2021, 11, 10, 9, 0, 0, 0, time.UTC),
+		End:         time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC),
+		Description: "My Activity",
+		ProjectID:   projectIDSample,
+	})
+	is.NoErr(err)
+
+	attempted, err := a.DeliverDueWebhooks(context.Background())
+	is.NoErr(err)
+
+	// Assert
+	is.Equal(1, attempted)
+
+	request := <-received
+	is.Equal(WebhookEventActivityCreated, request.event)
+	is.Equal(webhook.Sign(request.body), request.signature)
+
+	var payload webhookPayload
+	is.NoErr(json.Unmarshal(request.body, &payload))
+	is.Equal(WebhookEventActivityCreated, payload.Event)
+
+	var data Activity
+	is.NoErr(json.Unmarshal(payload.Data, &data))
+	is.Equal(activity.ID, data.ID)
+
+	_, deliveries, err := a.ReadWebhookDeliveries(context.Background(), principal, webhook.ID)
+	is.NoErr(err)
+	is.Equal(1, len(deliveries))
+	is.Equal(WebhookDeliveryStatusDelivered, deliveries[0].Status)
+	is.Equal(http.StatusNoContent, deliveries[0].ResponseStatus)
+}
+
+func TestDeliverWebhookRetriesFailedDelivery(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	webhook := &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		URL:            receiver.URL,
+		Secret:         "my-webhook-secret",
+		EventTypes:     []string{WebhookEventProjectArchived},
+	}
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, webhook)
+
+	a := &app{
+		Config:             &config{WebhookAllowInsecure: true},
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		ProjectRepository:  NewInMemProjectRepository(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  webhookRepository,
+	}
+
+	principal := &Principal{
+		Username:       "admin",
+		OrganizationID: organizationIDSample,
+	}
+
+	err := a.ArchiveProject(context.Background(), principal, projectIDSample)
+	is.NoErr(err)
+
+	// Act
+	attempted, err := a.DeliverDueWebhooks(context.Background())
+	is.NoErr(err)
+	attemptedAgain, err := a.DeliverDueWebhooks(context.Background())
+	is.NoErr(err)
+
+	// Assert
+	is.Equal(1, attempted)
+	is.Equal(0, attemptedAgain) // next attempt is delayed by the backoff
+
+	delivery := webhookRepository.deliveries[0]
+	is.Equal(WebhookDeliveryStatusPending, delivery.Status)
+	is.Equal(1, delivery.Attempts)
+	is.Equal(http.StatusInternalServerError, delivery.ResponseStatus)
+	is.True(delivery.NextAttemptAt.After(time.Now()))
+}
+
+func TestUnsubscribedEventIsNotQueued(t *testing.T) {
+	is := is.New(t)
+
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		URL:            "https://example.com/hooks",
+		Secret:         "my-webhook-secret",
+		EventTypes:     []string{WebhookEventActivityDeleted},
+	})
+
+	a := &app{
+		RepositoryTxer:     NewInMemRepositoryTxer(),
+		ProjectRepository:  NewInMemProjectRepository(),
+		AuditLogRepository: NewInMemAuditLogRepository(),
+		WebhookRepository:  webhookRepository,
+	}
+
+	err := a.ArchiveProject(context.Background(), &Principal{OrganizationID: organizationIDSample}, projectIDSample)
+
+	is.NoErr(err)
+	is.Equal(0, len(webhookRepository.deliveries))
+}
+
+func TestCreateWebhookWithInvalidURL(t *testing.T) {
+	is := is.New(t)
+
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		WebhookRepository: NewInMemWebhookRepository(),
+	}
+
+	for _, webhookURL := range []string{
+		"ftp://example.com",
+		"http://example.com/hooks",
+		"https://localhost/hooks",
+		"https://127.0.0.1:8080/hooks",
+		"https://10.0.0.1/hooks",
+		"https://192.168.1.10/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hooks",
+	} {
+		_, err := a.CreateWebhook(context.Background(), &Principal{}, webhookURL, "my-webhook-secret", []string{WebhookEventActivityCreated})
+		is.True(errors.Is(err, ErrWebhookInvalid)) // url must be rejected
+	}
+}
+
+func TestDeliverWebhookRefusesInternalAddress(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	requested := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	now := time.Now()
+	webhook := &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		URL:            receiver.URL,
+		Secret:         "my-webhook-secret",
+		EventTypes:     []string{WebhookEventProjectArchived},
+	}
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, webhook)
+	webhookRepository.deliveries = append(webhookRepository.deliveries, &WebhookDelivery{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		WebhookID:      webhook.ID,
+		EventType:      WebhookEventProjectArchived,
+		Payload:        []byte("{}"),
+		Status:         WebhookDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+	})
+
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		WebhookRepository: webhookRepository,
+	}
+
+	// Act
+	attempted, err := a.DeliverDueWebhooks(context.Background())
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(1, attempted)
+	is.True(!requested)
+
+	delivery := webhookRepository.deliveries[0]
+	is.Equal(1, delivery.Attempts)
+	is.True(strings.Contains(delivery.LastError, "not allowed"))
+}
+
+func TestDeliverWebhookOfDeletedWebhook(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+
+	now := time.Now()
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.deliveries = append(webhookRepository.deliveries, &WebhookDelivery{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		WebhookID:      uuid.New(),
+		EventType:      WebhookEventProjectArchived,
+		Payload:        []byte("{}"),
+		Status:         WebhookDeliveryStatusPending,
+		NextAttemptAt:  &now,
+		CreatedAt:      now,
+	})
+
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		WebhookRepository: webhookRepository,
+	}
+
+	// Act
+	attempted, err := a.DeliverDueWebhooks(context.Background())
+
+	// Assert
+	is.NoErr(err)
+	is.Equal(0, attempted)
+	is.True(webhookRepository.deliveries[0].NextAttemptAt.After(now)) // claimed
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	hx "github.com/baralga/htmx"
+	"github.com/baralga/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/schema"
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+	"github.com/pkg/errors"
+)
+
+type webhookFormModel struct {
+	CSRFToken  string
+	URL        string   `validate:"required,url,max=500"`
+	Secret     string   `validate:"required,min=16,max=100"`
+	EventTypes []string `validate:"required,min=1"`
+}
+
+var webhookEventTitles = map[string]string{
+	WebhookEventActivityCreated:   "Activity created",
+	WebhookEventActivityUpdated:   "Activity updated",
+	WebhookEventActivityDeleted:   "Activity deleted",
+	WebhookEventProjectCreated:    "Project created",
+	WebhookEventProjectArchived:   "Project archived",
+	WebhookEventProjectUnarchived: "Project unarchived",
+}
+
+func (a *app) HandleWebhooksPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		webhooks, err := a.ReadWebhooks(r.Context(), principal)
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		formModel := webhookFormModel{
+			CSRFToken:  csrf.Token(r),
+			EventTypes: WebhookEventTypes,
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Webhooks",
+		}
+
+		util.RenderHTML(w, WebhooksPage(pageContext, formModel, webhooks))
+	}
+}
+
+func (a *app) HandleWebhookForm() http.HandlerFunc {
+	isProduction := a.isProduction()
+	validator := validator.New()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			a.renderWebhooksView(w, r, principal, isProduction, webhookFormModel{EventTypes: WebhookEventTypes}, "")
+			return
+		}
+
+		var formModel webhookFormModel
+		err = schema.NewDecoder().Decode(&formModel, r.PostForm)
+		if err != nil {
+			a.renderWebhooksView(w, r, principal, isProduction, webhookFormModel{EventTypes: WebhookEventTypes}, "")
+			return
+		}
+
+		err = validator.Struct(formModel)
+		if err != nil {
+			a.renderWebhooksView(w, r, principal, isProduction, formModel, "Please enter an url, a secret of at least 16 characters and select the events.")
+			return
+		}
+
+		_, err = a.CreateWebhook(r.Context(), principal, formModel.URL, formModel.Secret, formModel.EventTypes)
+		if errors.Is(err, ErrWebhookInvalid) {
+			a.renderWebhooksView(w, r, principal, isProduction, formModel, "Please enter an https url of a public host and select the events.")
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderWebhooksView(w, r, principal, isProduction, webhookFormModel{EventTypes: WebhookEventTypes}, "")
+	}
+}
+
+func (a *app) HandleDeleteWebhook() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		webhookID, err := uuid.Parse(chi.URLParam(r, "webhook-id"))
+		if err != nil {
+			http.Error(w, "Invalid webhook.", http.StatusBadRequest)
+			return
+		}
+
+		err = a.DeleteWebhook(r.Context(), principal, webhookID)
+		if errors.Is(err, ErrWebhookNotFound) {
+			http.Error(w, "Webhook not found.", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		a.renderWebhooksView(w, r, principal, isProduction, webhookFormModel{EventTypes: WebhookEventTypes}, "")
+	}
+}
+
+func (a *app) HandleWebhookDeliveriesPage() http.HandlerFunc {
+	isProduction := a.isProduction()
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := r.Context().Value(contextKeyPrincipal).(*Principal)
+
+		if !principal.HasRole("ROLE_ADMIN") {
+			http.Error(w, "No permission.", http.StatusForbidden)
+			return
+		}
+
+		webhookID, err := uuid.Parse(chi.URLParam(r, "webhook-id"))
+		if err != nil {
+			http.Error(w, "Invalid webhook.", http.StatusBadRequest)
+			return
+		}
+
+		webhook, deliveries, err := a.ReadWebhookDeliveries(r.Context(), principal, webhookID)
+		if errors.Is(err, ErrWebhookNotFound) {
+			http.Error(w, "Webhook not found.", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			util.RenderProblemHTML(w, isProduction, err)
+			return
+		}
+
+		pageContext := &pageContext{
+			principal:   principal,
+			currentPath: r.URL.Path,
+			title:       "Webhook Deliveries",
+		}
+
+		util.RenderHTML(w, WebhookDeliveriesPage(pageContext, webhook, deliveries))
+	}
+}
+
+func (a *app) renderWebhooksView(w http.ResponseWriter, r *http.Request, principal *Principal, isProduction bool, formModel webhookFormModel, errorMessage string) {
+	webhooks, err := a.ReadWebhooks(r.Context(), principal)
+	if err != nil {
+		util.RenderProblemHTML(w, isProduction, err)
+		return
+	}
+
+	formModel.CSRFToken = csrf.Token(r)
+
+	util.RenderHTML(w, WebhooksView(principal, formModel, webhooks, errorMessage))
+}
+
+func WebhooksPage(pageContext *pageContext, formModel webhookFormModel, webhooks []*Webhook) g.Node {
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Webhooks")),
+						P(
+							Class("text-muted"),
+							g.Text("Webhooks push changes of activities and projects to your systems as JSON. "),
+							g.Text("Each request is signed with the secret as HMAC-SHA256 in the header X-Baralga-Signature. "),
+							g.Text("Failed deliveries are retried with increasing delay."),
+						),
+					),
+					Div(
+						ID("baralga__webhooks"),
+						WebhooksView(pageContext.principal, formModel, webhooks, ""),
+					),
+				),
+			),
+		},
+	)
+}
+
+func WebhooksView(principal *Principal, formModel webhookFormModel, webhooks []*Webhook, errorMessage string) g.Node {
+	var webhooksTitle g.Node
+	if len(webhooks) > 0 {
+		webhooksTitle = H5(
+			Class("mt-4"),
+			g.Text("Active Webhooks"),
+		)
+	}
+
+	return Div(
+		WebhookForm(formModel, errorMessage),
+		webhooksTitle,
+		g.Group(
+			g.Map(len(webhooks), func(i int) g.Node {
+				webhook := webhooks[i]
+
+				return Div(
+					Class("card mt-2"),
+					Div(
+						Class("card-body"),
+						Div(
+							Class("d-flex justify-content-between"),
+							Span(
+								Class("flex-grow-1"),
+								Code(g.Text(webhook.URL)),
+								g.Group(
+									g.Map(len(webhook.EventTypes), func(j int) g.Node {
+										return Span(
+											Class("badge bg-secondary ms-2"),
+											g.Text(webhook.EventTypes[j]),
+										)
+									}),
+								),
+								Small(
+									Class("text-muted ms-2"),
+									g.Textf("created on %v", util.FormatDateDE(webhook.CreatedAt.In(principal.Location()))),
+								),
+							),
+							A(
+								Href(fmt.Sprintf("/webhooks/%v/deliveries", webhook.ID)),
+								hx.Boost(),
+								Class("btn btn-outline-secondary btn-sm ms-1"),
+								TitleAttr("Show Deliveries"),
+								I(Class("bi-clock-history")),
+							),
+							FormEl(
+								hx.Post(fmt.Sprintf("/webhooks/%v/delete", webhook.ID)),
+								hx.Target("#baralga__webhooks"),
+								hx.Swap("innerHTML"),
+								hx.Confirm(fmt.Sprintf("Do you really want to delete the webhook %v?", webhook.URL)),
+
+								Input(
+									Type("hidden"),
+									Name("CSRFToken"),
+									Value(formModel.CSRFToken),
+								),
+								Button(
+									Type("submit"),
+									Class("btn btn-outline-secondary btn-sm ms-1"),
+									TitleAttr("Delete Webhook"),
+									I(Class("bi-trash2")),
+								),
+							),
+						),
+					),
+				)
+			}),
+		),
+	)
+}
+
+func WebhookForm(formModel webhookFormModel, errorMessage string) g.Node {
+	selected := make(map[string]bool)
+	for _, eventType := range formModel.EventTypes {
+		selected[eventType] = true
+	}
+
+	return FormEl(
+		ID("webhook_form"),
+		Class("mb-4 mt-2"),
+		hx.Post("/webhooks"),
+		hx.Target("#baralga__webhooks"),
+		hx.Swap("innerHTML"),
+
+		g.If(
+			errorMessage != "",
+			Div(
+				Class("alert alert-warning text-center"),
+				Role("alert"),
+				Span(g.Text(errorMessage)),
+			),
+		),
+
+		Input(
+			Type("hidden"),
+			Name("CSRFToken"),
+			Value(formModel.CSRFToken),
+		),
+
+		Div(
+			Class("input-group mb-2"),
+			Input(
+				ID("WebhookURL"),
+				Type("url"),
+				Name("URL"),
+				MaxLength("500"),
+				Value(formModel.URL),
+				g.Attr("required", "required"),
+				Class("form-control"),
+				g.Attr("placeholder", "https://example.com/hooks/baralga"),
+			),
+			Input(
+				ID("WebhookSecret"),
+				Type("text"),
+				Name("Secret"),
+				MaxLength("100"),
+				g.Attr("minlength", "16"),
+				Value(formModel.Secret),
+				g.Attr("required", "required"),
+				Class("form-control"),
+				g.Attr("placeholder", "Secret"),
+			),
+			Button(
+				Class("btn btn-outline-primary"),
+				TitleAttr("Create Webhook"),
+				I(Class("bi-broadcast me-2")),
+				g.Text("Create"),
+			),
+		),
+		Div(
+			g.Group(
+				g.Map(len(WebhookEventTypes), func(i int) g.Node {
+					eventType := WebhookEventTypes[i]
+
+					var checked g.Node
+					if selected[eventType] {
+						checked = g.Attr("checked", "checked")
+					}
+
+					return Div(
+						Class("form-check form-check-inline"),
+						Input(
+							ID("WebhookEvent"+fmt.Sprint(i)),
+							Type("checkbox"),
+							Name("EventTypes"),
+							Value(eventType),
+							Class("form-check-input"),
+							checked,
+						),
+						Label(
+							Class("form-check-label"),
+							g.Attr("for", "WebhookEvent"+fmt.Sprint(i)),
+							g.Text(webhookEventTitles[eventType]),
+						),
+					)
+				}),
+			),
+		),
+	)
+}
+
+func WebhookDeliveriesPage(pageContext *pageContext, webhook *Webhook, deliveries []*WebhookDelivery) g.Node {
+	loc := pageContext.principal.Location()
+
+	var emptyNote g.Node
+	if len(deliveries) == 0 {
+		emptyNote = P(g.Text("No deliveries yet."))
+	}
+
+	return Page(
+		pageContext.title,
+		pageContext.currentPath,
+		[]g.Node{
+			Navbar(pageContext),
+			Section(
+				Class("full-center"),
+				Div(
+					Class("container"),
+					Div(
+						Class("mt-4 mb-4"),
+						H2(g.Text("Webhook Deliveries")),
+						P(
+							Class("text-muted"),
+							g.Text("Latest deliveries to "),
+							Code(g.Text(webhook.URL)),
+							g.Text("."),
+						),
+						A(
+							Href("/webhooks"),
+							hx.Boost(),
+							I(Class("bi-arrow-left me-2")),
+							g.Text("Back to Webhooks"),
+						),
+					),
+					emptyNote,
+					Table(
+						ID("webhook-deliveries"),
+						Class("table table-borderless table-striped"),
+						THead(
+							Tr(
+								Th(g.Text("Created")),
+								Th(g.Text("Event")),
+								Th(g.Text("Status")),
+								Th(g.Text("Attempts")),
+								Th(g.Text("Last Attempt")),
+								Th(g.Text("Response")),
+							),
+						),
+						TBody(
+							g.Group(
+								g.Map(len(deliveries), func(i int) g.Node {
+									return WebhookDeliveryRow(deliveries[i], loc)
+								}),
+							),
+						),
+					),
+				),
+			),
+		},
+	)
+}
+
+func WebhookDeliveryRow(delivery *WebhookDelivery, loc *time.Location) g.Node {
+	statusClass := "bg-secondary"
+	switch delivery.Status {
+	case WebhookDeliveryStatusDelivered:
+		statusClass = "bg-success"
+	case WebhookDeliveryStatusFailed:
+		statusClass = "bg-danger"
+	}
+
+	lastAttempt := ""
+	if delivery.LastAttemptAt != nil {
+		lastAttempt = formatWebhookTime(*delivery.LastAttemptAt, loc)
+	}
+	if delivery.Status == WebhookDeliveryStatusPending && delivery.NextAttemptAt != nil && delivery.Attempts > 0 {
+		lastAttempt = fmt.Sprintf("%v, next at %v", lastAttempt, util.FormatTime(delivery.NextAttemptAt.In(loc)))
+	}
+
+	response := delivery.LastError
+	if delivery.ResponseStatus != 0 && response == "" {
+		response = fmt.Sprint(delivery.ResponseStatus)
+	}
+
+	return Tr(
+		Td(g.Text(formatWebhookTime(delivery.CreatedAt, loc))),
+		Td(g.Text(delivery.EventType)),
+		Td(
+			Span(
+				Class("badge "+statusClass),
+				g.Text(delivery.Status),
+			),
+		),
+		Td(g.Text(fmt.Sprint(delivery.Attempts))),
+		Td(g.Text(lastAttempt)),
+		Td(Small(g.Text(response))),
+	)
+}
+
+func formatWebhookTime(t time.Time, loc *time.Location) string {
+	return fmt.Sprintf("%v %v", util.FormatDateDE(t.In(loc)), util.FormatTime(t.In(loc)))
+}
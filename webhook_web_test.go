@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/matryer/is"
+)
+
+func TestHandleWebhooksPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		URL:            "https://example.com/hooks",
+		Secret:         "my-webhook-secret",
+		EventTypes:     []string{WebhookEventActivityCreated},
+		CreatedAt:      time.Now(),
+	})
+
+	a := &app{
+		Config:            &config{},
+		WebhookRepository: webhookRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/webhooks", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleWebhooksPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Webhooks # Baralga"))
+	is.True(strings.Contains(htmlBody, "https://example.com/hooks"))
+}
+
+func TestHandleWebhooksPageAsUser(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	a := &app{
+		Config:            &config{},
+		WebhookRepository: NewInMemWebhookRepository(),
+	}
+
+	r, _ := http.NewRequest("GET", "/webhooks", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_USER"},
+	}))
+
+	a.HandleWebhooksPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusForbidden)
+}
+
+func TestHandleWebhookForm(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	webhookRepository := NewInMemWebhookRepository()
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		WebhookRepository: webhookRepository,
+	}
+
+	data := url.Values{}
+	data["URL"] = []string{"https://example.com/hooks"}
+	data["Secret"] = []string{"my-webhook-secret"}
+	data["EventTypes"] = []string{WebhookEventActivityCreated, WebhookEventActivityDeleted}
+
+	r, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleWebhookForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(1, len(webhookRepository.webhooks))
+	is.Equal([]string{WebhookEventActivityCreated, WebhookEventActivityDeleted}, webhookRepository.webhooks[0].EventTypes)
+}
+
+func TestHandleWebhookFormWithShortSecret(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	webhookRepository := NewInMemWebhookRepository()
+	a := &app{
+		Config:            &config{},
+		RepositoryTxer:    NewInMemRepositoryTxer(),
+		WebhookRepository: webhookRepository,
+	}
+
+	data := url.Values{}
+	data["URL"] = []string{"https://example.com/hooks"}
+	data["Secret"] = []string{"short"}
+	data["EventTypes"] = []string{WebhookEventActivityCreated}
+
+	r, _ := http.NewRequest("POST", "/webhooks", strings.NewReader(data.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	a.HandleWebhookForm()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(0, len(webhookRepository.webhooks))
+	is.True(strings.Contains(httpRec.Body.String(), "secret of at least 16 characters"))
+}
+
+func TestHandleWebhookDeliveriesPage(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	webhook := &Webhook{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		URL:            "https://example.com/hooks",
+		Secret:         "my-webhook-secret",
+		EventTypes:     []string{WebhookEventActivityCreated},
+		CreatedAt:      time.Now(),
+	}
+	webhookRepository := NewInMemWebhookRepository()
+	webhookRepository.webhooks = append(webhookRepository.webhooks, webhook)
+
+	now := time.Now()
+	webhookRepository.deliveries = append(webhookRepository.deliveries, &WebhookDelivery{
+		ID:             uuid.New(),
+		OrganizationID: organizationIDSample,
+		WebhookID:      webhook.ID,
+		EventType:      WebhookEventActivityCreated,
+		Status:         WebhookDeliveryStatusFailed,
+		Attempts:       6,
+		LastAttemptAt:  &now,
+		LastError:      "connection refused",
+		CreatedAt:      now,
+	})
+
+	a := &app{
+		Config:            &config{},
+		WebhookRepository: webhookRepository,
+	}
+
+	r, _ := http.NewRequest("GET", "/webhooks/"+webhook.ID.String()+"/deliveries", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contextKeyPrincipal, &Principal{
+		OrganizationID: organizationIDSample,
+		Roles:          []string{"ROLE_ADMIN"},
+	}))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("webhook-id", webhook.ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	a.HandleWebhookDeliveriesPage()(httpRec, r)
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+
+	htmlBody := httpRec.Body.String()
+	is.True(strings.Contains(htmlBody, "Webhook Deliveries # Baralga"))
+	is.True(strings.Contains(htmlBody, "connection refused"))
+}